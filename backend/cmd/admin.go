@@ -0,0 +1,140 @@
+package main
+
+import (
+    "bufio"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+
+    "golang.org/x/term"
+
+    "xcloud-backend/internal/user"
+    "xcloud-backend/pkg/database"
+)
+
+// 密码不通过命令行参数传入，避免出现在进程列表和shell历史中
+const envResetPassword = "XCLOUD_RESET_PASSWORD"
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// runCreateAdmin 创建管理员用户
+// 用户名、邮箱可通过参数或环境变量提供，密码通过环境变量或交互输入提供
+func runCreateAdmin(args []string) error {
+    fs := newFlagSet("create-admin", "create-admin [-username 用户名] [-email 邮箱]")
+    username := fs.String("username", os.Getenv(database.EnvAdminUsername), "用户名（环境变量 "+database.EnvAdminUsername+"）")
+    email := fs.String("email", os.Getenv(database.EnvAdminEmail), "邮箱（环境变量 "+database.EnvAdminEmail+"）")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
+    var err error
+    if *username == "" {
+        if *username, err = prompt("用户名: "); err != nil {
+            return err
+        }
+    }
+    if *email == "" {
+        if *email, err = prompt("邮箱: "); err != nil {
+            return err
+        }
+    }
+    password := os.Getenv(database.EnvAdminPassword)
+    if password == "" {
+        if password, err = promptNewPassword(); err != nil {
+            return err
+        }
+    }
+    if len(password) < user.MinAdminPasswordLength {
+        return fmt.Errorf("密码长度不能少于%d位", user.MinAdminPasswordLength)
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    admin, err := user.NewService(db).CreateAdmin(*username, *email, password)
+    if err != nil {
+        return fmt.Errorf("创建管理员失败: %w", err)
+    }
+
+    fmt.Printf("管理员创建成功: %s (%s)\n", admin.Username, admin.ID)
+    return nil
+}
+
+// runResetPassword 重置用户密码
+func runResetPassword(args []string) error {
+    fs := newFlagSet("reset-password", "reset-password -username 用户名")
+    username := fs.String("username", "", "用户名")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if *username == "" {
+        fs.Usage()
+        return errors.New("缺少用户名")
+    }
+
+    password := os.Getenv(envResetPassword)
+    if password == "" {
+        var err error
+        if password, err = promptNewPassword(); err != nil {
+            return err
+        }
+    }
+    if len(password) < user.MinAdminPasswordLength {
+        return fmt.Errorf("密码长度不能少于%d位", user.MinAdminPasswordLength)
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    if err := user.NewService(db).ResetPassword(*username, password); err != nil {
+        return fmt.Errorf("重置密码失败: %w", err)
+    }
+
+    fmt.Printf("用户 %s 的密码已重置\n", *username)
+    return nil
+}
+
+// prompt 读取一行交互输入
+func prompt(label string) (string, error) {
+    if !term.IsTerminal(int(os.Stdin.Fd())) {
+        return "", fmt.Errorf("非交互环境，请通过参数或环境变量提供%s", strings.TrimSuffix(label, ": "))
+    }
+    fmt.Print(label)
+    line, err := stdin.ReadString('\n')
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSpace(line), nil
+}
+
+// promptNewPassword 交互输入新密码并确认
+func promptNewPassword() (string, error) {
+    fd := int(os.Stdin.Fd())
+    if !term.IsTerminal(fd) {
+        return "", errors.New("非交互环境，请通过环境变量提供密码")
+    }
+
+    fmt.Print("密码: ")
+    first, err := term.ReadPassword(fd)
+    fmt.Println()
+    if err != nil {
+        return "", err
+    }
+    fmt.Print("确认密码: ")
+    second, err := term.ReadPassword(fd)
+    fmt.Println()
+    if err != nil {
+        return "", err
+    }
+    if string(first) != string(second) {
+        return "", errors.New("两次输入的密码不一致")
+    }
+    return string(first), nil
+}
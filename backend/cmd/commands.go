@@ -0,0 +1,72 @@
+package main
+
+import (
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+)
+
+// command 命令行子命令
+type command struct {
+    name    string
+    summary string
+    run     func(args []string) error
+}
+
+// commands 返回全部子命令，不带子命令运行时默认执行 serve
+func commands() []command {
+    return []command{
+        {"serve", "启动API服务", runServe},
+        {"migrate", "数据库迁移（up/down/status/force）", runMigrate},
+        {"create-admin", "创建管理员用户", runCreateAdmin},
+        {"reset-password", "重置用户密码", runResetPassword},
+        {"create-partition", "创建账单月度分表", runCreatePartition},
+        {"sync", "云平台账单同步（run）", runSync},
+        {"commission", "返佣计算（recalc）", runCommission},
+        {"reconcile", "账单对账（run）", runReconcile},
+        {"settlement", "返佣结算关账（close/close-due）", runSettlement},
+    }
+}
+
+// dispatch 根据命令行参数执行子命令
+func dispatch(args []string) error {
+    name := "serve"
+    if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+        name, args = args[0], args[1:]
+    }
+
+    if name == "help" {
+        printUsage()
+        return nil
+    }
+    for _, cmd := range commands() {
+        if cmd.name == name {
+            return cmd.run(args)
+        }
+    }
+
+    printUsage()
+    return fmt.Errorf("未知的命令: %s", name)
+}
+
+func printUsage() {
+    fmt.Fprintln(os.Stderr, "用法: xcloud <命令> [参数]")
+    fmt.Fprintln(os.Stderr)
+    fmt.Fprintln(os.Stderr, "命令:")
+    for _, cmd := range commands() {
+        fmt.Fprintf(os.Stderr, "  %-18s %s\n", cmd.name, cmd.summary)
+    }
+    fmt.Fprintln(os.Stderr)
+    fmt.Fprintln(os.Stderr, "使用 xcloud <命令> -h 查看命令参数")
+}
+
+// newFlagSet 创建子命令参数解析器
+func newFlagSet(name, usage string) *flag.FlagSet {
+    fs := flag.NewFlagSet(name, flag.ContinueOnError)
+    fs.Usage = func() {
+        fmt.Fprintf(fs.Output(), "用法: xcloud %s\n\n参数:\n", usage)
+        fs.PrintDefaults()
+    }
+    return fs
+}
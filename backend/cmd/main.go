@@ -2,6 +2,8 @@ package main
 
 import (
     "context"
+    "errors"
+    "flag"
     "fmt"
     "net/http"
     "os"
@@ -10,16 +12,37 @@ import (
     "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/go-redis/redis/v8"
     "github.com/spf13/viper"
     "gorm.io/gorm"
 
     "xcloud-backend/internal/auth"
+    "xcloud-backend/internal/approval"
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/internal/billimport"
+    "xcloud-backend/internal/commission"
+    "xcloud-backend/internal/custimport"
     "xcloud-backend/internal/customer"
     "xcloud-backend/internal/contract"
+    "xcloud-backend/internal/credit"
+    "xcloud-backend/internal/currency"
+    "xcloud-backend/internal/events"
+    "xcloud-backend/internal/export"
+    "xcloud-backend/internal/notify"
+    "xcloud-backend/internal/reconcile"
+    "xcloud-backend/internal/report"
+    "xcloud-backend/internal/search"
+    "xcloud-backend/internal/settlement"
+    "xcloud-backend/internal/statement"
+    "xcloud-backend/internal/sysconfig"
     "xcloud-backend/internal/user"
+    "xcloud-backend/internal/webhook"
+    "xcloud-backend/pkg/config"
     "xcloud-backend/pkg/database"
+    "xcloud-backend/pkg/i18n"
     "xcloud-backend/pkg/logger"
     "xcloud-backend/pkg/middleware"
+    "xcloud-backend/pkg/storage"
     "xcloud-backend/docs"
 
     swaggerFiles "github.com/swaggo/files"
@@ -46,32 +69,94 @@ import (
 // @description JWT Token, 格式: Bearer <token>
 
 func main() {
-    // 初始化配置
-    initConfig()
+    // 加载并校验配置
+    cfg, err := config.Load()
+    if err != nil {
+        fmt.Fprintln(os.Stderr, err)
+        os.Exit(1)
+    }
 
     // 初始化日志
     logger.Init()
+    if cfg.ConfigFile == "" {
+        logger.GetLogger().Warn("未找到配置文件，使用默认配置和环境变量")
+    }
+
+    if err := dispatch(os.Args[1:]); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return
+        }
+        logger.GetLogger().Fatal(err)
+    }
+}
+
+// runServe 启动API服务
+func runServe(args []string) error {
+    fs := newFlagSet("serve", "serve")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+
     log := logger.GetLogger()
 
     // 初始化数据库
     db, err := database.InitDB()
     if err != nil {
-        log.Fatal("数据库初始化失败:", err)
+        return fmt.Errorf("数据库初始化失败: %w", err)
+    }
+
+    // 检查数据库版本
+    if err := checkSchema(db); err != nil {
+        return fmt.Errorf("数据库版本检查失败: %w", err)
+    }
+
+    // 注册参数校验错误翻译
+    if err := i18n.RegisterValidator(); err != nil {
+        return fmt.Errorf("参数校验翻译注册失败: %w", err)
     }
 
     // 初始化Redis
     rdb, err := database.InitRedis()
     if err != nil {
-        log.Fatal("Redis初始化失败:", err)
+        return fmt.Errorf("Redis初始化失败: %w", err)
     }
 
     // 初始化基础数据
     if err := database.InitializeData(db); err != nil {
-        log.Fatal("基础数据初始化失败:", err)
+        return fmt.Errorf("基础数据初始化失败: %w", err)
+    }
+
+    // 订阅系统配置变更通知
+    listenCtx, stopListen := context.WithCancel(context.Background())
+    defer stopListen()
+    sysconfig.Listen(listenCtx, rdb)
+
+    // 订阅各实例发布的实时事件，推送给本实例的SSE连接
+    events.Listen(listenCtx, rdb)
+
+    // 初始化文件存储
+    if err := storage.Setup(); err != nil {
+        return fmt.Errorf("文件存储初始化失败: %w", err)
     }
 
+    // 注册通知渠道，启动合同到期的每日处理、信用额度检查和Webhook投递
+    notify.Setup(listenCtx, db)
+    contract.StartExpiryScheduler(listenCtx, contract.NewService(db), rdb)
+    credit.StartMonitor(listenCtx, credit.NewService(db))
+    webhook.StartDispatcher(listenCtx, webhook.NewService(db))
+
+    // 补全已有客户公司名称的拼音首字母（搜索用）
+    go func() {
+        count, err := customer.NewService(db).BackfillInitials()
+        if err != nil {
+            log.Error("补全客户拼音首字母失败:", err)
+        } else if count > 0 {
+            log.Info("已补全客户拼音首字母:", count)
+        }
+    }()
+
     // 设置Gin模式
-    if viper.GetString("app.mode") == "production" {
+    if viper.GetString("app.mode") == "release" || viper.GetString("app.mode") == "production" {
         gin.SetMode(gin.ReleaseMode)
     }
 
@@ -80,8 +165,10 @@ func main() {
 
     // 配置服务器
     srv := &http.Server{
-        Addr:    ":" + viper.GetString("server.port"),
-        Handler: router,
+        Addr:         ":" + viper.GetString("server.port"),
+        Handler:      router,
+        ReadTimeout:  time.Duration(viper.GetInt("server.read_timeout")) * time.Second,
+        WriteTimeout: time.Duration(viper.GetInt("server.write_timeout")) * time.Second,
     }
 
     // 启动服务器
@@ -102,36 +189,14 @@ func main() {
     defer cancel()
 
     if err := srv.Shutdown(ctx); err != nil {
-        log.Fatal("服务器强制关闭:", err)
+        return fmt.Errorf("服务器强制关闭: %w", err)
     }
 
     log.Info("服务器已关闭")
+    return nil
 }
 
-func initConfig() {
-    viper.SetConfigName("config")
-    viper.SetConfigType("yaml")
-    viper.AddConfigPath("./configs")
-    viper.AddConfigPath(".")
-
-    // 设置默认值
-    viper.SetDefault("server.port", "8080")
-    viper.SetDefault("app.mode", "debug")
-    viper.SetDefault("database.host", "localhost")
-    viper.SetDefault("database.port", 5432)
-    viper.SetDefault("database.name", "xcloud")
-    viper.SetDefault("database.username", "xcloud")
-    viper.SetDefault("database.password", "xcloud123")
-    viper.SetDefault("redis.addr", "localhost:6379")
-    viper.SetDefault("redis.password", "")
-    viper.SetDefault("redis.db", 0)
-
-    if err := viper.ReadInConfig(); err != nil {
-        fmt.Printf("配置文件读取失败，使用默认配置: %v\n", err)
-    }
-}
-
-func setupRouter(db *gorm.DB, rdb interface{}) *gin.Engine {
+func setupRouter(db *gorm.DB, rdb *redis.Client) *gin.Engine {
     router := gin.New()
 
     // 中间件
@@ -159,6 +224,12 @@ func setupRouter(db *gorm.DB, rdb interface{}) *gin.Engine {
         authGroup := v1.Group("/auth")
         auth.RegisterRoutes(authGroup, db)
 
+        // 合同附件签名下载（不需要JWT认证）
+        contract.RegisterPublicRoutes(v1.Group("/contracts"), db)
+
+        // 实时事件推送（路由自行认证，支持通过查询参数传递一次性订阅凭证）
+        events.RegisterRoutes(v1.Group("/events"))
+
         // 需要认证的路由
         authenticated := v1.Group("/")
         authenticated.Use(middleware.JWTAuth())
@@ -170,10 +241,64 @@ func setupRouter(db *gorm.DB, rdb interface{}) *gin.Engine {
             // 客户管理路由
             customerGroup := authenticated.Group("/customers")
             customer.RegisterRoutes(customerGroup, db)
+            statement.RegisterCustomerRoutes(customerGroup, db)
+            credit.RegisterCustomerRoutes(customerGroup, db)
+            custimport.RegisterCustomerRoutes(customerGroup, db)
+
+            // 客户和合同搜索路由
+            searchGroup := authenticated.Group("/search")
+            search.RegisterRoutes(searchGroup, db)
 
             // 合同管理路由
             contractGroup := authenticated.Group("/contracts")
             contract.RegisterRoutes(contractGroup, db)
+            approval.RegisterContractRoutes(contractGroup, db)
+
+            // 合同审批路由
+            approvalGroup := authenticated.Group("/approvals")
+            approval.RegisterRoutes(approvalGroup, db)
+
+            // 账单明细与账单导入路由
+            billingGroup := authenticated.Group("/billing")
+            billing.RegisterRoutes(billingGroup, db)
+            billimport.RegisterRoutes(billingGroup.Group("/imports"), db)
+
+            // 汇率路由
+            rateGroup := authenticated.Group("/exchange-rates")
+            currency.RegisterRoutes(rateGroup, db)
+
+            // 返佣计算与结算路由
+            commissionGroup := authenticated.Group("/commission")
+            commission.RegisterRoutes(commissionGroup, db)
+            settlement.RegisterRoutes(commissionGroup, db)
+
+            // 客户对账单路由
+            statementGroup := authenticated.Group("/statements")
+            statement.RegisterRoutes(statementGroup, db)
+
+            // 对账路由
+            reconcileGroup := authenticated.Group("/reconciliations")
+            reconcile.RegisterRoutes(reconcileGroup, db)
+
+            // 统计报表路由
+            reportGroup := authenticated.Group("/reports")
+            report.RegisterRoutes(reportGroup, db)
+
+            // 数据导出路由
+            exportGroup := authenticated.Group("/exports")
+            export.RegisterRoutes(exportGroup, db)
+
+            // 通知中心路由
+            notificationGroup := authenticated.Group("/notifications")
+            notify.RegisterRoutes(notificationGroup, db)
+
+            // 系统配置路由
+            configGroup := authenticated.Group("/system-configs")
+            sysconfig.RegisterRoutes(configGroup, db, rdb)
+
+            // Webhook路由
+            webhookGroup := authenticated.Group("/webhooks")
+            webhook.RegisterRoutes(webhookGroup, db)
         }
     }
 
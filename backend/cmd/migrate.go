@@ -0,0 +1,182 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "strconv"
+
+    "github.com/spf13/viper"
+    "gorm.io/gorm"
+
+    "xcloud-backend/internal/approval"
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/internal/commission"
+    "xcloud-backend/internal/contract"
+    "xcloud-backend/internal/credit"
+    "xcloud-backend/internal/currency"
+    "xcloud-backend/internal/customer"
+    "xcloud-backend/internal/datasync"
+    "xcloud-backend/internal/export"
+    "xcloud-backend/internal/notify"
+    "xcloud-backend/internal/reconcile"
+    "xcloud-backend/internal/settlement"
+    "xcloud-backend/internal/statement"
+    "xcloud-backend/internal/sysconfig"
+    "xcloud-backend/internal/user"
+    "xcloud-backend/internal/webhook"
+    "xcloud-backend/migrations"
+    "xcloud-backend/pkg/database"
+    "xcloud-backend/pkg/logger"
+    "xcloud-backend/pkg/migrate"
+)
+
+const migrateUsage = `用法: xcloud migrate <命令>
+
+命令:
+  up            执行全部待执行的迁移
+  down [N]      回滚最近N个迁移（默认1）
+  status        查看迁移状态
+  force <版本>  将数据库标记为指定版本（修复dirty状态，或为已执行schema.sql的数据库建立基线）
+`
+
+// newMigrator 创建迁移执行器
+func newMigrator(db *gorm.DB) (*migrate.Migrator, error) {
+    sqlDB, err := db.DB()
+    if err != nil {
+        return nil, err
+    }
+    m, err := migrate.New(sqlDB, migrations.FS)
+    if err != nil {
+        return nil, err
+    }
+    m.SetLogger(logger.GetLogger().Infof)
+    return m, nil
+}
+
+// checkSchema 启动前检查数据库版本，存在待执行或dirty迁移时拒绝启动
+func checkSchema(db *gorm.DB) error {
+    m, err := newMigrator(db)
+    if err != nil {
+        return err
+    }
+
+    ctx := context.Background()
+    if viper.GetBool("database.auto_migrate") {
+        if err := m.Up(ctx); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+            return err
+        }
+    }
+    if err := m.Check(ctx); err != nil {
+        return err
+    }
+
+    return database.VerifySchema(db,
+        &user.User{},
+        &customer.Customer{},
+        &customer.StatusHistory{},
+        &contract.Contract{},
+        &commission.Rule{},
+        &commission.Record{},
+        &commission.Run{},
+        &billing.BillingData{},
+        &billing.SyncLog{},
+        &datasync.CloudProvider{},
+        &datasync.CustomerCloudConfig{},
+        &sysconfig.SystemConfig{},
+        &reconcile.Run{},
+        &reconcile.Discrepancy{},
+        &currency.ExchangeRate{},
+        &settlement.Settlement{},
+        &settlement.PayoutBatch{},
+        &settlement.PayoutItem{},
+        &statement.Job{},
+        &statement.Statement{},
+        &export.Job{},
+        &contract.Reminder{},
+        &contract.Version{},
+        &contract.Attachment{},
+        &approval.Chain{},
+        &approval.Step{},
+        &approval.Approval{},
+        &approval.Task{},
+        &credit.Alert{},
+        &webhook.Subscription{},
+        &webhook.Delivery{},
+        &notify.Notification{},
+        &notify.Preference{},
+        &notify.Template{},
+    )
+}
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) error {
+    if len(args) == 0 || args[0] == "-h" || args[0] == "help" {
+        fmt.Fprint(os.Stderr, migrateUsage)
+        return errors.New("缺少迁移命令")
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    m, err := newMigrator(db)
+    if err != nil {
+        return err
+    }
+
+    ctx := context.Background()
+    switch args[0] {
+    case "up":
+        err = m.Up(ctx)
+        if errors.Is(err, migrate.ErrNoChange) {
+            fmt.Println("数据库已是最新版本")
+            return nil
+        }
+        return err
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            if steps, err = strconv.Atoi(args[1]); err != nil {
+                return fmt.Errorf("回滚步数无效: %s", args[1])
+            }
+        }
+        err = m.Down(ctx, steps)
+        if errors.Is(err, migrate.ErrNoChange) {
+            fmt.Println("没有可回滚的迁移")
+            return nil
+        }
+        return err
+    case "status":
+        st, err := m.Status(ctx)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("当前版本: %d (最新: %d)\n", st.Current, m.Latest())
+        if st.Dirty {
+            fmt.Println("状态: dirty")
+        }
+        for _, v := range st.Applied {
+            fmt.Printf("  [已执行] %04d  %s\n", v.Version, v.AppliedAt.Format("2006-01-02 15:04:05"))
+        }
+        for _, mig := range st.Pending {
+            fmt.Printf("  [待执行] %04d_%s\n", mig.Version, mig.Name)
+        }
+        return nil
+    case "force":
+        if len(args) < 2 {
+            return errors.New("缺少版本号")
+        }
+        version, err := strconv.ParseInt(args[1], 10, 64)
+        if err != nil {
+            return fmt.Errorf("版本号无效: %s", args[1])
+        }
+        return m.Force(ctx, version)
+    default:
+        fmt.Fprint(os.Stderr, migrateUsage)
+        return fmt.Errorf("未知的迁移命令: %s", args[0])
+    }
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/internal/commission"
+    "xcloud-backend/internal/contract"
+    "xcloud-backend/internal/customer"
+    "xcloud-backend/internal/datasync"
+    "xcloud-backend/internal/reconcile"
+    "xcloud-backend/internal/settlement"
+    "xcloud-backend/pkg/database"
+    "xcloud-backend/pkg/logger"
+)
+
+// runCreatePartition 创建账单月度分表
+func runCreatePartition(args []string) error {
+    fs := newFlagSet("create-partition", "create-partition -month YYYY-MM [-count N]")
+    month := fs.String("month", billing.PeriodOf(time.Now()), "起始月份（YYYY-MM）")
+    count := fs.Int("count", 1, "从起始月份开始创建的月数")
+    if err := fs.Parse(args); err != nil {
+        return err
+    }
+    if *count < 1 {
+        return errors.New("月数必须大于0")
+    }
+
+    start, err := billing.ParsePeriod(*month)
+    if err != nil {
+        return err
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    svc := billing.NewService(db)
+    for i := 0; i < *count; i++ {
+        m := start.AddDate(0, i, 0)
+        if err := svc.EnsurePartition(m); err != nil {
+            return fmt.Errorf("创建分表 %s 失败: %w", billing.PartitionTable(m), err)
+        }
+        fmt.Printf("分表 %s 已就绪\n", billing.PartitionTable(m))
+    }
+    return nil
+}
+
+// runSync 云平台账单同步
+func runSync(args []string) error {
+    if len(args) == 0 || args[0] != "run" {
+        return errors.New("用法: xcloud sync run -customer 客户ID或编码 -provider 云平台 -period YYYY-MM")
+    }
+
+    fs := newFlagSet("sync run", "sync run -customer 客户ID或编码 -provider 云平台 -period YYYY-MM")
+    customerRef := fs.String("customer", "", "客户ID或客户编码")
+    provider := fs.String("provider", "", "云平台（tencent/alibaba/huawei/aws）")
+    period := fs.String("period", billing.PeriodOf(time.Now()), "计费周期（YYYY-MM）")
+    if err := fs.Parse(args[1:]); err != nil {
+        return err
+    }
+    if *customerRef == "" || *provider == "" {
+        fs.Usage()
+        return errors.New("缺少客户或云平台参数")
+    }
+    // 当前版本未内置云平台账单接口适配器，提前给出明确提示，不创建失败的同步日志
+    if !datasync.HasFetcher(billing.Provider(*provider)) {
+        return fmt.Errorf("云平台 %s 未配置账单同步适配器，请通过账单文件导入（POST /api/v1/billing/imports）上传账单", *provider)
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    customerID, err := resolveCustomer(db, *customerRef)
+    if err != nil {
+        return err
+    }
+
+    // 连接Redis后同步进度和统计失效可以实时推送到前端，连接失败不影响同步
+    if _, err := database.InitRedis(); err != nil {
+        logger.GetLogger().Warn("Redis连接失败，不推送实时事件:", err)
+    } else {
+        defer database.CloseRedis()
+    }
+
+    log, err := datasync.NewService(db).Run(context.Background(), customerID, billing.Provider(*provider), *period)
+    if log != nil {
+        fmt.Printf("同步日志: %s 状态: %s 记录数: %d\n", log.ID, log.Status, log.RecordsCount)
+    }
+    return err
+}
+
+// runCommission 返佣计算
+func runCommission(args []string) error {
+    if len(args) == 0 || args[0] != "recalc" {
+        return errors.New("用法: xcloud commission recalc -period YYYY-MM")
+    }
+
+    fs := newFlagSet("commission recalc", "commission recalc -period YYYY-MM")
+    period := fs.String("period", "", "计费周期（YYYY-MM）")
+    if err := fs.Parse(args[1:]); err != nil {
+        return err
+    }
+    if *period == "" {
+        fs.Usage()
+        return errors.New("缺少计费周期")
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    result, err := commission.NewEngine(db).Calculate(*period, nil)
+    if err != nil {
+        return err
+    }
+
+    fmt.Printf("计费周期: %s  计算任务: %s\n", result.Period, result.RunID)
+    fmt.Printf("合同数: %d  返佣记录: %d  调整记录: %d  未匹配规则: %d\n",
+        result.Contracts, result.Records, result.Adjustments, result.Unmatched)
+    fmt.Printf("计算基数合计: %.2f %s  返佣金额合计: %.4f %s  净变动: %+.4f\n",
+        result.TotalBase, result.ReportingCurrency, result.TotalCommission, result.ReportingCurrency, result.NetDelta)
+    for _, d := range result.Customers {
+        if d.Delta == 0 && d.Adjustments == 0 {
+            continue
+        }
+        fmt.Printf("  客户 %s  %.4f -> %.4f  变动: %+.4f  调整记录: %d\n", d.CustomerID, d.Before, d.After, d.Delta, d.Adjustments)
+    }
+    return nil
+}
+
+// runReconcile 账单对账
+func runReconcile(args []string) error {
+    if len(args) == 0 || args[0] != "run" {
+        return errors.New("用法: xcloud reconcile run -customer 客户ID或编码 -provider 云平台 -period YYYY-MM [-invoiced 金额]")
+    }
+
+    fs := newFlagSet("reconcile run", "reconcile run -customer 客户ID或编码 -provider 云平台 -period YYYY-MM [-invoiced 金额]")
+    customerRef := fs.String("customer", "", "客户ID或客户编码")
+    provider := fs.String("provider", "", "云平台（tencent/alibaba/huawei/aws）")
+    period := fs.String("period", "", "计费周期（YYYY-MM）")
+    invoiced := fs.Float64("invoiced", -1, "云平台账单总额，不指定时不比对")
+    if err := fs.Parse(args[1:]); err != nil {
+        return err
+    }
+    if *customerRef == "" || *provider == "" || *period == "" {
+        fs.Usage()
+        return errors.New("缺少客户、云平台或计费周期参数")
+    }
+    if !billing.Provider(*provider).IsValid() {
+        return fmt.Errorf("不支持的云平台: %s", *provider)
+    }
+
+    db, err := database.InitDB()
+    if err != nil {
+        return err
+    }
+    defer database.CloseDB()
+
+    customerID, err := resolveCustomer(db, *customerRef)
+    if err != nil {
+        return err
+    }
+
+    req := reconcile.RunRequest{
+        CustomerID: customerID.String(),
+        Provider:   billing.Provider(*provider),
+        Period:     *period,
+    }
+    if *invoiced >= 0 {
+        req.InvoicedTotal = invoiced
+    }
+    run, err := reconcile.NewService(db).Run(req, nil)
+    if err != nil {
+        return err
+    }
+
+    fmt.Printf("对账任务: %s\n", run.ID)
+    fmt.Printf("明细数: %d  无合同覆盖: %d\n", run.LineCount, run.UncoveredCount)
+    fmt.Printf("账单折后合计: %.2f  合同预期: %.2f", run.BilledTotal, run.ExpectedTotal)
+    if run.InvoicedTotal != nil {
+        fmt.Printf("  云平台账单: %.2f", *run.InvoicedTotal)
+    }
+    fmt.Printf("\n差异数: %d\n", run.DiscrepancyCount)
+    return nil
+}
+
+// runSettlement 返佣结算关账
+func runSettlement(args []string) error {
+    const usage = "用法: xcloud settlement close -contract 合同ID或编号 -period YYYY-MM | xcloud settlement close-due"
+    if len(args) == 0 {
+        return errors.New(usage)
+    }
+
+    switch args[0] {
+    case "close":
+        fs := newFlagSet("settlement close", "settlement close -contract 合同ID或编号 -period YYYY-MM")
+        contractRef := fs.String("contract", "", "合同ID或合同编号")
+        period := fs.String("period", "", "结算周期内任一计费月份（YYYY-MM）")
+        if err := fs.Parse(args[1:]); err != nil {
+            return err
+        }
+        if *contractRef == "" || *period == "" {
+            fs.Usage()
+            return errors.New("缺少合同或计费周期参数")
+        }
+
+        db, err := database.InitDB()
+        if err != nil {
+            return err
+        }
+        defer database.CloseDB()
+
+        contractID, err := resolveContract(db, *contractRef)
+        if err != nil {
+            return err
+        }
+        st, err := settlement.NewService(db).Close(contractID, *period, nil)
+        if err != nil {
+            return err
+        }
+        printSettlement(*st)
+        return nil
+
+    case "close-due":
+        db, err := database.InitDB()
+        if err != nil {
+            return err
+        }
+        defer database.CloseDB()
+
+        result, err := settlement.NewService(db).CloseDue(nil)
+        if err != nil {
+            return err
+        }
+        fmt.Printf("检查合同: %d  生成结算单: %d\n", result.Contracts, len(result.Settlements))
+        for _, st := range result.Settlements {
+            printSettlement(st)
+        }
+        return nil
+
+    default:
+        return errors.New(usage)
+    }
+}
+
+func printSettlement(st settlement.Settlement) {
+    fmt.Printf("结算单: %s  周期: %s ~ %s  返佣记录: %d  返佣合计: %.4f %s\n",
+        st.SettlementNo, st.CycleStart, st.CycleEnd, st.RecordCount, st.CommissionTotal, st.Currency)
+}
+
+// resolveContract 按合同ID或合同编号查找合同
+func resolveContract(db *gorm.DB, ref string) (uuid.UUID, error) {
+    if id, err := uuid.Parse(ref); err == nil {
+        return id, nil
+    }
+
+    var ct contract.Contract
+    if err := db.Select("id").Where("contract_no = ?", ref).First(&ct).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return uuid.Nil, fmt.Errorf("合同不存在: %s", ref)
+        }
+        return uuid.Nil, err
+    }
+    return ct.ID, nil
+}
+
+// resolveCustomer 按客户ID或客户编码查找客户
+func resolveCustomer(db *gorm.DB, ref string) (uuid.UUID, error) {
+    if id, err := uuid.Parse(ref); err == nil {
+        return id, nil
+    }
+
+    var c customer.Customer
+    if err := db.Select("id").Where("customer_code = ?", ref).First(&c).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return uuid.Nil, fmt.Errorf("客户不存在: %s", ref)
+        }
+        return uuid.Nil, err
+    }
+    return c.ID, nil
+}
@@ -0,0 +1,501 @@
+package approval
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	approvalSvc *Service
+	logger      *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		approvalSvc: NewService(db),
+		logger:      logger.GetLogger(),
+	}
+}
+
+// SubmitContract 提交合同审批
+// @Summary 提交合同审批
+// @Description 提交草稿合同审批：按合同金额和折扣率选择优先级最高的启用审批链并生成审批任务，合同进入待审批状态。
+// @Description 驳回后合同退回草稿，可修改后重新提交
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Success 201 {object} ApprovalResponse "提交成功"
+// @Failure 400 {object} ErrorResponse "合同不是草稿或没有适用的审批链"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Router /contracts/{id}/submit [post]
+func (h *Handler) SubmitContract(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	approval, err := h.approvalSvc.Submit(id, operator)
+	if err != nil {
+		h.respondError(c, "提交合同审批失败:", err)
+		return
+	}
+
+	h.logger.Info("合同已提交审批:", id, " 审批链:", approval.ChainName)
+	c.JSON(http.StatusCreated, ApprovalResponse{
+		Code:    201,
+		Message: i18n.T(c, "approval.submitted"),
+		Data:    *approval,
+	})
+}
+
+// ListContractApprovals 获取合同审批记录
+// @Summary 获取合同审批记录
+// @Description 获取合同的全部审批记录及每一步的审批人、审批意见和时间，按提交时间倒序
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Success 200 {object} ApprovalListResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Router /contracts/{id}/approvals [get]
+func (h *Handler) ListContractApprovals(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	approvals, err := h.approvalSvc.History(id)
+	if err != nil {
+		h.respondError(c, "获取合同审批记录失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ApprovalListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    approvals,
+	})
+}
+
+// Inbox 获取待我审批的任务
+// @Summary 获取待我审批的任务
+// @Description 分页获取指派给当前用户或当前用户角色的待审批任务，按提交时间排序
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} InboxResponse "获取成功"
+// @Router /approvals/inbox [get]
+func (h *Handler) Inbox(c *gin.Context) {
+	approver, ok := currentApprover(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := pagination(c)
+	items, total, err := h.approvalSvc.Inbox(approver, page, pageSize)
+	if err != nil {
+		h.respondError(c, "获取待审批任务失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, InboxResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: InboxData{
+			Tasks:    items,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// ApproveTask 同意审批任务
+// @Summary 同意审批任务
+// @Description 同意当前步骤，审批进入下一步；最后一步同意后合同生效
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Param request body DecisionRequest false "审批意见"
+// @Success 200 {object} TaskResponse "审批成功"
+// @Failure 400 {object} ErrorResponse "任务不是待审批状态"
+// @Failure 403 {object} ErrorResponse "不是该任务的审批人"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /approvals/tasks/{id}/approve [post]
+func (h *Handler) ApproveTask(c *gin.Context) {
+	h.decide(c, true)
+}
+
+// RejectTask 驳回审批任务
+// @Summary 驳回审批任务
+// @Description 驳回合同，审批结束，合同退回草稿；驳回必须填写审批意见
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Param request body DecisionRequest true "驳回意见"
+// @Success 200 {object} TaskResponse "驳回成功"
+// @Failure 400 {object} ErrorResponse "未填写驳回意见或任务不是待审批状态"
+// @Failure 403 {object} ErrorResponse "不是该任务的审批人"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /approvals/tasks/{id}/reject [post]
+func (h *Handler) RejectTask(c *gin.Context) {
+	h.decide(c, false)
+}
+
+// decide 处理审批任务，审批意见可省略请求体
+func (h *Handler) decide(c *gin.Context, approve bool) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var req DecisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+				"error":   i18n.Message(c, err),
+			})
+			return
+		}
+	}
+	approver, ok := currentApprover(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.approvalSvc.Decide(id, approver, approve, req.Comment)
+	if err != nil {
+		h.respondError(c, "处理审批任务失败:", err)
+		return
+	}
+
+	message := "approval.approved"
+	if !approve {
+		message = "approval.rejected"
+	}
+	h.logger.Info("审批任务已处理:", task.ID, " 合同:", task.ContractID, " 结果:", task.Status)
+	c.JSON(http.StatusOK, TaskResponse{
+		Code:    200,
+		Message: i18n.T(c, message),
+		Data:    *task,
+	})
+}
+
+// ListChains 获取审批链列表
+// @Summary 获取审批链列表
+// @Description 获取全部审批链及步骤，按优先级倒序
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ChainListResponse "获取成功"
+// @Router /approvals/chains [get]
+func (h *Handler) ListChains(c *gin.Context) {
+	chains, err := h.approvalSvc.ListChains()
+	if err != nil {
+		h.respondError(c, "获取审批链列表失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ChainListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    chains,
+	})
+}
+
+// GetChain 获取审批链详情
+// @Summary 获取审批链详情
+// @Description 获取审批链及步骤
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "审批链ID"
+// @Success 200 {object} ChainResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "审批链不存在"
+// @Router /approvals/chains/{id} [get]
+func (h *Handler) GetChain(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	chain, err := h.approvalSvc.GetChain(id)
+	if err != nil {
+		h.respondError(c, "获取审批链失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ChainResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *chain,
+	})
+}
+
+// CreateChain 创建审批链
+// @Summary 创建审批链
+// @Description 创建审批链，步骤按请求顺序依次审批，每一步指定审批角色（approver_role）或审批人（approver_id）。
+// @Description 合同金额不低于 min_contract_amount 且折扣率不高于 max_discount_rate 时适用，多个适用时选择优先级最高的
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ChainRequest true "审批链"
+// @Success 201 {object} ChainResponse "创建成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /approvals/chains [post]
+func (h *Handler) CreateChain(c *gin.Context) {
+	var req ChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	chain, err := h.approvalSvc.CreateChain(req, operator)
+	if err != nil {
+		h.respondError(c, "创建审批链失败:", err)
+		return
+	}
+
+	h.logger.Info("审批链已创建:", chain.ID, " ", chain.Name)
+	c.JSON(http.StatusCreated, ChainResponse{
+		Code:    201,
+		Message: i18n.T(c, "approval.chain_created"),
+		Data:    *chain,
+	})
+}
+
+// UpdateChain 更新审批链
+// @Summary 更新审批链
+// @Description 更新审批链并整体替换步骤，进行中的审批仍按提交时的步骤执行
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "审批链ID"
+// @Param request body ChainRequest true "审批链"
+// @Success 200 {object} ChainResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "审批链不存在"
+// @Router /approvals/chains/{id} [put]
+func (h *Handler) UpdateChain(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var req ChainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	chain, err := h.approvalSvc.UpdateChain(id, req, operator)
+	if err != nil {
+		h.respondError(c, "更新审批链失败:", err)
+		return
+	}
+
+	h.logger.Info("审批链已更新:", chain.ID, " ", chain.Name)
+	c.JSON(http.StatusOK, ChainResponse{
+		Code:    200,
+		Message: i18n.T(c, "approval.chain_updated"),
+		Data:    *chain,
+	})
+}
+
+// DeleteChain 删除审批链
+// @Summary 删除审批链
+// @Description 删除审批链，进行中的审批不受影响
+// @Tags 合同审批
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "审批链ID"
+// @Success 200 {object} ErrorResponse "删除成功"
+// @Failure 404 {object} ErrorResponse "审批链不存在"
+// @Router /approvals/chains/{id} [delete]
+func (h *Handler) DeleteChain(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.approvalSvc.DeleteChain(id); err != nil {
+		h.respondError(c, "删除审批链失败:", err)
+		return
+	}
+
+	h.logger.Info("审批链已删除:", id)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": i18n.T(c, "approval.chain_deleted"),
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404，非审批人返回403），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "approval.chain_not_found", "approval.task_not_found", "approval.contract_not_found":
+			status = http.StatusNotFound
+		case "approval.not_approver":
+			status = http.StatusForbidden
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// currentApprover 获取当前登录用户及角色
+func currentApprover(c *gin.Context) (Approver, bool) {
+	id, ok := currentUser(c)
+	if !ok {
+		return Approver{}, false
+	}
+	role, _ := c.Get("user_role")
+	roleStr, _ := role.(string)
+	return Approver{UserID: id, Role: roleStr}, true
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ApprovalResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    Approval `json:"data"`
+}
+
+type ApprovalListResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    []Approval `json:"data"`
+}
+
+type TaskResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Task   `json:"data"`
+}
+
+type InboxResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    InboxData `json:"data"`
+}
+
+type InboxData struct {
+	Tasks    []InboxItem `json:"tasks"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
+
+type ChainResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Chain  `json:"data"`
+}
+
+type ChainListResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    []Chain `json:"data"`
+}
@@ -0,0 +1,164 @@
+package approval
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Status 合同审批状态
+type Status string
+
+const (
+	StatusPending  Status = "pending"  // 审批中
+	StatusApproved Status = "approved" // 已通过，合同生效
+	StatusRejected Status = "rejected" // 已驳回，合同退回草稿
+)
+
+// TaskStatus 审批任务状态：waiting → pending → approved / rejected，驳回后未处理的后续任务为 skipped
+type TaskStatus string
+
+const (
+	TaskWaiting  TaskStatus = "waiting"  // 等待前序步骤
+	TaskPending  TaskStatus = "pending"  // 待审批
+	TaskApproved TaskStatus = "approved" // 已同意
+	TaskRejected TaskStatus = "rejected" // 已驳回
+	TaskSkipped  TaskStatus = "skipped"  // 前序步骤驳回，未处理
+)
+
+// Chain 审批链：合同金额不低于 MinContractAmount 且折扣率不高于 MaxDiscountRate 时适用（为空不限）
+// 多个审批链适用时选择优先级最高的
+type Chain struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name              string         `json:"name" gorm:"type:varchar(100);not null"`
+	Description       string         `json:"description,omitempty" gorm:"type:text"`
+	MinContractAmount *float64       `json:"min_contract_amount,omitempty" gorm:"type:decimal(15,2)"`
+	MaxDiscountRate   *float64       `json:"max_discount_rate,omitempty" gorm:"type:decimal(5,4)"`
+	Priority          int            `json:"priority" gorm:"not null;default:0"`
+	IsActive          bool           `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	CreatedBy         *uuid.UUID     `json:"created_by,omitempty"`
+	UpdatedBy         *uuid.UUID     `json:"updated_by,omitempty"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Steps []Step `json:"steps" gorm:"-"`
+}
+
+// TableName 设置表名
+func (Chain) TableName() string {
+	return "approval_chains"
+}
+
+// Matches 检查审批链是否适用于合同金额和折扣率
+func (c *Chain) Matches(amount, discountRate *float64) bool {
+	if c.MinContractAmount != nil && (amount == nil || *amount < *c.MinContractAmount) {
+		return false
+	}
+	if c.MaxDiscountRate != nil && (discountRate == nil || *discountRate > *c.MaxDiscountRate) {
+		return false
+	}
+	return true
+}
+
+// Step 审批链步骤，由指定用户或指定角色的任一用户审批
+type Step struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ChainID      uuid.UUID  `json:"chain_id" gorm:"type:uuid;not null"`
+	StepNo       int        `json:"step_no" gorm:"not null"`
+	Name         string     `json:"name" gorm:"type:varchar(100);not null"`
+	ApproverRole string     `json:"approver_role,omitempty" gorm:"type:varchar(20)"`
+	ApproverID   *uuid.UUID `json:"approver_id,omitempty" gorm:"type:uuid"`
+}
+
+// TableName 设置表名
+func (Step) TableName() string {
+	return "approval_chain_steps"
+}
+
+// Approval 合同的一次提交审批
+type Approval struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ContractID  uuid.UUID  `json:"contract_id" gorm:"type:uuid;not null"`
+	ChainID     *uuid.UUID `json:"chain_id,omitempty" gorm:"type:uuid"`
+	ChainName   string     `json:"chain_name" gorm:"type:varchar(100);not null"`
+	Status      Status     `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	CurrentStep int        `json:"current_step" gorm:"not null;default:1"`
+	SubmittedBy uuid.UUID  `json:"submitted_by" gorm:"type:uuid;not null"`
+	SubmittedAt time.Time  `json:"submitted_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+
+	Tasks []Task `json:"tasks" gorm:"-"`
+}
+
+// TableName 设置表名
+func (Approval) TableName() string {
+	return "contract_approvals"
+}
+
+// Task 审批任务，提交时按审批链步骤生成，审批链后续修改不影响进行中的审批
+type Task struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ApprovalID   uuid.UUID  `json:"approval_id" gorm:"type:uuid;not null"`
+	ContractID   uuid.UUID  `json:"contract_id" gorm:"type:uuid;not null"`
+	StepNo       int        `json:"step_no" gorm:"not null"`
+	StepName     string     `json:"step_name" gorm:"type:varchar(100);not null"`
+	ApproverRole string     `json:"approver_role,omitempty" gorm:"type:varchar(20)"`
+	ApproverID   *uuid.UUID `json:"approver_id,omitempty" gorm:"type:uuid"`
+	Status       TaskStatus `json:"status" gorm:"type:varchar(20);not null;default:'waiting'"`
+	Comment      string     `json:"comment,omitempty" gorm:"type:text"`
+	DecidedBy    *uuid.UUID `json:"decided_by,omitempty" gorm:"type:uuid"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TableName 设置表名
+func (Task) TableName() string {
+	return "approval_tasks"
+}
+
+// InboxItem 待我审批的任务及合同摘要
+type InboxItem struct {
+	Task
+	ContractNo     string    `json:"contract_no"`
+	ContractTitle  string    `json:"contract_title"`
+	CustomerName   string    `json:"customer_name"`
+	ContractAmount *float64  `json:"contract_amount,omitempty"`
+	DiscountRate   *float64  `json:"discount_rate,omitempty"`
+	Currency       string    `json:"currency"`
+	ChainName      string    `json:"chain_name"`
+	SubmittedBy    uuid.UUID `json:"submitted_by"`
+	SubmittedAt    time.Time `json:"submitted_at"`
+}
+
+// Approver 当前用户
+type Approver struct {
+	UserID uuid.UUID
+	Role   string
+}
+
+// 请求结构体
+
+// ChainRequest 创建或更新审批链请求，更新时整体替换步骤
+type ChainRequest struct {
+	Name              string        `json:"name" binding:"required,max=100" example:"大额合同审批"`
+	Description       string        `json:"description" example:"经理、财务、管理员依次审批"`
+	MinContractAmount *float64      `json:"min_contract_amount" binding:"omitempty,gte=0" example:"1000000"`
+	MaxDiscountRate   *float64      `json:"max_discount_rate" binding:"omitempty,gt=0,lte=1" example:"0.8"`
+	Priority          int           `json:"priority" example:"10"`
+	IsActive          *bool         `json:"is_active" example:"true"`
+	Steps             []StepRequest `json:"steps" binding:"required,min=1,dive"`
+}
+
+// StepRequest 审批链步骤，approver_role 与 approver_id 二选一
+type StepRequest struct {
+	Name         string `json:"name" binding:"required,max=100" example:"财务审批"`
+	ApproverRole string `json:"approver_role,omitempty" example:"finance"`
+	ApproverID   string `json:"approver_id,omitempty" example:"user-uuid"`
+}
+
+// DecisionRequest 审批意见
+type DecisionRequest struct {
+	Comment string `json:"comment" example:"同意"`
+}
@@ -0,0 +1,33 @@
+package approval
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册合同审批相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+	admins := middleware.RequireRole("admin")
+
+	router.GET("/inbox", handler.Inbox)
+	router.POST("/tasks/:id/approve", handler.ApproveTask)
+	router.POST("/tasks/:id/reject", handler.RejectTask)
+
+	chains := router.Group("/chains")
+	chains.GET("", handler.ListChains)
+	chains.GET("/:id", handler.GetChain)
+	chains.POST("", admins, handler.CreateChain)
+	chains.PUT("/:id", admins, handler.UpdateChain)
+	chains.DELETE("/:id", admins, handler.DeleteChain)
+}
+
+// RegisterContractRoutes 在合同路由下注册提交审批和审批记录路由
+func RegisterContractRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/:id/approvals", handler.ListContractApprovals)
+	router.POST("/:id/submit", middleware.RequireRole("admin", "manager", "employee", "finance"), handler.SubmitContract)
+}
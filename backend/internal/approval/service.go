@@ -0,0 +1,547 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/internal/notify"
+	"xcloud-backend/internal/user"
+	"xcloud-backend/internal/webhook"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+// Service 合同审批服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建合同审批服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ListChains 获取全部审批链及步骤，按优先级倒序
+func (s *Service) ListChains() ([]Chain, error) {
+	var chains []Chain
+	if err := s.db.Order("priority DESC, created_at").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	if err := s.loadSteps(chains); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
+
+// GetChain 获取审批链及步骤
+func (s *Service) GetChain(id uuid.UUID) (*Chain, error) {
+	var chain Chain
+	if err := s.db.First(&chain, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("approval.chain_not_found")
+		}
+		return nil, err
+	}
+	chains := []Chain{chain}
+	if err := s.loadSteps(chains); err != nil {
+		return nil, err
+	}
+	return &chains[0], nil
+}
+
+// CreateChain 创建审批链
+func (s *Service) CreateChain(req ChainRequest, operator uuid.UUID) (*Chain, error) {
+	steps, err := buildSteps(req.Steps)
+	if err != nil {
+		return nil, err
+	}
+	chain := &Chain{CreatedBy: &operator}
+	applyChain(chain, req, operator)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(chain).Error; err != nil {
+			return err
+		}
+		return saveSteps(tx, chain, steps)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// UpdateChain 更新审批链并整体替换步骤，进行中的审批仍按提交时的步骤执行
+func (s *Service) UpdateChain(id uuid.UUID, req ChainRequest, operator uuid.UUID) (*Chain, error) {
+	steps, err := buildSteps(req.Steps)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := s.GetChain(id)
+	if err != nil {
+		return nil, err
+	}
+	applyChain(chain, req, operator)
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("name", "description", "min_contract_amount", "max_discount_rate", "priority", "is_active", "updated_by").
+			Updates(chain).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chain_id = ?", chain.ID).Delete(&Step{}).Error; err != nil {
+			return err
+		}
+		return saveSteps(tx, chain, steps)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// DeleteChain 删除审批链，进行中的审批不受影响
+func (s *Service) DeleteChain(id uuid.UUID) error {
+	res := s.db.Delete(&Chain{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return i18n.NewError("approval.chain_not_found")
+	}
+	return nil
+}
+
+// Submit 提交草稿合同审批：按合同金额和折扣率选择审批链，生成审批任务，合同进入待审批状态
+func (s *Service) Submit(contractID, operator uuid.UUID) (*Approval, error) {
+	var approval Approval
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var ct contract.Contract
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ct, "id = ?", contractID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return i18n.NewError("approval.contract_not_found")
+			}
+			return err
+		}
+		if ct.Status != contract.StatusDraft {
+			return i18n.NewError("approval.not_submittable", string(ct.Status))
+		}
+
+		chain, err := s.selectChain(tx, &ct)
+		if err != nil {
+			return err
+		}
+		approval = Approval{
+			ContractID:  ct.ID,
+			ChainID:     &chain.ID,
+			ChainName:   chain.Name,
+			Status:      StatusPending,
+			CurrentStep: chain.Steps[0].StepNo,
+			SubmittedBy: operator,
+			SubmittedAt: time.Now(),
+		}
+		if err := tx.Create(&approval).Error; err != nil {
+			return err
+		}
+		for i, step := range chain.Steps {
+			task := Task{
+				ApprovalID:   approval.ID,
+				ContractID:   ct.ID,
+				StepNo:       step.StepNo,
+				StepName:     step.Name,
+				ApproverRole: step.ApproverRole,
+				ApproverID:   step.ApproverID,
+				Status:       TaskWaiting,
+			}
+			if i == 0 {
+				task.Status = TaskPending
+			}
+			approval.Tasks = append(approval.Tasks, task)
+		}
+		if err := tx.Create(&approval.Tasks).Error; err != nil {
+			return err
+		}
+		return tx.Model(&contract.Contract{}).Where("id = ?", ct.ID).Updates(map[string]interface{}{
+			"status":     contract.StatusPending,
+			"updated_at": time.Now(),
+			"updated_by": operator,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyPending(approval.Tasks[0])
+	return &approval, nil
+}
+
+// Decide 处理审批任务：同意时进入下一步，最后一步同意后合同生效；驳回时合同退回草稿，后续步骤不再处理
+func (s *Service) Decide(taskID uuid.UUID, approver Approver, approve bool, comment string) (*Task, error) {
+	if !approve && comment == "" {
+		return nil, i18n.NewError("approval.comment_required")
+	}
+
+	var task Task
+	var next *Task
+	var approval Approval
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, "id = ?", taskID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return i18n.NewError("approval.task_not_found")
+			}
+			return err
+		}
+		if task.Status != TaskPending {
+			return i18n.NewError("approval.task_not_pending", string(task.Status))
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&approval, "id = ?", task.ApprovalID).Error; err != nil {
+			return err
+		}
+		if approval.SubmittedBy == approver.UserID {
+			return i18n.NewError("approval.self_approval")
+		}
+		if !canDecide(&task, approver) {
+			return i18n.NewError("approval.not_approver")
+		}
+
+		now := time.Now()
+		task.Status = TaskRejected
+		if approve {
+			task.Status = TaskApproved
+		}
+		task.Comment = comment
+		task.DecidedBy = &approver.UserID
+		task.DecidedAt = &now
+		if err := tx.Model(&task).Select("status", "comment", "decided_by", "decided_at").Updates(&task).Error; err != nil {
+			return err
+		}
+
+		if !approve {
+			return s.finish(tx, &approval, StatusRejected, contract.StatusDraft, approver.UserID, now)
+		}
+
+		var following Task
+		err := tx.Where("approval_id = ? AND step_no > ?", approval.ID, task.StepNo).Order("step_no").First(&following).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.finish(tx, &approval, StatusApproved, contract.StatusActive, approver.UserID, now)
+		}
+		if err != nil {
+			return err
+		}
+		following.Status = TaskPending
+		if err := tx.Model(&following).Update("status", TaskPending).Error; err != nil {
+			return err
+		}
+		next = &following
+		return tx.Model(&approval).Update("current_step", following.StepNo).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if next != nil {
+		s.notifyPending(*next)
+	} else {
+		s.notifyFinished(approval, task)
+		if approval.Status == StatusApproved {
+			s.publishActivated(approval)
+		}
+	}
+	return &task, nil
+}
+
+// finish 结束审批：更新审批和合同状态，驳回时未处理的后续任务标记为 skipped；
+// 合同生效前重新检查客户仍为活跃状态，审批期间客户被暂停或流失时不能生效
+func (s *Service) finish(tx *gorm.DB, approval *Approval, status Status, contractStatus contract.ContractStatus, operator uuid.UUID, now time.Time) error {
+	if contractStatus == contract.StatusActive {
+		var cust struct {
+			Status string
+		}
+		err := tx.Table("customers").Select("customers.status").
+			Joins("JOIN contracts ON contracts.customer_id = customers.id").
+			Where("contracts.id = ?", approval.ContractID).
+			Clauses(clause.Locking{Strength: "SHARE", Table: clause.Table{Name: "customers"}}).
+			Take(&cust).Error
+		if err != nil {
+			return err
+		}
+		if cust.Status != string(customer.StatusActive) {
+			return i18n.NewError("approval.customer_inactive", cust.Status)
+		}
+	}
+
+	approval.Status = status
+	approval.FinishedAt = &now
+	if err := tx.Model(approval).Select("status", "finished_at").Updates(approval).Error; err != nil {
+		return err
+	}
+	if status == StatusRejected {
+		if err := tx.Model(&Task{}).Where("approval_id = ? AND status = ?", approval.ID, TaskWaiting).
+			Update("status", TaskSkipped).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Model(&contract.Contract{}).
+		Where("id = ? AND status = ?", approval.ContractID, contract.StatusPending).
+		Updates(map[string]interface{}{
+			"status":     contractStatus,
+			"updated_at": now,
+			"updated_by": operator,
+		}).Error
+}
+
+// Inbox 分页获取当前用户待审批的任务：指派给本人的任务及本人角色的任务，不包括本人提交的合同
+func (s *Service) Inbox(approver Approver, page, pageSize int) ([]InboxItem, int64, error) {
+	query := s.db.Table("approval_tasks t").
+		Joins("JOIN contract_approvals a ON a.id = t.approval_id").
+		Joins("JOIN contracts c ON c.id = t.contract_id").
+		Joins("LEFT JOIN customers cu ON cu.id = c.customer_id").
+		Where("t.status = ?", TaskPending).
+		Where("t.approver_id = ? OR (t.approver_id IS NULL AND t.approver_role = ?)", approver.UserID, approver.Role).
+		Where("a.submitted_by <> ?", approver.UserID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	items := []InboxItem{}
+	err := query.Select("t.*, c.contract_no, c.title AS contract_title, cu.company_name AS customer_name, " +
+		"c.contract_amount, c.discount_rate, c.currency, a.chain_name, a.submitted_by, a.submitted_at").
+		Order("a.submitted_at").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Scan(&items).Error
+	return items, total, err
+}
+
+// History 获取合同的全部审批记录及每一步的审批结果，按提交时间倒序
+func (s *Service) History(contractID uuid.UUID) ([]Approval, error) {
+	var count int64
+	if err := s.db.Model(&contract.Contract{}).Where("id = ?", contractID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, i18n.NewError("approval.contract_not_found")
+	}
+
+	approvals := []Approval{}
+	if err := s.db.Where("contract_id = ?", contractID).Order("submitted_at DESC").Find(&approvals).Error; err != nil {
+		return nil, err
+	}
+	if len(approvals) == 0 {
+		return approvals, nil
+	}
+	ids := make([]uuid.UUID, len(approvals))
+	for i, a := range approvals {
+		ids[i] = a.ID
+	}
+	var tasks []Task
+	if err := s.db.Where("approval_id IN ?", ids).Order("step_no").Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	byApproval := map[uuid.UUID][]Task{}
+	for _, t := range tasks {
+		byApproval[t.ApprovalID] = append(byApproval[t.ApprovalID], t)
+	}
+	for i := range approvals {
+		approvals[i].Tasks = byApproval[approvals[i].ID]
+	}
+	return approvals, nil
+}
+
+// selectChain 选择适用于合同的优先级最高的启用审批链
+func (s *Service) selectChain(tx *gorm.DB, ct *contract.Contract) (*Chain, error) {
+	var chains []Chain
+	if err := tx.Where("is_active = ?", true).Order("priority DESC, created_at").Find(&chains).Error; err != nil {
+		return nil, err
+	}
+	for i := range chains {
+		if !chains[i].Matches(ct.ContractAmount, ct.DiscountRate) {
+			continue
+		}
+		selected := chains[i : i+1]
+		if err := loadStepsWith(tx, selected); err != nil {
+			return nil, err
+		}
+		if len(selected[0].Steps) == 0 {
+			continue
+		}
+		return &selected[0], nil
+	}
+	return nil, i18n.NewError("approval.no_chain")
+}
+
+func (s *Service) loadSteps(chains []Chain) error {
+	return loadStepsWith(s.db, chains)
+}
+
+// loadStepsWith 加载审批链的步骤，按步骤序号排序
+func loadStepsWith(db *gorm.DB, chains []Chain) error {
+	if len(chains) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(chains))
+	for i, c := range chains {
+		ids[i] = c.ID
+	}
+	var steps []Step
+	if err := db.Where("chain_id IN ?", ids).Order("step_no").Find(&steps).Error; err != nil {
+		return err
+	}
+	byChain := map[uuid.UUID][]Step{}
+	for _, st := range steps {
+		byChain[st.ChainID] = append(byChain[st.ChainID], st)
+	}
+	for i := range chains {
+		chains[i].Steps = byChain[chains[i].ID]
+		if chains[i].Steps == nil {
+			chains[i].Steps = []Step{}
+		}
+	}
+	return nil
+}
+
+// canDecide 检查用户是否可以处理审批任务（提交人不能审批本人提交的合同，由调用方检查）
+func canDecide(task *Task, approver Approver) bool {
+	if task.ApproverID != nil {
+		return *task.ApproverID == approver.UserID
+	}
+	return task.ApproverRole == approver.Role
+}
+
+// applyChain 将请求写入审批链
+func applyChain(chain *Chain, req ChainRequest, operator uuid.UUID) {
+	chain.Name = req.Name
+	chain.Description = req.Description
+	chain.MinContractAmount = req.MinContractAmount
+	chain.MaxDiscountRate = req.MaxDiscountRate
+	chain.Priority = req.Priority
+	chain.IsActive = req.IsActive == nil || *req.IsActive
+	chain.UpdatedBy = &operator
+}
+
+// buildSteps 校验并生成审批链步骤，步骤序号按请求顺序从1开始
+func buildSteps(reqs []StepRequest) ([]Step, error) {
+	steps := make([]Step, len(reqs))
+	for i, r := range reqs {
+		step := Step{StepNo: i + 1, Name: r.Name}
+		switch {
+		case r.ApproverRole != "" && r.ApproverID == "":
+			if !user.UserRole(r.ApproverRole).IsValid() {
+				return nil, i18n.NewError("approval.invalid_role", r.ApproverRole)
+			}
+			step.ApproverRole = r.ApproverRole
+		case r.ApproverRole == "" && r.ApproverID != "":
+			id, err := uuid.Parse(r.ApproverID)
+			if err != nil {
+				return nil, i18n.NewError("approval.invalid_step", i+1)
+			}
+			step.ApproverID = &id
+		default:
+			return nil, i18n.NewError("approval.invalid_step", i+1)
+		}
+		steps[i] = step
+	}
+	return steps, nil
+}
+
+// saveSteps 保存审批链步骤，指定审批人需为启用的用户
+func saveSteps(tx *gorm.DB, chain *Chain, steps []Step) error {
+	for i := range steps {
+		if steps[i].ApproverID != nil {
+			var count int64
+			if err := tx.Model(&user.User{}).Where("id = ? AND is_active = ?", *steps[i].ApproverID, true).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				return i18n.NewError("approval.approver_not_found", steps[i].StepNo)
+			}
+		}
+		steps[i].ChainID = chain.ID
+	}
+	if err := tx.Create(&steps).Error; err != nil {
+		return err
+	}
+	chain.Steps = steps
+	return nil
+}
+
+// notifyPending 通知任务的审批人
+func (s *Service) notifyPending(task Task) {
+	var ids []uuid.UUID
+	var roles []user.UserRole
+	if task.ApproverID != nil {
+		ids = append(ids, *task.ApproverID)
+	} else {
+		roles = append(roles, user.UserRole(task.ApproverRole))
+	}
+	recipients, err := notify.Recipients(s.db, ids, roles)
+	if err != nil {
+		logger.GetLogger().Error("查询审批人失败:", task.ID, err)
+		return
+	}
+	contractNo := s.contractNo(task.ContractID)
+	notify.Publish(context.Background(), notify.Message{
+		Event:      "approval.pending",
+		Subject:    notify.Text{Code: "approval.notify.pending_subject", Args: []interface{}{contractNo}},
+		Body:       notify.Text{Code: "approval.notify.pending_body", Args: []interface{}{contractNo, task.StepNo, task.StepName}},
+		Link:       "/contracts/" + task.ContractID.String(),
+		Recipients: recipients,
+	})
+}
+
+// notifyFinished 通知提交人审批结果
+func (s *Service) notifyFinished(approval Approval, last Task) {
+	recipients, err := notify.Recipients(s.db, []uuid.UUID{approval.SubmittedBy}, nil)
+	if err != nil {
+		logger.GetLogger().Error("查询审批提交人失败:", approval.ID, err)
+		return
+	}
+	contractNo := s.contractNo(approval.ContractID)
+	msg := notify.Message{
+		Event:      "approval.approved",
+		Subject:    notify.Text{Code: "approval.notify.approved_subject", Args: []interface{}{contractNo}},
+		Body:       notify.Text{Code: "approval.notify.approved_body", Args: []interface{}{contractNo}},
+		Link:       "/contracts/" + approval.ContractID.String(),
+		Recipients: recipients,
+	}
+	if approval.Status == StatusRejected {
+		msg.Event = "approval.rejected"
+		msg.Subject = notify.Text{Code: "approval.notify.rejected_subject", Args: []interface{}{contractNo}}
+		msg.Body = notify.Text{Code: "approval.notify.rejected_body", Args: []interface{}{contractNo, last.StepName, last.Comment}}
+	}
+	notify.Publish(context.Background(), msg)
+}
+
+// publishActivated 发布合同生效事件
+func (s *Service) publishActivated(approval Approval) {
+	var ct contract.Contract
+	if err := s.db.First(&ct, "id = ?", approval.ContractID).Error; err != nil {
+		logger.GetLogger().Error("查询审批通过的合同失败:", approval.ContractID, err)
+		return
+	}
+	webhook.Publish(s.db, webhook.EventContractActivated, map[string]interface{}{
+		"contract_id":     ct.ID,
+		"contract_no":     ct.ContractNo,
+		"customer_id":     ct.CustomerID,
+		"title":           ct.Title,
+		"start_date":      ct.StartDate.Format("2006-01-02"),
+		"end_date":        ct.EndDate.Format("2006-01-02"),
+		"contract_amount": ct.ContractAmount,
+		"discount_rate":   ct.DiscountRate,
+		"currency":        ct.Currency,
+		"approval_id":     approval.ID,
+		"activated_at":    approval.FinishedAt,
+	})
+}
+
+// contractNo 获取合同编号，用于通知
+func (s *Service) contractNo(id uuid.UUID) string {
+	var no string
+	s.db.Model(&contract.Contract{}).Where("id = ?", id).Pluck("contract_no", &no)
+	return no
+}
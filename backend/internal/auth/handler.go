@@ -10,6 +10,7 @@ import (
     "gorm.io/gorm"
 
     "xcloud-backend/internal/user"
+    "xcloud-backend/pkg/i18n"
     "xcloud-backend/pkg/jwt"
     "xcloud-backend/pkg/logger"
 )
@@ -47,8 +48,8 @@ func (h *Handler) Login(c *gin.Context) {
         h.logger.Error("登录请求参数错误:", err)
         c.JSON(http.StatusBadRequest, ErrorResponse{
             Code:    400,
-            Message: "请求参数错误",
-            Error:   err.Error(),
+            Message: i18n.T(c, "common.invalid_params"),
+            Error:   i18n.Message(c, err),
         })
         return
     }
@@ -59,8 +60,8 @@ func (h *Handler) Login(c *gin.Context) {
         h.logger.Warn("用户登录失败:", req.Username, err)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "用户名或密码错误",
-            Error:   err.Error(),
+            Message: i18n.T(c, "auth.invalid_credentials"),
+            Error:   i18n.Message(c, err),
         })
         return
     }
@@ -70,12 +71,13 @@ func (h *Handler) Login(c *gin.Context) {
         user.ID.String(),
         user.Username,
         string(user.Role),
+        user.Language,
     )
     if err != nil {
         h.logger.Error("生成JWT令牌失败:", err)
         c.JSON(http.StatusInternalServerError, ErrorResponse{
             Code:    500,
-            Message: "登录失败",
+            Message: i18n.T(c, "auth.login_failed"),
             Error:   "Failed to generate tokens",
         })
         return
@@ -84,7 +86,7 @@ func (h *Handler) Login(c *gin.Context) {
     h.logger.Info("用户登录成功:", user.Username)
     c.JSON(http.StatusOK, LoginResponse{
         Code:    200,
-        Message: "登录成功",
+        Message: i18n.T(c, "auth.login_success"),
         Data: TokenData{
             AccessToken:  accessToken,
             RefreshToken: refreshToken,
@@ -111,8 +113,8 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Error("刷新令牌请求参数错误:", err)
         c.JSON(http.StatusBadRequest, ErrorResponse{
             Code:    400,
-            Message: "请求参数错误",
-            Error:   err.Error(),
+            Message: i18n.T(c, "common.invalid_params"),
+            Error:   i18n.Message(c, err),
         })
         return
     }
@@ -123,7 +125,7 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Warn("无效的刷新令牌:", err)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "刷新令牌无效",
+            Message: i18n.T(c, "auth.refresh_token_invalid"),
             Error:   err.Error(),
         })
         return
@@ -134,7 +136,7 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Warn("刷新令牌已过期:", claims.Subject)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "刷新令牌已过期",
+            Message: i18n.T(c, "auth.refresh_token_expired"),
             Error:   "Refresh token expired",
         })
         return
@@ -146,7 +148,7 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Error("用户ID格式错误:", claims.UserID, err)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "令牌无效",
+            Message: i18n.T(c, "auth.token_user_invalid"),
             Error:   "Invalid user ID in token",
         })
         return
@@ -157,8 +159,8 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Warn("用户不存在或无效:", userID, err)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "用户无效",
-            Error:   err.Error(),
+            Message: i18n.T(c, "auth.user_invalid"),
+            Error:   i18n.Message(c, err),
         })
         return
     }
@@ -167,7 +169,7 @@ func (h *Handler) Refresh(c *gin.Context) {
         h.logger.Warn("用户已被禁用:", user.Username)
         c.JSON(http.StatusUnauthorized, ErrorResponse{
             Code:    401,
-            Message: "用户已被禁用",
+            Message: i18n.T(c, "auth.user_disabled"),
             Error:   "User is inactive",
         })
         return
@@ -178,12 +180,13 @@ func (h *Handler) Refresh(c *gin.Context) {
         user.ID.String(),
         user.Username,
         string(user.Role),
+        user.Language,
     )
     if err != nil {
         h.logger.Error("生成新令牌失败:", err)
         c.JSON(http.StatusInternalServerError, ErrorResponse{
             Code:    500,
-            Message: "刷新失败",
+            Message: i18n.T(c, "auth.refresh_failed"),
             Error:   "Failed to generate new tokens",
         })
         return
@@ -192,7 +195,7 @@ func (h *Handler) Refresh(c *gin.Context) {
     h.logger.Info("令牌刷新成功:", user.Username)
     c.JSON(http.StatusOK, LoginResponse{
         Code:    200,
-        Message: "令牌刷新成功",
+        Message: i18n.T(c, "auth.refresh_success"),
         Data: TokenData{
             AccessToken:  accessToken,
             RefreshToken: refreshToken,
@@ -227,6 +230,6 @@ func (h *Handler) Logout(c *gin.Context) {
 
     c.JSON(http.StatusOK, BaseResponse{
         Code:    200,
-        Message: "登出成功",
+        Message: i18n.T(c, "auth.logout_success"),
     })
 }
\ No newline at end of file
@@ -0,0 +1,359 @@
+package billimport
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	importSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		importSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// CreateImport 导入云平台账单文件
+// @Summary 导入账单文件
+// @Description 上传腾讯云、阿里云、华为云账单导出文件或AWS CUR（CSV/XLSX），按云平台列映射转换为账单明细。
+// @Description dry_run=true 时仅解析校验并返回预览；否则创建异步导入任务，替换该客户在该云平台、计费周期的账单明细。
+// @Description 存在错误行时任务失败且不写入数据，skip_invalid=true 时跳过错误行导入其余数据（需要管理员或经理权限）
+// @Tags 账单导入
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "账单文件（.csv/.xlsx）"
+// @Param customer_id formData string true "客户ID"
+// @Param provider formData string true "云平台" Enums(tencent, alibaba, huawei, aws)
+// @Param period formData string true "计费周期（YYYY-MM）"
+// @Param account_id formData string false "云账号ID（文件中没有账号列时使用）"
+// @Param dry_run formData bool false "仅预览，不导入"
+// @Param skip_invalid formData bool false "跳过错误行"
+// @Success 200 {object} PreviewResponse "预览结果"
+// @Success 202 {object} JobDetailResponse "导入任务已创建"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 413 {object} ErrorResponse "文件过大"
+// @Router /billing/imports [post]
+func (h *Handler) CreateImport(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("upload.import_max_size"))
+
+	var req ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": i18n.T(c, "import.file_too_large"),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "import.file_required"),
+		})
+		return
+	}
+	format, ok := FormatOf(file.Filename)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "import.unsupported_format"),
+		})
+		return
+	}
+
+	path, err := saveTemp(file, format)
+	if err != nil {
+		h.logger.Error("保存上传文件失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	if req.DryRun {
+		defer os.Remove(path)
+		preview, err := h.importSvc.Preview(req, path, format)
+		if err != nil {
+			h.logger.Warn("账单文件预览失败:", file.Filename, err)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.Message(c, err),
+			})
+			return
+		}
+		localize(c, preview.Errors)
+		c.JSON(http.StatusOK, PreviewResponse{
+			Code:    200,
+			Message: i18n.T(c, "import.preview_success"),
+			Data:    *preview,
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	operator, err := uuid.Parse(userID.(string))
+	if err != nil {
+		os.Remove(path)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return
+	}
+
+	job, err := h.importSvc.Start(req, path, format, file.Filename, operator)
+	if err != nil {
+		os.Remove(path)
+		h.logger.Warn("创建账单导入任务失败:", file.Filename, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	h.logger.Info("账单导入任务已创建:", job.ID, " 文件:", file.Filename)
+	c.JSON(http.StatusAccepted, JobDetailResponse{
+		Code:    202,
+		Message: i18n.T(c, "import.started"),
+		Data:    toJobResponse(job),
+	})
+}
+
+// ListImports 获取账单导入任务列表
+// @Summary 获取导入任务列表
+// @Description 分页获取账单文件导入任务，按开始时间倒序
+// @Tags 账单导入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id query string false "客户ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} JobListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /billing/imports [get]
+func (h *Handler) ListImports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	var customerID *uuid.UUID
+	if raw := c.Query("customer_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+			})
+			return
+		}
+		customerID = &id
+	}
+
+	logs, total, err := h.importSvc.List(customerID, page, pageSize)
+	if err != nil {
+		h.logger.Error("获取导入任务列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	items := make([]JobResponse, len(logs))
+	for i := range logs {
+		items[i] = toJobResponse(&logs[i])
+	}
+	c.JSON(http.StatusOK, JobListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: JobListData{
+			Jobs:     items,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetImport 获取账单导入任务
+// @Summary 获取导入任务
+// @Description 获取导入任务状态和进度
+// @Tags 账单导入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} JobDetailResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /billing/imports/{id} [get]
+func (h *Handler) GetImport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	job, err := h.importSvc.Get(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, JobDetailResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    toJobResponse(job),
+	})
+}
+
+// GetImportReport 获取账单导入任务的错误报告
+// @Summary 获取导入错误报告
+// @Description 获取导入任务的汇总和行级错误（最多保存1000条）
+// @Tags 账单导入
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} ReportResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /billing/imports/{id}/errors [get]
+func (h *Handler) GetImportReport(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	report, err := h.importSvc.Report(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	localize(c, report.Errors)
+	c.JSON(http.StatusOK, ReportResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *report,
+	})
+}
+
+// localize 按请求语言生成行级错误信息；早期任务保存的错误没有错误码，保留原信息
+func localize(c *gin.Context, errs []RowError) {
+	for i := range errs {
+		if errs[i].Code != "" {
+			errs[i].Message = i18n.T(c, errs[i].Code, errs[i].Args...)
+		}
+	}
+}
+
+// saveTemp 将上传文件保存为临时文件
+func saveTemp(fh *multipart.FileHeader, format Format) (string, error) {
+	src, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "xcloud-import-*."+string(format))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type PreviewResponse struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    PreviewResult `json:"data"`
+}
+
+type JobDetailResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    JobResponse `json:"data"`
+}
+
+type JobListData struct {
+	Jobs     []JobResponse `json:"jobs"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+type JobListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    JobListData `json:"data"`
+}
+
+type ReportResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    Details `json:"data"`
+}
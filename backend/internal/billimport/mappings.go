@@ -0,0 +1,176 @@
+package billimport
+
+import (
+	"strings"
+
+	"xcloud-backend/internal/billing"
+)
+
+// Field 账单明细的标准字段
+type Field string
+
+const (
+	FieldAccountID      Field = "account_id"
+	FieldServiceType    Field = "service_type"
+	FieldResourceID     Field = "resource_id"
+	FieldResourceName   Field = "resource_name"
+	FieldUsageAmount    Field = "usage_amount"
+	FieldUsageUnit      Field = "usage_unit"
+	FieldUnitPrice      Field = "unit_price"
+	FieldOriginalCost   Field = "original_cost"
+	FieldDiscountedCost Field = "discounted_cost"
+	FieldCurrency       Field = "currency"
+	FieldBillingDate    Field = "billing_date"
+	FieldRegion         Field = "region"
+	FieldZone           Field = "zone"
+)
+
+// Mapping 云平台账单文件的列映射
+// Columns 中每个字段按顺序列出候选表头（不区分大小写），取文件中第一个出现的列
+type Mapping struct {
+	Columns map[Field][]string
+	// TagPrefixes 以这些前缀开头的列作为资源标签，去掉前缀后作为标签名
+	TagPrefixes []string
+	// Currency 文件中没有币种列时使用的币种，为空时使用系统默认币种
+	Currency string
+}
+
+// mappings 各云平台账单导出文件的列映射，兼容中英文控制台导出格式
+var mappings = map[billing.Provider]Mapping{
+	// 腾讯云费用中心「资源账单」/「明细账单」导出
+	billing.ProviderTencent: {
+		Columns: map[Field][]string{
+			FieldAccountID:      {"使用者UIN", "支付者UIN", "OwnerUin", "PayerUin"},
+			FieldServiceType:    {"产品名称", "ProductName", "产品编码", "ProductCode"},
+			FieldResourceID:     {"资源ID", "ResourceId"},
+			FieldResourceName:   {"实例名称", "资源别名", "ResourceName"},
+			FieldUsageAmount:    {"组件使用量", "使用量", "UsedAmount"},
+			FieldUsageUnit:      {"组件用量单位", "用量单位", "UsedAmountUnit"},
+			FieldUnitPrice:      {"组件刊例价", "单价", "SinglePrice"},
+			FieldOriginalCost:   {"原价", "刊例价", "TotalCost"},
+			FieldDiscountedCost: {"优惠后总价", "折后总价", "RealTotalCost", "RealCost"},
+			FieldCurrency:       {"币种", "Currency"},
+			FieldBillingDate:    {"扣费时间", "用量开始时间", "FeeBeginTime", "PayTime"},
+			FieldRegion:         {"地域", "RegionName"},
+			FieldZone:           {"可用区", "ZoneName"},
+		},
+		TagPrefixes: []string{"标签:", "Tag:"},
+	},
+	// 阿里云费用中心「账单明细」导出
+	billing.ProviderAlibaba: {
+		Columns: map[Field][]string{
+			FieldAccountID:      {"账号ID", "账号", "OwnerID", "BillAccountID"},
+			FieldServiceType:    {"产品", "产品名称", "ProductName", "产品Code", "ProductCode"},
+			FieldResourceID:     {"实例ID", "InstanceID"},
+			FieldResourceName:   {"实例昵称", "NickName"},
+			FieldUsageAmount:    {"用量", "Usage"},
+			FieldUsageUnit:      {"用量单位", "UsageUnit"},
+			FieldUnitPrice:      {"单价", "官网单价", "ListPrice"},
+			FieldOriginalCost:   {"官网价", "原价", "PretaxGrossAmount"},
+			FieldDiscountedCost: {"应付金额", "优惠后金额", "PretaxAmount"},
+			FieldCurrency:       {"币种", "Currency"},
+			FieldBillingDate:    {"账单日期", "消费时间", "BillingDate", "UsageStartTime"},
+			FieldRegion:         {"地域", "Region"},
+			FieldZone:           {"可用区", "Zone"},
+		},
+		TagPrefixes: []string{"标签:", "Tag:"},
+	},
+	// 华为云费用中心「资源详单」导出
+	billing.ProviderHuawei: {
+		Columns: map[Field][]string{
+			FieldAccountID:      {"账号ID", "账号", "Account ID", "Account"},
+			FieldServiceType:    {"产品类型", "云服务类型", "Service Type", "Product Type"},
+			FieldResourceID:     {"资源ID", "Resource ID"},
+			FieldResourceName:   {"资源名称", "Resource Name"},
+			FieldUsageAmount:    {"使用量", "Usage"},
+			FieldUsageUnit:      {"使用量单位", "Usage Unit"},
+			FieldUnitPrice:      {"单价", "Unit Price"},
+			FieldOriginalCost:   {"官网价", "List Price"},
+			FieldDiscountedCost: {"应付金额", "Amount Due", "Amount"},
+			FieldCurrency:       {"币种", "Currency"},
+			FieldBillingDate:    {"消费时间", "Expenditure Time", "Consumption Time"},
+			FieldRegion:         {"区域", "Region"},
+			FieldZone:           {"可用区", "AZ"},
+		},
+	},
+	// AWS Cost and Usage Report（CUR，兼容 CUR 2.0 列名）
+	billing.ProviderAWS: {
+		Columns: map[Field][]string{
+			FieldAccountID:      {"lineItem/UsageAccountId", "line_item_usage_account_id"},
+			FieldServiceType:    {"lineItem/ProductCode", "line_item_product_code", "product/ProductName", "product_product_name"},
+			FieldResourceID:     {"lineItem/ResourceId", "line_item_resource_id"},
+			FieldResourceName:   {"resourceTags/user:Name", "resource_tags_user_name"},
+			FieldUsageAmount:    {"lineItem/UsageAmount", "line_item_usage_amount"},
+			FieldUsageUnit:      {"pricing/unit", "pricing_unit"},
+			FieldUnitPrice:      {"lineItem/UnblendedRate", "line_item_unblended_rate"},
+			FieldOriginalCost:   {"lineItem/UnblendedCost", "line_item_unblended_cost"},
+			FieldDiscountedCost: {"lineItem/NetUnblendedCost", "line_item_net_unblended_cost"},
+			FieldCurrency:       {"lineItem/CurrencyCode", "line_item_currency_code"},
+			FieldBillingDate:    {"lineItem/UsageStartDate", "line_item_usage_start_date"},
+			FieldRegion:         {"product/region", "product/regionCode", "product_region_code"},
+			FieldZone:           {"lineItem/AvailabilityZone", "line_item_availability_zone"},
+		},
+		TagPrefixes: []string{"resourceTags/user:", "resource_tags_user_"},
+		Currency:    "USD",
+	},
+}
+
+// MappingFor 获取云平台的列映射
+func MappingFor(provider billing.Provider) (Mapping, bool) {
+	m, ok := mappings[provider]
+	return m, ok
+}
+
+// normalizeHeader 统一表头格式：去除BOM和空白，转小写
+func normalizeHeader(h string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+}
+
+// columnIndex 表头到列序号的映射结果
+type columnIndex struct {
+	header []string
+	fields map[Field]int
+	tags   map[int]string
+}
+
+// resolve 按表头解析列位置
+func (m Mapping) resolve(header []string) columnIndex {
+	positions := make(map[string]int, len(header))
+	for i, h := range header {
+		key := normalizeHeader(h)
+		if _, exists := positions[key]; !exists {
+			positions[key] = i
+		}
+	}
+
+	idx := columnIndex{header: header, fields: map[Field]int{}, tags: map[int]string{}}
+	for field, candidates := range m.Columns {
+		for _, name := range candidates {
+			if i, ok := positions[normalizeHeader(name)]; ok {
+				idx.fields[field] = i
+				break
+			}
+		}
+	}
+	for i, h := range header {
+		key := normalizeHeader(h)
+		for _, prefix := range m.TagPrefixes {
+			p := normalizeHeader(prefix)
+			if strings.HasPrefix(key, p) && len(key) > len(p) {
+				// 小写转换不改变字节长度，可按前缀长度截取原始表头中的标签名
+				original := strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+				idx.tags[i] = original[len(p):]
+				break
+			}
+		}
+	}
+	return idx
+}
+
+// complete 是否包含导入所需的最少列：服务类型和任一费用列
+func (idx columnIndex) complete() bool {
+	_, hasService := idx.fields[FieldServiceType]
+	_, hasOriginal := idx.fields[FieldOriginalCost]
+	_, hasDiscounted := idx.fields[FieldDiscountedCost]
+	return hasService && (hasOriginal || hasDiscounted)
+}
@@ -0,0 +1,110 @@
+package billimport
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/internal/billing"
+)
+
+// SyncTypeImport 账单文件导入在同步日志中的类型
+const SyncTypeImport = "import"
+
+// RowError 行级错误，Message 按请求语言由 Code 和 Args 生成
+type RowError struct {
+	Row     int           `json:"row" example:"12"`
+	Column  string        `json:"column,omitempty" example:"原价"`
+	Code    string        `json:"code,omitempty" example:"import.row.invalid_number"`
+	Args    []interface{} `json:"args,omitempty" swaggertype:"array,string"`
+	Message string        `json:"message" example:"数值格式错误: abc"`
+}
+
+// Details 导入任务明细，保存在 sync_logs.details
+type Details struct {
+	FileName        string     `json:"file_name"`
+	Format          Format     `json:"format"`
+	SkipInvalid     bool       `json:"skip_invalid"`
+	Summary         Summary    `json:"summary"`
+	Errors          []RowError `json:"errors"`
+	ErrorsTruncated bool       `json:"errors_truncated"`
+}
+
+// Summary 导入汇总
+type Summary struct {
+	TotalRows           int     `json:"total_rows"`
+	ValidRows           int     `json:"valid_rows"`
+	ErrorRows           int     `json:"error_rows"`
+	TotalOriginalCost   float64 `json:"total_original_cost"`
+	TotalDiscountedCost float64 `json:"total_discounted_cost"`
+}
+
+// ImportRequest 账单文件导入请求（multipart/form-data，文件字段为 file）
+type ImportRequest struct {
+	CustomerID  string           `form:"customer_id" binding:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider    billing.Provider `form:"provider" binding:"required,oneof=tencent alibaba huawei aws" example:"tencent"`
+	Period      string           `form:"period" binding:"required" example:"2024-06"`
+	AccountID   string           `form:"account_id" example:"100012345678"`
+	DryRun      bool             `form:"dry_run" example:"false"`
+	SkipInvalid bool             `form:"skip_invalid" example:"false"`
+}
+
+// PreviewResult 试导入（dry run）结果，不写入数据
+type PreviewResult struct {
+	Summary         Summary               `json:"summary"`
+	Lines           []billing.BillingData `json:"lines"`
+	Errors          []RowError            `json:"errors"`
+	ErrorsTruncated bool                  `json:"errors_truncated"`
+}
+
+// JobResponse 导入任务响应
+type JobResponse struct {
+	ID             uuid.UUID          `json:"id"`
+	CustomerID     uuid.UUID          `json:"customer_id"`
+	Provider       billing.Provider   `json:"provider"`
+	Period         string             `json:"period"`
+	Status         billing.SyncStatus `json:"status"`
+	FileName       string             `json:"file_name,omitempty"`
+	TotalCount     int                `json:"total_count"`
+	ProcessedCount int                `json:"processed_count"`
+	ErrorCount     int                `json:"error_count"`
+	RecordsCount   int                `json:"records_count"`
+	Progress       float64            `json:"progress"`
+	ErrorMessage   string             `json:"error_message,omitempty"`
+	StartTime      time.Time          `json:"start_time"`
+	EndTime        *time.Time         `json:"end_time,omitempty"`
+	CreatedBy      *uuid.UUID         `json:"created_by,omitempty"`
+}
+
+// toJobResponse 将同步日志转换为导入任务响应
+func toJobResponse(log *billing.SyncLog) JobResponse {
+	resp := JobResponse{
+		ID:             log.ID,
+		CustomerID:     log.CustomerID,
+		Provider:       log.Provider,
+		Period:         log.SyncPeriod,
+		Status:         log.Status,
+		TotalCount:     log.TotalCount,
+		ProcessedCount: log.ProcessedCount,
+		ErrorCount:     log.ErrorCount,
+		RecordsCount:   log.RecordsCount,
+		ErrorMessage:   log.ErrorMessage,
+		StartTime:      log.StartTime,
+		EndTime:        log.EndTime,
+		CreatedBy:      log.CreatedBy,
+	}
+
+	var details Details
+	if len(log.Details) > 0 && json.Unmarshal(log.Details, &details) == nil {
+		resp.FileName = details.FileName
+	}
+
+	switch {
+	case log.Status != billing.SyncStatusRunning:
+		resp.Progress = 100
+	case log.TotalCount > 0:
+		resp.Progress = float64(log.ProcessedCount * 100 / log.TotalCount)
+	}
+	return resp
+}
@@ -0,0 +1,232 @@
+package billimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+
+	"xcloud-backend/internal/billing"
+)
+
+// dateLayouts 支持的账单日期格式
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"2006/1/2",
+	"2006-1-2",
+	"1/2/2006",
+	"20060102",
+}
+
+// currencyAliases 账单中常见的中文币种名称
+var currencyAliases = map[string]string{
+	"人民币": "CNY",
+	"元":   "CNY",
+	"美元":  "USD",
+}
+
+// normalizer 将账单文件的行转换为账单明细
+type normalizer struct {
+	mapping     Mapping
+	idx         columnIndex
+	periodStart time.Time
+	periodEnd   time.Time
+	accountID   string
+}
+
+func newNormalizer(m Mapping, idx columnIndex, period time.Time, accountID string) *normalizer {
+	return &normalizer{
+		mapping:     m,
+		idx:         idx,
+		periodStart: period,
+		periodEnd:   period.AddDate(0, 1, 0),
+		accountID:   accountID,
+	}
+}
+
+// cell 读取字段对应的单元格，列不存在或越界时返回空字符串
+func (n *normalizer) cell(row []string, f Field) string {
+	i, ok := n.idx.fields[f]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// column 字段对应的原始表头，用于错误报告
+func (n *normalizer) column(f Field) string {
+	if i, ok := n.idx.fields[f]; ok && i < len(n.idx.header) {
+		return strings.TrimSpace(n.idx.header[i])
+	}
+	return string(f)
+}
+
+// blank 是否为空行
+func blank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// line 转换一行账单，返回该行的全部错误
+func (n *normalizer) line(row []string, rowNum int) (billing.BillingData, []RowError) {
+	var errs []RowError
+	fail := func(f Field, code string, args ...interface{}) {
+		errs = append(errs, RowError{Row: rowNum, Column: n.column(f), Code: code, Args: args})
+	}
+	number := func(f Field) (float64, bool) {
+		raw := n.cell(row, f)
+		if raw == "" {
+			return 0, false
+		}
+		v, err := parseNumber(raw)
+		if err != nil {
+			fail(f, "import.row.invalid_number", raw)
+			return 0, false
+		}
+		return v, true
+	}
+
+	line := billing.BillingData{
+		AccountID:    truncate(n.cell(row, FieldAccountID), 100),
+		ServiceType:  truncate(n.cell(row, FieldServiceType), 50),
+		ResourceID:   truncate(n.cell(row, FieldResourceID), 200),
+		ResourceName: truncate(n.cell(row, FieldResourceName), 200),
+		UsageUnit:    truncate(n.cell(row, FieldUsageUnit), 20),
+		Region:       truncate(n.cell(row, FieldRegion), 50),
+		Zone:         truncate(n.cell(row, FieldZone), 50),
+	}
+	if line.AccountID == "" {
+		line.AccountID = n.accountID
+	}
+	if line.AccountID == "" {
+		fail(FieldAccountID, "import.row.account_missing")
+	}
+	if line.ServiceType == "" {
+		fail(FieldServiceType, "import.row.service_missing")
+	}
+
+	line.UsageAmount, _ = number(FieldUsageAmount)
+	unitPrice, hasUnitPrice := number(FieldUnitPrice)
+	original, hasOriginal := number(FieldOriginalCost)
+	discounted, hasDiscounted := number(FieldDiscountedCost)
+	switch {
+	case hasOriginal && !hasDiscounted:
+		discounted = original
+	case hasDiscounted && !hasOriginal:
+		original = discounted
+	case n.cell(row, FieldOriginalCost) == "" && n.cell(row, FieldDiscountedCost) == "":
+		fail(FieldOriginalCost, "import.row.cost_missing")
+	}
+	if !hasUnitPrice && line.UsageAmount != 0 {
+		unitPrice = original / line.UsageAmount
+	}
+	line.UnitPrice = unitPrice
+	line.OriginalCost = original
+	line.DiscountedCost = discounted
+
+	if raw := n.cell(row, FieldCurrency); raw != "" {
+		currency, ok := normalizeCurrency(raw)
+		if !ok {
+			fail(FieldCurrency, "import.row.invalid_currency", raw)
+		}
+		line.Currency = currency
+	} else {
+		line.Currency = n.mapping.Currency
+	}
+
+	line.BillingDate = n.periodStart
+	if raw := n.cell(row, FieldBillingDate); raw != "" {
+		date, err := parseDate(raw)
+		switch {
+		case err != nil:
+			fail(FieldBillingDate, "import.row.invalid_date", raw)
+		case date.Before(n.periodStart) || !date.Before(n.periodEnd):
+			fail(FieldBillingDate, "import.row.date_out_of_period", date.Format("2006-01-02"))
+		default:
+			line.BillingDate = date
+		}
+	}
+
+	if len(n.idx.tags) > 0 {
+		tags := map[string]string{}
+		for i, name := range n.idx.tags {
+			if i < len(row) && strings.TrimSpace(row[i]) != "" {
+				tags[name] = strings.TrimSpace(row[i])
+			}
+		}
+		if len(tags) > 0 {
+			line.Tags, _ = json.Marshal(tags)
+		}
+	}
+
+	raw := make(map[string]string, len(row))
+	for i, v := range row {
+		if i < len(n.idx.header) && v != "" {
+			raw[strings.TrimSpace(n.idx.header[i])] = v
+		}
+	}
+	line.RawData, _ = json.Marshal(raw)
+
+	return line, errs
+}
+
+// parseNumber 解析金额、用量，兼容千分位和货币符号
+func parseNumber(s string) (float64, error) {
+	s = strings.NewReplacer(",", "", "¥", "", "￥", "", "$", "", " ", "").Replace(s)
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseDate 解析账单日期，兼容Excel日期序列号，仅保留日期部分
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(s, 64); err == nil && serial > 0 {
+		if t, err := excelize.ExcelDateToTime(serial, false); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析日期: %s", s)
+}
+
+// normalizeCurrency 转换为三位ISO币种代码
+func normalizeCurrency(s string) (string, bool) {
+	if code, ok := currencyAliases[s]; ok {
+		return code, true
+	}
+	code := strings.ToUpper(s)
+	if len(code) != 3 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// truncate 按字符数截断超出字段长度的值
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
@@ -0,0 +1,160 @@
+package billimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// Format 账单文件格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// maxHeaderScan 查找表头时最多扫描的行数（部分云平台导出文件在表头前有标题、汇总行）
+const maxHeaderScan = 20
+
+// FormatOf 根据文件名判断文件格式
+func FormatOf(filename string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".xlsx":
+		return FormatXLSX, true
+	default:
+		return "", false
+	}
+}
+
+// rowReader 逐行读取账单文件
+type rowReader interface {
+	// Next 返回下一行，读完时返回 io.EOF
+	Next() ([]string, error)
+	Close() error
+}
+
+// openReader 打开账单文件
+func openReader(path string, format Format) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return openCSV(path)
+	case FormatXLSX:
+		return openXLSX(path)
+	default:
+		return nil, i18n.NewError("import.unsupported_format")
+	}
+}
+
+type csvReader struct {
+	file *os.File
+	r    *csv.Reader
+}
+
+// openCSV 打开CSV文件，非UTF-8编码的文件按GB18030（兼容GBK）解码
+func openCSV(path string) (*csvReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(f, 64*1024)
+	head, _ := br.Peek(4096)
+	var src io.Reader = br
+	if !isUTF8(head) {
+		src = transform.NewReader(br, simplifiedchinese.GB18030.NewDecoder())
+	}
+
+	r := csv.NewReader(src)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.ReuseRecord = false
+	return &csvReader{file: f, r: r}, nil
+}
+
+func (c *csvReader) Next() ([]string, error) {
+	return c.r.Read()
+}
+
+func (c *csvReader) Close() error {
+	return c.file.Close()
+}
+
+// isUTF8 判断文件开头是否为UTF-8编码，忽略被截断的末尾字符
+func isUTF8(b []byte) bool {
+	for i := 0; i < utf8.UTFMax && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return true
+		}
+		b = b[:len(b)-1]
+	}
+	return utf8.Valid(b)
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+// openXLSX 打开Excel文件，读取第一个工作表
+func openXLSX(path string) (*xlsxReader, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, i18n.NewError("import.unsupported_format")
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		f.Close()
+		return nil, i18n.NewError("import.empty_file")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxReader{file: f, rows: rows}, nil
+}
+
+func (x *xlsxReader) Next() ([]string, error) {
+	if !x.rows.Next() {
+		if err := x.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	return x.rows.Columns()
+}
+
+func (x *xlsxReader) Close() error {
+	x.rows.Close()
+	return x.file.Close()
+}
+
+// readHeader 在文件开头查找表头，返回列映射和表头所在行号（从1开始）
+func readHeader(r rowReader, m Mapping) (columnIndex, int, error) {
+	for line := 1; line <= maxHeaderScan; line++ {
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return columnIndex{}, 0, err
+		}
+		if idx := m.resolve(row); idx.complete() {
+			return idx, line, nil
+		}
+	}
+	return columnIndex{}, 0, i18n.NewError("import.header_not_found")
+}
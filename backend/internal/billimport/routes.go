@@ -0,0 +1,18 @@
+package billimport
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册账单文件导入相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("", handler.ListImports)
+	router.GET("/:id", handler.GetImport)
+	router.GET("/:id/errors", handler.GetImportReport)
+	router.POST("", middleware.RequireRole("admin", "manager"), handler.CreateImport)
+}
@@ -0,0 +1,383 @@
+package billimport
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/internal/events"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// previewLines 试导入返回的示例明细行数
+	previewLines = 20
+	// maxReportErrors 错误报告最多保存的行级错误数
+	maxReportErrors = 1000
+	// progressInterval 每处理多少行更新一次任务进度
+	progressInterval = 1000
+	// staleAfter 超过该时长仍处于运行中的导入任务视为已中断（如服务重启），不再阻止重新导入
+	staleAfter = 6 * time.Hour
+)
+
+// Service 账单文件导入服务
+type Service struct {
+	db         *gorm.DB
+	billingSvc *billing.Service
+}
+
+// NewService 创建账单文件导入服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:         db,
+		billingSvc: billing.NewService(db),
+	}
+}
+
+// parseResult 账单文件解析结果，有效明细通过回调逐行交给调用方，不在结果中保存
+type parseResult struct {
+	errors          []RowError
+	errorsTruncated bool
+	summary         Summary
+}
+
+func (r *parseResult) addErrors(errs []RowError) {
+	for _, e := range errs {
+		if len(r.errors) >= maxReportErrors {
+			r.errorsTruncated = true
+			return
+		}
+		r.errors = append(r.errors, e)
+	}
+}
+
+// validate 校验导入请求，返回客户ID和计费周期起始日期
+func (s *Service) validate(req ImportRequest) (uuid.UUID, time.Time, error) {
+	customerID, err := uuid.Parse(req.CustomerID)
+	if err != nil {
+		return uuid.Nil, time.Time{}, i18n.NewError("common.invalid_params")
+	}
+	period, err := billing.ParsePeriod(req.Period)
+	if err != nil {
+		return uuid.Nil, time.Time{}, i18n.NewError("import.invalid_period")
+	}
+	if _, ok := MappingFor(req.Provider); !ok {
+		return uuid.Nil, time.Time{}, i18n.NewError("import.unsupported_provider")
+	}
+
+	var count int64
+	if err := s.db.Model(&customer.Customer{}).Where("id = ?", customerID).Count(&count).Error; err != nil {
+		return uuid.Nil, time.Time{}, err
+	}
+	if count == 0 {
+		return uuid.Nil, time.Time{}, i18n.NewError("import.customer_not_found")
+	}
+	return customerID, period, nil
+}
+
+// parse 解析账单文件
+// onLine 逐行接收有效明细，返回错误时停止解析；progress 在处理过程中回调已处理行数
+func (s *Service) parse(path string, format Format, req ImportRequest, period time.Time, onLine func(billing.BillingData) error, progress func(int)) (*parseResult, error) {
+	mapping, _ := MappingFor(req.Provider)
+	r, err := openReader(path, format)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	idx, headerRow, err := readHeader(r, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	n := newNormalizer(mapping, idx, period, req.AccountID)
+	result := &parseResult{}
+	for rowNum := headerRow + 1; ; rowNum++ {
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(row) {
+			continue
+		}
+
+		result.summary.TotalRows++
+		line, errs := n.line(row, rowNum)
+		if len(errs) > 0 {
+			result.summary.ErrorRows++
+			result.addErrors(errs)
+		} else {
+			result.summary.ValidRows++
+			result.summary.TotalOriginalCost += line.OriginalCost
+			result.summary.TotalDiscountedCost += line.DiscountedCost
+			if err := onLine(line); err != nil {
+				return nil, err
+			}
+		}
+
+		if progress != nil && result.summary.TotalRows%progressInterval == 0 {
+			progress(result.summary.TotalRows)
+		}
+	}
+
+	if result.summary.TotalRows == 0 {
+		return nil, i18n.NewError("import.empty_file")
+	}
+	return result, nil
+}
+
+// countRows 统计文件的数据行数（不含表头之前的内容），用于计算进度
+func countRows(path string, format Format, mapping Mapping) (int, error) {
+	r, err := openReader(path, format)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	if _, _, err := readHeader(r, mapping); err != nil {
+		return 0, err
+	}
+	count := 0
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !blank(row) {
+			count++
+		}
+	}
+}
+
+// Preview 试导入：解析并校验文件，返回汇总、示例明细和错误，不写入数据
+func (s *Service) Preview(req ImportRequest, path string, format Format) (*PreviewResult, error) {
+	_, period, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+	var lines []billing.BillingData
+	result, err := s.parse(path, format, req, period, func(line billing.BillingData) error {
+		if len(lines) < previewLines {
+			lines = append(lines, line)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PreviewResult{
+		Summary:         result.summary,
+		Lines:           lines,
+		Errors:          result.errors,
+		ErrorsTruncated: result.errorsTruncated,
+	}, nil
+}
+
+// Start 创建导入任务并在后台执行，任务完成后删除上传的临时文件
+func (s *Service) Start(req ImportRequest, path string, format Format, fileName string, operator uuid.UUID) (*billing.SyncLog, error) {
+	customerID, period, err := s.validate(req)
+	if err != nil {
+		return nil, err
+	}
+
+	details, _ := json.Marshal(Details{FileName: fileName, Format: format, SkipInvalid: req.SkipInvalid, Errors: []RowError{}})
+	log := &billing.SyncLog{
+		CustomerID: customerID,
+		Provider:   req.Provider,
+		SyncType:   SyncTypeImport,
+		SyncPeriod: req.Period,
+		StartTime:  time.Now(),
+		Status:     billing.SyncStatusRunning,
+		Details:    details,
+		CreatedBy:  &operator,
+	}
+	// 锁定客户记录后再检查和创建任务，同一客户的并发导入请求依次执行，不会同时开始
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var c customer.Customer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+			First(&c, "id = ?", customerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return i18n.NewError("import.customer_not_found")
+			}
+			return err
+		}
+
+		var running int64
+		err := tx.Model(&billing.SyncLog{}).
+			Where("customer_id = ? AND provider = ? AND sync_period = ? AND status = ? AND start_time > ?",
+				customerID, req.Provider, req.Period, billing.SyncStatusRunning, time.Now().Add(-staleAfter)).
+			Count(&running).Error
+		if err != nil {
+			return err
+		}
+		if running > 0 {
+			return i18n.NewError("import.already_running")
+		}
+		return tx.Create(log).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer os.Remove(path)
+		s.run(log.ID, operator, req, customerID, period, path, format, fileName)
+	}()
+	return log, nil
+}
+
+// run 执行导入任务，处理过程中向发起人推送进度
+func (s *Service) run(id, operator uuid.UUID, req ImportRequest, customerID uuid.UUID, period time.Time, path string, format Format, fileName string) {
+	log := logger.GetLogger()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("账单导入任务异常:", id, r)
+			s.finish(id, nil, 0, errors.New("导入任务异常终止"), Details{FileName: fileName, Format: format})
+		}
+	}()
+
+	details := Details{FileName: fileName, Format: format, SkipInvalid: req.SkipInvalid}
+	mapping, _ := MappingFor(req.Provider)
+
+	total, err := countRows(path, format, mapping)
+	if err != nil {
+		s.finish(id, nil, 0, err, details)
+		return
+	}
+	s.db.Model(&billing.SyncLog{}).Where("id = ?", id).UpdateColumn("total_count", total)
+	progress := events.JobProgress{JobType: events.JobBillImport, JobID: id, Status: string(billing.SyncStatusRunning), Total: total}
+	events.PublishJob(&operator, progress)
+
+	// 边解析边写入，存在错误行且未选择跳过、或没有有效明细时回滚，原有账单保持不变
+	var result *parseResult
+	count, err := s.billingSvc.ReplaceStream(customerID, req.Provider, req.Period, func(add func(billing.BillingData) error) error {
+		var err error
+		result, err = s.parse(path, format, req, period, add, func(processed int) {
+			s.db.Model(&billing.SyncLog{}).Where("id = ?", id).UpdateColumn("processed_count", processed)
+			progress.Processed = processed
+			events.PublishJob(&operator, progress)
+		})
+		if err != nil {
+			return err
+		}
+		switch {
+		case result.summary.ErrorRows > 0 && !req.SkipInvalid:
+			return i18n.NewError("import.rows_invalid", result.summary.ErrorRows)
+		case result.summary.ValidRows == 0:
+			return i18n.NewError("import.no_valid_rows")
+		}
+		return nil
+	})
+	if result != nil {
+		details.Summary = result.summary
+		details.Errors = result.errors
+		details.ErrorsTruncated = result.errorsTruncated
+	}
+	s.finish(id, result, count, err, details)
+	if err == nil {
+		log.Info("账单导入完成:", id, " 记录数:", count)
+	}
+}
+
+// finish 更新导入任务的结束状态并向发起人推送
+func (s *Service) finish(id uuid.UUID, result *parseResult, count int, runErr error, details Details) {
+	if details.Errors == nil {
+		details.Errors = []RowError{}
+	}
+	raw, _ := json.Marshal(details)
+
+	updates := map[string]interface{}{
+		"end_time":      time.Now(),
+		"status":        billing.SyncStatusSuccess,
+		"records_count": count,
+		"details":       string(raw),
+	}
+	if result != nil {
+		updates["processed_count"] = result.summary.TotalRows
+		updates["error_count"] = result.summary.ErrorRows
+	}
+	if runErr != nil {
+		updates["status"] = billing.SyncStatusFailed
+		updates["error_message"] = runErr.Error()
+		logger.GetLogger().Error("账单导入失败:", id, runErr)
+	}
+
+	if err := s.db.Model(&billing.SyncLog{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		logger.GetLogger().Error("更新导入任务状态失败:", id, err)
+		return
+	}
+
+	var log billing.SyncLog
+	if err := s.db.First(&log, "id = ?", id).Error; err != nil {
+		logger.GetLogger().Error("获取导入任务失败:", id, err)
+		return
+	}
+	events.PublishJob(log.CreatedBy, events.JobProgress{
+		JobType:   events.JobBillImport,
+		JobID:     log.ID,
+		Status:    string(log.Status),
+		Processed: log.ProcessedCount,
+		Total:     log.TotalCount,
+		Error:     log.ErrorMessage,
+	})
+}
+
+// List 分页获取导入任务，customerID 为空时返回全部客户
+func (s *Service) List(customerID *uuid.UUID, page, pageSize int) ([]billing.SyncLog, int64, error) {
+	query := s.db.Model(&billing.SyncLog{}).Where("sync_type = ?", SyncTypeImport)
+	if customerID != nil {
+		query = query.Where("customer_id = ?", *customerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []billing.SyncLog
+	err := query.Order("start_time DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&logs).Error
+	return logs, total, err
+}
+
+// Get 获取导入任务
+func (s *Service) Get(id uuid.UUID) (*billing.SyncLog, error) {
+	var log billing.SyncLog
+	err := s.db.Where("id = ? AND sync_type = ?", id, SyncTypeImport).First(&log).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("import.not_found")
+		}
+		return nil, err
+	}
+	return &log, nil
+}
+
+// Report 获取导入任务的明细（汇总和行级错误报告）
+func (s *Service) Report(id uuid.UUID) (*Details, error) {
+	log, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	details := &Details{Errors: []RowError{}}
+	if len(log.Details) > 0 {
+		if err := json.Unmarshal(log.Details, details); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
+)
+
+type Handler struct {
+	billingSvc *Service
+	logger     *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		billingSvc: NewService(db),
+		logger:     logger.GetLogger(),
+	}
+}
+
+// ListLines 获取账单明细列表
+// @Summary 获取账单明细列表
+// @Description 游标分页获取账单明细。通用过滤参数为 filter[字段][运算符]=取值，如 filter[billing_period]=2024-01、filter[discounted_cost][gte]=100，
+// @Description 可过滤字段：id、customer_id、provider、account_id、service_type、resource_id、region、billing_period、billing_date、
+// @Description original_cost、discounted_cost、currency、created_at；sort 为逗号分隔的排序字段，- 表示倒序，默认按账单日期倒序
+// @Tags 账单数据
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sort query string false "排序字段" default(-billing_date)
+// @Param limit query int false "每页数量" default(20)
+// @Param cursor query string false "分页游标，取上一次响应的 next_cursor 或 prev_cursor"
+// @Param count query bool false "是否返回总数"
+// @Success 200 {object} LineListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /billing/lines [get]
+func (h *Handler) ListLines(c *gin.Context) {
+	params, err := LineQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		h.respondError(c, "解析账单明细查询参数失败:", err)
+		return
+	}
+
+	lines, page, err := h.billingSvc.ListLines(params)
+	if err != nil {
+		h.respondError(c, "获取账单明细列表失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LineListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: LineListData{
+			Lines:      lines,
+			Pagination: *page,
+		},
+	})
+}
+
+// respondError 业务错误返回400，其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type LineListData struct {
+	Lines      []BillingData `json:"lines"`
+	Pagination query.Page    `json:"pagination"`
+}
+
+type LineListResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    LineListData `json:"data"`
+}
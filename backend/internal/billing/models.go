@@ -0,0 +1,121 @@
+package billing
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Provider 云服务商枚举
+type Provider string
+
+const (
+	ProviderTencent Provider = "tencent" // 腾讯云
+	ProviderAlibaba Provider = "alibaba" // 阿里云
+	ProviderHuawei  Provider = "huawei"  // 华为云
+	ProviderAWS     Provider = "aws"     // AWS
+)
+
+// ProviderValues 全部云服务商取值，用于列表过滤
+var ProviderValues = []string{string(ProviderTencent), string(ProviderAlibaba), string(ProviderHuawei), string(ProviderAWS)}
+
+// IsValid 检查云服务商是否有效
+func (p Provider) IsValid() bool {
+	switch p {
+	case ProviderTencent, ProviderAlibaba, ProviderHuawei, ProviderAWS:
+		return true
+	default:
+		return false
+	}
+}
+
+// PeriodLayout 计费周期格式（YYYY-MM）
+const PeriodLayout = "2006-01"
+
+// ParsePeriod 解析计费周期，返回该月第一天
+func ParsePeriod(period string) (time.Time, error) {
+	t, err := time.ParseInLocation(PeriodLayout, period, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("计费周期格式错误，应为YYYY-MM: %s", period)
+	}
+	return t, nil
+}
+
+// PeriodOf 返回日期所属的计费周期
+func PeriodOf(t time.Time) string {
+	return t.Format(PeriodLayout)
+}
+
+// PartitionTable 返回日期所属的账单分表名称
+func PartitionTable(t time.Time) string {
+	return "billing_data_" + t.Format("200601")
+}
+
+// BillingData 账单明细（按月分表，查询 billing_data_template 会包含全部分表）
+type BillingData struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID     uuid.UUID       `json:"customer_id" gorm:"type:uuid;not null"`
+	Provider       Provider        `json:"provider" gorm:"type:cloud_provider;not null"`
+	AccountID      string          `json:"account_id" gorm:"type:varchar(100);not null"`
+	ServiceType    string          `json:"service_type" gorm:"type:varchar(50);not null"`
+	ResourceID     string          `json:"resource_id,omitempty" gorm:"type:varchar(200)"`
+	ResourceName   string          `json:"resource_name,omitempty" gorm:"type:varchar(200)"`
+	UsageAmount    float64         `json:"usage_amount" gorm:"type:decimal(15,6);not null"`
+	UsageUnit      string          `json:"usage_unit,omitempty" gorm:"type:varchar(20)"`
+	UnitPrice      float64         `json:"unit_price" gorm:"type:decimal(15,6);not null"`
+	OriginalCost   float64         `json:"original_cost" gorm:"type:decimal(15,2);not null"`
+	DiscountedCost float64         `json:"discounted_cost" gorm:"type:decimal(15,2);not null"`
+	Currency       string          `json:"currency" gorm:"type:varchar(3);not null;default:'CNY'"`
+	BillingDate    time.Time       `json:"billing_date" gorm:"type:date;not null"`
+	BillingPeriod  string          `json:"billing_period" gorm:"type:varchar(10);not null"`
+	Region         string          `json:"region,omitempty" gorm:"type:varchar(50)"`
+	Zone           string          `json:"zone,omitempty" gorm:"type:varchar(50)"`
+	Tags           json.RawMessage `json:"tags,omitempty" gorm:"type:jsonb"`
+	RawData        json.RawMessage `json:"-" gorm:"type:jsonb"`
+	SyncAt         time.Time       `json:"sync_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (BillingData) TableName() string {
+	return "billing_data_template"
+}
+
+// SyncStatus 同步状态
+type SyncStatus string
+
+const (
+	SyncStatusRunning SyncStatus = "running" // 同步中
+	SyncStatusSuccess SyncStatus = "success" // 成功
+	SyncStatusFailed  SyncStatus = "failed"  // 失败
+)
+
+// SyncLog 数据同步日志
+type SyncLog struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID     uuid.UUID       `json:"customer_id" gorm:"type:uuid;not null"`
+	Provider       Provider        `json:"provider" gorm:"type:cloud_provider;not null"`
+	SyncType       string          `json:"sync_type" gorm:"type:varchar(50);not null"`
+	SyncPeriod     string          `json:"sync_period,omitempty" gorm:"type:varchar(10)"`
+	StartTime      time.Time       `json:"start_time" gorm:"not null"`
+	EndTime        *time.Time      `json:"end_time,omitempty"`
+	Status         SyncStatus      `json:"status" gorm:"type:varchar(20);not null"`
+	RecordsCount   int             `json:"records_count" gorm:"default:0"`
+	TotalCount     int             `json:"total_count" gorm:"not null;default:0"`
+	ProcessedCount int             `json:"processed_count" gorm:"not null;default:0"`
+	ErrorCount     int             `json:"error_count" gorm:"not null;default:0"`
+	ErrorMessage   string          `json:"error_message,omitempty" gorm:"type:text"`
+	Details        json.RawMessage `json:"-" gorm:"type:jsonb"`
+	CreatedBy      *uuid.UUID      `json:"created_by,omitempty" gorm:"type:uuid"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// TableName 设置表名
+func (SyncLog) TableName() string {
+	return "sync_logs"
+}
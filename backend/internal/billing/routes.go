@@ -0,0 +1,13 @@
+package billing
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterRoutes 注册账单明细查询路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/lines", handler.ListLines)
+}
@@ -0,0 +1,166 @@
+package billing
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/query"
+)
+
+const (
+	// defaultCurrency 未配置 billing.default_currency 时的默认币种
+	defaultCurrency = "CNY"
+	// replaceBatchSize 替换账单时每批写入的明细数
+	replaceBatchSize = 500
+)
+
+// IngestHook 账单明细写入后的回调
+type IngestHook func(customerID uuid.UUID, provider Provider, period string)
+
+var (
+	hooksMu     sync.RWMutex
+	ingestHooks []IngestHook
+)
+
+// OnIngest 注册账单明细写入后的回调，如统计报表缓存失效
+func OnIngest(hook IngestHook) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	ingestHooks = append(ingestHooks, hook)
+}
+
+// notifyIngest 依次执行账单写入回调
+func notifyIngest(customerID uuid.UUID, provider Provider, period string) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, hook := range ingestHooks {
+		hook(customerID, provider, period)
+	}
+}
+
+// Service 账单数据服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建账单数据服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// LineQuery 账单明细列表可过滤、排序的字段，数据导出使用相同的过滤条件
+var LineQuery = query.Resource{
+	Fields: map[string]query.Field{
+		"id":              {Column: "billing_data_template.id", Kind: query.UUID, Sortable: true},
+		"customer_id":     {Column: "billing_data_template.customer_id", Kind: query.UUID},
+		"provider":        {Column: "billing_data_template.provider", Kind: query.Enum, Values: ProviderValues, Sortable: true},
+		"account_id":      {Column: "billing_data_template.account_id", Kind: query.String},
+		"service_type":    {Column: "billing_data_template.service_type", Kind: query.String, Sortable: true},
+		"resource_id":     {Column: "billing_data_template.resource_id", Kind: query.String, Nullable: true},
+		"region":          {Column: "billing_data_template.region", Kind: query.String, Nullable: true},
+		"billing_period":  {Column: "billing_data_template.billing_period", Kind: query.String, Sortable: true},
+		"billing_date":    {Column: "billing_data_template.billing_date", Kind: query.Date, Sortable: true},
+		"original_cost":   {Column: "billing_data_template.original_cost", Kind: query.Number, Sortable: true},
+		"discounted_cost": {Column: "billing_data_template.discounted_cost", Kind: query.Number, Sortable: true},
+		"currency":        {Column: "billing_data_template.currency", Kind: query.String},
+		"created_at":      {Column: "billing_data_template.created_at", Kind: query.Time, Sortable: true},
+	},
+	Sort: "-billing_date",
+	Key:  "id",
+}
+
+// ListLines 按通用查询参数游标分页查询账单明细（不含被替换的明细）
+func (s *Service) ListLines(params *query.Params) ([]BillingData, *query.Page, error) {
+	return query.Find[BillingData](s.db.Model(&BillingData{}), params)
+}
+
+// EnsurePartition 创建指定月份的账单分表（已存在时跳过）
+func (s *Service) EnsurePartition(month time.Time) error {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.Local)
+	return s.db.Exec("SELECT create_billing_data_partition(?::date)", first.Format("2006-01-02")).Error
+}
+
+// ReplaceLines 用新的账单明细替换客户在指定云平台、计费周期的账单
+// 被替换的明细做软删除保留，新明细写入计费周期所在月的分表
+func (s *Service) ReplaceLines(customerID uuid.UUID, provider Provider, period string, lines []BillingData) (int, error) {
+	return s.ReplaceStream(customerID, provider, period, func(add func(BillingData) error) error {
+		for _, line := range lines {
+			if err := add(line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReplaceStream 与 ReplaceLines 相同，但明细由 fill 逐行提供，在同一事务中按批写入，
+// 不需要先把全部明细读入内存。fill 返回错误时事务回滚，原有账单保持不变
+func (s *Service) ReplaceStream(customerID uuid.UUID, provider Provider, period string, fill func(add func(BillingData) error) error) (int, error) {
+	periodStart, err := ParsePeriod(period)
+	if err != nil {
+		return 0, err
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	// 计费周期为自然月，明细都写入该月的分表
+	if err := s.EnsurePartition(periodStart); err != nil {
+		return 0, err
+	}
+	table := PartitionTable(periodStart)
+
+	now := time.Now()
+	currency := sysconfig.NewService(s.db, database.GetRedis()).GetString(sysconfig.KeyDefaultCurrency, defaultCurrency)
+	count := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ? AND provider = ? AND billing_period = ?", customerID, provider, period).
+			Delete(&BillingData{}).Error; err != nil {
+			return err
+		}
+
+		batch := make([]BillingData, 0, replaceBatchSize)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			if err := tx.Table(table).CreateInBatches(batch, replaceBatchSize).Error; err != nil {
+				return err
+			}
+			batch = batch[:0]
+			return nil
+		}
+		add := func(line BillingData) error {
+			if line.BillingDate.Before(periodStart) || !line.BillingDate.Before(periodEnd) {
+				return errors.New("账单日期不在计费周期内: " + line.BillingDate.Format("2006-01-02"))
+			}
+			line.CustomerID = customerID
+			line.Provider = provider
+			line.BillingPeriod = period
+			if line.Currency == "" {
+				line.Currency = currency
+			}
+			line.SyncAt = now
+			batch = append(batch, line)
+			count++
+			if len(batch) >= replaceBatchSize {
+				return flush()
+			}
+			return nil
+		}
+
+		if err := fill(add); err != nil {
+			return err
+		}
+		return flush()
+	})
+	if err != nil {
+		return 0, err
+	}
+	notifyIngest(customerID, provider, period)
+	return count, nil
+}
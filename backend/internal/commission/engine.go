@@ -0,0 +1,512 @@
+package commission
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/currency"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// defaultPrecision 未配置 commission.precision 时的返佣金额精度
+	defaultPrecision = 2
+	// maxPrecision 返佣金额精度上限，与 commission_records.commission_amount 的小数位一致
+	maxPrecision = 4
+)
+
+// Engine 返佣规则引擎
+//
+// 计算口径：
+//   - 合同在计费周期内生效期间的账单明细参与计算，计算基数为折扣后费用
+//   - 按（云平台, 服务类型）汇总客户当期费用，以汇总金额确定适用的阶梯规则，
+//     该组全部明细使用同一返佣比例；服务类型为 "*" 的规则作为兜底
+//   - 规则的固定返佣金额按明细基数占比分摊到该组明细
+//   - 返佣金额按系统配置 commission.precision 的小数位四舍五入
+//   - 阶梯区间和固定返佣金额以合同币种计，明细按计费周期汇率折算为合同币种后汇总；
+//     返佣记录保存账单原币金额，并按计费周期汇率折算为报告币种（billing.reporting_currency）
+//   - 未锁定的返佣记录直接替换为新结果；已支付或已关账的记录不修改，
+//     按账单明细（云平台、账号、服务类型、资源、账单日期）比对新结果，差额写入关联原记录的调整记录
+type Engine struct {
+	db        *gorm.DB
+	precision int
+	reporting string
+	rates     *currency.Converter
+}
+
+// NewEngine 创建返佣规则引擎
+func NewEngine(db *gorm.DB) *Engine {
+	return &Engine{db: db}
+}
+
+// Result 返佣计算结果
+// TotalBase、TotalCommission 为计算后计费周期的返佣合计（含已锁定记录和调整记录），
+// NetDelta 为本次计算前后返佣合计的变动，金额均为报告币种
+type Result struct {
+	RunID             uuid.UUID       `json:"run_id"`
+	Period            string          `json:"period"`
+	ReportingCurrency string          `json:"reporting_currency"`
+	Contracts         int             `json:"contracts"`
+	Records           int             `json:"records"`
+	Adjustments       int             `json:"adjustments"`
+	Unmatched         int             `json:"unmatched"`
+	TotalBase         float64         `json:"total_base"`
+	TotalCommission   float64         `json:"total_commission"`
+	NetDelta          float64         `json:"net_delta"`
+	Customers         []CustomerDelta `json:"customers"`
+}
+
+// Calculate 计算（重算）指定计费周期的返佣，并记录计算任务
+func (e *Engine) Calculate(period string, operator *uuid.UUID) (*Result, error) {
+	periodStart, err := billing.ParsePeriod(period)
+	if err != nil {
+		return nil, i18n.NewError("commission.invalid_period")
+	}
+	periodEnd := periodStart.AddDate(0, 1, 0)
+
+	var contracts []contract.Contract
+	err = e.db.Where("status IN ? AND start_date < ? AND end_date >= ?",
+		[]contract.ContractStatus{contract.StatusActive, contract.StatusExpired, contract.StatusTerminated},
+		periodEnd, periodStart).
+		Order("contract_no").
+		Find(&contracts).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询合同失败: %w", err)
+	}
+
+	e.precision = e.loadPrecision()
+	e.reporting = currency.NewService(e.db).ReportingCurrency()
+	e.rates = currency.NewConverter(e.db, period)
+	result := &Result{Period: period, ReportingCurrency: e.reporting, Contracts: len(contracts), Customers: []CustomerDelta{}}
+	now := time.Now()
+
+	err = e.db.Transaction(func(tx *gorm.DB) error {
+		deltas := map[uuid.UUID]*CustomerDelta{}
+		for _, ct := range contracts {
+			// 锁定合同行，与结算关账互斥
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").
+				First(&contract.Contract{}, "id = ?", ct.ID).Error; err != nil {
+				return err
+			}
+			_, before, err := periodTotals(tx, ct.ID, period)
+			if err != nil {
+				return err
+			}
+
+			fresh, keys, unmatched, err := e.calculateContract(tx, ct, period, periodStart, periodEnd)
+			if err != nil {
+				return err
+			}
+			result.Unmatched += unmatched
+
+			// 清除未锁定的旧记录（含之前生成的未锁定调整记录）后写入新结果
+			if err := tx.Where("contract_id = ? AND billing_period = ? AND status <> ? AND settlement_id IS NULL", ct.ID, period, StatusPaid).
+				Delete(&Record{}).Error; err != nil {
+				return err
+			}
+			records, adjustments, err := e.adjustLocked(tx, ct, period, fresh, keys)
+			if err != nil {
+				return err
+			}
+			for i := range records {
+				records[i].Status = StatusCalculated
+				records[i].CalculatedAt = &now
+				records[i].CreatedBy = operator
+				records[i].UpdatedBy = operator
+			}
+			if len(records) > 0 {
+				if err := tx.CreateInBatches(records, 500).Error; err != nil {
+					return err
+				}
+			}
+			result.Records += len(records) - adjustments
+			result.Adjustments += adjustments
+
+			base, after, err := periodTotals(tx, ct.ID, period)
+			if err != nil {
+				return err
+			}
+			result.TotalBase += base
+			result.TotalCommission += after
+
+			d, ok := deltas[ct.CustomerID]
+			if !ok {
+				d = &CustomerDelta{CustomerID: ct.CustomerID}
+				deltas[ct.CustomerID] = d
+			}
+			d.Before += before
+			d.After += after
+			d.Adjustments += adjustments
+		}
+
+		for _, d := range deltas {
+			d.Before = round(d.Before, e.precision)
+			d.After = round(d.After, e.precision)
+			d.Delta = round(d.After-d.Before, e.precision)
+			result.NetDelta += d.Delta
+			result.Customers = append(result.Customers, *d)
+		}
+		sort.Slice(result.Customers, func(i, j int) bool {
+			a, b := math.Abs(result.Customers[i].Delta), math.Abs(result.Customers[j].Delta)
+			if a != b {
+				return a > b
+			}
+			return result.Customers[i].CustomerID.String() < result.Customers[j].CustomerID.String()
+		})
+		result.TotalBase = round(result.TotalBase, 2)
+		result.TotalCommission = round(result.TotalCommission, e.precision)
+		result.NetDelta = round(result.NetDelta, e.precision)
+
+		customers, err := json.Marshal(result.Customers)
+		if err != nil {
+			return err
+		}
+		run := Run{
+			BillingPeriod:     period,
+			ReportingCurrency: e.reporting,
+			Contracts:         result.Contracts,
+			Records:           result.Records,
+			Adjustments:       result.Adjustments,
+			Unmatched:         result.Unmatched,
+			TotalBase:         result.TotalBase,
+			TotalCommission:   result.TotalCommission,
+			NetDelta:          result.NetDelta,
+			Customers:         customers,
+			CreatedBy:         operator,
+		}
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		result.RunID = run.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// periodTotals 合同在计费周期的返佣基数、返佣金额合计（报告币种，含全部记录类型）
+func periodTotals(tx *gorm.DB, contractID uuid.UUID, period string) (float64, float64, error) {
+	var totals struct {
+		Base       float64
+		Commission float64
+	}
+	err := tx.Model(&Record{}).
+		Select("COALESCE(SUM(base_amount_reporting), 0) AS base, COALESCE(SUM(commission_amount_reporting), 0) AS commission").
+		Where("contract_id = ? AND billing_period = ?", contractID, period).
+		Scan(&totals).Error
+	return totals.Base, totals.Commission, err
+}
+
+// lineKey 账单明细的业务键，用于在账单重新同步（明细ID变化）后匹配已锁定的返佣记录
+func lineKey(line billing.BillingData) string {
+	return string(line.Provider) + "|" + line.AccountID + "|" + line.ServiceType + "|" + line.ResourceID + "|" + line.BillingDate.Format("2006-01-02")
+}
+
+// adjustLocked 将新计算结果与已锁定记录比对
+// 没有已锁定记录的明细直接使用新结果；有已锁定记录的明细不再写入新结果，
+// 新结果与已锁定记录（含已锁定的调整记录）合计的差额生成调整记录，
+// 返回待写入的记录及其中调整记录的数量
+func (e *Engine) adjustLocked(tx *gorm.DB, ct contract.Contract, period string, fresh []Record, keys map[uuid.UUID]string) ([]Record, int, error) {
+	var locked []Record
+	err := tx.Where("contract_id = ? AND billing_period = ? AND (status = ? OR settlement_id IS NOT NULL)", ct.ID, period, StatusPaid).
+		Order("created_at").
+		Find(&locked).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("查询已锁定返佣记录失败: %w", err)
+	}
+	if len(locked) == 0 {
+		return fresh, 0, nil
+	}
+
+	// 已锁定记录对应的明细可能已被重新同步替换（软删除），按业务键匹配
+	lineIDs := make([]uuid.UUID, 0, len(locked))
+	for _, r := range locked {
+		lineIDs = append(lineIDs, r.BillingDataID)
+	}
+	var lines []billing.BillingData
+	if err := tx.Unscoped().
+		Select("id", "provider", "account_id", "service_type", "resource_id", "billing_date").
+		Where("id IN ?", lineIDs).
+		Find(&lines).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询账单数据失败: %w", err)
+	}
+	lockedKeys := make(map[uuid.UUID]string, len(lines))
+	for _, line := range lines {
+		lockedKeys[line.ID] = lineKey(line)
+	}
+
+	lockedByKey := map[string][]Record{}
+	var order []string
+	for _, r := range locked {
+		key, ok := lockedKeys[r.BillingDataID]
+		if !ok {
+			key = "record:" + r.ID.String()
+		}
+		if _, seen := lockedByKey[key]; !seen {
+			order = append(order, key)
+		}
+		lockedByKey[key] = append(lockedByKey[key], r)
+	}
+
+	freshByKey := map[string][]Record{}
+	var records []Record
+	for _, r := range fresh {
+		key := keys[r.BillingDataID]
+		if _, ok := lockedByKey[key]; ok {
+			freshByKey[key] = append(freshByKey[key], r)
+			continue
+		}
+		records = append(records, r)
+	}
+
+	adjustments := 0
+	for _, key := range order {
+		if adj, ok := e.adjustment(lockedByKey[key], freshByKey[key]); ok {
+			records = append(records, adj)
+			adjustments++
+		}
+	}
+	return records, adjustments, nil
+}
+
+// adjustment 计算同一明细新结果与已锁定记录合计的差额，差额可忽略时返回 false
+func (e *Engine) adjustment(locked, fresh []Record) (Record, bool) {
+	var base, commission, baseReporting, commissionReporting float64
+	for _, r := range fresh {
+		base += r.BaseAmount
+		commission += r.CommissionAmount
+		baseReporting += r.BaseAmountReporting
+		commissionReporting += r.CommissionAmountReporting
+	}
+	for _, r := range locked {
+		base -= r.BaseAmount
+		commission -= r.CommissionAmount
+		baseReporting -= r.BaseAmountReporting
+		commissionReporting -= r.CommissionAmountReporting
+	}
+
+	minUnit := math.Pow10(-e.precision) / 2
+	if math.Abs(commissionReporting) < minUnit && math.Abs(baseReporting) < 0.005 {
+		return Record{}, false
+	}
+
+	// 调整记录关联首个已锁定的返佣记录，明细、规则等信息优先取自新结果
+	anchor := locked[0]
+	for _, r := range locked {
+		if r.RecordType != RecordAdjustment {
+			anchor = r
+			break
+		}
+	}
+	anchorID := anchor.ID
+	if anchor.RecordType == RecordAdjustment && anchor.AdjustsRecordID != nil {
+		anchorID = *anchor.AdjustsRecordID
+	}
+	template := anchor
+	if len(fresh) > 0 {
+		template = fresh[0]
+	}
+
+	return Record{
+		BillingDataID:             template.BillingDataID,
+		CustomerID:                anchor.CustomerID,
+		ContractID:                anchor.ContractID,
+		ContractVersionID:         template.ContractVersionID,
+		RuleID:                    template.RuleID,
+		Provider:                  template.Provider,
+		ServiceType:               template.ServiceType,
+		BaseAmount:                round(base, 2),
+		CommissionRate:            template.CommissionRate,
+		CommissionAmount:          round(commission, e.precision),
+		Currency:                  template.Currency,
+		ReportingCurrency:         e.reporting,
+		ExchangeRate:              template.ExchangeRate,
+		BaseAmountReporting:       round(baseReporting, 2),
+		CommissionAmountReporting: round(commissionReporting, e.precision),
+		BillingPeriod:             anchor.BillingPeriod,
+		RecordType:                RecordAdjustment,
+		AdjustsRecordID:           &anchorID,
+	}, true
+}
+
+// loadPrecision 读取返佣金额精度配置，限定在 0 到 maxPrecision 之间
+func (e *Engine) loadPrecision() int {
+	p := sysconfig.NewService(e.db, database.GetRedis()).GetInt(sysconfig.KeyCommissionPrecision, defaultPrecision)
+	if p < 0 {
+		return 0
+	}
+	if p > maxPrecision {
+		return maxPrecision
+	}
+	return p
+}
+
+type groupKey struct {
+	provider    billing.Provider
+	serviceType string
+}
+
+// calculateContract 计算单个合同的返佣记录（不写库），同时返回明细ID到业务键的映射
+func (e *Engine) calculateContract(tx *gorm.DB, ct contract.Contract, period string, periodStart, periodEnd time.Time) ([]Record, map[uuid.UUID]string, int, error) {
+	var rules []Rule
+	err := tx.Where("contract_id = ? AND is_active = true AND effective_date < ? AND (expiry_date IS NULL OR expiry_date >= ?)",
+		ct.ID, periodEnd, periodStart).
+		Order("tier_min").
+		Find(&rules).Error
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("查询返佣规则失败: %w", err)
+	}
+	if len(rules) == 0 {
+		return nil, nil, 0, nil
+	}
+
+	from, to := periodStart, periodEnd
+	if ct.StartDate.After(from) {
+		from = ct.StartDate
+	}
+	if contractEnd := ct.EndDate.AddDate(0, 0, 1); contractEnd.Before(to) {
+		to = contractEnd
+	}
+
+	var lines []billing.BillingData
+	err = tx.Where("customer_id = ? AND billing_period = ? AND billing_date >= ? AND billing_date < ?",
+		ct.CustomerID, period, from, to).
+		Find(&lines).Error
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("查询账单数据失败: %w", err)
+	}
+
+	// 每条明细按账单日期关联当时生效的合同版本
+	versions, err := contract.LoadVersions(tx, &ct)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	groups := map[groupKey][]billing.BillingData{}
+	totals := map[groupKey]float64{}
+	keys := make(map[uuid.UUID]string, len(lines))
+	for _, line := range lines {
+		key := groupKey{line.Provider, line.ServiceType}
+		cost, err := e.rates.Convert(line.DiscountedCost, lineCurrency(line), contractCurrency(ct))
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		totals[key] += cost
+		groups[key] = append(groups[key], line)
+		keys[line.ID] = lineKey(line)
+	}
+
+	var records []Record
+	unmatched := 0
+	for key, group := range groups {
+		rule := selectRule(rules, key.provider, key.serviceType, totals[key])
+		if rule == nil {
+			unmatched += len(group)
+			continue
+		}
+		built, err := e.buildRecords(ct, versions, *rule, group, totals[key], period)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		records = append(records, built...)
+	}
+	return records, keys, unmatched, nil
+}
+
+// selectRule 按服务类型精确匹配优先，再按阶梯区间选择规则
+func selectRule(rules []Rule, provider billing.Provider, serviceType string, total float64) *Rule {
+	var fallback *Rule
+	for i := range rules {
+		r := &rules[i]
+		if !r.Matches(provider, serviceType) || !r.InTier(total) {
+			continue
+		}
+		if r.ServiceType == serviceType {
+			return r
+		}
+		if fallback == nil {
+			fallback = r
+		}
+	}
+	return fallback
+}
+
+// buildRecords 生成一组明细的返佣记录
+// groupTotal 为该组明细折算为合同币种后的合计，固定返佣金额按合同币种占比分摊后折回明细币种
+func (e *Engine) buildRecords(ct contract.Contract, versions []contract.Version, rule Rule, lines []billing.BillingData, groupTotal float64, period string) ([]Record, error) {
+	records := make([]Record, 0, len(lines))
+	fixed := 0.0
+	if rule.FixedAmount != nil && groupTotal != 0 {
+		fixed = *rule.FixedAmount
+	}
+
+	for _, line := range lines {
+		amount := line.DiscountedCost * rule.CommissionRate
+		if fixed != 0 {
+			// fixed × (明细合同币种金额 / groupTotal) 折回明细币种，汇率相互抵消
+			amount += fixed * line.DiscountedCost / groupTotal
+		}
+		rate, err := e.rates.Rate(lineCurrency(line), e.reporting)
+		if err != nil {
+			return nil, err
+		}
+		var versionID *uuid.UUID
+		if v := contract.VersionAt(versions, line.BillingDate); v != nil {
+			versionID = &v.ID
+		}
+		records = append(records, Record{
+			BillingDataID:             line.ID,
+			CustomerID:                ct.CustomerID,
+			ContractID:                ct.ID,
+			ContractVersionID:         versionID,
+			RuleID:                    rule.ID,
+			Provider:                  line.Provider,
+			ServiceType:               line.ServiceType,
+			BaseAmount:                round(line.DiscountedCost, 2),
+			CommissionRate:            rule.CommissionRate,
+			CommissionAmount:          round(amount, e.precision),
+			Currency:                  lineCurrency(line),
+			ReportingCurrency:         e.reporting,
+			ExchangeRate:              rate,
+			BaseAmountReporting:       round(line.DiscountedCost*rate, 2),
+			CommissionAmountReporting: round(amount*rate, e.precision),
+			BillingPeriod:             period,
+		})
+	}
+	return records, nil
+}
+
+// contractCurrency 合同币种，未设置时按默认币种处理
+func contractCurrency(ct contract.Contract) string {
+	if ct.Currency == "" {
+		return currency.DefaultReportingCurrency
+	}
+	return currency.Normalize(ct.Currency)
+}
+
+// lineCurrency 账单明细币种，未设置时按默认币种处理
+func lineCurrency(line billing.BillingData) string {
+	if line.Currency == "" {
+		return currency.DefaultReportingCurrency
+	}
+	return currency.Normalize(line.Currency)
+}
+
+// round 按指定小数位四舍五入
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,302 @@
+package commission
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
+)
+
+type Handler struct {
+	db            *gorm.DB
+	commissionSvc *Service
+	logger        *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		db:            db,
+		commissionSvc: NewService(db),
+		logger:        logger.GetLogger(),
+	}
+}
+
+// Recalculate 返佣重算
+// @Summary 返佣重算
+// @Description 重新执行计费周期的返佣计算：未锁定的记录直接替换，已支付或已关账的记录保持不变，
+// @Description 差额写入关联原记录的调整记录，返回按客户的净变动（需要管理员或经理权限）
+// @Tags 返佣计算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RecalculateRequest true "重算参数"
+// @Success 201 {object} RecalculateResponse "计算完成"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /commission/recalculations [post]
+func (h *Handler) Recalculate(c *gin.Context) {
+	var req RecalculateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	operator, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return
+	}
+
+	result, err := NewEngine(h.db).Calculate(req.Period, &operator)
+	if err != nil {
+		h.respondError(c, "返佣重算失败:", err)
+		return
+	}
+
+	h.logger.Info("返佣重算完成:", result.Period, " 调整记录:", result.Adjustments, " 净变动:", result.NetDelta)
+	c.JSON(http.StatusCreated, RecalculateResponse{
+		Code:    201,
+		Message: i18n.T(c, "commission.recalculated"),
+		Data:    *result,
+	})
+}
+
+// ListRuns 获取返佣计算任务列表
+// @Summary 获取返佣计算任务列表
+// @Description 分页获取返佣计算/重算任务汇总，按创建时间倒序
+// @Tags 返佣计算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "计费周期（YYYY-MM）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} RunListResponse "获取成功"
+// @Router /commission/recalculations [get]
+func (h *Handler) ListRuns(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	runs, total, err := h.commissionSvc.ListRuns(c.Query("period"), page, pageSize)
+	if err != nil {
+		h.logger.Error("获取返佣计算任务列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RunListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: RunListData{
+			Runs:     runs,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetRun 获取返佣计算任务
+// @Summary 获取返佣计算任务
+// @Description 获取返佣计算任务汇总及按客户的净变动
+// @Tags 返佣计算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "计算任务ID"
+// @Success 200 {object} RunResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "计算任务不存在"
+// @Router /commission/recalculations/{id} [get]
+func (h *Handler) GetRun(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	run, err := h.commissionSvc.GetRun(id)
+	if err != nil {
+		h.respondError(c, "获取返佣计算任务失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RunResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *run,
+	})
+}
+
+// ListRecords 获取返佣记录列表
+// @Summary 获取返佣记录列表
+// @Description 游标分页获取返佣记录。通用过滤参数为 filter[字段][运算符]=取值，如 filter[billing_period]=2024-01、filter[status][in]=calculated,paid，
+// @Description 可过滤字段：id、customer_id、contract_id、rule_id、provider、service_type、billing_period、base_amount、commission_amount、currency、
+// @Description status、record_type、settlement_id、paid_at、created_at；sort 为逗号分隔的排序字段，- 表示倒序，默认按计费周期和创建时间倒序
+// @Tags 返佣计算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sort query string false "排序字段" default(-billing_period,-created_at)
+// @Param limit query int false "每页数量" default(20)
+// @Param cursor query string false "分页游标，取上一次响应的 next_cursor 或 prev_cursor"
+// @Param count query bool false "是否返回总数"
+// @Success 200 {object} RecordListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /commission/records [get]
+func (h *Handler) ListRecords(c *gin.Context) {
+	params, err := RecordQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		h.respondError(c, "解析返佣记录查询参数失败:", err)
+		return
+	}
+
+	records, page, err := h.commissionSvc.ListRecords(params)
+	if err != nil {
+		h.respondError(c, "获取返佣记录列表失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecordListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: RecordListData{
+			Records:    records,
+			Pagination: *page,
+		},
+	})
+}
+
+// ListAdjustments 获取返佣记录的调整记录
+// @Summary 获取返佣记录的调整记录
+// @Description 获取已锁定返佣记录在重算中产生的调整记录
+// @Tags 返佣计算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "返佣记录ID"
+// @Success 200 {object} AdjustmentListResponse "获取成功"
+// @Router /commission/records/{id}/adjustments [get]
+func (h *Handler) ListAdjustments(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	records, err := h.commissionSvc.Adjustments(id)
+	if err != nil {
+		h.logger.Error("获取调整记录失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, AdjustmentListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    records,
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		if i18nErr.Code == "commission.run_not_found" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type RecalculateResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Result `json:"data"`
+}
+
+type RunResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Run    `json:"data"`
+}
+
+type RunListData struct {
+	Runs     []Run `json:"runs"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+type RunListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    RunListData `json:"data"`
+}
+
+type RecordListData struct {
+	Records    []Record   `json:"records"`
+	Pagination query.Page `json:"pagination"`
+}
+
+type RecordListResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    RecordListData `json:"data"`
+}
+
+type AdjustmentListResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    []Record `json:"data"`
+}
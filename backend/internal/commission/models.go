@@ -0,0 +1,148 @@
+package commission
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+)
+
+// RecordStatus 返佣记录状态枚举
+type RecordStatus string
+
+const (
+	StatusPending    RecordStatus = "pending"    // 待计算
+	StatusCalculated RecordStatus = "calculated" // 已计算
+	StatusPaid       RecordStatus = "paid"       // 已支付
+)
+
+// RecordType 返佣记录类型
+type RecordType string
+
+const (
+	RecordCommission RecordType = "commission" // 规则引擎计算的返佣
+	RecordAdjustment RecordType = "adjustment" // 已锁定记录重算后的差额调整
+)
+
+// AllServices 适用于全部服务类型的返佣规则
+const AllServices = "*"
+
+// Rule 返佣规则
+type Rule struct {
+	ID             uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ContractID     uuid.UUID        `json:"contract_id" gorm:"type:uuid;not null"`
+	Provider       billing.Provider `json:"provider" gorm:"type:cloud_provider;not null"`
+	ServiceType    string           `json:"service_type" gorm:"type:varchar(50);not null"`
+	TierMin        float64          `json:"tier_min" gorm:"type:decimal(15,2);not null;default:0"`
+	TierMax        *float64         `json:"tier_max,omitempty" gorm:"type:decimal(15,2)"`
+	CommissionRate float64          `json:"commission_rate" gorm:"type:decimal(5,4);not null"`
+	FixedAmount    *float64         `json:"fixed_amount,omitempty" gorm:"type:decimal(15,2)"`
+	IsActive       bool             `json:"is_active" gorm:"not null;default:true"`
+	EffectiveDate  time.Time        `json:"effective_date" gorm:"type:date;not null"`
+	ExpiryDate     *time.Time       `json:"expiry_date,omitempty" gorm:"type:date"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	CreatedBy      *uuid.UUID       `json:"created_by,omitempty"`
+	UpdatedBy      *uuid.UUID       `json:"updated_by,omitempty"`
+	DeletedAt      gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Rule) TableName() string {
+	return "commission_rules"
+}
+
+// Matches 检查规则是否适用于指定的云平台和服务类型
+func (r *Rule) Matches(provider billing.Provider, serviceType string) bool {
+	return r.Provider == provider && (r.ServiceType == serviceType || r.ServiceType == AllServices)
+}
+
+// InTier 检查金额是否落在规则的阶梯区间 [tier_min, tier_max)
+func (r *Rule) InTier(amount float64) bool {
+	if amount < r.TierMin {
+		return false
+	}
+	return r.TierMax == nil || amount < *r.TierMax
+}
+
+// Record 返佣记录
+// BaseAmount、CommissionAmount 为账单原币（Currency）金额，
+// *Reporting 为按计费周期汇率 ExchangeRate 折算的报告币种金额。
+// 已支付或已关账（SettlementID 非空）的记录被锁定，重算差额以调整记录（RecordType 为 adjustment）写入
+type Record struct {
+	ID                        uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BillingDataID             uuid.UUID        `json:"billing_data_id" gorm:"type:uuid;not null"`
+	CustomerID                uuid.UUID        `json:"customer_id" gorm:"type:uuid;not null"`
+	ContractID                uuid.UUID        `json:"contract_id" gorm:"type:uuid;not null"`
+	ContractVersionID         *uuid.UUID       `json:"contract_version_id,omitempty" gorm:"type:uuid"`
+	RuleID                    uuid.UUID        `json:"rule_id" gorm:"type:uuid;not null"`
+	Provider                  billing.Provider `json:"provider" gorm:"type:cloud_provider;not null"`
+	ServiceType               string           `json:"service_type" gorm:"type:varchar(50);not null"`
+	BaseAmount                float64          `json:"base_amount" gorm:"type:decimal(15,2);not null"`
+	CommissionRate            float64          `json:"commission_rate" gorm:"type:decimal(5,4);not null"`
+	CommissionAmount          float64          `json:"commission_amount" gorm:"type:decimal(15,4);not null"`
+	Currency                  string           `json:"currency" gorm:"type:varchar(3);not null;default:'CNY'"`
+	ReportingCurrency         string           `json:"reporting_currency" gorm:"type:varchar(3);not null;default:'CNY'"`
+	ExchangeRate              float64          `json:"exchange_rate" gorm:"type:decimal(18,8);not null;default:1"`
+	BaseAmountReporting       float64          `json:"base_amount_reporting" gorm:"type:decimal(15,2);not null"`
+	CommissionAmountReporting float64          `json:"commission_amount_reporting" gorm:"type:decimal(15,4);not null"`
+	Status                    RecordStatus     `json:"status" gorm:"type:commission_status;not null;default:'pending'"`
+	BillingPeriod             string           `json:"billing_period" gorm:"type:varchar(10);not null"`
+	CalculatedAt              *time.Time       `json:"calculated_at,omitempty"`
+	PaidAt                    *time.Time       `json:"paid_at,omitempty"`
+	PaymentReference          string           `json:"payment_reference,omitempty" gorm:"type:varchar(100)"`
+	SettlementID              *uuid.UUID       `json:"settlement_id,omitempty" gorm:"type:uuid"`
+	RecordType                RecordType       `json:"record_type" gorm:"type:varchar(20);not null;default:'commission'"`
+	AdjustsRecordID           *uuid.UUID       `json:"adjusts_record_id,omitempty" gorm:"type:uuid"`
+	CreatedAt                 time.Time        `json:"created_at"`
+	UpdatedAt                 time.Time        `json:"updated_at"`
+	CreatedBy                 *uuid.UUID       `json:"created_by,omitempty"`
+	UpdatedBy                 *uuid.UUID       `json:"updated_by,omitempty"`
+	DeletedAt                 gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Record) TableName() string {
+	return "commission_records"
+}
+
+// CustomerDelta 单个客户在一次计算中的返佣金额变动（报告币种）
+type CustomerDelta struct {
+	CustomerID  uuid.UUID `json:"customer_id"`
+	Before      float64   `json:"before"`
+	After       float64   `json:"after"`
+	Delta       float64   `json:"delta"`
+	Adjustments int       `json:"adjustments"`
+}
+
+// Run 返佣计算任务，记录每次计算/重算的汇总
+type Run struct {
+	ID                uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BillingPeriod     string          `json:"billing_period" gorm:"type:varchar(10);not null"`
+	ReportingCurrency string          `json:"reporting_currency" gorm:"type:varchar(3);not null"`
+	Contracts         int             `json:"contracts" gorm:"not null;default:0"`
+	Records           int             `json:"records" gorm:"not null;default:0"`
+	Adjustments       int             `json:"adjustments" gorm:"not null;default:0"`
+	Unmatched         int             `json:"unmatched" gorm:"not null;default:0"`
+	TotalBase         float64         `json:"total_base" gorm:"type:decimal(15,2);not null;default:0"`
+	TotalCommission   float64         `json:"total_commission" gorm:"type:decimal(15,4);not null;default:0"`
+	NetDelta          float64         `json:"net_delta" gorm:"type:decimal(15,4);not null;default:0"`
+	Customers         json.RawMessage `json:"customers,omitempty" gorm:"type:jsonb"`
+	CreatedAt         time.Time       `json:"created_at"`
+	CreatedBy         *uuid.UUID      `json:"created_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Run) TableName() string {
+	return "commission_runs"
+}
+
+// 请求结构体
+
+// RecalculateRequest 返佣重算请求
+type RecalculateRequest struct {
+	Period string `json:"period" binding:"required" example:"2024-06"`
+}
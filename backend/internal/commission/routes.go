@@ -0,0 +1,19 @@
+package commission
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册返佣计算相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/recalculations", handler.ListRuns)
+	router.GET("/recalculations/:id", handler.GetRun)
+	router.POST("/recalculations", middleware.RequireRole("admin", "manager"), handler.Recalculate)
+	router.GET("/records", handler.ListRecords)
+	router.GET("/records/:id/adjustments", handler.ListAdjustments)
+}
@@ -0,0 +1,93 @@
+package commission
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/query"
+)
+
+// Service 返佣计算任务查询服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建返佣计算任务查询服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ListRuns 分页查询返佣计算任务，按创建时间倒序
+func (s *Service) ListRuns(period string, page, pageSize int) ([]Run, int64, error) {
+	query := s.db.Model(&Run{})
+	if period != "" {
+		query = query.Where("billing_period = ?", period)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []Run
+	err := query.Omit("customers").Order("created_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&runs).Error
+	return runs, total, err
+}
+
+// RecordQuery 返佣记录列表可过滤、排序的字段，数据导出使用相同的过滤条件
+var RecordQuery = query.Resource{
+	Fields: map[string]query.Field{
+		"id":                {Column: "commission_records.id", Kind: query.UUID, Sortable: true},
+		"customer_id":       {Column: "commission_records.customer_id", Kind: query.UUID},
+		"contract_id":       {Column: "commission_records.contract_id", Kind: query.UUID},
+		"rule_id":           {Column: "commission_records.rule_id", Kind: query.UUID},
+		"provider":          {Column: "commission_records.provider", Kind: query.Enum, Values: billing.ProviderValues, Sortable: true},
+		"service_type":      {Column: "commission_records.service_type", Kind: query.String, Sortable: true},
+		"billing_period":    {Column: "commission_records.billing_period", Kind: query.String, Sortable: true},
+		"base_amount":       {Column: "commission_records.base_amount", Kind: query.Number, Sortable: true},
+		"commission_amount": {Column: "commission_records.commission_amount", Kind: query.Number, Sortable: true},
+		"currency":          {Column: "commission_records.currency", Kind: query.String},
+		"status": {
+			Column: "commission_records.status", Kind: query.Enum, Sortable: true,
+			Values: []string{string(StatusPending), string(StatusCalculated), string(StatusPaid)},
+		},
+		"record_type": {
+			Column: "commission_records.record_type", Kind: query.Enum,
+			Values: []string{string(RecordCommission), string(RecordAdjustment)},
+		},
+		"settlement_id": {Column: "commission_records.settlement_id", Kind: query.UUID, Nullable: true},
+		"paid_at":       {Column: "commission_records.paid_at", Kind: query.Time, Nullable: true},
+		"created_at":    {Column: "commission_records.created_at", Kind: query.Time, Sortable: true},
+	},
+	Sort: "-billing_period,-created_at",
+	Key:  "id",
+}
+
+// ListRecords 按通用查询参数游标分页查询返佣记录
+func (s *Service) ListRecords(params *query.Params) ([]Record, *query.Page, error) {
+	return query.Find[Record](s.db.Model(&Record{}), params)
+}
+
+// GetRun 获取返佣计算任务（含按客户的变动）
+func (s *Service) GetRun(id uuid.UUID) (*Run, error) {
+	var run Run
+	if err := s.db.First(&run, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("commission.run_not_found")
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Adjustments 查询返佣记录的调整记录
+func (s *Service) Adjustments(recordID uuid.UUID) ([]Record, error) {
+	var records []Record
+	err := s.db.Where("adjusts_record_id = ?", recordID).Order("created_at").Find(&records).Error
+	return records, err
+}
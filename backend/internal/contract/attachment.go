@@ -0,0 +1,265 @@
+package contract
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "mime"
+    "path"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+    "unicode"
+    "unicode/utf8"
+
+    "github.com/gabriel-vasile/mimetype"
+    "github.com/google/uuid"
+    "github.com/spf13/viper"
+    "gorm.io/gorm"
+
+    "xcloud-backend/pkg/i18n"
+    "xcloud-backend/pkg/logger"
+    "xcloud-backend/pkg/storage"
+)
+
+const (
+    // sniffSize 识别文件类型读取的文件头长度
+    sniffSize = 3072
+    // maxFileNameBytes 附件文件名最大长度
+    maxFileNameBytes = 255
+)
+
+// blockedExtensions 禁止上传的可执行文件和脚本扩展名，文件名中任一段扩展名命中即拒绝（防止 scan.exe.pdf）
+var blockedExtensions = map[string]bool{
+    "exe": true, "com": true, "bat": true, "cmd": true, "msi": true, "dll": true, "scr": true,
+    "ps1": true, "vbs": true, "js": true, "jar": true, "sh": true, "php": true, "py": true,
+    "html": true, "htm": true, "svg": true, "hta": true, "lnk": true,
+}
+
+// UploadAttachment 上传合同附件：校验文件名、大小和类型（按文件内容识别），计算 SHA-256 后写入存储
+func (s *Service) UploadAttachment(ctx context.Context, contractID uuid.UUID, fileName string, r io.Reader, size int64, operator uuid.UUID) (*Attachment, error) {
+    name, err := cleanFileName(fileName)
+    if err != nil {
+        return nil, err
+    }
+    if size <= 0 {
+        return nil, i18n.NewError("contract.attachment_empty")
+    }
+    if maxSize := viper.GetInt64("upload.max_size"); size > maxSize {
+        return nil, i18n.NewError("contract.attachment_too_large", maxSize>>20)
+    }
+    if _, err := s.find(s.db, contractID, false); err != nil {
+        return nil, err
+    }
+
+    head := make([]byte, sniffSize)
+    n, err := io.ReadFull(r, head)
+    if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+        return nil, fmt.Errorf("读取上传文件失败: %w", err)
+    }
+    head = head[:n]
+    detected := mimetype.Detect(head)
+    if !allowedType(detected) {
+        return nil, i18n.NewError("contract.attachment_type_not_allowed", detected.String())
+    }
+    ext := strings.ToLower(path.Ext(name))
+    if !extensionMatches(ext, detected) {
+        return nil, i18n.NewError("contract.attachment_extension_mismatch", ext, detected.String())
+    }
+
+    store, err := storage.Default()
+    if err != nil {
+        return nil, err
+    }
+    att := &Attachment{
+        ID:          uuid.New(),
+        ContractID:  contractID,
+        FileName:    name,
+        ContentType: detected.String(),
+        Size:        size,
+        Storage:     store.Name(),
+        UploadedBy:  operator,
+    }
+    att.StorageKey = fmt.Sprintf("contracts/%s/%s%s", contractID, att.ID, ext)
+
+    hasher := sha256.New()
+    body := io.TeeReader(io.MultiReader(bytes.NewReader(head), r), hasher)
+    if err := store.Put(ctx, att.StorageKey, body, size, att.ContentType); err != nil {
+        return nil, fmt.Errorf("保存合同附件失败: %w", err)
+    }
+    att.Checksum = hex.EncodeToString(hasher.Sum(nil))
+
+    if err := s.db.Create(att).Error; err != nil {
+        if delErr := store.Delete(ctx, att.StorageKey); delErr != nil {
+            logger.GetLogger().Error("清理合同附件文件失败:", att.StorageKey, " ", delErr)
+        }
+        return nil, err
+    }
+    s.signAttachment(att, time.Now())
+    return att, nil
+}
+
+// Attachments 获取合同的全部附件，按上传时间排序，附带签名下载地址
+func (s *Service) Attachments(contractID uuid.UUID) ([]Attachment, error) {
+    if _, err := s.find(s.db, contractID, false); err != nil {
+        return nil, err
+    }
+    list := []Attachment{}
+    if err := s.db.Where("contract_id = ?", contractID).Order("created_at").Find(&list).Error; err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    for i := range list {
+        s.signAttachment(&list[i], now)
+    }
+    return list, nil
+}
+
+// Attachment 获取合同附件，附带签名下载地址
+func (s *Service) Attachment(contractID, id uuid.UUID) (*Attachment, error) {
+    att, err := s.findAttachment(contractID, id)
+    if err != nil {
+        return nil, err
+    }
+    s.signAttachment(att, time.Now())
+    return att, nil
+}
+
+// DeleteAttachment 删除合同附件及存储中的文件，只有上传人、管理员和经理可以删除
+func (s *Service) DeleteAttachment(ctx context.Context, contractID, id, operator uuid.UUID, role string) error {
+    att, err := s.findAttachment(contractID, id)
+    if err != nil {
+        return err
+    }
+    if att.UploadedBy != operator && role != "admin" && role != "manager" {
+        return i18n.NewError("contract.attachment_forbidden")
+    }
+    if err := s.db.Delete(att).Error; err != nil {
+        return err
+    }
+    store, err := storage.Default()
+    if err == nil {
+        err = store.Delete(ctx, att.StorageKey)
+    }
+    if err != nil {
+        // 记录已删除，文件残留不影响使用
+        logger.GetLogger().Error("删除合同附件文件失败:", att.StorageKey, " ", err)
+    }
+    return nil
+}
+
+// OpenAttachment 校验签名下载地址并打开附件文件，调用方负责关闭
+func (s *Service) OpenAttachment(ctx context.Context, contractID, id uuid.UUID, expires, signature string) (*Attachment, io.ReadCloser, error) {
+    exp, err := strconv.ParseInt(expires, 10, 64)
+    if err != nil || time.Now().Unix() > exp ||
+        !hmac.Equal([]byte(signature), []byte(attachmentSignature(contractID, id, exp))) {
+        return nil, nil, i18n.NewError("contract.attachment_link_invalid")
+    }
+    att, err := s.findAttachment(contractID, id)
+    if err != nil {
+        return nil, nil, err
+    }
+    store, err := storage.Default()
+    if err != nil {
+        return nil, nil, err
+    }
+    rc, err := store.Get(ctx, att.StorageKey)
+    if errors.Is(err, storage.ErrNotFound) {
+        return nil, nil, i18n.NewError("contract.attachment_not_found")
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+    return att, rc, nil
+}
+
+// findAttachment 获取合同下的附件
+func (s *Service) findAttachment(contractID, id uuid.UUID) (*Attachment, error) {
+    var att Attachment
+    if err := s.db.First(&att, "id = ? AND contract_id = ?", id, contractID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, i18n.NewError("contract.attachment_not_found")
+        }
+        return nil, err
+    }
+    return &att, nil
+}
+
+// signAttachment 生成有效期为 storage.url_expire_seconds 的签名下载地址
+func (s *Service) signAttachment(att *Attachment, now time.Time) {
+    expiresAt := now.Add(time.Duration(viper.GetInt("storage.url_expire_seconds")) * time.Second)
+    exp := expiresAt.Unix()
+    att.DownloadURL = fmt.Sprintf("/api/v1/contracts/%s/attachments/%s/download?expires=%d&signature=%s",
+        att.ContractID, att.ID, exp, attachmentSignature(att.ContractID, att.ID, exp))
+    att.URLExpiresAt = &expiresAt
+}
+
+// attachmentSignature 下载地址签名：以 jwt.secret 为密钥的 HMAC-SHA256
+func attachmentSignature(contractID, id uuid.UUID, expires int64) string {
+    mac := hmac.New(sha256.New, []byte(viper.GetString("jwt.secret")))
+    fmt.Fprintf(mac, "contract-attachment:%s:%s:%d", contractID, id, expires)
+    return hex.EncodeToString(mac.Sum(nil))
+}
+
+// cleanFileName 检查上传文件名：去掉客户端路径和控制字符，拒绝隐藏文件、超长文件名和可执行文件扩展名
+func cleanFileName(name string) (string, error) {
+    name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+    name = strings.Map(func(r rune) rune {
+        if unicode.IsControl(r) || strings.ContainsRune(`<>:"|?*`, r) {
+            return -1
+        }
+        return r
+    }, name)
+    name = strings.TrimSpace(name)
+
+    if name == "" || name == "." || name == ".." || strings.HasPrefix(name, ".") ||
+        !utf8.ValidString(name) || len(name) > maxFileNameBytes {
+        return "", i18n.NewError("contract.attachment_invalid_name")
+    }
+    parts := strings.Split(strings.ToLower(name), ".")
+    for _, ext := range parts[1:] {
+        if blockedExtensions[strings.TrimSpace(ext)] {
+            return "", i18n.NewError("contract.attachment_invalid_name")
+        }
+    }
+    if filepath.Ext(name) == "" {
+        return "", i18n.NewError("contract.attachment_invalid_name")
+    }
+    return name, nil
+}
+
+// allowedType 文件类型（或其父类型）是否在 upload.allowed_types 中
+func allowedType(detected *mimetype.MIME) bool {
+    allowed := viper.GetStringSlice("upload.allowed_types")
+    for m := detected; m != nil; m = m.Parent() {
+        for _, t := range allowed {
+            if m.Is(t) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// extensionMatches 扩展名是否与识别的文件类型一致，如 .jpg/.jpeg 对应 image/jpeg
+func extensionMatches(ext string, detected *mimetype.MIME) bool {
+    for m := detected; m != nil; m = m.Parent() {
+        if m.Extension() == ext {
+            return true
+        }
+        mediaType, _, _ := mime.ParseMediaType(m.String())
+        exts, _ := mime.ExtensionsByType(mediaType)
+        for _, e := range exts {
+            if e == ext {
+                return true
+            }
+        }
+    }
+    return false
+}
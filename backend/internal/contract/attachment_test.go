@@ -0,0 +1,74 @@
+package contract
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+func TestCleanFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "合同扫描件.pdf", want: "合同扫描件.pdf"},
+		{name: "C:\\Users\\finance\\contract.pdf", want: "contract.pdf"},
+		{name: "../../etc/contract.pdf", want: "contract.pdf"},
+		{name: "  report\x00 2024.xlsx ", want: "report 2024.xlsx"},
+		{name: "what?.pdf", want: "what.pdf"},
+		{name: "scan.exe.pdf", wantErr: true},
+		{name: "scan.PDF.Exe", wantErr: true},
+		{name: "invoice.pdf.js", wantErr: true},
+		{name: "page.html", wantErr: true},
+		{name: ".htaccess", wantErr: true},
+		{name: "..", wantErr: true},
+		{name: "", wantErr: true},
+		{name: "README", wantErr: true},
+		{name: strings.Repeat("a", 252) + ".pdf", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cleanFileName(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("cleanFileName(%q) = %q, want error", tt.name, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("cleanFileName(%q) error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("cleanFileName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtensionMatches(t *testing.T) {
+	pdf := []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	jpeg := []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		ext  string
+		head []byte
+		want bool
+	}{
+		{ext: ".pdf", head: pdf, want: true},
+		{ext: ".jpg", head: jpeg, want: true},
+		{ext: ".jpeg", head: jpeg, want: true},
+		{ext: ".png", head: png, want: true},
+		{ext: ".pdf", head: jpeg, want: false},
+		{ext: ".jpg", head: png, want: false},
+		{ext: ".png", head: pdf, want: false},
+	}
+	for _, tt := range tests {
+		detected := mimetype.Detect(tt.head)
+		if got := extensionMatches(tt.ext, detected); got != tt.want {
+			t.Errorf("extensionMatches(%q, %s) = %v, want %v", tt.ext, detected, got, tt.want)
+		}
+	}
+}
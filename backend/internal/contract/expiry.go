@@ -0,0 +1,229 @@
+package contract
+
+import (
+    "context"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/go-redis/redis/v8"
+    "github.com/google/uuid"
+    "github.com/spf13/viper"
+    "gorm.io/gorm/clause"
+
+    "xcloud-backend/internal/notify"
+    "xcloud-backend/internal/sysconfig"
+    "xcloud-backend/internal/user"
+    "xcloud-backend/pkg/logger"
+)
+
+const (
+    // expiryLockPrefix 每日到期处理的 Redis 锁，保证多实例部署时每天只执行一次
+    expiryLockPrefix = "xcloud:contract:expiry:"
+    // expiryCheckInterval 检查是否需要执行每日到期处理的间隔
+    expiryCheckInterval = time.Hour
+    // defaultReminderDays 未配置 contract.reminder_days 时的提前提醒天数
+    defaultReminderDays = "60,30,7"
+)
+
+// StartExpiryScheduler 启动合同到期的每日处理，ctx 取消时退出
+// 每小时检查一次，当天到达 contract.expiry_check_hour 点后执行，执行失败时下次检查重试
+func StartExpiryScheduler(ctx context.Context, svc *Service, rdb *redis.Client) {
+    go func() {
+        ticker := time.NewTicker(expiryCheckInterval)
+        defer ticker.Stop()
+
+        lastRun := ""
+        for {
+            if day, ok := svc.runDaily(ctx, rdb, lastRun); ok {
+                lastRun = day
+            }
+            select {
+            case <-ctx.Done():
+                return
+            case <-ticker.C:
+            }
+        }
+    }()
+}
+
+// runDaily 当天尚未执行时执行到期处理，返回当天日期和是否已完成
+func (s *Service) runDaily(ctx context.Context, rdb *redis.Client, lastRun string) (string, bool) {
+    now := time.Now()
+    day := now.Format("2006-01-02")
+    if day == lastRun || now.Hour() < viper.GetInt("contract.expiry_check_hour") {
+        return day, false
+    }
+
+    log := logger.GetLogger()
+    lockKey := expiryLockPrefix + day
+    if rdb != nil {
+        acquired, err := rdb.SetNX(ctx, lockKey, 1, 48*time.Hour).Result()
+        if err != nil {
+            log.Error("获取合同到期处理锁失败:", err)
+            return day, false
+        }
+        if !acquired {
+            // 其他实例已执行
+            return day, true
+        }
+    }
+
+    result, err := s.RunExpiry(ctx, Today())
+    if err != nil {
+        log.Error("合同到期处理失败:", err)
+        if rdb != nil {
+            rdb.Del(ctx, lockKey)
+        }
+        return day, false
+    }
+    log.Info("合同到期处理完成: 到期合同:", result.Expired, " 发送提醒:", result.RemindersSent)
+    return day, true
+}
+
+// RunExpiry 执行合同到期处理：将超过结束日期的生效合同置为已到期，并按提前天数发送到期提醒
+func (s *Service) RunExpiry(ctx context.Context, today time.Time) (*ExpiryResult, error) {
+    result := &ExpiryResult{}
+    if s.config.GetBool(sysconfig.KeyContractAutoExpire, true) {
+        expired, err := s.expire(ctx, today)
+        if err != nil {
+            return nil, err
+        }
+        result.Expired = expired
+    }
+    sent, err := s.remind(ctx, today)
+    if err != nil {
+        return nil, err
+    }
+    result.RemindersSent = sent
+    return result, nil
+}
+
+// expire 将结束日期早于 today 的生效合同置为已到期并通知
+func (s *Service) expire(ctx context.Context, today time.Time) (int, error) {
+    list, err := s.findActive(today, "contracts.end_date < ?", dateString(today))
+    if err != nil || len(list) == 0 {
+        return 0, err
+    }
+
+    expired := 0
+    for _, ec := range list {
+        res := s.db.Model(&Contract{}).
+            Where("id = ? AND status = ?", ec.ID, StatusActive).
+            Updates(map[string]interface{}{"status": StatusExpired, "updated_at": time.Now()})
+        if res.Error != nil {
+            return expired, res.Error
+        }
+        if res.RowsAffected == 0 {
+            continue
+        }
+        expired++
+
+        recipients, err := s.recipients(ec.CreatedBy)
+        if err != nil {
+            return expired, err
+        }
+        end := dateString(ec.EndDate)
+        notify.Publish(ctx, notify.Message{
+            Event:      "contract.expired",
+            Subject:    notify.Text{Code: "contract.notify.expired_subject", Args: []interface{}{ec.ContractNo}},
+            Body:       notify.Text{Code: "contract.notify.expired_body", Args: []interface{}{ec.ContractNo, ec.Title, ec.CustomerName, end}},
+            Link:       "/contracts/" + ec.ID.String(),
+            Recipients: recipients,
+        })
+    }
+    return expired, nil
+}
+
+// remind 发送到期提醒：剩余天数不超过某个提前天数时，按最接近的提前天数提醒一次
+// 例如提前天数为 60,30,7，剩余 25 天的合同发送 30 天提醒；合同续期（结束日期变化）后重新提醒
+func (s *Service) remind(ctx context.Context, today time.Time) (int, error) {
+    leads := s.reminderDays()
+    if len(leads) == 0 {
+        return 0, nil
+    }
+    list, err := s.Expiring(today, leads[len(leads)-1])
+    if err != nil {
+        return 0, err
+    }
+
+    log := logger.GetLogger()
+    sent := 0
+    for _, ec := range list {
+        lead := leadFor(leads, ec.DaysLeft)
+        recipients, err := s.recipients(ec.CreatedBy)
+        if err != nil {
+            return sent, err
+        }
+
+        reminder := &Reminder{
+            ContractID:     ec.ID,
+            EndDate:        ec.EndDate,
+            LeadDays:       lead,
+            RecipientCount: len(recipients),
+            SentAt:         time.Now(),
+        }
+        res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+        if res.Error != nil {
+            return sent, res.Error
+        }
+        if res.RowsAffected == 0 {
+            // 已提醒过
+            continue
+        }
+
+        err = notify.Publish(ctx, notify.Message{
+            Event:   "contract.expiring",
+            Subject: notify.Text{Code: "contract.notify.expiring_subject", Args: []interface{}{ec.ContractNo, ec.DaysLeft}},
+            Body: notify.Text{Code: "contract.notify.expiring_body",
+                Args: []interface{}{ec.ContractNo, ec.Title, ec.CustomerName, dateString(ec.EndDate), ec.DaysLeft}},
+            Link:       "/contracts/" + ec.ID.String(),
+            Recipients: recipients,
+        })
+        if err != nil {
+            // 删除提醒记录，下次执行时重新发送
+            log.Error("发送合同到期提醒失败:", ec.ContractNo, " ", err)
+            s.db.Delete(reminder)
+            continue
+        }
+        sent++
+    }
+    return sent, nil
+}
+
+// recipients 合同到期通知的接收人：合同创建人和全部经理（仅启用的用户）
+func (s *Service) recipients(owner *uuid.UUID) ([]notify.Recipient, error) {
+    var owners []uuid.UUID
+    if owner != nil {
+        owners = append(owners, *owner)
+    }
+    return notify.Recipients(s.db, owners, []user.UserRole{user.RoleManager})
+}
+
+// reminderDays 解析 contract.reminder_days，返回去重后的升序提前天数
+func (s *Service) reminderDays() []int {
+    raw := s.config.GetString(sysconfig.KeyContractReminderDays, defaultReminderDays)
+    seen := map[int]bool{}
+    var leads []int
+    for _, part := range strings.Split(raw, ",") {
+        days, err := strconv.Atoi(strings.TrimSpace(part))
+        if err != nil || days < 0 || days > MaxExpiringDays || seen[days] {
+            continue
+        }
+        seen[days] = true
+        leads = append(leads, days)
+    }
+    sort.Ints(leads)
+    return leads
+}
+
+// leadFor 剩余天数对应的提前天数：不小于剩余天数的最小提前天数
+func leadFor(leads []int, daysLeft int) int {
+    for _, lead := range leads {
+        if daysLeft <= lead {
+            return lead
+        }
+    }
+    return leads[len(leads)-1]
+}
@@ -1,44 +1,145 @@
 package contract
 
 import (
+    "errors"
+    "mime"
     "net/http"
+    "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/viper"
     "gorm.io/gorm"
+
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/pkg/i18n"
+    "xcloud-backend/pkg/logger"
 )
 
 type Handler struct {
-    db *gorm.DB
+    db          *gorm.DB
+    contractSvc *Service
+    logger      *logrus.Logger
 }
 
 func NewHandler(db *gorm.DB) *Handler {
-    return &Handler{db: db}
+    return &Handler{
+        db:          db,
+        contractSvc: NewService(db),
+        logger:      logger.GetLogger(),
+    }
 }
 
 // GetContracts 获取合同列表
 // @Summary 获取合同列表
-// @Description 分页获取合同列表
+// @Description 游标分页获取合同列表。通用过滤参数为 filter[字段][运算符]=取值，如 filter[end_date][lte]=2024-12-31，
+// @Description 可过滤字段：id、contract_no、title、customer_id、status、start_date、end_date、contract_amount、currency、created_at、updated_at；
+// @Description sort 为逗号分隔的排序字段，- 表示倒序，默认按创建时间倒序。search 按合同编号和标题全文和模糊匹配，按相关度排序的搜索见 /search
 // @Tags 合同管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "页码" default(1)
-// @Param page_size query int false "每页数量" default(20)
+// @Param sort query string false "排序字段" default(-created_at)
+// @Param limit query int false "每页数量" default(20)
+// @Param cursor query string false "分页游标，取上一次响应的 next_cursor 或 prev_cursor"
+// @Param count query bool false "是否返回总数"
+// @Param search query string false "搜索关键词"
 // @Param customer_id query string false "客户ID"
-// @Param status query string false "合同状态"
+// @Param status query string false "合同状态" Enums(draft, pending, active, expired, terminated)
+// @Param provider query string false "返佣规则的云平台" Enums(tencent, alibaba, huawei, aws)
+// @Param level query int false "客户级别"
+// @Param business_type query string false "客户业务类型"
 // @Success 200 {object} ContractListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 401 {object} ErrorResponse "未认证"
 // @Router /contracts [get]
 func (h *Handler) GetContracts(c *gin.Context) {
-    // TODO: 实现获取合同列表逻辑
-    c.JSON(http.StatusOK, gin.H{
-        "code":    200,
-        "message": "获取合同列表成功",
-        "data": gin.H{
-            "contracts": []gin.H{},
-            "total":     0,
-            "page":      1,
-            "page_size": 20,
+    filter := ListFilter{
+        Search:       c.Query("search"),
+        Status:       ContractStatus(c.Query("status")),
+        Provider:     billing.Provider(c.Query("provider")),
+        BusinessType: c.Query("business_type"),
+    }
+    if raw := c.Query("customer_id"); raw != "" {
+        customerID, err := uuid.Parse(raw)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "code":    400,
+                "message": i18n.T(c, "common.invalid_params"),
+            })
+            return
+        }
+        filter.CustomerID = &customerID
+    }
+    if raw := c.Query("level"); raw != "" {
+        level, err := strconv.Atoi(raw)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "code":    400,
+                "message": i18n.T(c, "common.invalid_params"),
+            })
+            return
+        }
+        filter.CustomerLevel = level
+    }
+    params, err := ListQuery.Parse(c.Request.URL.Query())
+    if err != nil {
+        h.respondError(c, "解析合同列表查询参数失败:", err)
+        return
+    }
+
+    contracts, page, err := h.contractSvc.List(filter, params)
+    if err != nil {
+        h.respondError(c, "获取合同列表失败:", err)
+        return
+    }
+
+    data := ContractListData{
+        Contracts:  make([]ContractData, 0, len(contracts)),
+        Pagination: *page,
+    }
+    for i := range contracts {
+        data.Contracts = append(data.Contracts, toContractData(&contracts[i]))
+    }
+    c.JSON(http.StatusOK, ContractListResponse{
+        Code:    200,
+        Message: i18n.T(c, "contract.list_success"),
+        Data:    data,
+    })
+}
+
+// GetExpiringContracts 获取即将到期的合同
+// @Summary 获取即将到期的合同
+// @Description 获取指定天数内到期的生效合同，按结束日期排序，供控制台展示
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param within query string false "到期天数，如 30d（1-365天）" default(30d)
+// @Success 200 {object} ExpiringContractListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /contracts/expiring [get]
+func (h *Handler) GetExpiringContracts(c *gin.Context) {
+    days, err := ParseWithin(c.Query("within"))
+    if err != nil {
+        h.respondError(c, "获取即将到期合同失败:", err)
+        return
+    }
+
+    list, err := h.contractSvc.Expiring(Today(), days)
+    if err != nil {
+        h.respondError(c, "获取即将到期合同失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, ExpiringContractListResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data: ExpiringContractListData{
+            Contracts:  list,
+            WithinDays: days,
         },
     })
 }
@@ -55,23 +156,27 @@ func (h *Handler) GetContracts(c *gin.Context) {
 // @Failure 404 {object} ErrorResponse "合同不存在"
 // @Router /contracts/{id} [get]
 func (h *Handler) GetContract(c *gin.Context) {
-    id := c.Param("id")
-    // TODO: 实现获取合同详情逻辑
-    c.JSON(http.StatusOK, gin.H{
-        "code":    200,
-        "message": "获取合同详情成功",
-        "data": gin.H{
-            "id":          id,
-            "contract_no": "CON202401001",
-            "title":       "示例合同",
-            "status":      "active",
-        },
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+
+    ct, err := h.contractSvc.Get(id)
+    if err != nil {
+        h.respondError(c, "获取合同详情失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, ContractResponse{
+        Code:    200,
+        Message: i18n.T(c, "contract.detail_success"),
+        Data:    toContractData(ct),
     })
 }
 
 // CreateContract 创建合同
 // @Summary 创建合同
-// @Description 创建新的合同记录
+// @Description 创建草稿合同，提交审批并通过最后一级审批后生效
 // @Tags 合同管理
 // @Accept json
 // @Produce json
@@ -85,21 +190,484 @@ func (h *Handler) CreateContract(c *gin.Context) {
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{
             "code":    400,
-            "message": "请求参数错误",
-            "error":   err.Error(),
+            "message": i18n.T(c, "common.invalid_params"),
+            "error":   i18n.Message(c, err),
         })
         return
     }
 
-    // TODO: 实现创建合同逻辑
-    c.JSON(http.StatusCreated, gin.H{
-        "code":    201,
-        "message": "合同创建成功",
-        "data": gin.H{
-            "id":          "new-contract-id",
-            "contract_no": req.ContractNo,
-            "title":       req.Title,
-            "status":      "draft",
-        },
+    operator, ok := currentUser(c)
+    if !ok {
+        return
+    }
+
+    ct, err := h.contractSvc.Create(req, operator)
+    if err != nil {
+        h.respondError(c, "创建合同失败:", err)
+        return
+    }
+
+    h.logger.Info("合同已创建:", ct.ContractNo, " ", ct.ID)
+    c.JSON(http.StatusCreated, ContractResponse{
+        Code:    201,
+        Message: i18n.T(c, "contract.created"),
+        Data:    toContractData(ct),
+    })
+}
+
+// ListVersions 获取合同版本列表
+// @Summary 获取合同版本列表
+// @Description 获取合同的全部版本，按生效日期升序；每个版本在下一版本生效前有效
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Success 200 {object} VersionListResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Router /contracts/{id}/versions [get]
+func (h *Handler) ListVersions(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+
+    versions, err := h.contractSvc.Versions(id)
+    if err != nil {
+        h.respondError(c, "获取合同版本失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, VersionListResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    versions,
     })
-}
\ No newline at end of file
+}
+
+// GetVersion 获取合同版本
+// @Summary 获取合同版本
+// @Description 按版本号获取合同版本的条款
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param version path int true "版本号"
+// @Success 200 {object} VersionResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "合同或版本不存在"
+// @Router /contracts/{id}/versions/{version} [get]
+func (h *Handler) GetVersion(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    versionNo, err := strconv.Atoi(c.Param("version"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+        })
+        return
+    }
+
+    version, err := h.contractSvc.Version(id, versionNo)
+    if err != nil {
+        h.respondError(c, "获取合同版本失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, VersionResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    *version,
+    })
+}
+
+// DiffVersions 比较合同版本
+// @Summary 比较合同版本
+// @Description 比较合同两个版本的条款（标题、结束日期、付款条件、合同金额、折扣率），返回变化的条款
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param from query int true "起始版本号"
+// @Param to query int true "目标版本号"
+// @Success 200 {object} VersionDiffResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "合同或版本不存在"
+// @Router /contracts/{id}/versions/diff [get]
+func (h *Handler) DiffVersions(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    from, errFrom := strconv.Atoi(c.Query("from"))
+    to, errTo := strconv.Atoi(c.Query("to"))
+    if errFrom != nil || errTo != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+        })
+        return
+    }
+
+    diff, err := h.contractSvc.Diff(id, from, to)
+    if err != nil {
+        h.respondError(c, "比较合同版本失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, VersionDiffResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    *diff,
+    })
+}
+
+// AmendContract 修订合同
+// @Summary 修订合同
+// @Description 为生效中的合同创建新版本，未填写的条款沿用上一版本，生效日期须晚于当前最新版本。
+// @Description 返佣计算和对账按账单日期使用当时生效的版本；生效日期早于已计算的周期时需重新计算返佣（需要管理员或经理权限）
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param body body AmendContractRequest true "修订内容"
+// @Success 201 {object} VersionResponse "修订成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Router /contracts/{id}/amendments [post]
+func (h *Handler) AmendContract(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    var req AmendContractRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+            "error":   i18n.Message(c, err),
+        })
+        return
+    }
+    operator, ok := currentUser(c)
+    if !ok {
+        return
+    }
+
+    version, err := h.contractSvc.Amend(id, req, operator)
+    if err != nil {
+        h.respondError(c, "修订合同失败:", err)
+        return
+    }
+
+    h.logger.Info("合同已修订:", id, " 版本:", version.VersionNo, " 生效日期:", dateString(version.EffectiveDate))
+    c.JSON(http.StatusCreated, VersionResponse{
+        Code:    201,
+        Message: i18n.T(c, "contract.amended"),
+        Data:    *version,
+    })
+}
+
+// ListAttachments 获取合同附件列表
+// @Summary 获取合同附件列表
+// @Description 获取合同的全部附件，每个附件附带签名的限时下载地址（有效期 storage.url_expire_seconds 秒）
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Success 200 {object} AttachmentListResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Router /contracts/{id}/attachments [get]
+func (h *Handler) ListAttachments(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+
+    list, err := h.contractSvc.Attachments(id)
+    if err != nil {
+        h.respondError(c, "获取合同附件失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, AttachmentListResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    list,
+    })
+}
+
+// GetAttachment 获取合同附件
+// @Summary 获取合同附件
+// @Description 获取合同附件信息及新的签名下载地址
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param attachment_id path string true "附件ID"
+// @Success 200 {object} AttachmentResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "合同附件不存在"
+// @Router /contracts/{id}/attachments/{attachment_id} [get]
+func (h *Handler) GetAttachment(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    attachmentID, ok := attachmentPathID(c)
+    if !ok {
+        return
+    }
+
+    att, err := h.contractSvc.Attachment(id, attachmentID)
+    if err != nil {
+        h.respondError(c, "获取合同附件失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, AttachmentResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    *att,
+    })
+}
+
+// UploadAttachment 上传合同附件
+// @Summary 上传合同附件
+// @Description 上传签署后的合同扫描件等文件。文件类型按内容识别，须在 upload.allowed_types 中且与扩展名一致，
+// @Description 大小不超过 upload.max_size；不允许可执行文件和脚本扩展名。返回文件的 SHA-256 和签名下载地址（只读用户不可上传）
+// @Tags 合同管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param file formData file true "附件文件"
+// @Success 201 {object} AttachmentResponse "上传成功"
+// @Failure 400 {object} ErrorResponse "文件名、类型不符合要求"
+// @Failure 404 {object} ErrorResponse "合同不存在"
+// @Failure 413 {object} ErrorResponse "文件过大"
+// @Router /contracts/{id}/attachments [post]
+func (h *Handler) UploadAttachment(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    // 预留 multipart 表单开销
+    c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("upload.max_size")+1<<20)
+
+    file, err := c.FormFile("file")
+    if err != nil {
+        var tooLarge *http.MaxBytesError
+        if errors.As(err, &tooLarge) {
+            c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+                "code":    413,
+                "message": i18n.T(c, "contract.attachment_too_large", viper.GetInt64("upload.max_size")>>20),
+            })
+            return
+        }
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "contract.attachment_required"),
+        })
+        return
+    }
+    operator, ok := currentUser(c)
+    if !ok {
+        return
+    }
+
+    src, err := file.Open()
+    if err != nil {
+        h.respondError(c, "读取上传文件失败:", err)
+        return
+    }
+    defer src.Close()
+
+    att, err := h.contractSvc.UploadAttachment(c.Request.Context(), id, file.Filename, src, file.Size, operator)
+    if err != nil {
+        h.respondError(c, "上传合同附件失败:", err)
+        return
+    }
+
+    h.logger.Info("合同附件已上传:", id, " ", att.FileName, " 大小:", att.Size, " SHA-256:", att.Checksum)
+    c.JSON(http.StatusCreated, AttachmentResponse{
+        Code:    201,
+        Message: i18n.T(c, "contract.attachment_uploaded"),
+        Data:    *att,
+    })
+}
+
+// DeleteAttachment 删除合同附件
+// @Summary 删除合同附件
+// @Description 删除合同附件及存储中的文件，只有上传人、管理员和经理可以删除
+// @Tags 合同管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "合同ID"
+// @Param attachment_id path string true "附件ID"
+// @Success 200 {object} BaseResponse "删除成功"
+// @Failure 403 {object} ErrorResponse "无权删除"
+// @Failure 404 {object} ErrorResponse "合同附件不存在"
+// @Router /contracts/{id}/attachments/{attachment_id} [delete]
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    attachmentID, ok := attachmentPathID(c)
+    if !ok {
+        return
+    }
+    operator, ok := currentUser(c)
+    if !ok {
+        return
+    }
+    role, _ := c.Get("user_role")
+    roleStr, _ := role.(string)
+
+    if err := h.contractSvc.DeleteAttachment(c.Request.Context(), id, attachmentID, operator, roleStr); err != nil {
+        h.respondError(c, "删除合同附件失败:", err)
+        return
+    }
+
+    h.logger.Info("合同附件已删除:", id, " ", attachmentID)
+    c.JSON(http.StatusOK, gin.H{
+        "code":    200,
+        "message": i18n.T(c, "contract.attachment_deleted"),
+    })
+}
+
+// DownloadAttachment 下载合同附件
+// @Summary 下载合同附件
+// @Description 通过附件列表或详情返回的签名地址下载文件，无需登录；地址过期或签名错误时返回403
+// @Tags 合同管理
+// @Produce octet-stream
+// @Param id path string true "合同ID"
+// @Param attachment_id path string true "附件ID"
+// @Param expires query int true "过期时间（Unix 秒）"
+// @Param signature query string true "签名"
+// @Success 200 {file} file "附件文件"
+// @Failure 403 {object} ErrorResponse "下载地址无效或已过期"
+// @Failure 404 {object} ErrorResponse "合同附件不存在"
+// @Router /contracts/{id}/attachments/{attachment_id}/download [get]
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    attachmentID, ok := attachmentPathID(c)
+    if !ok {
+        return
+    }
+
+    att, file, err := h.contractSvc.OpenAttachment(c.Request.Context(), id, attachmentID, c.Query("expires"), c.Query("signature"))
+    if err != nil {
+        h.respondError(c, "下载合同附件失败:", err)
+        return
+    }
+    defer file.Close()
+
+    c.DataFromReader(http.StatusOK, att.Size, att.ContentType, file, map[string]string{
+        "Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": att.FileName}),
+        "X-Content-Type-Options": "nosniff",
+        "ETag":                   strconv.Quote(att.Checksum),
+        "Cache-Control":          "private, no-store",
+    })
+}
+
+// respondError 业务错误返回400（不存在时返回404，无权访问附件时返回403），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+    var i18nErr *i18n.Error
+    if errors.As(err, &i18nErr) {
+        status := http.StatusBadRequest
+        switch i18nErr.Code {
+        case "contract.not_found", "contract.version_not_found", "contract.customer_not_found",
+            "contract.attachment_not_found":
+            status = http.StatusNotFound
+        case "contract.attachment_forbidden", "contract.attachment_link_invalid":
+            status = http.StatusForbidden
+        }
+        c.JSON(status, gin.H{
+            "code":    status,
+            "message": i18n.Message(c, err),
+        })
+        return
+    }
+    h.logger.Error(logMessage, err)
+    c.JSON(http.StatusInternalServerError, gin.H{
+        "code":    500,
+        "message": i18n.T(c, "common.internal_error"),
+    })
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+    userID, _ := c.Get("user_id")
+    id, err := uuid.Parse(userID.(string))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_user_id"),
+        })
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+    id, err := uuid.Parse(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+        })
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// attachmentPathID 解析路径中的附件ID参数
+func attachmentPathID(c *gin.Context) (uuid.UUID, bool) {
+    id, err := uuid.Parse(c.Param("attachment_id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+        })
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// toContractData 转换为合同响应数据
+func toContractData(ct *Contract) ContractData {
+    data := ContractData{
+        ID:              ct.ID.String(),
+        ContractNo:      ct.ContractNo,
+        CustomerID:      ct.CustomerID.String(),
+        Title:           ct.Title,
+        Status:          string(ct.Status),
+        StartDate:       dateString(ct.StartDate),
+        EndDate:         dateString(ct.EndDate),
+        SettlementCycle: ct.SettlementCycle,
+        PaymentTerms:    ct.PaymentTerms,
+        Currency:        ct.Currency,
+        CreatedAt:       ct.CreatedAt.Format(time.RFC3339),
+        UpdatedAt:       ct.UpdatedAt.Format(time.RFC3339),
+    }
+    if ct.ContractAmount != nil {
+        data.ContractAmount = *ct.ContractAmount
+    }
+    if ct.DiscountRate != nil {
+        data.DiscountRate = *ct.DiscountRate
+    }
+    return data
+}
@@ -1,5 +1,152 @@
 package contract
 
+import (
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+
+    "xcloud-backend/pkg/query"
+)
+
+// ContractStatus 合同状态枚举
+type ContractStatus string
+
+const (
+    StatusDraft      ContractStatus = "draft"      // 草稿
+    StatusPending    ContractStatus = "pending"    // 待审批
+    StatusActive     ContractStatus = "active"     // 生效中
+    StatusExpired    ContractStatus = "expired"    // 已到期
+    StatusTerminated ContractStatus = "terminated" // 已终止
+)
+
+// IsValid 检查合同状态是否有效
+func (s ContractStatus) IsValid() bool {
+    switch s {
+    case StatusDraft, StatusPending, StatusActive, StatusExpired, StatusTerminated:
+        return true
+    default:
+        return false
+    }
+}
+
+// Contract 合同模型
+type Contract struct {
+    ID              uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+    ContractNo      string         `json:"contract_no" gorm:"type:varchar(50);uniqueIndex;not null"`
+    CustomerID      uuid.UUID      `json:"customer_id" gorm:"type:uuid;not null"`
+    Title           string         `json:"title" gorm:"type:varchar(200);not null"`
+    Status          ContractStatus `json:"status" gorm:"type:contract_status;not null;default:'draft'"`
+    StartDate       time.Time      `json:"start_date" gorm:"type:date;not null"`
+    EndDate         time.Time      `json:"end_date" gorm:"type:date;not null"`
+    SettlementCycle int            `json:"settlement_cycle" gorm:"not null;default:1"`
+    PaymentTerms    string         `json:"payment_terms" gorm:"type:text"`
+    ContractAmount  *float64       `json:"contract_amount,omitempty" gorm:"type:decimal(15,2)"`
+    DiscountRate    *float64       `json:"discount_rate,omitempty" gorm:"type:decimal(5,4)"`
+    Currency        string         `json:"currency" gorm:"type:varchar(3);not null;default:'CNY'"`
+    CreatedAt       time.Time      `json:"created_at"`
+    UpdatedAt       time.Time      `json:"updated_at"`
+    CreatedBy       *uuid.UUID     `json:"created_by,omitempty"`
+    UpdatedBy       *uuid.UUID     `json:"updated_by,omitempty"`
+    DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Contract) TableName() string {
+    return "contracts"
+}
+
+// Reminder 已发送的合同到期提醒
+type Reminder struct {
+    ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+    ContractID     uuid.UUID `json:"contract_id" gorm:"type:uuid;not null"`
+    EndDate        time.Time `json:"end_date" gorm:"type:date;not null"`
+    LeadDays       int       `json:"lead_days" gorm:"not null"`
+    RecipientCount int       `json:"recipient_count" gorm:"not null;default:0"`
+    SentAt         time.Time `json:"sent_at"`
+}
+
+// TableName 设置表名
+func (Reminder) TableName() string {
+    return "contract_reminders"
+}
+
+// Version 合同版本，记录某一生效日期起的合同条款，创建后不可修改
+type Version struct {
+    ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+    ContractID     uuid.UUID  `json:"contract_id" gorm:"type:uuid;not null"`
+    VersionNo      int        `json:"version_no" gorm:"not null"`
+    EffectiveDate  time.Time  `json:"effective_date" gorm:"type:date;not null"`
+    Title          string     `json:"title" gorm:"type:varchar(200);not null"`
+    EndDate        time.Time  `json:"end_date" gorm:"type:date;not null"`
+    PaymentTerms   string     `json:"payment_terms" gorm:"type:text"`
+    ContractAmount *float64   `json:"contract_amount,omitempty" gorm:"type:decimal(15,2)"`
+    DiscountRate   *float64   `json:"discount_rate,omitempty" gorm:"type:decimal(5,4)"`
+    Reason         string     `json:"reason,omitempty" gorm:"type:text"`
+    CreatedAt      time.Time  `json:"created_at"`
+    CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Version) TableName() string {
+    return "contract_versions"
+}
+
+// Attachment 合同附件（如签署后的合同扫描件），文件保存在存储驱动中
+type Attachment struct {
+    ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+    ContractID  uuid.UUID `json:"contract_id" gorm:"type:uuid;not null"`
+    FileName    string    `json:"file_name" gorm:"type:varchar(255);not null"`
+    ContentType string    `json:"content_type" gorm:"type:varchar(100);not null"`
+    Size        int64     `json:"size" gorm:"not null"`
+    Checksum    string    `json:"checksum" gorm:"type:varchar(64);not null"` // SHA-256（十六进制）
+    Storage     string    `json:"-" gorm:"type:varchar(20);not null"`
+    StorageKey  string    `json:"-" gorm:"type:varchar(500);not null"`
+    UploadedBy  uuid.UUID `json:"uploaded_by" gorm:"type:uuid;not null"`
+    CreatedAt   time.Time `json:"created_at"`
+
+    // DownloadURL 带签名的限时下载地址，无需登录即可访问
+    DownloadURL  string     `json:"download_url,omitempty" gorm:"-"`
+    URLExpiresAt *time.Time `json:"url_expires_at,omitempty" gorm:"-"`
+}
+
+// TableName 设置表名
+func (Attachment) TableName() string {
+    return "contract_attachments"
+}
+
+// FieldChange 两个版本间变化的条款
+type FieldChange struct {
+    Field string      `json:"field"`
+    From  interface{} `json:"from"`
+    To    interface{} `json:"to"`
+}
+
+// VersionDiff 两个版本的条款差异
+type VersionDiff struct {
+    From    Version       `json:"from"`
+    To      Version       `json:"to"`
+    Changes []FieldChange `json:"changes"`
+}
+
+// ExpiringContract 即将到期的合同
+type ExpiringContract struct {
+    ID           uuid.UUID  `json:"id"`
+    ContractNo   string     `json:"contract_no"`
+    Title        string     `json:"title"`
+    CustomerID   uuid.UUID  `json:"customer_id"`
+    CustomerName string     `json:"customer_name"`
+    EndDate      time.Time  `json:"end_date"`
+    DaysLeft     int        `json:"days_left" gorm:"-"`
+    CreatedBy    *uuid.UUID `json:"-"`
+}
+
+// ExpiryResult 到期处理结果
+type ExpiryResult struct {
+    Expired       int `json:"expired"`
+    RemindersSent int `json:"reminders_sent"`
+}
+
 // 请求结构体
 
 // CreateContractRequest 创建合同请求
@@ -13,17 +160,25 @@ type CreateContractRequest struct {
     PaymentTerms     string  `json:"payment_terms" example:"月结30天"`
     ContractAmount   float64 `json:"contract_amount" example:"1000000.00"`
     DiscountRate     float64 `json:"discount_rate" example:"0.85"`
+    Currency         string  `json:"currency,omitempty" binding:"omitempty,len=3" example:"CNY"`
 }
 
 // UpdateContractRequest 更新合同请求
+// 标题、结束日期、付款条件、合同金额、折扣率属于版本化条款，需通过合同修订（AmendContractRequest）变更
 type UpdateContractRequest struct {
-    Title           string  `json:"title,omitempty" example:"云服务代理合同"`
-    Status          string  `json:"status,omitempty" example:"active"`
-    EndDate         string  `json:"end_date,omitempty" example:"2024-12-31"`
-    SettlementCycle int     `json:"settlement_cycle,omitempty" example:"1"`
-    PaymentTerms    string  `json:"payment_terms,omitempty" example:"月结30天"`
-    ContractAmount  float64 `json:"contract_amount,omitempty" example:"1000000.00"`
-    DiscountRate    float64 `json:"discount_rate,omitempty" example:"0.85"`
+    Status          string `json:"status,omitempty" example:"active"`
+    SettlementCycle int    `json:"settlement_cycle,omitempty" example:"1"`
+}
+
+// AmendContractRequest 合同修订请求，未填写的条款沿用上一版本
+type AmendContractRequest struct {
+    EffectiveDate  string   `json:"effective_date" binding:"required" example:"2024-07-01"`
+    Reason         string   `json:"reason" binding:"required" example:"续签并调整折扣"`
+    Title          *string  `json:"title,omitempty" example:"云服务代理合同"`
+    EndDate        *string  `json:"end_date,omitempty" example:"2025-06-30"`
+    PaymentTerms   *string  `json:"payment_terms,omitempty" example:"月结45天"`
+    ContractAmount *float64 `json:"contract_amount,omitempty" example:"1200000.00"`
+    DiscountRate   *float64 `json:"discount_rate,omitempty" binding:"omitempty,gt=0,lte=1" example:"0.82"`
 }
 
 // 响应结构体
@@ -54,6 +209,7 @@ type ContractData struct {
     PaymentTerms    string  `json:"payment_terms" example:"月结30天"`
     ContractAmount  float64 `json:"contract_amount" example:"1000000.00"`
     DiscountRate    float64 `json:"discount_rate" example:"0.85"`
+    Currency        string  `json:"currency" example:"CNY"`
     CreatedAt       string  `json:"created_at" example:"2024-01-01T00:00:00Z"`
     UpdatedAt       string  `json:"updated_at" example:"2024-01-01T00:00:00Z"`
 }
@@ -67,10 +223,8 @@ type ContractResponse struct {
 
 // ContractListData 合同列表数据
 type ContractListData struct {
-    Contracts []ContractData `json:"contracts"`
-    Total     int64          `json:"total" example:"100"`
-    Page      int            `json:"page" example:"1"`
-    PageSize  int            `json:"page_size" example:"20"`
+    Contracts  []ContractData `json:"contracts"`
+    Pagination query.Page     `json:"pagination"`
 }
 
 // ContractListResponse 合同列表响应
@@ -78,4 +232,52 @@ type ContractListResponse struct {
     Code    int              `json:"code" example:"200"`
     Message string           `json:"message" example:"获取成功"`
     Data    ContractListData `json:"data"`
-}
\ No newline at end of file
+}
+
+// ExpiringContractListData 即将到期合同列表数据
+type ExpiringContractListData struct {
+    Contracts  []ExpiringContract `json:"contracts"`
+    WithinDays int                `json:"within_days" example:"30"`
+}
+
+// ExpiringContractListResponse 即将到期合同列表响应
+type ExpiringContractListResponse struct {
+    Code    int                      `json:"code" example:"200"`
+    Message string                   `json:"message" example:"获取成功"`
+    Data    ExpiringContractListData `json:"data"`
+}
+
+// VersionResponse 合同版本响应
+type VersionResponse struct {
+    Code    int     `json:"code" example:"200"`
+    Message string  `json:"message" example:"获取成功"`
+    Data    Version `json:"data"`
+}
+
+// VersionListResponse 合同版本列表响应
+type VersionListResponse struct {
+    Code    int       `json:"code" example:"200"`
+    Message string    `json:"message" example:"获取成功"`
+    Data    []Version `json:"data"`
+}
+
+// VersionDiffResponse 合同版本差异响应
+type VersionDiffResponse struct {
+    Code    int         `json:"code" example:"200"`
+    Message string      `json:"message" example:"获取成功"`
+    Data    VersionDiff `json:"data"`
+}
+
+// AttachmentResponse 合同附件响应
+type AttachmentResponse struct {
+    Code    int        `json:"code" example:"200"`
+    Message string     `json:"message" example:"获取成功"`
+    Data    Attachment `json:"data"`
+}
+
+// AttachmentListResponse 合同附件列表响应
+type AttachmentListResponse struct {
+    Code    int          `json:"code" example:"200"`
+    Message string       `json:"message" example:"获取成功"`
+    Data    []Attachment `json:"data"`
+}
@@ -3,6 +3,8 @@ package contract
 import (
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+
+    "xcloud-backend/pkg/middleware"
 )
 
 // RegisterRoutes 注册合同管理相关路由
@@ -10,6 +12,22 @@ func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
     handler := NewHandler(db)
 
     router.GET("", handler.GetContracts)
+    router.GET("/expiring", handler.GetExpiringContracts)
     router.GET("/:id", handler.GetContract)
     router.POST("", handler.CreateContract)
+    router.GET("/:id/versions", handler.ListVersions)
+    router.GET("/:id/versions/diff", handler.DiffVersions)
+    router.GET("/:id/versions/:version", handler.GetVersion)
+    router.POST("/:id/amendments", middleware.RequireRole("admin", "manager"), handler.AmendContract)
+    router.GET("/:id/attachments", handler.ListAttachments)
+    router.GET("/:id/attachments/:attachment_id", handler.GetAttachment)
+    router.POST("/:id/attachments", middleware.RequireRole("admin", "manager", "employee", "finance"), handler.UploadAttachment)
+    router.DELETE("/:id/attachments/:attachment_id", handler.DeleteAttachment)
+}
+
+// RegisterPublicRoutes 注册无需登录的合同路由：附件下载使用签名地址鉴权
+func RegisterPublicRoutes(router *gin.RouterGroup, db *gorm.DB) {
+    handler := NewHandler(db)
+
+    router.GET("/:id/attachments/:attachment_id/download", handler.DownloadAttachment)
 }
\ No newline at end of file
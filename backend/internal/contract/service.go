@@ -0,0 +1,295 @@
+package contract
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/internal/currency"
+    "xcloud-backend/internal/customer"
+    "xcloud-backend/internal/sysconfig"
+    "xcloud-backend/pkg/database"
+    "xcloud-backend/pkg/fulltext"
+    "xcloud-backend/pkg/i18n"
+    "xcloud-backend/pkg/query"
+)
+
+const (
+    // DefaultExpiringDays 默认查询的即将到期天数
+    DefaultExpiringDays = 30
+    // MaxExpiringDays 即将到期天数上限
+    MaxExpiringDays = 365
+)
+
+// Service 合同服务
+type Service struct {
+    db     *gorm.DB
+    config *sysconfig.Service
+}
+
+// NewService 创建合同服务
+func NewService(db *gorm.DB) *Service {
+    return &Service{
+        db:     db,
+        config: sysconfig.NewService(db, database.GetRedis()),
+    }
+}
+
+// Create 创建草稿合同，并以合同条款生成版本1
+// 合同提交审批并通过最后一级审批后生效
+func (s *Service) Create(req CreateContractRequest, operator uuid.UUID) (*Contract, error) {
+    customerID, err := uuid.Parse(req.CustomerID)
+    if err != nil {
+        return nil, i18n.NewError("contract.customer_not_found")
+    }
+    start, err := time.Parse("2006-01-02", req.StartDate)
+    if err != nil {
+        return nil, i18n.NewError("contract.invalid_date", "start_date")
+    }
+    end, err := time.Parse("2006-01-02", req.EndDate)
+    if err != nil {
+        return nil, i18n.NewError("contract.invalid_date", "end_date")
+    }
+    if end.Before(start) {
+        return nil, i18n.NewError("contract.end_before_start")
+    }
+    if req.SettlementCycle < 0 || req.DiscountRate < 0 || req.DiscountRate > 1 || req.ContractAmount < 0 {
+        return nil, i18n.NewError("common.invalid_params")
+    }
+
+    var cust customer.Customer
+    if err := s.db.Select("id, status").First(&cust, "id = ?", customerID).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, i18n.NewError("contract.customer_not_found")
+        }
+        return nil, err
+    }
+    // 暂停和流失的客户不能新建合同
+    if cust.Status != customer.StatusActive {
+        return nil, i18n.NewError("contract.customer_not_active", string(cust.Status))
+    }
+    // 检查和保存使用同一个去除首尾空格后的合同编号
+    contractNo := strings.TrimSpace(req.ContractNo)
+    if contractNo == "" {
+        return nil, i18n.NewError("common.invalid_params")
+    }
+    var count int64
+    if err := s.db.Unscoped().Model(&Contract{}).Where("contract_no = ?", contractNo).Count(&count).Error; err != nil {
+        return nil, err
+    }
+    if count > 0 {
+        return nil, i18n.NewError("contract.no_exists", contractNo)
+    }
+
+    ct := &Contract{
+        ContractNo:      contractNo,
+        CustomerID:      customerID,
+        Title:           req.Title,
+        Status:          StatusDraft,
+        StartDate:       start,
+        EndDate:         end,
+        SettlementCycle: req.SettlementCycle,
+        PaymentTerms:    req.PaymentTerms,
+        Currency:        currency.Normalize(req.Currency),
+        CreatedBy:       &operator,
+        UpdatedBy:       &operator,
+    }
+    if ct.SettlementCycle == 0 {
+        ct.SettlementCycle = 1
+    }
+    if ct.Currency == "" {
+        ct.Currency = currency.Normalize(s.config.GetString(sysconfig.KeyDefaultCurrency, currency.DefaultReportingCurrency))
+    }
+    if req.ContractAmount > 0 {
+        ct.ContractAmount = &req.ContractAmount
+    }
+    if req.DiscountRate > 0 {
+        ct.DiscountRate = &req.DiscountRate
+    }
+
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(ct).Error; err != nil {
+            return err
+        }
+        _, err := LoadVersions(tx, ct)
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return ct, nil
+}
+
+// Get 获取合同
+func (s *Service) Get(id uuid.UUID) (*Contract, error) {
+    return s.find(s.db, id, false)
+}
+
+// SearchFields 合同搜索字段：合同编号、标题
+var SearchFields = fulltext.Fields{
+    Vector:  "contracts.search_vector",
+    Columns: []string{"contracts.contract_no", "contracts.title"},
+    Prefix:  []string{"contracts.contract_no", "contracts.title"},
+}
+
+// ListFilter 合同列表查询条件，零值表示不过滤
+type ListFilter struct {
+    Search     string
+    CustomerID *uuid.UUID
+    Status     ContractStatus
+    // Provider 有该云平台返佣规则的合同
+    Provider billing.Provider
+    // CustomerLevel、BusinessType 按合同客户的级别和业务类型过滤
+    CustomerLevel int
+    BusinessType  string
+}
+
+// Validate 校验查询条件
+func (f ListFilter) Validate() error {
+    if f.Status != "" && !f.Status.IsValid() {
+        return i18n.NewError("contract.invalid_status", string(f.Status))
+    }
+    if f.Provider != "" && !f.Provider.IsValid() {
+        return i18n.NewError("contract.invalid_provider", string(f.Provider))
+    }
+    if f.CustomerLevel < 0 {
+        return i18n.NewError("common.invalid_params")
+    }
+    return nil
+}
+
+// Scope 将查询条件应用到合同查询
+func (f ListFilter) Scope(db *gorm.DB) *gorm.DB {
+    db = SearchFields.Scope(f.Search)(db)
+    if f.CustomerID != nil {
+        db = db.Where("contracts.customer_id = ?", *f.CustomerID)
+    }
+    if f.Status != "" {
+        db = db.Where("contracts.status = ?", f.Status)
+    }
+    if f.Provider != "" {
+        db = db.Where(`EXISTS (SELECT 1 FROM commission_rules r
+            WHERE r.contract_id = contracts.id AND r.deleted_at IS NULL AND r.provider = ?)`, f.Provider)
+    }
+    if f.CustomerLevel > 0 || f.BusinessType != "" {
+        customers := db.Session(&gorm.Session{NewDB: true}).Model(&customer.Customer{}).Select("id")
+        if f.CustomerLevel > 0 {
+            customers = customers.Where("level = ?", f.CustomerLevel)
+        }
+        if f.BusinessType != "" {
+            customers = customers.Where("business_type = ?", f.BusinessType)
+        }
+        db = db.Where("contracts.customer_id IN (?)", customers)
+    }
+    return db
+}
+
+// ListQuery 合同列表可过滤、排序的字段，数据导出使用相同的过滤条件
+var ListQuery = query.Resource{
+    Fields: map[string]query.Field{
+        "id":          {Column: "contracts.id", Kind: query.UUID, Sortable: true},
+        "contract_no": {Column: "contracts.contract_no", Kind: query.String, Sortable: true},
+        "title":       {Column: "contracts.title", Kind: query.String, Sortable: true},
+        "customer_id": {Column: "contracts.customer_id", Kind: query.UUID},
+        "status": {
+            Column: "contracts.status", Kind: query.Enum, Sortable: true,
+            Values: []string{string(StatusDraft), string(StatusPending), string(StatusActive), string(StatusExpired), string(StatusTerminated)},
+        },
+        "start_date":      {Column: "contracts.start_date", Kind: query.Date, Sortable: true},
+        "end_date":        {Column: "contracts.end_date", Kind: query.Date, Sortable: true},
+        "contract_amount": {Column: "contracts.contract_amount", Kind: query.Number, Nullable: true},
+        "currency":        {Column: "contracts.currency", Kind: query.String},
+        "created_at":      {Column: "contracts.created_at", Kind: query.Time, Sortable: true},
+        "updated_at":      {Column: "contracts.updated_at", Kind: query.Time, Sortable: true},
+    },
+    Sort: "-created_at",
+    Key:  "id",
+}
+
+// List 按通用查询参数游标分页查询合同，filter 为关键词、云平台等快捷条件
+func (s *Service) List(filter ListFilter, params *query.Params) ([]Contract, *query.Page, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, nil, err
+    }
+    return query.Find[Contract](filter.Scope(s.db.Model(&Contract{})), params)
+}
+
+// Search 分页查询合同；有关键词时按相关度排序，否则按创建时间倒序
+func (s *Service) Search(filter ListFilter, page, pageSize int) ([]Contract, int64, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, 0, err
+    }
+    db := filter.Scope(s.db.Model(&Contract{}))
+
+    var total int64
+    if err := db.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    contracts := []Contract{}
+    err := db.Scopes(SearchFields.Order(filter.Search, "contracts.created_at DESC")).
+        Offset((page - 1) * pageSize).
+        Limit(pageSize).
+        Find(&contracts).Error
+    return contracts, total, err
+}
+
+// ParseWithin 解析即将到期天数，支持 30d 和 30 两种写法，为空时使用默认值
+func ParseWithin(s string) (int, error) {
+    s = strings.TrimSpace(s)
+    if s == "" {
+        return DefaultExpiringDays, nil
+    }
+    days, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(s), "d"))
+    if err != nil || days < 1 || days > MaxExpiringDays {
+        return 0, i18n.NewError("contract.invalid_within", MaxExpiringDays)
+    }
+    return days, nil
+}
+
+// Expiring 获取 today 起 days 天内到期的生效合同，按结束日期排序
+func (s *Service) Expiring(today time.Time, days int) ([]ExpiringContract, error) {
+    return s.findActive(today, "contracts.end_date >= ? AND contracts.end_date <= ?",
+        dateString(today), dateString(today.AddDate(0, 0, days)))
+}
+
+// findActive 按结束日期条件查询生效合同及客户名称，并计算剩余天数
+func (s *Service) findActive(today time.Time, cond string, args ...interface{}) ([]ExpiringContract, error) {
+    query := s.db.Model(&Contract{}).
+        Select("contracts.id, contracts.contract_no, contracts.title, contracts.customer_id, " +
+            "customers.company_name AS customer_name, contracts.end_date, contracts.created_by").
+        Joins("LEFT JOIN customers ON customers.id = contracts.customer_id").
+        Where("contracts.status = ?", StatusActive).
+        Where(cond, args...)
+
+    var list []ExpiringContract
+    if err := query.Order("contracts.end_date, contracts.contract_no").Scan(&list).Error; err != nil {
+        return nil, err
+    }
+    for i := range list {
+        list[i].DaysLeft = daysBetween(today, list[i].EndDate)
+    }
+    return list, nil
+}
+
+// Today 当天日期，按本地时区取年月日
+func Today() time.Time {
+    y, m, d := time.Now().Date()
+    return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dateString 格式化为数据库日期
+func dateString(t time.Time) string {
+    return t.Format("2006-01-02")
+}
+
+// daysBetween 两个日期相差的天数，忽略时间和时区
+func daysBetween(from, to time.Time) int {
+    a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+    b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+    return int(b.Sub(a).Hours() / 24)
+}
@@ -0,0 +1,213 @@
+package contract
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+
+    "xcloud-backend/pkg/i18n"
+)
+
+// LoadVersions 获取合同的全部版本，按生效日期升序
+// 没有版本的合同（如直接写库创建）以当前条款补建版本1
+func LoadVersions(db *gorm.DB, ct *Contract) ([]Version, error) {
+    var versions []Version
+    if err := db.Where("contract_id = ?", ct.ID).Order("effective_date").Find(&versions).Error; err != nil {
+        return nil, fmt.Errorf("查询合同版本失败: %w", err)
+    }
+    if len(versions) > 0 {
+        return versions, nil
+    }
+
+    initial := Version{
+        ContractID:     ct.ID,
+        VersionNo:      1,
+        EffectiveDate:  ct.StartDate,
+        Title:          ct.Title,
+        EndDate:        ct.EndDate,
+        PaymentTerms:   ct.PaymentTerms,
+        ContractAmount: ct.ContractAmount,
+        DiscountRate:   ct.DiscountRate,
+        CreatedBy:      ct.CreatedBy,
+    }
+    if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&initial).Error; err != nil {
+        return nil, fmt.Errorf("创建合同初始版本失败: %w", err)
+    }
+    if err := db.Where("contract_id = ?", ct.ID).Order("effective_date").Find(&versions).Error; err != nil {
+        return nil, fmt.Errorf("查询合同版本失败: %w", err)
+    }
+    return versions, nil
+}
+
+// VersionAt 返回 date 当天生效的版本：生效日期不晚于 date 的最新版本，早于首个版本时返回首个版本
+// versions 需按生效日期升序
+func VersionAt(versions []Version, date time.Time) *Version {
+    if len(versions) == 0 {
+        return nil
+    }
+    day := dateString(date)
+    current := &versions[0]
+    for i := range versions[1:] {
+        v := &versions[i+1]
+        if dateString(v.EffectiveDate) > day {
+            break
+        }
+        current = v
+    }
+    return current
+}
+
+// Versions 获取合同的全部版本
+func (s *Service) Versions(contractID uuid.UUID) ([]Version, error) {
+    ct, err := s.find(s.db, contractID, false)
+    if err != nil {
+        return nil, err
+    }
+    return LoadVersions(s.db, ct)
+}
+
+// Version 获取合同的指定版本
+func (s *Service) Version(contractID uuid.UUID, versionNo int) (*Version, error) {
+    versions, err := s.Versions(contractID)
+    if err != nil {
+        return nil, err
+    }
+    for i := range versions {
+        if versions[i].VersionNo == versionNo {
+            return &versions[i], nil
+        }
+    }
+    return nil, i18n.NewError("contract.version_not_found", versionNo)
+}
+
+// Diff 比较合同两个版本的条款
+func (s *Service) Diff(contractID uuid.UUID, from, to int) (*VersionDiff, error) {
+    a, err := s.Version(contractID, from)
+    if err != nil {
+        return nil, err
+    }
+    b, err := s.Version(contractID, to)
+    if err != nil {
+        return nil, err
+    }
+    return &VersionDiff{From: *a, To: *b, Changes: diffVersions(a, b)}, nil
+}
+
+// Amend 修订生效中的合同：以上一版本为基础生成新版本，合同记录同步为最新版本的条款
+// 生效日期必须晚于当前最新版本；生效日期早于今天时，已计算周期的返佣需重新计算
+func (s *Service) Amend(contractID uuid.UUID, req AmendContractRequest, operator uuid.UUID) (*Version, error) {
+    effective, err := time.Parse("2006-01-02", req.EffectiveDate)
+    if err != nil {
+        return nil, i18n.NewError("contract.invalid_date", "effective_date")
+    }
+
+    var next Version
+    err = s.db.Transaction(func(tx *gorm.DB) error {
+        ct, err := s.find(tx, contractID, true)
+        if err != nil {
+            return err
+        }
+        if ct.Status != StatusActive {
+            return i18n.NewError("contract.not_amendable", string(ct.Status))
+        }
+        versions, err := LoadVersions(tx, ct)
+        if err != nil {
+            return err
+        }
+        latest := versions[len(versions)-1]
+        if dateString(effective) <= dateString(latest.EffectiveDate) {
+            return i18n.NewError("contract.invalid_effective_date", dateString(latest.EffectiveDate))
+        }
+
+        next = latest
+        next.ID = uuid.Nil
+        next.VersionNo = latest.VersionNo + 1
+        next.EffectiveDate = effective
+        next.Reason = req.Reason
+        next.CreatedAt = time.Time{}
+        next.CreatedBy = &operator
+        if req.Title != nil {
+            next.Title = *req.Title
+        }
+        if req.EndDate != nil {
+            if next.EndDate, err = time.Parse("2006-01-02", *req.EndDate); err != nil {
+                return i18n.NewError("contract.invalid_date", "end_date")
+            }
+        }
+        if req.PaymentTerms != nil {
+            next.PaymentTerms = *req.PaymentTerms
+        }
+        if req.ContractAmount != nil {
+            next.ContractAmount = req.ContractAmount
+        }
+        if req.DiscountRate != nil {
+            next.DiscountRate = req.DiscountRate
+        }
+        if dateString(next.EndDate) < dateString(effective) {
+            return i18n.NewError("contract.end_before_effective")
+        }
+        if len(diffVersions(&latest, &next)) == 0 {
+            return i18n.NewError("contract.amendment_no_change")
+        }
+
+        if err := tx.Create(&next).Error; err != nil {
+            return err
+        }
+        return tx.Model(&Contract{}).Where("id = ?", ct.ID).Updates(map[string]interface{}{
+            "title":           next.Title,
+            "end_date":        next.EndDate,
+            "payment_terms":   next.PaymentTerms,
+            "contract_amount": next.ContractAmount,
+            "discount_rate":   next.DiscountRate,
+            "updated_at":      time.Now(),
+            "updated_by":      operator,
+        }).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return &next, nil
+}
+
+// find 获取合同，lock 为 true 时锁定合同行
+func (s *Service) find(db *gorm.DB, id uuid.UUID, lock bool) (*Contract, error) {
+    if lock {
+        db = db.Clauses(clause.Locking{Strength: "UPDATE"})
+    }
+    var ct Contract
+    if err := db.First(&ct, "id = ?", id).Error; err != nil {
+        if errors.Is(err, gorm.ErrRecordNotFound) {
+            return nil, i18n.NewError("contract.not_found")
+        }
+        return nil, err
+    }
+    return &ct, nil
+}
+
+// diffVersions 列出两个版本间变化的条款（不含生效日期）
+func diffVersions(a, b *Version) []FieldChange {
+    changes := []FieldChange{}
+    add := func(field string, from, to interface{}) {
+        if from != to {
+            changes = append(changes, FieldChange{Field: field, From: from, To: to})
+        }
+    }
+    add("title", a.Title, b.Title)
+    add("end_date", dateString(a.EndDate), dateString(b.EndDate))
+    add("payment_terms", a.PaymentTerms, b.PaymentTerms)
+    add("contract_amount", optional(a.ContractAmount), optional(b.ContractAmount))
+    add("discount_rate", optional(a.DiscountRate), optional(b.DiscountRate))
+    return changes
+}
+
+// optional 可空数值，空值为 nil
+func optional(v *float64) interface{} {
+    if v == nil {
+        return nil
+    }
+    return *v
+}
@@ -0,0 +1,98 @@
+package credit
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	creditSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		creditSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// GetCustomerCredit 获取客户信用额度使用情况
+// @Summary 获取客户信用额度使用情况
+// @Description 信用敞口为未结算的账单花费（计费周期未被已关账结算单覆盖的折后金额，按计费周期汇率折算为报告币种），
+// @Description 并汇总全部下级客户。达到 credit.warning_thresholds 配置的阈值（默认 80%、100%）时 level 为 warning，
+// @Description 使用率达到 100% 时为 exceeded。返回本客户按计费周期的未结算花费和直属下级客户的敞口；
+// @Description 缺少汇率无法折算的花费不计入敞口，列在 unconverted 中
+// @Tags 客户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "客户ID"
+// @Success 200 {object} ExposureResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "客户不存在"
+// @Router /customers/{id}/credit [get]
+func (h *Handler) GetCustomerCredit(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	exposure, err := h.creditSvc.Customer(id)
+	if err != nil {
+		h.respondError(c, "获取客户信用额度失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ExposureResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *exposure,
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		if i18nErr.Code == "credit.customer_not_found" {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ExposureResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    Exposure `json:"data"`
+}
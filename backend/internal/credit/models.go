@@ -0,0 +1,80 @@
+package credit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Level 信用额度使用状态
+type Level string
+
+const (
+	LevelNormal   Level = "normal"   // 未达到预警阈值
+	LevelWarning  Level = "warning"  // 达到预警阈值
+	LevelExceeded Level = "exceeded" // 超过信用额度
+)
+
+// Exposure 客户信用敞口：未结算的账单花费（折后金额，报告币种），含全部下级客户
+type Exposure struct {
+	CustomerID   uuid.UUID  `json:"customer_id"`
+	CustomerCode string     `json:"customer_code"`
+	CompanyName  string     `json:"company_name"`
+	ParentID     *uuid.UUID `json:"parent_id,omitempty"`
+	Currency     string     `json:"currency"`
+	// CreditLimit 信用额度（报告币种），未设置时不检查
+	CreditLimit *float64 `json:"credit_limit,omitempty"`
+	// OwnExposure 本客户的未结算花费
+	OwnExposure float64 `json:"own_exposure"`
+	// TotalExposure 本客户及全部下级客户的未结算花费
+	TotalExposure float64  `json:"total_exposure"`
+	Available     *float64 `json:"available,omitempty"`
+	// Utilization 额度使用率，如 0.85 表示 85%
+	Utilization *float64 `json:"utilization,omitempty"`
+	// Threshold 已达到的最高预警阈值（百分比），未达到时为 0
+	Threshold int   `json:"threshold"`
+	Level     Level `json:"level"`
+
+	// Unconverted 缺少汇率、无法折算为报告币种的本客户未结算花费，未计入敞口
+	Unconverted []UnconvertedAmount `json:"unconverted,omitempty"`
+
+	Periods  []PeriodExposure `json:"periods,omitempty"`
+	Children []Exposure       `json:"children,omitempty"`
+}
+
+// UnconvertedAmount 缺少汇率的未结算花费（原币种）
+type UnconvertedAmount struct {
+	Period   string  `json:"period" example:"2024-06"`
+	Currency string  `json:"currency" example:"USD"`
+	Amount   float64 `json:"amount"`
+}
+
+// PeriodExposure 本客户一个计费周期的未结算花费
+type PeriodExposure struct {
+	Period string  `json:"period"`
+	Amount float64 `json:"amount"`
+}
+
+// Alert 已发送的额度预警，每个客户每个阈值只通知一次；使用率回落到阈值以下后删除，再次达到时重新通知
+type Alert struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID  uuid.UUID `json:"customer_id" gorm:"type:uuid;not null"`
+	Threshold   int       `json:"threshold" gorm:"not null"`
+	Exposure    float64   `json:"exposure" gorm:"type:decimal(15,2);not null"`
+	CreditLimit float64   `json:"credit_limit" gorm:"type:decimal(15,2);not null"`
+	Currency    string    `json:"currency" gorm:"type:varchar(3);not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 设置表名
+func (Alert) TableName() string {
+	return "credit_alerts"
+}
+
+// CheckResult 额度检查结果
+type CheckResult struct {
+	Checked   int `json:"checked"`
+	Warning   int `json:"warning"`
+	Exceeded  int `json:"exceeded"`
+	AlertSent int `json:"alerts_sent"`
+}
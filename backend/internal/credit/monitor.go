@@ -0,0 +1,52 @@
+package credit
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/pkg/logger"
+)
+
+// StartMonitor 启动信用额度检查：新账单写入后以及每隔 credit.check_interval_minutes 分钟检查一次，ctx 取消时退出
+// 预警记录保证每个阈值只通知一次，多实例同时检查不会重复通知
+func StartMonitor(ctx context.Context, svc *Service) {
+	trigger := make(chan struct{}, 1)
+	billing.OnIngest(func(uuid.UUID, billing.Provider, string) {
+		// 检查进行中时合并为一次
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	})
+
+	interval := time.Duration(viper.GetInt("credit.check_interval_minutes")) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-trigger:
+			}
+			result, err := svc.Check(ctx)
+			if err != nil {
+				logger.GetLogger().Error("信用额度检查失败:", err)
+				continue
+			}
+			if result.AlertSent > 0 {
+				logger.GetLogger().Info("信用额度检查完成: 预警:", result.Warning, " 超额:", result.Exceeded, " 发送通知:", result.AlertSent)
+			}
+		}
+	}()
+}
@@ -0,0 +1,13 @@
+package credit
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterCustomerRoutes 在客户路由组下注册信用额度查询路由
+func RegisterCustomerRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/:id/credit", handler.GetCustomerCredit)
+}
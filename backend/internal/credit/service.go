@@ -0,0 +1,390 @@
+package credit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/currency"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/internal/notify"
+	"xcloud-backend/internal/settlement"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/internal/user"
+	"xcloud-backend/internal/webhook"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// defaultThresholds 未配置 credit.warning_thresholds 时的预警阈值（百分比）
+	defaultThresholds = "80,100"
+	// maxThreshold 预警阈值上限（百分比）
+	maxThreshold = 1000
+)
+
+// Service 客户信用额度服务
+type Service struct {
+	db     *gorm.DB
+	config *sysconfig.Service
+}
+
+// NewService 创建客户信用额度服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:     db,
+		config: sysconfig.NewService(db, database.GetRedis()),
+	}
+}
+
+// snapshot 全部客户的信用敞口
+type snapshot struct {
+	currency   string
+	thresholds []int
+	exposures  map[uuid.UUID]*Exposure
+	children   map[uuid.UUID][]uuid.UUID
+	periods    map[uuid.UUID]map[string]float64
+	owners     map[uuid.UUID]*uuid.UUID
+}
+
+// Customer 获取客户的信用敞口，包括本客户按计费周期的未结算花费和直属下级客户的敞口
+// 只计算该客户及其下级客户，不计算全部客户
+func (s *Service) Customer(id uuid.UUID) (*Exposure, error) {
+	ids, err := s.subtree(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, i18n.NewError("credit.customer_not_found")
+	}
+	snap, err := s.snapshot(ids)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := snap.exposures[id]
+	if !ok {
+		return nil, i18n.NewError("credit.customer_not_found")
+	}
+
+	result := *e
+	result.Periods = []PeriodExposure{}
+	for period, amount := range snap.periods[id] {
+		result.Periods = append(result.Periods, PeriodExposure{Period: period, Amount: round(amount)})
+	}
+	sort.Slice(result.Periods, func(i, j int) bool { return result.Periods[i].Period < result.Periods[j].Period })
+	result.Children = []Exposure{}
+	for _, child := range snap.children[id] {
+		result.Children = append(result.Children, *snap.exposures[child])
+	}
+	sort.Slice(result.Children, func(i, j int) bool { return result.Children[i].CustomerCode < result.Children[j].CustomerCode })
+	return &result, nil
+}
+
+// Breaches 达到预警阈值的客户，按使用率倒序，limit 大于 0 时只返回前 limit 个
+func (s *Service) Breaches(limit int) ([]Exposure, error) {
+	snap, err := s.snapshot(nil)
+	if err != nil {
+		return nil, err
+	}
+	list := []Exposure{}
+	for _, e := range snap.exposures {
+		if e.Level != LevelNormal {
+			list = append(list, *e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if *list[i].Utilization != *list[j].Utilization {
+			return *list[i].Utilization > *list[j].Utilization
+		}
+		return list[i].CustomerCode < list[j].CustomerCode
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// Check 检查全部设置了信用额度的客户，首次达到某个预警阈值时通知客户创建人、经理和财务
+func (s *Service) Check(ctx context.Context) (*CheckResult, error) {
+	snap, err := s.snapshot(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{}
+	for id, e := range snap.exposures {
+		if e.CreditLimit == nil {
+			continue
+		}
+		result.Checked++
+		switch e.Level {
+		case LevelWarning:
+			result.Warning++
+		case LevelExceeded:
+			result.Exceeded++
+		}
+
+		// 使用率回落到阈值以下时清除预警记录，再次达到时重新通知
+		if err := s.db.Where("customer_id = ? AND threshold > ?", id, e.Threshold).Delete(&Alert{}).Error; err != nil {
+			return result, err
+		}
+		if e.Threshold == 0 {
+			continue
+		}
+		alert := &Alert{
+			CustomerID:  id,
+			Threshold:   e.Threshold,
+			Exposure:    e.TotalExposure,
+			CreditLimit: *e.CreditLimit,
+			Currency:    e.Currency,
+		}
+		res := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+		if res.Error != nil {
+			return result, res.Error
+		}
+		if res.RowsAffected == 0 {
+			// 已通知过
+			continue
+		}
+		if err := s.notifyAlert(ctx, e, snap.owners[id]); err != nil {
+			// 删除预警记录，下次检查时重新发送
+			logger.GetLogger().Error("发送信用额度预警失败:", e.CustomerCode, " ", err)
+			s.db.Delete(alert)
+			continue
+		}
+		if e.Level == LevelExceeded {
+			s.publishExceeded(e, alert)
+		}
+		result.AlertSent++
+	}
+	return result, nil
+}
+
+// notifyAlert 发送信用额度预警
+func (s *Service) notifyAlert(ctx context.Context, e *Exposure, owner *uuid.UUID) error {
+	var owners []uuid.UUID
+	if owner != nil {
+		owners = append(owners, *owner)
+	}
+	recipients, err := notify.Recipients(s.db, owners, []user.UserRole{user.RoleManager, user.RoleFinance})
+	if err != nil {
+		return err
+	}
+	event, subject, body := "credit.warning", "credit.notify.warning_subject", "credit.notify.warning_body"
+	if e.Level == LevelExceeded {
+		event, subject, body = "credit.exceeded", "credit.notify.exceeded_subject", "credit.notify.exceeded_body"
+	}
+	return notify.Publish(ctx, notify.Message{
+		Event:   event,
+		Subject: notify.Text{Code: subject, Args: []interface{}{e.CompanyName, e.Threshold}},
+		Body: notify.Text{Code: body, Args: []interface{}{
+			e.CompanyName, e.CustomerCode, e.TotalExposure, e.Currency, *e.CreditLimit, e.Currency, *e.Utilization * 100,
+		}},
+		Link:       "/customers/" + e.CustomerID.String(),
+		Recipients: recipients,
+	})
+}
+
+// publishExceeded 发布信用额度超额事件
+func (s *Service) publishExceeded(e *Exposure, alert *Alert) {
+	webhook.Publish(s.db, webhook.EventCreditExceeded, map[string]interface{}{
+		"customer_id":    e.CustomerID,
+		"customer_code":  e.CustomerCode,
+		"company_name":   e.CompanyName,
+		"credit_limit":   alert.CreditLimit,
+		"total_exposure": alert.Exposure,
+		"utilization":    e.Utilization,
+		"threshold":      alert.Threshold,
+		"currency":       alert.Currency,
+	})
+}
+
+// subtree 客户及其全部下级客户的ID，客户不存在时返回空
+func (s *Service) subtree(id uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	err := s.db.Raw(`WITH RECURSIVE tree AS (
+			SELECT id FROM customers WHERE id = ? AND deleted_at IS NULL
+			UNION
+			SELECT c.id FROM customers c JOIN tree t ON c.parent_id = t.id WHERE c.deleted_at IS NULL
+		) SELECT id FROM tree`, id).Scan(&ids).Error
+	return ids, err
+}
+
+// snapshot 计算客户的信用敞口并沿上级客户汇总，ids 为空时计算全部客户
+// 未结算花费为计费周期未被已关账结算单覆盖的账单折后金额，按计费周期汇率折算为报告币种；
+// 缺少汇率的花费记入客户的 Unconverted，不影响其他客户
+func (s *Service) snapshot(ids []uuid.UUID) (*snapshot, error) {
+	snap := &snapshot{
+		currency:   currency.NewService(s.db).ReportingCurrency(),
+		thresholds: s.Thresholds(),
+		exposures:  map[uuid.UUID]*Exposure{},
+		children:   map[uuid.UUID][]uuid.UUID{},
+		periods:    map[uuid.UUID]map[string]float64{},
+		owners:     map[uuid.UUID]*uuid.UUID{},
+	}
+
+	customerQuery := s.db.Select("id, customer_code, company_name, parent_id, credit_limit, created_by")
+	if ids != nil {
+		customerQuery = customerQuery.Where("id IN ?", ids)
+	}
+	var customers []customer.Customer
+	if err := customerQuery.Find(&customers).Error; err != nil {
+		return nil, fmt.Errorf("查询客户失败: %w", err)
+	}
+	for _, c := range customers {
+		snap.exposures[c.ID] = &Exposure{
+			CustomerID:   c.ID,
+			CustomerCode: c.CustomerCode,
+			CompanyName:  c.CompanyName,
+			ParentID:     c.ParentID,
+			Currency:     snap.currency,
+			CreditLimit:  c.CreditLimit,
+			Level:        LevelNormal,
+		}
+		snap.owners[c.ID] = c.CreatedBy
+	}
+	for _, c := range customers {
+		if c.ParentID != nil && snap.exposures[*c.ParentID] != nil {
+			snap.children[*c.ParentID] = append(snap.children[*c.ParentID], c.ID)
+		}
+	}
+
+	var rows []struct {
+		CustomerID    uuid.UUID
+		BillingPeriod string
+		Currency      string
+		Amount        float64
+	}
+	billingQuery := s.db.Table("billing_data_template b")
+	if ids != nil {
+		billingQuery = billingQuery.Where("b.customer_id IN ?", ids)
+	}
+	err := billingQuery.
+		Select("b.customer_id, b.billing_period, b.currency, SUM(b.discounted_cost) AS amount").
+		Where("b.deleted_at IS NULL").
+		Where(`NOT EXISTS (SELECT 1 FROM commission_settlements cs
+			WHERE cs.customer_id = b.customer_id AND cs.status = ?
+			AND b.billing_period BETWEEN cs.cycle_start AND cs.cycle_end)`, settlement.StatusClosed).
+		Group("b.customer_id, b.billing_period, b.currency").
+		Order("b.customer_id, b.billing_period, b.currency").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("汇总未结算花费失败: %w", err)
+	}
+
+	converters := map[string]*currency.Converter{}
+	for _, r := range rows {
+		e, ok := snap.exposures[r.CustomerID]
+		if !ok {
+			continue
+		}
+		conv, ok := converters[r.BillingPeriod]
+		if !ok {
+			conv = currency.NewConverter(s.db, r.BillingPeriod)
+			converters[r.BillingPeriod] = conv
+		}
+		amount, err := conv.Convert(r.Amount, r.Currency, snap.currency)
+		var missing *i18n.Error
+		if errors.As(err, &missing) {
+			logger.GetLogger().Warn("信用敞口缺少汇率:", e.CustomerCode, " ", r.BillingPeriod, " ", r.Currency)
+			e.Unconverted = append(e.Unconverted, UnconvertedAmount{
+				Period:   r.BillingPeriod,
+				Currency: r.Currency,
+				Amount:   round(r.Amount),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e.OwnExposure += amount
+		if snap.periods[r.CustomerID] == nil {
+			snap.periods[r.CustomerID] = map[string]float64{}
+		}
+		snap.periods[r.CustomerID][r.BillingPeriod] += amount
+	}
+
+	visiting := map[uuid.UUID]bool{}
+	done := map[uuid.UUID]bool{}
+	var total func(id uuid.UUID) float64
+	total = func(id uuid.UUID) float64 {
+		e := snap.exposures[id]
+		if done[id] || visiting[id] {
+			// 上级关系出现环时不重复计算
+			return e.TotalExposure
+		}
+		visiting[id] = true
+		sum := e.OwnExposure
+		for _, child := range snap.children[id] {
+			sum += total(child)
+		}
+		visiting[id] = false
+		done[id] = true
+		e.TotalExposure = sum
+		return sum
+	}
+	for id := range snap.exposures {
+		total(id)
+	}
+	for _, e := range snap.exposures {
+		e.OwnExposure = round(e.OwnExposure)
+		e.TotalExposure = round(e.TotalExposure)
+		evaluate(e, snap.thresholds)
+	}
+	return snap, nil
+}
+
+// evaluate 计算可用额度、使用率和预警级别
+func evaluate(e *Exposure, thresholds []int) {
+	if e.CreditLimit == nil || *e.CreditLimit <= 0 {
+		e.CreditLimit = nil
+		return
+	}
+	available := round(*e.CreditLimit - e.TotalExposure)
+	utilization := math.Round(e.TotalExposure / *e.CreditLimit * 10000) / 10000
+	e.Available = &available
+	e.Utilization = &utilization
+
+	percent := utilization * 100
+	for _, t := range thresholds {
+		if percent >= float64(t) {
+			e.Threshold = t
+		}
+	}
+	switch {
+	case utilization >= 1:
+		e.Level = LevelExceeded
+	case e.Threshold > 0:
+		e.Level = LevelWarning
+	}
+}
+
+// Thresholds 解析 credit.warning_thresholds，返回去重后的升序阈值（百分比）
+func (s *Service) Thresholds() []int {
+	raw := s.config.GetString(sysconfig.KeyCreditWarningThresholds, defaultThresholds)
+	seen := map[int]bool{}
+	var thresholds []int
+	for _, part := range strings.Split(raw, ",") {
+		t, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "%")))
+		if err != nil || t <= 0 || t > maxThreshold || seen[t] {
+			continue
+		}
+		seen[t] = true
+		thresholds = append(thresholds, t)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
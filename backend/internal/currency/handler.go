@@ -0,0 +1,339 @@
+package currency
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	currencySvc *Service
+	logger      *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		currencySvc: NewService(db),
+		logger:      logger.GetLogger(),
+	}
+}
+
+// ListRates 获取汇率列表
+// @Summary 获取汇率列表
+// @Description 分页获取汇率，按汇率日期倒序，同时返回当前报告币种
+// @Tags 汇率管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param base_currency query string false "基准币种"
+// @Param quote_currency query string false "报价币种"
+// @Param rate_type query string false "汇率类型（daily/monthly）"
+// @Param period query string false "计费周期（YYYY-MM）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} RateListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /exchange-rates [get]
+func (h *Handler) ListRates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	filter := RateFilter{
+		BaseCurrency:  c.Query("base_currency"),
+		QuoteCurrency: c.Query("quote_currency"),
+		RateType:      RateType(c.Query("rate_type")),
+		Period:        c.Query("period"),
+	}
+	if filter.RateType != "" && !filter.RateType.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "currency.invalid_rate_type"),
+		})
+		return
+	}
+
+	rates, total, err := h.currencySvc.List(filter, page, pageSize)
+	if err != nil {
+		var i18nErr *i18n.Error
+		if errors.As(err, &i18nErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.Message(c, err),
+			})
+			return
+		}
+		h.logger.Error("获取汇率列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RateListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: RateListData{
+			Rates:             rates,
+			ReportingCurrency: h.currencySvc.ReportingCurrency(),
+			Total:             total,
+			Page:              page,
+			PageSize:          pageSize,
+		},
+	})
+}
+
+// UpsertRates 批量录入汇率
+// @Summary 批量录入汇率
+// @Description 手工录入日汇率或月度汇率，同一币种对、类型、日期已存在时覆盖，同一批中重复时以最后一条为准（需要管理员或经理权限）
+// @Tags 汇率管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body UpsertRatesRequest true "汇率列表"
+// @Success 200 {object} RateSaveResponse "保存成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /exchange-rates [post]
+func (h *Handler) UpsertRates(c *gin.Context) {
+	var req UpsertRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.currencySvc.Upsert(req.Rates, SourceManual, &operator)
+	h.respondSaved(c, rates, err)
+}
+
+// ImportRates 导入汇率文件
+// @Summary 导入汇率文件
+// @Description 上传 CSV 文件批量录入汇率，表头为 base_currency,quote_currency,rate,rate_type,rate_date，重复的币种对、类型、日期以最后一行为准（需要管理员或经理权限）
+// @Tags 汇率管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "汇率CSV文件"
+// @Success 200 {object} RateSaveResponse "保存成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 413 {object} ErrorResponse "文件过大"
+// @Router /exchange-rates/import [post]
+func (h *Handler) ImportRates(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("upload.import_max_size"))
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": i18n.T(c, "import.file_too_large"),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "currency.file_required"),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error("读取汇率文件失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+	defer file.Close()
+
+	rates, err := h.currencySvc.ImportCSV(file, &operator)
+	h.respondSaved(c, rates, err)
+}
+
+// FetchRates 拉取汇率
+// @Summary 拉取汇率
+// @Description 通过已注册的汇率来源适配器拉取并保存汇率（需要管理员或经理权限）
+// @Tags 汇率管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body FetchRatesRequest true "拉取参数"
+// @Success 200 {object} RateSaveResponse "保存成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /exchange-rates/fetch [post]
+func (h *Handler) FetchRates(c *gin.Context) {
+	var req FetchRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	rates, err := h.currencySvc.Fetch(c.Request.Context(), req, &operator)
+	h.respondSaved(c, rates, err)
+}
+
+// DeleteRate 删除汇率
+// @Summary 删除汇率
+// @Description 删除指定汇率（需要管理员或经理权限）
+// @Tags 汇率管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "汇率ID"
+// @Success 200 {object} BaseResponse "删除成功"
+// @Failure 404 {object} ErrorResponse "汇率不存在"
+// @Router /exchange-rates/{id} [delete]
+func (h *Handler) DeleteRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	if err := h.currencySvc.Delete(id); err != nil {
+		var i18nErr *i18n.Error
+		if errors.As(err, &i18nErr) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code":    404,
+				"message": i18n.Message(c, err),
+			})
+			return
+		}
+		h.logger.Error("删除汇率失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": i18n.T(c, "common.delete_success"),
+	})
+}
+
+// respondSaved 返回汇率保存结果
+func (h *Handler) respondSaved(c *gin.Context, rates []ExchangeRate, err error) {
+	if err != nil {
+		var i18nErr *i18n.Error
+		if errors.As(err, &i18nErr) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.Message(c, err),
+			})
+			return
+		}
+		h.logger.Error("保存汇率失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	h.logger.Info("汇率已保存:", len(rates), " 条")
+	c.JSON(http.StatusOK, RateSaveResponse{
+		Code:    200,
+		Message: i18n.T(c, "currency.saved"),
+		Data: RateSaveData{
+			Saved: len(rates),
+			Rates: rates,
+		},
+	})
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// 响应结构体
+
+type BaseResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type RateListData struct {
+	Rates             []ExchangeRate `json:"rates"`
+	ReportingCurrency string         `json:"reporting_currency"`
+	Total             int64          `json:"total"`
+	Page              int            `json:"page"`
+	PageSize          int            `json:"page_size"`
+}
+
+type RateListResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    RateListData `json:"data"`
+}
+
+type RateSaveData struct {
+	Saved int            `json:"saved"`
+	Rates []ExchangeRate `json:"rates"`
+}
+
+type RateSaveResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    RateSaveData `json:"data"`
+}
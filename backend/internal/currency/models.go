@@ -0,0 +1,88 @@
+package currency
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RateType 汇率类型
+type RateType string
+
+const (
+	RateDaily   RateType = "daily"   // 日汇率
+	RateMonthly RateType = "monthly" // 月度汇率，rate_date 为当月1日
+)
+
+// IsValid 检查汇率类型是否有效
+func (t RateType) IsValid() bool {
+	return t == RateDaily || t == RateMonthly
+}
+
+// SourceManual 手工录入的汇率来源
+const SourceManual = "manual"
+
+// DateLayout 汇率日期格式
+const DateLayout = "2006-01-02"
+
+// ExchangeRate 汇率：1 单位 BaseCurrency = Rate 单位 QuoteCurrency
+type ExchangeRate struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BaseCurrency  string     `json:"base_currency" gorm:"type:varchar(3);not null"`
+	QuoteCurrency string     `json:"quote_currency" gorm:"type:varchar(3);not null"`
+	Rate          float64    `json:"rate" gorm:"type:decimal(18,8);not null"`
+	RateType      RateType   `json:"rate_type" gorm:"type:varchar(10);not null;default:'monthly'"`
+	RateDate      time.Time  `json:"rate_date" gorm:"type:date;not null"`
+	Source        string     `json:"source" gorm:"type:varchar(50);not null;default:'manual'"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CreatedBy     *uuid.UUID `json:"created_by,omitempty"`
+}
+
+// TableName 设置表名
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
+
+// Normalize 统一币种代码格式（三位大写字母）
+func Normalize(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidCode 检查是否为三位字母的币种代码
+func IsValidCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// 请求结构体
+
+// RateInput 汇率录入项
+type RateInput struct {
+	BaseCurrency  string   `json:"base_currency" binding:"required,len=3" example:"USD"`
+	QuoteCurrency string   `json:"quote_currency" binding:"required,len=3" example:"CNY"`
+	Rate          float64  `json:"rate" binding:"required,gt=0" example:"7.1234"`
+	RateType      RateType `json:"rate_type" binding:"required,oneof=daily monthly" example:"monthly"`
+	RateDate      string   `json:"rate_date" binding:"required" example:"2024-06-01"`
+}
+
+// UpsertRatesRequest 批量录入汇率请求，同一币种对、类型、日期的汇率会被覆盖，请求中重复时以最后一条为准
+type UpsertRatesRequest struct {
+	Rates []RateInput `json:"rates" binding:"required,min=1,dive"`
+}
+
+// FetchRatesRequest 通过汇率适配器拉取汇率请求
+type FetchRatesRequest struct {
+	Source       string   `json:"source" binding:"required" example:"boc"`
+	BaseCurrency string   `json:"base_currency" binding:"required,len=3" example:"USD"`
+	RateType     RateType `json:"rate_type" binding:"required,oneof=daily monthly" example:"monthly"`
+	RateDate     string   `json:"rate_date" binding:"required" example:"2024-06-01"`
+}
@@ -0,0 +1,20 @@
+package currency
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册汇率相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+	managers := middleware.RequireRole("admin", "manager")
+
+	router.GET("", handler.ListRates)
+	router.POST("", managers, handler.UpsertRates)
+	router.POST("/import", managers, handler.ImportRates)
+	router.POST("/fetch", managers, handler.FetchRates)
+	router.DELETE("/:id", managers, handler.DeleteRate)
+}
@@ -0,0 +1,393 @@
+package currency
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+)
+
+// DefaultReportingCurrency 未配置 billing.reporting_currency 时的报告币种
+const DefaultReportingCurrency = "CNY"
+
+// Fetcher 汇率拉取适配器（如央行中间价、外汇牌价接口）
+type Fetcher interface {
+	// FetchRates 拉取 base 兑其他币种在指定日期的汇率，返回 quote 币种到汇率的映射
+	FetchRates(ctx context.Context, base string, date time.Time) (map[string]float64, error)
+}
+
+var (
+	fetchersMu sync.RWMutex
+	fetchers   = map[string]Fetcher{}
+)
+
+// RegisterFetcher 注册汇率拉取适配器
+func RegisterFetcher(source string, f Fetcher) {
+	fetchersMu.Lock()
+	defer fetchersMu.Unlock()
+	fetchers[source] = f
+}
+
+func getFetcher(source string) (Fetcher, bool) {
+	fetchersMu.RLock()
+	defer fetchersMu.RUnlock()
+	f, ok := fetchers[source]
+	return f, ok
+}
+
+// Service 汇率服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建汇率服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// ReportingCurrency 当前报告币种
+func (s *Service) ReportingCurrency() string {
+	code := Normalize(sysconfig.NewService(s.db, database.GetRedis()).GetString(sysconfig.KeyReportingCurrency, DefaultReportingCurrency))
+	if !IsValidCode(code) {
+		return DefaultReportingCurrency
+	}
+	return code
+}
+
+// RateFilter 汇率查询条件
+type RateFilter struct {
+	BaseCurrency  string
+	QuoteCurrency string
+	RateType      RateType
+	Period        string
+}
+
+// List 分页查询汇率，按汇率日期倒序
+func (s *Service) List(filter RateFilter, page, pageSize int) ([]ExchangeRate, int64, error) {
+	query := s.db.Model(&ExchangeRate{})
+	if filter.BaseCurrency != "" {
+		query = query.Where("base_currency = ?", Normalize(filter.BaseCurrency))
+	}
+	if filter.QuoteCurrency != "" {
+		query = query.Where("quote_currency = ?", Normalize(filter.QuoteCurrency))
+	}
+	if filter.RateType != "" {
+		query = query.Where("rate_type = ?", filter.RateType)
+	}
+	if filter.Period != "" {
+		start, err := billing.ParsePeriod(filter.Period)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where("rate_date >= ? AND rate_date < ?", start, start.AddDate(0, 1, 0))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rates []ExchangeRate
+	err := query.Order("rate_date DESC, base_currency, quote_currency").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&rates).Error
+	return rates, total, err
+}
+
+// Upsert 批量录入汇率，同一币种对、类型、日期已存在时覆盖；
+// 同一批中重复的币种对、类型、日期以最后一行为准
+func (s *Service) Upsert(inputs []RateInput, source string, operator *uuid.UUID) ([]ExchangeRate, error) {
+	type rateKey struct {
+		base, quote string
+		rateType    RateType
+		date        string
+	}
+	rates := make([]ExchangeRate, 0, len(inputs))
+	seen := make(map[rateKey]int, len(inputs))
+	for i, in := range inputs {
+		rate, err := buildRate(in, source, operator)
+		if err != nil {
+			return nil, i18n.NewError("currency.invalid_rate_row", i+1, err.Error())
+		}
+		key := rateKey{rate.BaseCurrency, rate.QuoteCurrency, rate.RateType, rate.RateDate.Format("2006-01-02")}
+		if j, ok := seen[key]; ok {
+			rates[j] = rate
+			continue
+		}
+		seen[key] = len(rates)
+		rates = append(rates, rate)
+	}
+	if len(rates) == 0 {
+		return rates, nil
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "base_currency"}, {Name: "quote_currency"}, {Name: "rate_type"}, {Name: "rate_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(&rates, 500).Error
+	if err != nil {
+		return nil, fmt.Errorf("保存汇率失败: %w", err)
+	}
+	return rates, nil
+}
+
+// buildRate 校验汇率录入项；月度汇率的日期统一为当月1日
+func buildRate(in RateInput, source string, operator *uuid.UUID) (ExchangeRate, error) {
+	base, quote := Normalize(in.BaseCurrency), Normalize(in.QuoteCurrency)
+	if !IsValidCode(base) || !IsValidCode(quote) {
+		return ExchangeRate{}, i18n.NewError("currency.invalid_code")
+	}
+	if base == quote {
+		return ExchangeRate{}, i18n.NewError("currency.same_pair")
+	}
+	if !(in.Rate > 0) {
+		return ExchangeRate{}, i18n.NewError("currency.invalid_rate")
+	}
+	if !in.RateType.IsValid() {
+		return ExchangeRate{}, i18n.NewError("currency.invalid_rate_type")
+	}
+	date, err := parseRateDate(in.RateDate)
+	if err != nil {
+		return ExchangeRate{}, err
+	}
+	if in.RateType == RateMonthly {
+		date = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          in.Rate,
+		RateType:      in.RateType,
+		RateDate:      date,
+		Source:        source,
+		CreatedBy:     operator,
+	}, nil
+}
+
+// parseRateDate 解析汇率日期，支持 YYYY-MM-DD 和 YYYY-MM（当月1日）
+func parseRateDate(v string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if t, err := time.Parse(DateLayout, v); err == nil {
+		return t, nil
+	}
+	if t, err := billing.ParsePeriod(v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, i18n.NewError("currency.invalid_date", v)
+}
+
+// requiredColumns 汇率 CSV 文件的必填列，rate_type 为可选列
+var requiredColumns = []string{"base_currency", "quote_currency", "rate", "rate_date"}
+
+// ImportCSV 导入汇率 CSV 文件
+// 表头为 base_currency,quote_currency,rate,rate_type,rate_date；rate_type 为空时按月度汇率处理
+func (s *Service) ImportCSV(r io.Reader, operator *uuid.UUID) ([]ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, i18n.NewError("currency.invalid_file")
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, col := range requiredColumns {
+		if _, ok := index[col]; !ok {
+			return nil, i18n.NewError("currency.missing_column", col)
+		}
+	}
+
+	cell := func(row []string, col string) string {
+		i, ok := index[col]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var inputs []RateInput
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, i18n.NewError("currency.invalid_file")
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(strings.ReplaceAll(cell(row, "rate"), ",", ""), 64)
+		if err != nil {
+			return nil, i18n.NewError("currency.invalid_csv_row", line, cell(row, "rate"))
+		}
+		rateType := RateType(strings.ToLower(cell(row, "rate_type")))
+		if rateType == "" {
+			rateType = RateMonthly
+		}
+		inputs = append(inputs, RateInput{
+			BaseCurrency:  cell(row, "base_currency"),
+			QuoteCurrency: cell(row, "quote_currency"),
+			Rate:          rate,
+			RateType:      rateType,
+			RateDate:      cell(row, "rate_date"),
+		})
+	}
+	if len(inputs) == 0 {
+		return nil, i18n.NewError("currency.empty_file")
+	}
+	return s.Upsert(inputs, SourceManual, operator)
+}
+
+// Fetch 通过已注册的适配器拉取并保存汇率
+func (s *Service) Fetch(ctx context.Context, req FetchRatesRequest, operator *uuid.UUID) ([]ExchangeRate, error) {
+	fetcher, ok := getFetcher(req.Source)
+	if !ok {
+		return nil, i18n.NewError("currency.fetcher_not_found", req.Source)
+	}
+	date, err := parseRateDate(req.RateDate)
+	if err != nil {
+		return nil, err
+	}
+	base := Normalize(req.BaseCurrency)
+	quotes, err := fetcher.FetchRates(ctx, base, date)
+	if err != nil {
+		return nil, i18n.NewError("currency.fetch_failed", err.Error())
+	}
+
+	inputs := make([]RateInput, 0, len(quotes))
+	for quote, rate := range quotes {
+		if Normalize(quote) == base {
+			continue
+		}
+		inputs = append(inputs, RateInput{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Rate:          rate,
+			RateType:      req.RateType,
+			RateDate:      date.Format(DateLayout),
+		})
+	}
+	return s.Upsert(inputs, req.Source, operator)
+}
+
+// Delete 删除汇率
+func (s *Service) Delete(id uuid.UUID) error {
+	result := s.db.Delete(&ExchangeRate{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return i18n.NewError("currency.rate_not_found_id")
+	}
+	return nil
+}
+
+// RateFor 查询计费周期内 from 兑 to 的汇率
+// 优先使用当月月度汇率，其次使用当月最后一个日汇率；正向汇率不存在时使用反向汇率的倒数
+func (s *Service) RateFor(from, to, period string) (float64, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == to {
+		return 1, nil
+	}
+	start, err := billing.ParsePeriod(period)
+	if err != nil {
+		return 0, err
+	}
+	end := start.AddDate(0, 1, 0)
+
+	var rates []ExchangeRate
+	err = s.db.Where("((base_currency = ? AND quote_currency = ?) OR (base_currency = ? AND quote_currency = ?)) AND rate_date >= ? AND rate_date < ?",
+		from, to, to, from, start, end).
+		Find(&rates).Error
+	if err != nil {
+		return 0, fmt.Errorf("查询汇率失败: %w", err)
+	}
+	if rate, ok := pickRate(rates, from); ok {
+		return rate, nil
+	}
+	return 0, i18n.NewError("currency.rate_not_found", from, to, period)
+}
+
+// pickRate 从同一币种对（含反向）的候选汇率中选择：
+// 月度汇率优先于日汇率，日期晚的优先，同等条件下正向汇率优先
+func pickRate(rates []ExchangeRate, from string) (float64, bool) {
+	var best *ExchangeRate
+	better := func(a, b *ExchangeRate) bool {
+		if a.RateType != b.RateType {
+			return a.RateType == RateMonthly
+		}
+		if !a.RateDate.Equal(b.RateDate) {
+			return a.RateDate.After(b.RateDate)
+		}
+		return a.BaseCurrency == from && b.BaseCurrency != from
+	}
+	for i := range rates {
+		if best == nil || better(&rates[i], best) {
+			best = &rates[i]
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	if best.BaseCurrency == from {
+		return best.Rate, true
+	}
+	return 1 / best.Rate, true
+}
+
+// Converter 按计费周期汇率换算金额，缓存已查询的汇率
+// 非并发安全，适用于单次计算任务内使用
+type Converter struct {
+	svc    *Service
+	period string
+	cache  map[string]float64
+}
+
+// NewConverter 创建计费周期的汇率换算器
+func NewConverter(db *gorm.DB, period string) *Converter {
+	return &Converter{svc: NewService(db), period: period, cache: map[string]float64{}}
+}
+
+// Rate 查询 from 兑 to 的汇率
+func (c *Converter) Rate(from, to string) (float64, error) {
+	from, to = Normalize(from), Normalize(to)
+	if from == "" || to == "" || from == to {
+		return 1, nil
+	}
+	key := from + "/" + to
+	if rate, ok := c.cache[key]; ok {
+		return rate, nil
+	}
+	rate, err := c.svc.RateFor(from, to, c.period)
+	if err != nil {
+		return 0, err
+	}
+	c.cache[key] = rate
+	return rate, nil
+}
+
+// Convert 将 from 币种金额换算为 to 币种（不做舍入）
+func (c *Converter) Convert(amount float64, from, to string) (float64, error) {
+	rate, err := c.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return amount * rate, nil
+}
@@ -0,0 +1,203 @@
+package custimport
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	importSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		importSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// GetTemplate 下载客户导入模板
+// @Summary 下载客户导入模板
+// @Description 下载客户批量导入模板。表头与客户导出文件（GET /exports/customers）一致，XLSX 模板附带各列填写说明
+// @Tags 客户管理
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param format query string false "文件格式（csv, xlsx）" default(xlsx)
+// @Success 200 {file} file "导入模板"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /customers/import-template [get]
+func (h *Handler) GetTemplate(c *gin.Context) {
+	format := Format(c.DefaultQuery("format", string(FormatXLSX)))
+	if !format.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "import.unsupported_format"),
+		})
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", "attachment; filename=customer_import_template."+string(format))
+	c.Status(http.StatusOK)
+	if err := WriteTemplate(format, c.Writer); err != nil {
+		h.logger.Error("生成客户导入模板失败:", err)
+		c.Abort()
+	}
+}
+
+// ImportCustomers 批量导入客户
+// @Summary 批量导入客户
+// @Description 上传 CSV/XLSX 文件批量创建客户，按表头名称识别列（支持模板的中文列名），客户导出文件可直接导入。
+// @Description 逐行校验客户编码唯一（不能与已有客户和文件中其他行重复）、上级客户编码（可引用已有客户或文件中的客户）、电话和邮箱格式。
+// @Description dry_run=true 时仅校验并返回预览；否则全部行有效时在一个事务中创建客户，存在错误行时不写入任何客户并返回行级错误（需要管理员或经理权限）
+// @Tags 客户管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "客户文件（CSV/XLSX）"
+// @Param dry_run formData bool false "仅预览" default(false)
+// @Success 200 {object} ImportResponse "预览成功"
+// @Success 201 {object} ImportResponse "导入成功"
+// @Failure 400 {object} ImportResponse "请求参数错误或存在错误行"
+// @Failure 413 {object} ErrorResponse "文件过大"
+// @Router /customers/import [post]
+func (h *Handler) ImportCustomers(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, viper.GetInt64("upload.import_max_size"))
+
+	var req ImportRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"code":    413,
+				"message": i18n.T(c, "import.file_too_large"),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "customer_import.file_required"),
+		})
+		return
+	}
+	format, ok := FormatOf(fileHeader.Filename)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "import.unsupported_format"),
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error("读取客户导入文件失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+	defer file.Close()
+
+	if req.DryRun {
+		result, err := h.importSvc.Preview(file, format)
+		if err != nil {
+			h.respondError(c, "客户导入预览失败:", result, err)
+			return
+		}
+		localize(c, result)
+		c.JSON(http.StatusOK, ImportResponse{
+			Code:    200,
+			Message: i18n.T(c, "import.preview_success"),
+			Data:    result,
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	operator, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return
+	}
+
+	result, err := h.importSvc.Import(file, format, operator)
+	if err != nil {
+		h.respondError(c, "导入客户失败:", result, err)
+		return
+	}
+	h.logger.Info("客户批量导入完成:", fileHeader.Filename, " 创建客户数:", result.Created)
+	localize(c, result)
+	c.JSON(http.StatusCreated, ImportResponse{
+		Code:    201,
+		Message: i18n.T(c, "customer_import.success", result.Created),
+		Data:    result,
+	})
+}
+
+// respondError 业务错误返回400（存在错误行时附带行级错误），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, result *Result, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		localize(c, result)
+		c.JSON(http.StatusBadRequest, ImportResponse{
+			Code:    400,
+			Message: i18n.Message(c, err),
+			Data:    result,
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ImportResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    *Result `json:"data,omitempty"`
+}
+
+// localize 按请求语言生成行级错误信息
+func localize(c *gin.Context, result *Result) {
+	if result == nil {
+		return
+	}
+	for i := range result.Errors {
+		e := &result.Errors[i]
+		e.Message = i18n.T(c, e.Code, e.Args...)
+	}
+}
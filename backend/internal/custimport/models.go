@@ -0,0 +1,116 @@
+package custimport
+
+import (
+	"github.com/google/uuid"
+)
+
+// Format 导入文件格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// IsValid 检查格式是否有效
+func (f Format) IsValid() bool {
+	return f == FormatCSV || f == FormatXLSX
+}
+
+// ContentType 文件的 MIME 类型
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// Columns 导入模板的列，与客户导出文件的前几列一致，导出文件可直接导入
+var Columns = []string{
+	"customer_code", "company_name", "contact_name", "contact_phone", "contact_email", "address",
+	"level", "business_type", "credit_limit", "parent_code",
+}
+
+// ReadOnlyColumns 客户导出文件中只读的列，导入时忽略（新客户的状态均为 active）
+var ReadOnlyColumns = []string{"status", "created_at"}
+
+// requiredColumns 必须存在的列
+var requiredColumns = []string{"customer_code", "company_name", "contact_name"}
+
+// columnAliases 表头的中文别名
+var columnAliases = map[string]string{
+	"客户编码":   "customer_code",
+	"公司名称":   "company_name",
+	"联系人":    "contact_name",
+	"联系电话":   "contact_phone",
+	"联系邮箱":   "contact_email",
+	"地址":     "address",
+	"客户级别":   "level",
+	"业务类型":   "business_type",
+	"信用额度":   "credit_limit",
+	"上级客户编码": "parent_code",
+}
+
+// columnNotes 模板说明页中各列的填写说明
+var columnNotes = map[string]string{
+	"customer_code": "客户编码，必填，最长20个字符，不能包含空白，不能与已有客户或文件中其他行重复",
+	"company_name":  "公司名称，必填，最长200个字符",
+	"contact_name":  "联系人，必填，最长50个字符",
+	"contact_phone": "联系电话，选填，由数字组成，可包含开头的 + 以及 - 和空格，如 13800138000、010-12345678",
+	"contact_email": "联系邮箱，选填，如 contact@example.com",
+	"address":       "地址，选填",
+	"level":         "客户级别，选填，正整数；为空时有上级客户取上级级别加1，否则为1",
+	"business_type": "业务类型，选填，最长50个字符",
+	"credit_limit":  "信用额度，选填，非负数，最多两位小数",
+	"parent_code":   "上级客户编码，选填，可引用已有客户或本文件中的客户",
+}
+
+// RowError 行级错误，Message 按请求语言由 Code 和 Args 生成
+type RowError struct {
+	Row     int           `json:"row" example:"12"`
+	Column  string        `json:"column,omitempty" example:"contact_email"`
+	Code    string        `json:"code" example:"customer_import.row.invalid_email"`
+	Args    []interface{} `json:"args,omitempty" swaggertype:"array,string"`
+	Message string        `json:"message" example:"邮箱格式错误: abc"`
+}
+
+// Row 校验通过的客户行
+type Row struct {
+	Row int `json:"row" example:"2"`
+	// ID 客户ID，预览时为临时ID，导入后为新客户的ID
+	ID           uuid.UUID `json:"id"`
+	CustomerCode string    `json:"customer_code" example:"CUST001"`
+	CompanyName  string    `json:"company_name" example:"示例科技公司"`
+	ContactName  string    `json:"contact_name" example:"张三"`
+	ContactPhone string    `json:"contact_phone,omitempty" example:"13800138000"`
+	ContactEmail string    `json:"contact_email,omitempty" example:"contact@example.com"`
+	Address      string    `json:"address,omitempty"`
+	Level        int       `json:"level" example:"1"`
+	BusinessType string    `json:"business_type,omitempty" example:"互联网"`
+	CreditLimit  *float64  `json:"credit_limit,omitempty" example:"1000000"`
+	ParentCode   string    `json:"parent_code,omitempty" example:"AGENT001"`
+	// ParentID 上级客户ID，上级为本文件中的客户时同 ID
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// Summary 导入汇总
+type Summary struct {
+	TotalRows int `json:"total_rows"`
+	ValidRows int `json:"valid_rows"`
+	ErrorRows int `json:"error_rows"`
+}
+
+// Result 预览或导入结果；存在错误行时不写入任何客户
+type Result struct {
+	Summary         Summary    `json:"summary"`
+	Rows            []Row      `json:"rows"`
+	Errors          []RowError `json:"errors"`
+	ErrorsTruncated bool       `json:"errors_truncated"`
+	// Created 实际创建的客户数，预览时为0
+	Created int `json:"created"`
+}
+
+// ImportRequest 客户导入请求（multipart/form-data，文件字段为 file）
+type ImportRequest struct {
+	DryRun bool `form:"dry_run" example:"true"`
+}
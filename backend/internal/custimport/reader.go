@@ -0,0 +1,122 @@
+package custimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// FormatOf 根据文件名判断文件格式
+func FormatOf(filename string) (Format, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, true
+	case ".xlsx":
+		return FormatXLSX, true
+	default:
+		return "", false
+	}
+}
+
+// readRows 读取导入文件的全部行（含表头），XLSX 读取第一个工作表
+func readRows(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r)
+	default:
+		return nil, i18n.NewError("import.unsupported_format")
+	}
+}
+
+// readCSV 读取CSV文件，去除UTF-8 BOM，非UTF-8编码的文件按GB18030（兼容GBK）解码
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) {
+		if data, err = simplifiedchinese.GB18030.NewDecoder().Bytes(data); err != nil {
+			return nil, i18n.NewError("import.unsupported_format")
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, i18n.NewError("import.unsupported_format")
+	}
+	return rows, nil
+}
+
+// readXLSX 读取Excel文件的第一个工作表
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, i18n.NewError("import.unsupported_format")
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, i18n.NewError("customer_import.empty_file")
+	}
+	return f.GetRows(sheets[0])
+}
+
+// WriteTemplate 写出导入模板：表头与客户导出文件一致；XLSX 模板另附填写说明工作表
+func WriteTemplate(format Format, w io.Writer) error {
+	if format == FormatCSV {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return err
+		}
+		cw := csv.NewWriter(w)
+		if err := cw.Write(Columns); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	header := make([]interface{}, len(Columns))
+	for i, c := range Columns {
+		header[i] = c
+	}
+	if err := f.SetSheetRow("Sheet1", "A1", &header); err != nil {
+		return err
+	}
+
+	const notes = "说明"
+	if _, err := f.NewSheet(notes); err != nil {
+		return err
+	}
+	if err := f.SetSheetRow(notes, "A1", &[]interface{}{"列名", "说明"}); err != nil {
+		return err
+	}
+	for i, c := range Columns {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := f.SetSheetRow(notes, cell, &[]interface{}{c, columnNotes[c]}); err != nil {
+			return err
+		}
+	}
+	cell, _ := excelize.CoordinatesToCellName(1, len(Columns)+2)
+	readOnly := []interface{}{strings.Join(ReadOnlyColumns, ", "), "客户导出文件中的只读列，导入时忽略；新客户的状态均为 active"}
+	if err := f.SetSheetRow(notes, cell, &readOnly); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
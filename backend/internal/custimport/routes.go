@@ -0,0 +1,16 @@
+package custimport
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterCustomerRoutes 在客户路由组下注册批量导入路由
+func RegisterCustomerRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/import-template", handler.GetTemplate)
+	router.POST("/import", middleware.RequireRole("admin", "manager"), handler.ImportCustomers)
+}
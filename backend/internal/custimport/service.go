@@ -0,0 +1,432 @@
+package custimport
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// MaxRows 单个文件最多导入的客户数
+	MaxRows = 5000
+	// maxReportErrors 最多返回的行级错误数
+	maxReportErrors = 1000
+	// batchSize 批量写入的每批行数
+	batchSize = 200
+	// lookupChunk 查询已有客户编码时每次查询的编码数
+	lookupChunk = 1000
+	// maxCreditLimit 信用额度上限（decimal(15,2)）
+	maxCreditLimit = 1e13
+)
+
+// phonePattern 电话号码：数字开头和结尾，可包含开头的 + 以及 - 和空格
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 -]*[0-9]$`)
+
+// Service 客户批量导入服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建客户批量导入服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Preview 校验导入文件并返回将要创建的客户和行级错误，不写入数据
+func (s *Service) Preview(r io.Reader, format Format) (*Result, error) {
+	return s.check(s.db, r, format)
+}
+
+// Import 校验导入文件，全部行有效时在一个事务中创建客户（上级客户先于下级创建）；
+// 存在错误行时不写入任何客户，返回带行级错误的结果和 customer_import.rows_invalid 错误
+func (s *Service) Import(r io.Reader, format Format, operator uuid.UUID) (*Result, error) {
+	var result *Result
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if result, err = s.check(tx, r, format); err != nil {
+			return err
+		}
+		if result.Summary.ErrorRows > 0 {
+			return i18n.NewError("customer_import.rows_invalid", result.Summary.ErrorRows)
+		}
+
+		customers := make([]customer.Customer, len(result.Rows))
+		for i, row := range result.Rows {
+			customers[i] = customer.Customer{
+				ID:           row.ID,
+				CustomerCode: row.CustomerCode,
+				CompanyName:  row.CompanyName,
+				ContactName:  row.ContactName,
+				ContactPhone: row.ContactPhone,
+				ContactEmail: row.ContactEmail,
+				Address:      row.Address,
+				Status:       customer.StatusActive,
+				ParentID:     row.ParentID,
+				Level:        row.Level,
+				BusinessType: row.BusinessType,
+				CreditLimit:  row.CreditLimit,
+				CreatedBy:    &operator,
+				UpdatedBy:    &operator,
+			}
+		}
+		if err := tx.CreateInBatches(&customers, batchSize).Error; err != nil {
+			// 校验后其他请求创建了相同编码的客户
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return i18n.NewError("customer_import.code_conflict")
+			}
+			return err
+		}
+		result.Created = len(customers)
+		return nil
+	})
+	return result, err
+}
+
+// checker 单个文件的校验状态
+type checker struct {
+	rows    []*Row
+	invalid map[int]bool
+	result  *Result
+}
+
+// fail 记录行级错误
+func (c *checker) fail(row int, column, code string, args ...interface{}) {
+	c.invalid[row] = true
+	if len(c.result.Errors) >= maxReportErrors {
+		c.result.ErrorsTruncated = true
+		return
+	}
+	c.result.Errors = append(c.result.Errors, RowError{Row: row, Column: column, Code: code, Args: args})
+}
+
+// check 解析并校验导入文件，db 用于查询已有客户
+func (s *Service) check(db *gorm.DB, r io.Reader, format Format) (*Result, error) {
+	lines, err := readRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// 第一个非空行为表头
+	start := 0
+	for start < len(lines) && blank(lines[start]) {
+		start++
+	}
+	if start == len(lines) {
+		return nil, i18n.NewError("customer_import.empty_file")
+	}
+	index, err := headerIndex(lines[start])
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{invalid: map[int]bool{}, result: &Result{Rows: []Row{}, Errors: []RowError{}}}
+	for i := start + 1; i < len(lines); i++ {
+		if blank(lines[i]) {
+			continue
+		}
+		if len(c.rows) == MaxRows {
+			return nil, i18n.NewError("customer_import.too_many_rows", MaxRows)
+		}
+		c.rows = append(c.rows, c.parse(lines[i], i+1, index))
+	}
+	if len(c.rows) == 0 {
+		return nil, i18n.NewError("customer_import.empty_file")
+	}
+
+	if err := s.resolve(db, c); err != nil {
+		return nil, err
+	}
+
+	c.result.Summary.TotalRows = len(c.rows)
+	c.result.Summary.ErrorRows = len(c.invalid)
+	c.result.Summary.ValidRows = len(c.rows) - len(c.invalid)
+	for _, row := range c.ordered() {
+		c.result.Rows = append(c.result.Rows, *row)
+	}
+	return c.result, nil
+}
+
+// headerIndex 解析表头，返回各列的位置；未知列忽略，缺少必填列时返回错误
+func headerIndex(header []string) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, c := range Columns {
+		known[c] = true
+	}
+	index := map[string]int{}
+	for i, h := range header {
+		name := strings.ToLower(strings.TrimSpace(h))
+		if alias, ok := columnAliases[name]; ok {
+			name = alias
+		}
+		if _, dup := index[name]; known[name] && !dup {
+			index[name] = i
+		}
+	}
+	var missing []string
+	for _, c := range requiredColumns {
+		if _, ok := index[c]; !ok {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, i18n.NewError("customer_import.missing_columns", strings.Join(missing, ", "))
+	}
+	return index, nil
+}
+
+// blank 是否为空行
+func blank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parse 校验一行的字段格式，不检查编码是否已存在和上级客户
+func (c *checker) parse(line []string, rowNum int, index map[string]int) *Row {
+	cell := func(column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(line) {
+			return ""
+		}
+		return strings.TrimSpace(line[i])
+	}
+	text := func(column string, maxLen int, required bool) string {
+		v := cell(column)
+		switch {
+		case v == "" && required:
+			c.fail(rowNum, column, "customer_import.row.required")
+		case utf8.RuneCountInString(v) > maxLen:
+			c.fail(rowNum, column, "customer_import.row.too_long", maxLen)
+		}
+		return v
+	}
+
+	row := &Row{
+		Row:          rowNum,
+		CustomerCode: text("customer_code", 20, true),
+		CompanyName:  text("company_name", 200, true),
+		ContactName:  text("contact_name", 50, true),
+		ContactPhone: text("contact_phone", 20, false),
+		ContactEmail: text("contact_email", 100, false),
+		Address:      cell("address"),
+		BusinessType: text("business_type", 50, false),
+		ParentCode:   cell("parent_code"),
+	}
+	if strings.IndexFunc(row.CustomerCode, unicode.IsSpace) >= 0 {
+		c.fail(rowNum, "customer_code", "customer_import.row.code_whitespace", row.CustomerCode)
+	}
+	if row.ContactPhone != "" && !phonePattern.MatchString(row.ContactPhone) {
+		c.fail(rowNum, "contact_phone", "customer_import.row.invalid_phone", row.ContactPhone)
+	}
+	if row.ContactEmail != "" {
+		if addr, err := mail.ParseAddress(row.ContactEmail); err != nil || addr.Address != row.ContactEmail {
+			c.fail(rowNum, "contact_email", "customer_import.row.invalid_email", row.ContactEmail)
+		}
+	}
+	if raw := cell("level"); raw != "" {
+		level, err := strconv.Atoi(raw)
+		if err != nil || level < 1 {
+			c.fail(rowNum, "level", "customer_import.row.invalid_level", raw)
+		}
+		row.Level = level
+	}
+	if raw := cell("credit_limit"); raw != "" {
+		limit, err := strconv.ParseFloat(strings.ReplaceAll(raw, ",", ""), 64)
+		if err != nil || limit < 0 || limit >= maxCreditLimit || math.Abs(limit*100-math.Round(limit*100)) > 1e-6 {
+			c.fail(rowNum, "credit_limit", "customer_import.row.invalid_credit", raw)
+		} else {
+			row.CreditLimit = &limit
+		}
+	}
+	if row.ParentCode != "" && row.ParentCode == row.CustomerCode {
+		c.fail(rowNum, "parent_code", "customer_import.row.self_parent")
+	}
+	return row
+}
+
+// existing 已有客户（含已删除，客户编码的唯一约束包含已删除的客户）
+type existing struct {
+	ID           uuid.UUID
+	CustomerCode string
+	Level        int
+	DeletedAt    gorm.DeletedAt
+}
+
+// resolve 检查客户编码唯一性、解析上级客户、检测循环引用并计算级别
+func (s *Service) resolve(db *gorm.DB, c *checker) error {
+	byCode := map[string]*Row{}
+	for _, row := range c.rows {
+		if row.CustomerCode == "" {
+			continue
+		}
+		if first, ok := byCode[row.CustomerCode]; ok {
+			c.fail(row.Row, "customer_code", "customer_import.row.duplicate_code", first.Row)
+			continue
+		}
+		byCode[row.CustomerCode] = row
+	}
+
+	found, err := s.lookup(db, c.rows)
+	if err != nil {
+		return err
+	}
+	for _, row := range c.rows {
+		if e, ok := found[row.CustomerCode]; ok {
+			if e.DeletedAt.Valid {
+				c.fail(row.Row, "customer_code", "customer_import.row.code_deleted", row.CustomerCode)
+			} else {
+				c.fail(row.Row, "customer_code", "customer_import.row.code_exists", row.CustomerCode)
+			}
+		}
+	}
+
+	// 上级为已有客户
+	for _, row := range c.rows {
+		if row.ParentCode == "" || row.ParentCode == row.CustomerCode {
+			continue
+		}
+		if _, inFile := byCode[row.ParentCode]; inFile {
+			continue
+		}
+		e, ok := found[row.ParentCode]
+		if !ok || e.DeletedAt.Valid {
+			c.fail(row.Row, "parent_code", "customer_import.row.parent_not_found", row.ParentCode)
+			continue
+		}
+		parentID := e.ID
+		row.ParentID = &parentID
+		if row.Level == 0 {
+			row.Level = e.Level + 1
+		}
+	}
+
+	// 上级为本文件中的客户：检测循环引用
+	for _, row := range c.rows {
+		if c.inCycle(row, byCode) {
+			c.fail(row.Row, "parent_code", "customer_import.row.parent_cycle", row.ParentCode)
+		}
+	}
+
+	// 按上级在前的顺序分配ID、计算级别，上级行存在错误时下级也不能导入
+	done := map[*Row]bool{}
+	var visit func(row *Row)
+	visit = func(row *Row) {
+		if done[row] {
+			return
+		}
+		done[row] = true
+		if parent, ok := byCode[row.ParentCode]; ok && row.ParentID == nil && parent != row && !c.invalid[row.Row] {
+			visit(parent)
+			if c.invalid[parent.Row] {
+				c.fail(row.Row, "parent_code", "customer_import.row.parent_invalid", parent.Row)
+			} else {
+				row.ParentID = &parent.ID
+				if row.Level == 0 {
+					row.Level = parent.Level + 1
+				}
+			}
+		}
+		if row.Level == 0 {
+			row.Level = 1
+		}
+		row.ID = uuid.New()
+	}
+	for _, row := range c.rows {
+		visit(row)
+	}
+	return nil
+}
+
+// inCycle 沿本文件中的上级客户向上查找是否回到自身
+func (c *checker) inCycle(row *Row, byCode map[string]*Row) bool {
+	current := row
+	for steps := 0; steps < len(c.rows); steps++ {
+		parent, ok := byCode[current.ParentCode]
+		if !ok || current.ParentID != nil || parent == current {
+			return false
+		}
+		if parent == row {
+			return true
+		}
+		current = parent
+	}
+	return false
+}
+
+// lookup 查询文件中出现的客户编码和上级客户编码对应的已有客户
+func (s *Service) lookup(db *gorm.DB, rows []*Row) (map[string]existing, error) {
+	seen := map[string]bool{}
+	var codes []string
+	for _, row := range rows {
+		for _, code := range []string{row.CustomerCode, row.ParentCode} {
+			if code != "" && !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	found := map[string]existing{}
+	for i := 0; i < len(codes); i += lookupChunk {
+		end := i + lookupChunk
+		if end > len(codes) {
+			end = len(codes)
+		}
+		var list []existing
+		err := db.Unscoped().Model(&customer.Customer{}).
+			Select("id, customer_code, level, deleted_at").
+			Where("customer_code IN ?", codes[i:end]).
+			Find(&list).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range list {
+			found[e.CustomerCode] = e
+		}
+	}
+	return found, nil
+}
+
+// ordered 有效的行，上级客户在前，其余保持文件中的顺序
+func (c *checker) ordered() []*Row {
+	byID := map[uuid.UUID]*Row{}
+	for _, row := range c.rows {
+		byID[row.ID] = row
+	}
+	added := map[*Row]bool{}
+	var rows []*Row
+	var add func(row *Row)
+	add = func(row *Row) {
+		if added[row] || c.invalid[row.Row] {
+			return
+		}
+		added[row] = true
+		if row.ParentID != nil {
+			if parent, ok := byID[*row.ParentID]; ok {
+				add(parent)
+			}
+		}
+		rows = append(rows, row)
+	}
+	for _, row := range c.rows {
+		add(row)
+	}
+	return rows
+}
@@ -1,44 +1,87 @@
 package customer
 
 import (
+    "errors"
     "net/http"
+    "strconv"
+    "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+    "github.com/sirupsen/logrus"
     "gorm.io/gorm"
+
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/pkg/i18n"
+    "xcloud-backend/pkg/logger"
 )
 
 type Handler struct {
-    db *gorm.DB
+    db          *gorm.DB
+    customerSvc *Service
+    logger      *logrus.Logger
 }
 
 func NewHandler(db *gorm.DB) *Handler {
-    return &Handler{db: db}
+    return &Handler{
+        db:          db,
+        customerSvc: NewService(db),
+        logger:      logger.GetLogger(),
+    }
 }
 
 // GetCustomers 获取客户列表
 // @Summary 获取客户列表
-// @Description 分页获取客户列表
+// @Description 游标分页获取客户列表。通用过滤参数为 filter[字段][运算符]=取值，如 filter[status][in]=active,suspended、filter[created_at][gte]=2024-01-01，
+// @Description 可过滤字段：id、customer_code、company_name、contact_name、status、level、business_type、parent_id、credit_limit、created_at、updated_at；
+// @Description sort 为逗号分隔的排序字段，- 表示倒序，默认按客户编码排序。search 按客户编码、公司名称、联系人全文和模糊匹配，
+// @Description 只包含英文字母时还匹配公司名称的拼音首字母（如 alyjs 匹配阿里云计算）；按相关度排序的搜索见 /search
 // @Tags 客户管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "页码" default(1)
-// @Param page_size query int false "每页数量" default(20)
+// @Param sort query string false "排序字段" default(customer_code)
+// @Param limit query int false "每页数量" default(20)
+// @Param cursor query string false "分页游标，取上一次响应的 next_cursor 或 prev_cursor"
+// @Param count query bool false "是否返回总数"
 // @Param search query string false "搜索关键词"
+// @Param status query string false "客户状态" Enums(active, suspended, churned)
+// @Param level query int false "客户级别"
+// @Param business_type query string false "业务类型"
+// @Param provider query string false "绑定的云平台" Enums(tencent, alibaba, huawei, aws)
+// @Param parent_id query string false "上级客户ID"
 // @Success 200 {object} CustomerListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 401 {object} ErrorResponse "未认证"
 // @Router /customers [get]
 func (h *Handler) GetCustomers(c *gin.Context) {
-    // TODO: 实现获取客户列表逻辑
-    c.JSON(http.StatusOK, gin.H{
-        "code":    200,
-        "message": "获取客户列表成功",
-        "data": gin.H{
-            "customers": []gin.H{},
-            "total":     0,
-            "page":      1,
-            "page_size": 20,
-        },
+    filter, ok := listFilter(c)
+    if !ok {
+        return
+    }
+    params, err := ListQuery.Parse(c.Request.URL.Query())
+    if err != nil {
+        h.respondError(c, "解析客户列表查询参数失败:", err)
+        return
+    }
+
+    customers, page, err := h.customerSvc.List(filter, params)
+    if err != nil {
+        h.respondError(c, "获取客户列表失败:", err)
+        return
+    }
+
+    data := CustomerListData{
+        Customers:  make([]CustomerData, 0, len(customers)),
+        Pagination: *page,
+    }
+    for i := range customers {
+        data.Customers = append(data.Customers, toCustomerData(&customers[i]))
+    }
+    c.JSON(http.StatusOK, CustomerListResponse{
+        Code:    200,
+        Message: i18n.T(c, "customer.list_success"),
+        Data:    data,
     })
 }
 
@@ -58,7 +101,7 @@ func (h *Handler) GetCustomer(c *gin.Context) {
     // TODO: 实现获取客户详情逻辑
     c.JSON(http.StatusOK, gin.H{
         "code":    200,
-        "message": "获取客户详情成功",
+        "message": i18n.T(c, "customer.detail_success"),
         "data": gin.H{
             "id":           id,
             "company_name": "示例公司",
@@ -83,8 +126,8 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{
             "code":    400,
-            "message": "请求参数错误",
-            "error":   err.Error(),
+            "message": i18n.T(c, "common.invalid_params"),
+            "error":   i18n.Message(c, err),
         })
         return
     }
@@ -92,7 +135,7 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
     // TODO: 实现创建客户逻辑
     c.JSON(http.StatusCreated, gin.H{
         "code":    201,
-        "message": "客户创建成功",
+        "message": i18n.T(c, "customer.created"),
         "data": gin.H{
             "id":           "new-customer-id",
             "company_name": req.CompanyName,
@@ -103,7 +146,7 @@ func (h *Handler) CreateCustomer(c *gin.Context) {
 
 // UpdateCustomer 更新客户
 // @Summary 更新客户信息
-// @Description 根据ID更新客户信息
+// @Description 根据ID更新客户信息，状态通过 POST /customers/{id}/status 变更
 // @Tags 客户管理
 // @Accept json
 // @Produce json
@@ -119,8 +162,8 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
     if err := c.ShouldBindJSON(&req); err != nil {
         c.JSON(http.StatusBadRequest, gin.H{
             "code":    400,
-            "message": "请求参数错误",
-            "error":   err.Error(),
+            "message": i18n.T(c, "common.invalid_params"),
+            "error":   i18n.Message(c, err),
         })
         return
     }
@@ -128,7 +171,7 @@ func (h *Handler) UpdateCustomer(c *gin.Context) {
     // TODO: 实现更新客户逻辑
     c.JSON(http.StatusOK, gin.H{
         "code":    200,
-        "message": "客户更新成功",
+        "message": i18n.T(c, "customer.updated"),
         "data": gin.H{
             "id":           id,
             "company_name": req.CompanyName,
@@ -153,9 +196,262 @@ func (h *Handler) DeleteCustomer(c *gin.Context) {
     // TODO: 实现删除客户逻辑
     c.JSON(http.StatusOK, gin.H{
         "code":    200,
-        "message": "客户删除成功",
+        "message": i18n.T(c, "customer.deleted"),
         "data": gin.H{
             "id": id,
         },
     })
-}
\ No newline at end of file
+}
+
+// ChangeStatus 变更客户状态
+// @Summary 变更客户状态
+// @Description 客户状态为 active（活跃）、suspended（暂停）、churned（流失），必须填写变更原因。
+// @Description 暂停时停用客户全部云平台账号的数据同步，且不能为客户新建合同；从暂停恢复活跃时重新启用这些账号的同步。
+// @Description 只有全部合同已终止或到期的客户可以标记为流失，流失客户可以重新激活
+// @Tags 客户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "客户ID"
+// @Param body body ChangeStatusRequest true "目标状态和原因"
+// @Success 200 {object} StatusChangeResponse "变更成功"
+// @Failure 400 {object} ErrorResponse "状态不合法或存在未结束的合同"
+// @Failure 404 {object} ErrorResponse "客户不存在"
+// @Router /customers/{id}/status [post]
+func (h *Handler) ChangeStatus(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+    operator, ok := currentUser(c)
+    if !ok {
+        return
+    }
+    var req ChangeStatusRequest
+    if err := c.ShouldBindJSON(&req); err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+            "error":   i18n.Message(c, err),
+        })
+        return
+    }
+
+    history, err := h.customerSvc.ChangeStatus(id, req, operator)
+    if err != nil {
+        h.respondError(c, "变更客户状态失败:", err)
+        return
+    }
+    h.logger.Info("客户状态变更:", id, " ", history.FromStatus, " -> ", history.ToStatus)
+
+    c.JSON(http.StatusOK, StatusChangeResponse{
+        Code:    200,
+        Message: i18n.T(c, "customer.status_changed"),
+        Data:    *history,
+    })
+}
+
+// GetStatusHistory 获取客户状态变更记录
+// @Summary 获取客户状态变更记录
+// @Description 按变更时间倒序返回客户的全部状态变更及原因
+// @Tags 客户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "客户ID"
+// @Success 200 {object} StatusHistoryResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "客户不存在"
+// @Router /customers/{id}/status-history [get]
+func (h *Handler) GetStatusHistory(c *gin.Context) {
+    id, ok := pathID(c)
+    if !ok {
+        return
+    }
+
+    items, err := h.customerSvc.History(id)
+    if err != nil {
+        h.respondError(c, "获取客户状态变更记录失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, StatusHistoryResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data:    items,
+    })
+}
+
+// ListStatusHistory 查询全部客户的状态变更记录
+// @Summary 查询客户状态变更记录
+// @Description 分页查询全部客户的状态变更记录，可按目标状态和变更日期筛选，用于流失分析（如 status=churned）
+// @Tags 客户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "变更后的状态" Enums(active, suspended, churned)
+// @Param start_date query string false "变更开始日期 YYYY-MM-DD"
+// @Param end_date query string false "变更结束日期 YYYY-MM-DD（含）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} StatusHistoryListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /customers/status-history [get]
+func (h *Handler) ListStatusHistory(c *gin.Context) {
+    filter := HistoryFilter{Status: CustomerStatus(c.Query("status"))}
+    for _, p := range []struct {
+        name   string
+        target **time.Time
+    }{{"start_date", &filter.From}, {"end_date", &filter.To}} {
+        raw := c.Query(p.name)
+        if raw == "" {
+            continue
+        }
+        t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "code":    400,
+                "message": i18n.T(c, "customer.invalid_date", p.name),
+            })
+            return
+        }
+        *p.target = &t
+    }
+    filter.Page, filter.PageSize = pagination(c)
+
+    items, total, err := h.customerSvc.ListHistory(filter)
+    if err != nil {
+        h.respondError(c, "查询客户状态变更记录失败:", err)
+        return
+    }
+
+    c.JSON(http.StatusOK, StatusHistoryListResponse{
+        Code:    200,
+        Message: i18n.T(c, "common.fetch_success"),
+        Data: StatusHistoryListData{
+            Items:    items,
+            Total:    total,
+            Page:     filter.Page,
+            PageSize: filter.PageSize,
+        },
+    })
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+    var i18nErr *i18n.Error
+    if errors.As(err, &i18nErr) {
+        status := http.StatusBadRequest
+        if i18nErr.Code == "customer.not_found" {
+            status = http.StatusNotFound
+        }
+        c.JSON(status, gin.H{
+            "code":    status,
+            "message": i18n.Message(c, err),
+        })
+        return
+    }
+    h.logger.Error(logMessage, err)
+    c.JSON(http.StatusInternalServerError, gin.H{
+        "code":    500,
+        "message": i18n.T(c, "common.internal_error"),
+    })
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+    userID, _ := c.Get("user_id")
+    id, err := uuid.Parse(userID.(string))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_user_id"),
+        })
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+    id, err := uuid.Parse(c.Param("id"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, gin.H{
+            "code":    400,
+            "message": i18n.T(c, "common.invalid_params"),
+        })
+        return uuid.Nil, false
+    }
+    return id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+    page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+    pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+    if page < 1 {
+        page = 1
+    }
+    if pageSize < 1 || pageSize > 100 {
+        pageSize = 20
+    }
+    return page, pageSize
+}
+
+// listFilter 解析客户列表查询条件
+func listFilter(c *gin.Context) (ListFilter, bool) {
+    filter := ListFilter{
+        Search:       c.Query("search"),
+        Status:       CustomerStatus(c.Query("status")),
+        BusinessType: c.Query("business_type"),
+        Provider:     billing.Provider(c.Query("provider")),
+    }
+    if raw := c.Query("level"); raw != "" {
+        level, err := strconv.Atoi(raw)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "code":    400,
+                "message": i18n.T(c, "common.invalid_params"),
+            })
+            return filter, false
+        }
+        filter.Level = level
+    }
+    if raw := c.Query("parent_id"); raw != "" {
+        parentID, err := uuid.Parse(raw)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, gin.H{
+                "code":    400,
+                "message": i18n.T(c, "common.invalid_params"),
+            })
+            return filter, false
+        }
+        filter.ParentID = &parentID
+    }
+    return filter, true
+}
+
+// toCustomerData 转换为客户响应数据
+func toCustomerData(cu *Customer) CustomerData {
+    data := CustomerData{
+        ID:           cu.ID.String(),
+        CustomerCode: cu.CustomerCode,
+        CompanyName:  cu.CompanyName,
+        ContactName:  cu.ContactName,
+        ContactPhone: cu.ContactPhone,
+        ContactEmail: cu.ContactEmail,
+        Address:      cu.Address,
+        Status:       string(cu.Status),
+        Level:        cu.Level,
+        BusinessType: cu.BusinessType,
+        CreatedAt:    cu.CreatedAt.Format(time.RFC3339),
+        UpdatedAt:    cu.UpdatedAt.Format(time.RFC3339),
+    }
+    if cu.ParentID != nil {
+        parentID := cu.ParentID.String()
+        data.ParentID = &parentID
+    }
+    if cu.CreditLimit != nil {
+        data.CreditLimit = *cu.CreditLimit
+    }
+    return data
+}
@@ -1,5 +1,113 @@
 package customer
 
+import (
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+
+    "xcloud-backend/pkg/pinyin"
+    "xcloud-backend/pkg/query"
+)
+
+// CustomerStatus 客户状态枚举
+type CustomerStatus string
+
+const (
+    StatusActive    CustomerStatus = "active"    // 活跃
+    StatusSuspended CustomerStatus = "suspended" // 暂停：停用云平台账号同步，不能新建合同
+    StatusChurned   CustomerStatus = "churned"   // 流失：全部合同已终止或到期
+)
+
+// transitions 允许的状态变更
+var transitions = map[CustomerStatus][]CustomerStatus{
+    StatusActive:    {StatusSuspended, StatusChurned},
+    StatusSuspended: {StatusActive, StatusChurned},
+    StatusChurned:   {StatusActive},
+}
+
+// IsValid 是否为合法的客户状态
+func (s CustomerStatus) IsValid() bool {
+    _, ok := transitions[s]
+    return ok
+}
+
+// CanTransitionTo 是否允许从当前状态变更为 to
+func (s CustomerStatus) CanTransitionTo(to CustomerStatus) bool {
+    for _, next := range transitions[s] {
+        if next == to {
+            return true
+        }
+    }
+    return false
+}
+
+// Customer 客户模型
+type Customer struct {
+    ID           uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+    CustomerCode string         `json:"customer_code" gorm:"type:varchar(20);uniqueIndex;not null"`
+    CompanyName  string         `json:"company_name" gorm:"type:varchar(200);not null"`
+    ContactName  string         `json:"contact_name" gorm:"type:varchar(50);not null"`
+    ContactPhone string         `json:"contact_phone" gorm:"type:varchar(20)"`
+    ContactEmail string         `json:"contact_email" gorm:"type:varchar(100)"`
+    Address      string         `json:"address" gorm:"type:text"`
+    Status       CustomerStatus `json:"status" gorm:"type:customer_status;not null;default:'active'"`
+    ParentID     *uuid.UUID     `json:"parent_id,omitempty" gorm:"type:uuid"`
+    Level        int            `json:"level" gorm:"not null;default:1"`
+    BusinessType string         `json:"business_type" gorm:"type:varchar(50)"`
+    CreditLimit  *float64       `json:"credit_limit,omitempty" gorm:"type:decimal(15,2)"`
+    NameInitials string         `json:"-" gorm:"type:varchar(200)"` // 公司名称拼音首字母，用于搜索
+    CreatedAt    time.Time      `json:"created_at"`
+    UpdatedAt    time.Time      `json:"updated_at"`
+    CreatedBy    *uuid.UUID     `json:"created_by,omitempty"`
+    UpdatedBy    *uuid.UUID     `json:"updated_by,omitempty"`
+    DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Customer) TableName() string {
+    return "customers"
+}
+
+// BeforeSave 保存公司名称时同步更新拼音首字母
+func (c *Customer) BeforeSave(tx *gorm.DB) error {
+    name := c.CompanyName
+    switch dest := tx.Statement.Dest.(type) {
+    case map[string]interface{}:
+        name, _ = dest["company_name"].(string)
+    case *Customer:
+        name = dest.CompanyName
+    }
+    if name != "" {
+        tx.Statement.SetColumn("name_initials", pinyin.Initials(name))
+    }
+    return nil
+}
+
+// StatusHistory 客户状态变更记录
+type StatusHistory struct {
+    ID         uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+    CustomerID uuid.UUID      `json:"customer_id" gorm:"type:uuid;not null"`
+    FromStatus CustomerStatus `json:"from_status" gorm:"type:varchar(20);not null"`
+    ToStatus   CustomerStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+    Reason     string         `json:"reason" gorm:"type:text;not null"`
+    ChangedBy  *uuid.UUID     `json:"changed_by,omitempty" gorm:"type:uuid"`
+    ChangedAt  time.Time      `json:"changed_at" gorm:"not null"`
+}
+
+// TableName 设置表名
+func (StatusHistory) TableName() string {
+    return "customer_status_history"
+}
+
+// StatusHistoryItem 状态变更记录及客户信息
+type StatusHistoryItem struct {
+    StatusHistory
+    CustomerCode  string `json:"customer_code"`
+    CompanyName   string `json:"company_name"`
+    ChangedByName string `json:"changed_by_name,omitempty"`
+}
+
 // 请求结构体
 
 // CreateCustomerRequest 创建客户请求
@@ -16,18 +124,23 @@ type CreateCustomerRequest struct {
     CreditLimit  float64 `json:"credit_limit" example:"1000000.00"`
 }
 
-// UpdateCustomerRequest 更新客户请求
+// UpdateCustomerRequest 更新客户请求，状态通过 ChangeStatusRequest 变更
 type UpdateCustomerRequest struct {
     CompanyName  string  `json:"company_name,omitempty" example:"示例科技公司"`
     ContactName  string  `json:"contact_name,omitempty" example:"张三"`
     ContactPhone string  `json:"contact_phone,omitempty" example:"13800138000"`
     ContactEmail string  `json:"contact_email,omitempty" example:"contact@example.com"`
     Address      string  `json:"address,omitempty" example:"北京市朝阳区示例大厦"`
-    Status       string  `json:"status,omitempty" example:"active"`
     BusinessType string  `json:"business_type,omitempty" example:"互联网"`
     CreditLimit  float64 `json:"credit_limit,omitempty" example:"1000000.00"`
 }
 
+// ChangeStatusRequest 变更客户状态请求
+type ChangeStatusRequest struct {
+    Status CustomerStatus `json:"status" binding:"required" example:"suspended"`
+    Reason string         `json:"reason" binding:"required,max=500" example:"连续三个月未付款"`
+}
+
 // 响应结构体
 
 // BaseResponse 基础响应
@@ -70,10 +183,8 @@ type CustomerResponse struct {
 
 // CustomerListData 客户列表数据
 type CustomerListData struct {
-    Customers []CustomerData `json:"customers"`
-    Total     int64          `json:"total" example:"100"`
-    Page      int            `json:"page" example:"1"`
-    PageSize  int            `json:"page_size" example:"20"`
+    Customers  []CustomerData `json:"customers"`
+    Pagination query.Page     `json:"pagination"`
 }
 
 // CustomerListResponse 客户列表响应
@@ -81,4 +192,33 @@ type CustomerListResponse struct {
     Code    int              `json:"code" example:"200"`
     Message string           `json:"message" example:"获取成功"`
     Data    CustomerListData `json:"data"`
+}
+
+// StatusHistoryResponse 客户状态变更记录响应
+type StatusHistoryResponse struct {
+    Code    int                 `json:"code" example:"200"`
+    Message string              `json:"message" example:"获取成功"`
+    Data    []StatusHistoryItem `json:"data"`
+}
+
+// StatusHistoryListData 状态变更记录分页数据
+type StatusHistoryListData struct {
+    Items    []StatusHistoryItem `json:"items"`
+    Total    int64               `json:"total" example:"100"`
+    Page     int                 `json:"page" example:"1"`
+    PageSize int                 `json:"page_size" example:"20"`
+}
+
+// StatusHistoryListResponse 状态变更记录分页响应
+type StatusHistoryListResponse struct {
+    Code    int                   `json:"code" example:"200"`
+    Message string                `json:"message" example:"获取成功"`
+    Data    StatusHistoryListData `json:"data"`
+}
+
+// StatusChangeResponse 客户状态变更响应
+type StatusChangeResponse struct {
+    Code    int           `json:"code" example:"200"`
+    Message string        `json:"message" example:"客户状态已更新"`
+    Data    StatusHistory `json:"data"`
 }
\ No newline at end of file
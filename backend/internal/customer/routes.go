@@ -3,6 +3,8 @@ package customer
 import (
     "github.com/gin-gonic/gin"
     "gorm.io/gorm"
+
+    "xcloud-backend/pkg/middleware"
 )
 
 // RegisterRoutes 注册客户管理相关路由
@@ -10,8 +12,11 @@ func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
     handler := NewHandler(db)
 
     router.GET("", handler.GetCustomers)
+    router.GET("/status-history", handler.ListStatusHistory)
     router.GET("/:id", handler.GetCustomer)
     router.POST("", handler.CreateCustomer)
     router.PUT("/:id", handler.UpdateCustomer)
     router.DELETE("/:id", handler.DeleteCustomer)
+    router.POST("/:id/status", middleware.RequireRole("admin", "manager"), handler.ChangeStatus)
+    router.GET("/:id/status-history", handler.GetStatusHistory)
 }
\ No newline at end of file
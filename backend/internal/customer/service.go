@@ -0,0 +1,314 @@
+package customer
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+
+    "xcloud-backend/internal/billing"
+    "xcloud-backend/internal/datasync"
+    "xcloud-backend/pkg/fulltext"
+    "xcloud-backend/pkg/i18n"
+    "xcloud-backend/pkg/pinyin"
+    "xcloud-backend/pkg/query"
+)
+
+// openContractStatuses 客户流失前必须终止或到期的合同状态
+var openContractStatuses = []string{"draft", "pending", "active"}
+
+// Service 客户服务
+type Service struct {
+    db *gorm.DB
+}
+
+// NewService 创建客户服务
+func NewService(db *gorm.DB) *Service {
+    return &Service{db: db}
+}
+
+// SearchFields 客户搜索字段：编码、公司名称、联系人，公司名称支持拼音首字母
+var SearchFields = fulltext.Fields{
+    Vector:   "customers.search_vector",
+    Columns:  []string{"customers.customer_code", "customers.company_name", "customers.contact_name"},
+    Initials: "customers.name_initials",
+    Prefix:   []string{"customers.customer_code", "customers.company_name"},
+}
+
+// ListFilter 客户列表查询条件，零值表示不过滤
+type ListFilter struct {
+    Search       string
+    Status       CustomerStatus
+    Level        int
+    BusinessType string
+    // Provider 绑定了该云平台账号的客户
+    Provider billing.Provider
+    ParentID *uuid.UUID
+}
+
+// Validate 校验查询条件
+func (f ListFilter) Validate() error {
+    if f.Status != "" && !f.Status.IsValid() {
+        return i18n.NewError("customer.invalid_status", string(f.Status))
+    }
+    if f.Provider != "" && !f.Provider.IsValid() {
+        return i18n.NewError("customer.invalid_provider", string(f.Provider))
+    }
+    if f.Level < 0 {
+        return i18n.NewError("common.invalid_params")
+    }
+    return nil
+}
+
+// Scope 将查询条件应用到客户查询
+func (f ListFilter) Scope(db *gorm.DB) *gorm.DB {
+    db = SearchFields.Scope(f.Search)(db)
+    if f.Status != "" {
+        db = db.Where("customers.status = ?", f.Status)
+    }
+    if f.Level > 0 {
+        db = db.Where("customers.level = ?", f.Level)
+    }
+    if f.BusinessType != "" {
+        db = db.Where("customers.business_type = ?", f.BusinessType)
+    }
+    if f.Provider != "" {
+        db = db.Where(`EXISTS (SELECT 1 FROM customer_cloud_configs cc
+            JOIN cloud_providers p ON p.id = cc.provider_id
+            WHERE cc.customer_id = customers.id AND cc.deleted_at IS NULL AND p.provider = ?)`, f.Provider)
+    }
+    if f.ParentID != nil {
+        db = db.Where("customers.parent_id = ?", *f.ParentID)
+    }
+    return db
+}
+
+// ListQuery 客户列表可过滤、排序的字段，数据导出使用相同的过滤条件
+var ListQuery = query.Resource{
+    Fields: map[string]query.Field{
+        "id":            {Column: "customers.id", Kind: query.UUID, Sortable: true},
+        "customer_code": {Column: "customers.customer_code", Kind: query.String, Sortable: true},
+        "company_name":  {Column: "customers.company_name", Kind: query.String, Sortable: true},
+        "contact_name":  {Column: "customers.contact_name", Kind: query.String, Sortable: true},
+        "status": {
+            Column: "customers.status", Kind: query.Enum, Sortable: true,
+            Values: []string{string(StatusActive), string(StatusSuspended), string(StatusChurned)},
+        },
+        "level":         {Column: "customers.level", Kind: query.Integer, Sortable: true},
+        "business_type": {Column: "customers.business_type", Kind: query.String, Nullable: true},
+        "parent_id":     {Column: "customers.parent_id", Kind: query.UUID, Nullable: true},
+        "credit_limit":  {Column: "customers.credit_limit", Kind: query.Number, Nullable: true},
+        "created_at":    {Column: "customers.created_at", Kind: query.Time, Sortable: true},
+        "updated_at":    {Column: "customers.updated_at", Kind: query.Time, Sortable: true},
+    },
+    Sort: "customer_code",
+    Key:  "id",
+}
+
+// List 按通用查询参数游标分页查询客户，filter 为关键词、云平台等快捷条件
+func (s *Service) List(filter ListFilter, params *query.Params) ([]Customer, *query.Page, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, nil, err
+    }
+    return query.Find[Customer](filter.Scope(s.db.Model(&Customer{})), params)
+}
+
+// Search 分页查询客户；有关键词时按相关度排序，否则按客户编码排序
+func (s *Service) Search(filter ListFilter, page, pageSize int) ([]Customer, int64, error) {
+    if err := filter.Validate(); err != nil {
+        return nil, 0, err
+    }
+    db := filter.Scope(s.db.Model(&Customer{}))
+
+    var total int64
+    if err := db.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    customers := []Customer{}
+    err := db.Scopes(SearchFields.Order(filter.Search, "customers.customer_code")).
+        Offset((page - 1) * pageSize).
+        Limit(pageSize).
+        Find(&customers).Error
+    return customers, total, err
+}
+
+// HistoryFilter 状态变更记录查询条件
+type HistoryFilter struct {
+    Status   CustomerStatus
+    From     *time.Time
+    To       *time.Time
+    Page     int
+    PageSize int
+}
+
+// ChangeStatus 变更客户状态并记录原因
+// 暂停时停用客户全部云平台账号的数据同步，恢复活跃时只重新启用因暂停而停用的账号；
+// 流失要求客户的全部合同已终止或到期
+func (s *Service) ChangeStatus(customerID uuid.UUID, req ChangeStatusRequest, operator uuid.UUID) (*StatusHistory, error) {
+    to := req.Status
+    if !to.IsValid() {
+        return nil, i18n.NewError("customer.invalid_status", string(to))
+    }
+    reason := strings.TrimSpace(req.Reason)
+    if reason == "" {
+        return nil, i18n.NewError("customer.reason_required")
+    }
+
+    var history *StatusHistory
+    err := s.db.Transaction(func(tx *gorm.DB) error {
+        var c Customer
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, "id = ?", customerID).Error; err != nil {
+            if errors.Is(err, gorm.ErrRecordNotFound) {
+                return i18n.NewError("customer.not_found")
+            }
+            return err
+        }
+        if c.Status == to {
+            return i18n.NewError("customer.status_unchanged", string(to))
+        }
+        if !c.Status.CanTransitionTo(to) {
+            return i18n.NewError("customer.invalid_transition", string(c.Status), string(to))
+        }
+
+        if to == StatusChurned {
+            var open int64
+            err := tx.Table("contracts").
+                Where("customer_id = ? AND deleted_at IS NULL AND status IN ?", customerID, openContractStatuses).
+                Count(&open).Error
+            if err != nil {
+                return err
+            }
+            if open > 0 {
+                return i18n.NewError("customer.open_contracts", open)
+            }
+        }
+
+        switch {
+        case to == StatusSuspended:
+            err := tx.Model(&datasync.CustomerCloudConfig{}).
+                Where("customer_id = ? AND sync_enabled = ?", customerID, true).
+                Updates(map[string]interface{}{"sync_enabled": false, "sync_paused": true, "updated_by": operator}).Error
+            if err != nil {
+                return fmt.Errorf("暂停云平台账号同步失败: %w", err)
+            }
+        case c.Status == StatusSuspended:
+            // 从暂停恢复活跃时重新启用同步；流失时只清除暂停标记，账号保持停用
+            updates := map[string]interface{}{"sync_paused": false, "updated_by": operator}
+            if to == StatusActive {
+                updates["sync_enabled"] = true
+            }
+            err := tx.Model(&datasync.CustomerCloudConfig{}).
+                Where("customer_id = ? AND sync_paused = ?", customerID, true).
+                Updates(updates).Error
+            if err != nil {
+                return fmt.Errorf("恢复云平台账号同步失败: %w", err)
+            }
+        }
+
+        err := tx.Model(&c).Updates(map[string]interface{}{"status": to, "updated_by": operator}).Error
+        if err != nil {
+            return err
+        }
+
+        history = &StatusHistory{
+            CustomerID: customerID,
+            FromStatus: c.Status,
+            ToStatus:   to,
+            Reason:     reason,
+            ChangedBy:  &operator,
+            ChangedAt:  time.Now(),
+        }
+        return tx.Create(history).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+    return history, nil
+}
+
+// History 客户的状态变更记录，按时间倒序
+func (s *Service) History(customerID uuid.UUID) ([]StatusHistoryItem, error) {
+    var count int64
+    if err := s.db.Model(&Customer{}).Where("id = ?", customerID).Count(&count).Error; err != nil {
+        return nil, err
+    }
+    if count == 0 {
+        return nil, i18n.NewError("customer.not_found")
+    }
+
+    items := []StatusHistoryItem{}
+    err := s.historyQuery().
+        Select(historyColumns).
+        Where("h.customer_id = ?", customerID).
+        Order("h.changed_at DESC").
+        Scan(&items).Error
+    return items, err
+}
+
+// ListHistory 分页查询全部客户的状态变更记录，用于流失分析
+func (s *Service) ListHistory(filter HistoryFilter) ([]StatusHistoryItem, int64, error) {
+    query := s.historyQuery()
+    if filter.Status != "" {
+        if !filter.Status.IsValid() {
+            return nil, 0, i18n.NewError("customer.invalid_status", string(filter.Status))
+        }
+        query = query.Where("h.to_status = ?", filter.Status)
+    }
+    if filter.From != nil {
+        query = query.Where("h.changed_at >= ?", *filter.From)
+    }
+    if filter.To != nil {
+        query = query.Where("h.changed_at < ?", filter.To.AddDate(0, 0, 1))
+    }
+
+    var total int64
+    if err := query.Count(&total).Error; err != nil {
+        return nil, 0, err
+    }
+    items := []StatusHistoryItem{}
+    err := query.Select(historyColumns).
+        Order("h.changed_at DESC").
+        Offset((filter.Page - 1) * filter.PageSize).
+        Limit(filter.PageSize).
+        Scan(&items).Error
+    return items, total, err
+}
+
+const historyColumns = "h.*, c.customer_code, c.company_name, u.username AS changed_by_name"
+
+// historyQuery 状态变更记录关联客户和操作人
+func (s *Service) historyQuery() *gorm.DB {
+    return s.db.Table("customer_status_history h").
+        Joins("JOIN customers c ON c.id = h.customer_id").
+        Joins("LEFT JOIN users u ON u.id = h.changed_by")
+}
+
+// BackfillInitials 为尚未计算拼音首字母的客户补全 name_initials，返回更新的客户数
+func (s *Service) BackfillInitials() (int, error) {
+    updated := 0
+    for {
+        var batch []Customer
+        err := s.db.Unscoped().Select("id, company_name").
+            Where("name_initials IS NULL").
+            Limit(500).
+            Find(&batch).Error
+        if err != nil {
+            return updated, err
+        }
+        if len(batch) == 0 {
+            return updated, nil
+        }
+        for _, c := range batch {
+            err := s.db.Unscoped().Model(&Customer{}).Where("id = ?", c.ID).
+                UpdateColumn("name_initials", pinyin.Initials(c.CompanyName)).Error
+            if err != nil {
+                return updated, err
+            }
+            updated++
+        }
+    }
+}
@@ -0,0 +1,59 @@
+package datasync
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+)
+
+// CloudProvider 云服务商配置
+type CloudProvider struct {
+	ID             uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name           string           `json:"name" gorm:"type:varchar(50);not null"`
+	Provider       billing.Provider `json:"provider" gorm:"type:cloud_provider;not null"`
+	APIEndpoint    string           `json:"api_endpoint" gorm:"column:api_endpoint;type:varchar(200);not null"`
+	APIVersion     string           `json:"api_version,omitempty" gorm:"column:api_version;type:varchar(20)"`
+	IsActive       bool             `json:"is_active" gorm:"not null;default:true"`
+	RateLimit      int              `json:"rate_limit" gorm:"default:100"`
+	RetryCount     int              `json:"retry_count" gorm:"default:3"`
+	TimeoutSeconds int              `json:"timeout_seconds" gorm:"default:30"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	CreatedBy      *uuid.UUID       `json:"created_by,omitempty"`
+	UpdatedBy      *uuid.UUID       `json:"updated_by,omitempty"`
+	DeletedAt      gorm.DeletedAt   `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (CloudProvider) TableName() string {
+	return "cloud_providers"
+}
+
+// CustomerCloudConfig 客户云平台账号配置
+type CustomerCloudConfig struct {
+	ID                 uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID         uuid.UUID      `json:"customer_id" gorm:"type:uuid;not null"`
+	ProviderID         uuid.UUID      `json:"provider_id" gorm:"type:uuid;not null"`
+	APIKeyEncrypted    string         `json:"-" gorm:"column:api_key_encrypted;type:text;not null"`
+	SecretKeyEncrypted string         `json:"-" gorm:"column:secret_key_encrypted;type:text"`
+	AccountID          string         `json:"account_id,omitempty" gorm:"type:varchar(100)"`
+	IsActive           bool           `json:"is_active" gorm:"not null;default:true"`
+	SyncEnabled        bool           `json:"sync_enabled" gorm:"not null;default:true"`
+	SyncPaused         bool           `json:"sync_paused" gorm:"not null;default:false"` // 因客户暂停而停用同步，恢复活跃时重新启用
+	LastSyncAt         *time.Time     `json:"last_sync_at,omitempty"`
+	CreatedAt          time.Time      `json:"created_at"`
+	UpdatedAt          time.Time      `json:"updated_at"`
+	CreatedBy          *uuid.UUID     `json:"created_by,omitempty"`
+	UpdatedBy          *uuid.UUID     `json:"updated_by,omitempty"`
+	DeletedAt          gorm.DeletedAt `json:"-" gorm:"index"`
+
+	CloudProvider CloudProvider `json:"cloud_provider" gorm:"foreignKey:ProviderID"`
+}
+
+// TableName 设置表名
+func (CustomerCloudConfig) TableName() string {
+	return "customer_cloud_configs"
+}
@@ -0,0 +1,221 @@
+package datasync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/events"
+	"xcloud-backend/internal/notify"
+	"xcloud-backend/internal/user"
+	"xcloud-backend/internal/webhook"
+	"xcloud-backend/pkg/logger"
+)
+
+// SyncTypeBilling 账单同步
+const SyncTypeBilling = "billing"
+
+// Fetcher 云平台账单拉取适配器
+type Fetcher interface {
+	// FetchBilling 拉取客户账号在计费周期内的账单明细
+	FetchBilling(ctx context.Context, cfg CustomerCloudConfig, period string) ([]billing.BillingData, error)
+}
+
+var (
+	fetchersMu sync.RWMutex
+	fetchers   = map[billing.Provider]Fetcher{}
+)
+
+// RegisterFetcher 注册云平台账单拉取适配器
+func RegisterFetcher(provider billing.Provider, f Fetcher) {
+	fetchersMu.Lock()
+	defer fetchersMu.Unlock()
+	fetchers[provider] = f
+}
+
+// HasFetcher 云平台是否已注册账单拉取适配器
+func HasFetcher(provider billing.Provider) bool {
+	_, ok := getFetcher(provider)
+	return ok
+}
+
+func getFetcher(provider billing.Provider) (Fetcher, bool) {
+	fetchersMu.RLock()
+	defer fetchersMu.RUnlock()
+	f, ok := fetchers[provider]
+	return f, ok
+}
+
+// Service 数据同步服务
+type Service struct {
+	db         *gorm.DB
+	billingSvc *billing.Service
+}
+
+// NewService 创建数据同步服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:         db,
+		billingSvc: billing.NewService(db),
+	}
+}
+
+// Run 同步客户在指定云平台、计费周期的账单数据，并记录同步日志
+func (s *Service) Run(ctx context.Context, customerID uuid.UUID, provider billing.Provider, period string) (*billing.SyncLog, error) {
+	if !provider.IsValid() {
+		return nil, fmt.Errorf("不支持的云平台: %s", provider)
+	}
+	if _, err := billing.ParsePeriod(period); err != nil {
+		return nil, err
+	}
+
+	var cfg CustomerCloudConfig
+	err := s.db.Joins("CloudProvider").
+		Where("customer_cloud_configs.customer_id = ? AND \"CloudProvider\".provider = ?", customerID, provider).
+		Where("customer_cloud_configs.is_active = true").
+		First(&cfg).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("客户未配置 %s 云平台账号", provider)
+		}
+		return nil, err
+	}
+
+	log := billing.SyncLog{
+		CustomerID: customerID,
+		Provider:   provider,
+		SyncType:   SyncTypeBilling,
+		SyncPeriod: period,
+		StartTime:  time.Now(),
+		Status:     billing.SyncStatusRunning,
+	}
+	if err := s.db.Create(&log).Error; err != nil {
+		return nil, err
+	}
+	owner := s.owner(customerID)
+	events.PublishJob(owner, progressOf(&log))
+
+	count, runErr := s.fetchAndStore(ctx, cfg, provider, period)
+	if runErr == nil {
+		now := time.Now()
+		s.db.Model(&cfg).UpdateColumn("last_sync_at", now)
+	}
+	if err := s.finish(&log, count, runErr); err != nil {
+		return &log, err
+	}
+	events.PublishJob(owner, progressOf(&log))
+	return &log, runErr
+}
+
+func (s *Service) fetchAndStore(ctx context.Context, cfg CustomerCloudConfig, provider billing.Provider, period string) (int, error) {
+	if !cfg.SyncEnabled {
+		return 0, errors.New("该云平台账号未启用数据同步")
+	}
+	fetcher, ok := getFetcher(provider)
+	if !ok {
+		return 0, fmt.Errorf("云平台 %s 未注册账单同步适配器", provider)
+	}
+
+	lines, err := fetcher.FetchBilling(ctx, cfg, period)
+	if err != nil {
+		return 0, fmt.Errorf("拉取账单失败: %w", err)
+	}
+	for i := range lines {
+		if lines[i].AccountID == "" {
+			lines[i].AccountID = cfg.AccountID
+		}
+	}
+	return s.billingSvc.ReplaceLines(cfg.CustomerID, provider, period, lines)
+}
+
+// finish 更新同步日志的结束状态，同步失败时通知并发布 sync.failed 事件
+func (s *Service) finish(log *billing.SyncLog, count int, runErr error) error {
+	now := time.Now()
+	log.EndTime = &now
+	log.RecordsCount = count
+	log.Status = billing.SyncStatusSuccess
+	if runErr != nil {
+		log.Status = billing.SyncStatusFailed
+		log.ErrorMessage = runErr.Error()
+	}
+	if err := s.db.Save(log).Error; err != nil {
+		return err
+	}
+	if runErr != nil {
+		s.notifyFailed(log)
+		webhook.Publish(s.db, webhook.EventSyncFailed, map[string]interface{}{
+			"sync_log_id": log.ID,
+			"customer_id": log.CustomerID,
+			"provider":    log.Provider,
+			"sync_type":   log.SyncType,
+			"sync_period": log.SyncPeriod,
+			"error":       log.ErrorMessage,
+			"started_at":  log.StartTime,
+			"ended_at":    log.EndTime,
+		})
+	}
+	return nil
+}
+
+// owner 客户创建人，同步任务的进度推送给创建人
+func (s *Service) owner(customerID uuid.UUID) *uuid.UUID {
+	var cust struct {
+		CreatedBy *uuid.UUID
+	}
+	err := s.db.Table("customers").Select("created_by").Where("id = ?", customerID).Take(&cust).Error
+	if err != nil {
+		logger.GetLogger().Warn("查询客户创建人失败:", customerID, " ", err)
+		return nil
+	}
+	return cust.CreatedBy
+}
+
+// progressOf 同步任务进度，同步一次拉取全部账单，处理数即写入的记录数
+func progressOf(log *billing.SyncLog) events.JobProgress {
+	return events.JobProgress{
+		JobType:   events.JobSync,
+		JobID:     log.ID,
+		Status:    string(log.Status),
+		Processed: log.RecordsCount,
+		Total:     log.RecordsCount,
+		Error:     log.ErrorMessage,
+	}
+}
+
+// notifyFailed 通知客户创建人和管理员账单同步失败
+func (s *Service) notifyFailed(log *billing.SyncLog) {
+	var cust struct {
+		CustomerCode string
+		CompanyName  string
+		CreatedBy    *uuid.UUID
+	}
+	err := s.db.Table("customers").Select("customer_code, company_name, created_by").
+		Where("id = ?", log.CustomerID).Take(&cust).Error
+	if err != nil {
+		logger.GetLogger().Error("查询同步失败的客户失败:", log.CustomerID, " ", err)
+		return
+	}
+	var owners []uuid.UUID
+	if cust.CreatedBy != nil {
+		owners = append(owners, *cust.CreatedBy)
+	}
+	recipients, err := notify.Recipients(s.db, owners, []user.UserRole{user.RoleAdmin})
+	if err != nil {
+		logger.GetLogger().Error("查询同步失败通知接收人失败:", log.ID, " ", err)
+		return
+	}
+	notify.Publish(context.Background(), notify.Message{
+		Event:   "sync.failed",
+		Subject: notify.Text{Code: "datasync.notify.failed_subject", Args: []interface{}{cust.CompanyName, log.Provider}},
+		Body: notify.Text{Code: "datasync.notify.failed_body",
+			Args: []interface{}{cust.CompanyName, cust.CustomerCode, log.Provider, log.SyncPeriod, log.ErrorMessage}},
+		Link:       "/customers/" + log.CustomerID.String(),
+		Recipients: recipients,
+	})
+}
@@ -0,0 +1,233 @@
+package events
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/middleware"
+)
+
+const (
+	// defaultHeartbeat 未配置 events.heartbeat_seconds 时的心跳间隔（秒）
+	defaultHeartbeat = 25
+	// retryMillis 建议客户端断线后的重连间隔（毫秒）
+	retryMillis = 3000
+	// writeTimeout 单次写出的超时时间，客户端长时间不读取时断开连接
+	writeTimeout = 10 * time.Second
+)
+
+type Handler struct {
+	logger *logrus.Logger
+}
+
+func NewHandler() *Handler {
+	return &Handler{
+		logger: logger.GetLogger(),
+	}
+}
+
+// Stream 订阅实时事件
+// @Summary 订阅实时事件
+// @Description 以 Server-Sent Events 推送当前用户的实时事件，事件名为类型，data 为 JSON：
+// @Description job.progress（账单同步、账单导入、数据导出任务的进度和结束状态）、notification.created（新的站内通知）、
+// @Description dashboard.invalidated（统计数据已变化）、resync（断线太久无法补发，应重新加载页面数据）、token.expired（访问令牌已过期）。
+// @Description 每隔 events.heartbeat_seconds 秒发送注释行作为心跳。断线重连时携带 Last-Event-ID 请求头（浏览器 EventSource 自动携带），
+// @Description 补发期间错过的事件。浏览器 EventSource 不能设置请求头，可先调用 POST /events/ticket 取得一次性凭证，
+// @Description 通过 ticket 查询参数传递；凭证使用后即失效，断线重连时需重新取得凭证，并通过 last_event_id 查询参数补发。
+// @Description 访问令牌过期时推送 token.expired 事件后断开连接，客户端刷新令牌后重新连接
+// @Tags 实时事件
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param ticket query string false "一次性事件订阅凭证（未设置 Authorization 请求头时使用）"
+// @Param Last-Event-ID header string false "最后收到的事件ID"
+// @Param last_event_id query string false "最后收到的事件ID（未设置 Last-Event-ID 请求头时使用）"
+// @Success 200 {string} string "事件流"
+// @Failure 401 {object} ErrorResponse "未认证"
+// @Router /events [get]
+func (h *Handler) Stream(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+
+	// 先订阅再补发，补发期间到达的事件按ID去重，不会遗漏
+	sub := local.subscribe(userID)
+	defer local.unsubscribe(sub)
+
+	ctx := c.Request.Context()
+	missed, err := replay(ctx, userID, lastID)
+	if err != nil {
+		h.logger.Warn("补发实时事件失败:", userID, " ", err)
+	}
+
+	w := c.Writer
+	rc := http.NewResponseController(w)
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// write 写出一段事件并立即发送，每次写出单独设置超时，不受服务器 write_timeout 限制
+	write := func(format string, args ...interface{}) bool {
+		rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !write("retry: %d\n\n", retryMillis) {
+		return
+	}
+	sent := lastID
+	for _, e := range missed {
+		if !writeEvent(write, e) {
+			return
+		}
+		if e.ID != "" {
+			sent = e.ID
+		}
+	}
+
+	// 连接不能超过访问令牌的有效期
+	var expired <-chan time.Time
+	if exp, ok := c.Get(middleware.TokenExpiresAtKey); ok {
+		timer := time.NewTimer(time.Until(exp.(time.Time)))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	ticker := time.NewTicker(heartbeat())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-expired:
+			write("event: %s\ndata: {}\n\n", TypeTokenExpired)
+			return
+		case <-sub.dropped:
+			// 客户端读取太慢，断开后由客户端重连补发
+			return
+		case <-ticker.C:
+			if !write(": ping\n\n") {
+				return
+			}
+		case e := <-sub.ch:
+			if sent != "" && e.ID != "" && compareID(e.ID, sent) <= 0 {
+				continue
+			}
+			if !writeEvent(write, e) {
+				return
+			}
+			if e.ID != "" {
+				sent = e.ID
+			}
+		}
+	}
+}
+
+// CreateTicket 获取事件订阅凭证
+// @Summary 获取事件订阅凭证
+// @Description 签发一次性事件订阅凭证，有效期 30 秒，用于浏览器 EventSource 通过 ticket 查询参数订阅实时事件，
+// @Description 避免将访问令牌放在URL中。连接的有效期不超过签发凭证时所用的访问令牌
+// @Tags 实时事件
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TicketResponse "获取成功"
+// @Failure 401 {object} ErrorResponse "未认证"
+// @Failure 500 {object} ErrorResponse "服务器内部错误"
+// @Router /events/ticket [post]
+func (h *Handler) CreateTicket(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	t := ticket{UserID: userID, Language: c.GetString(i18n.ContextKey)}
+	if exp, ok := c.Get(middleware.TokenExpiresAtKey); ok {
+		expiresAt := exp.(time.Time)
+		t.ExpiresAt = &expiresAt
+	}
+	id, err := issueTicket(c.Request.Context(), t)
+	if err != nil {
+		h.logger.Error("签发事件订阅凭证失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, TicketResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: TicketData{
+			Ticket:    id,
+			ExpiresIn: int(ticketTTL / time.Second),
+		},
+	})
+}
+
+// writeEvent 按 SSE 格式写出事件，data 为单行 JSON
+func writeEvent(write func(string, ...interface{}) bool, e *Event) bool {
+	if e.ID != "" {
+		return write("id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+	}
+	return write("event: %s\ndata: %s\n\n", e.Type, e.Data)
+}
+
+// heartbeat 心跳间隔，应小于反向代理的空闲超时
+func heartbeat() time.Duration {
+	seconds := viper.GetInt("events.heartbeat_seconds")
+	if seconds <= 0 {
+		seconds = defaultHeartbeat
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type TicketResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    TicketData `json:"data"`
+}
+
+type TicketData struct {
+	Ticket string `json:"ticket"`
+	// ExpiresIn 凭证有效期（秒）
+	ExpiresIn int `json:"expires_in" example:"30"`
+}
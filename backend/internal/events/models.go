@@ -0,0 +1,67 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// 推送给前端的事件类型
+const (
+	// TypeJobProgress 后台任务（账单同步、账单导入、数据导出）进度和结束状态
+	TypeJobProgress = "job.progress"
+	// TypeNotification 新的站内通知
+	TypeNotification = "notification.created"
+	// TypeDashboardInvalidated 统计数据已变化，前端应重新加载仪表盘
+	TypeDashboardInvalidated = "dashboard.invalidated"
+	// TypeResync 断线期间的事件已无法补发，前端应重新加载页面数据
+	TypeResync = "resync"
+	// TypeTokenExpired 访问令牌已过期，连接随后断开，前端应刷新令牌后重新连接
+	TypeTokenExpired = "token.expired"
+)
+
+// JobType 后台任务类型
+type JobType string
+
+const (
+	JobSync       JobType = "sync"
+	JobBillImport JobType = "bill_import"
+	JobExport     JobType = "export"
+)
+
+// Event 实时事件，ID 为 Redis Stream 的条目ID，按时间递增，作为 SSE 的事件ID
+type Event struct {
+	ID string `json:"id,omitempty"`
+	// UserID 接收人，为空时推送给全部在线用户
+	UserID *uuid.UUID      `json:"user_id,omitempty"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// visibleTo 事件是否推送给指定用户
+func (e *Event) visibleTo(userID uuid.UUID) bool {
+	return e.UserID == nil || *e.UserID == userID
+}
+
+// JobProgress 后台任务进度
+type JobProgress struct {
+	JobType   JobType   `json:"job_type" example:"bill_import"`
+	JobID     uuid.UUID `json:"job_id"`
+	Status    string    `json:"status" example:"running"`
+	Processed int       `json:"processed" example:"3000"`
+	Total     int       `json:"total" example:"12000"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// NotificationCreated 新站内通知的摘要，完整内容通过通知中心接口获取
+type NotificationCreated struct {
+	ID    uuid.UUID `json:"id"`
+	Event string    `json:"event" example:"contract.expiring"`
+	Title string    `json:"title"`
+	Link  string    `json:"link,omitempty"`
+}
+
+// DashboardInvalidated 统计数据变化的范围
+type DashboardInvalidated struct {
+	Scope string `json:"scope" example:"reports"`
+}
@@ -0,0 +1,55 @@
+package events
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册实时事件路由，路由自行认证：浏览器 EventSource 不能设置请求头，
+// 订阅时也可以通过 ticket 查询参数传递一次性凭证
+func RegisterRoutes(router *gin.RouterGroup) {
+	handler := NewHandler()
+
+	router.POST("/ticket", middleware.JWTAuth(), handler.CreateTicket)
+	router.GET("", ticketAuth(), handler.Stream)
+}
+
+// ticketAuth 带有 ticket 查询参数时使用一次性凭证认证，否则按 Authorization 请求头认证
+func ticketAuth() gin.HandlerFunc {
+	jwtAuth := middleware.JWTAuth()
+
+	return func(c *gin.Context) {
+		id := c.Query("ticket")
+		if id == "" || c.GetHeader("Authorization") != "" {
+			jwtAuth(c)
+			return
+		}
+
+		t, err := redeemTicket(c.Request.Context(), id)
+		if err != nil {
+			if err != errTicketInvalid {
+				logger.GetLogger().Error("读取事件订阅凭证失败:", err)
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    401,
+				"message": i18n.T(c, "auth.ticket_invalid"),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", t.UserID.String())
+		if t.ExpiresAt != nil {
+			c.Set(middleware.TokenExpiresAtKey, *t.ExpiresAt)
+		}
+		if t.Language != "" {
+			c.Set(i18n.ContextKey, t.Language)
+		}
+		c.Next()
+	}
+}
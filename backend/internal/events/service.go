@@ -0,0 +1,264 @@
+package events
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// streamKey 保存最近事件的 Redis Stream，断线重连时按 Last-Event-ID 补发
+	streamKey = "xcloud:events:stream"
+	// channel 跨实例分发事件的 Redis 发布订阅频道
+	channel = "xcloud:events"
+	// defaultReplaySize 未配置 events.replay_size 时 Stream 保留的事件数
+	defaultReplaySize = 10000
+	// replayBatch 补发时每次从 Stream 读取的条目数
+	replayBatch = 500
+	// bufferSize 每个连接待发送的事件数上限，超过时断开连接，由客户端重连后补发
+	bufferSize = 64
+)
+
+// idPattern Redis Stream 条目ID格式（毫秒时间戳-序号）
+var idPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// subscriber 本实例上的一个 SSE 连接
+type subscriber struct {
+	userID uuid.UUID
+	ch     chan *Event
+	// dropped 缓冲区已满时关闭，连接随后断开
+	dropped chan struct{}
+	once    sync.Once
+}
+
+func (s *subscriber) drop() {
+	s.once.Do(func() { close(s.dropped) })
+}
+
+// hub 本实例的 SSE 连接，按接收人分发事件
+type hub struct {
+	mu   sync.RWMutex
+	subs map[*subscriber]struct{}
+}
+
+var local = &hub{subs: make(map[*subscriber]struct{})}
+
+func (h *hub) subscribe(userID uuid.UUID) *subscriber {
+	s := &subscriber{
+		userID:  userID,
+		ch:      make(chan *Event, bufferSize),
+		dropped: make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.subs[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (h *hub) unsubscribe(s *subscriber) {
+	h.mu.Lock()
+	delete(h.subs, s)
+	h.mu.Unlock()
+}
+
+// deliver 将事件分发给本实例上相应用户的连接，不阻塞发布方
+func (h *hub) deliver(e *Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subs {
+		if !e.visibleTo(s.userID) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			s.drop()
+		}
+	}
+}
+
+// Publish 向指定用户推送事件，失败只记录日志，不影响业务流程
+func Publish(userID uuid.UUID, eventType string, data interface{}) {
+	publish(&userID, eventType, data)
+}
+
+// Broadcast 向全部在线用户推送事件
+func Broadcast(eventType string, data interface{}) {
+	publish(nil, eventType, data)
+}
+
+// PublishJob 向任务发起人推送后台任务进度，userID 为空（如命令行发起的任务）时不推送
+func PublishJob(userID *uuid.UUID, progress JobProgress) {
+	if userID == nil {
+		return
+	}
+	Publish(*userID, TypeJobProgress, progress)
+}
+
+// localSeq 未连接Redis时生成事件ID的序号
+var localSeq atomic.Int64
+
+// publish 将事件写入 Stream 以便补发，再通过发布订阅分发到各实例；
+// 未连接Redis时（如命令行）只分发给本实例的连接
+func publish(userID *uuid.UUID, eventType string, data interface{}) {
+	log := logger.GetLogger()
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Error("序列化实时事件失败:", eventType, " ", err)
+		return
+	}
+	e := &Event{UserID: userID, Type: eventType, Data: raw}
+
+	rdb := database.GetRedis()
+	if rdb == nil {
+		e.ID = fmt.Sprintf("%d-%d", time.Now().UnixMilli(), localSeq.Add(1))
+		local.deliver(e)
+		return
+	}
+
+	ctx := context.Background()
+	entry, _ := json.Marshal(e)
+	id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: streamKey,
+		MaxLen: replaySize(),
+		Approx: true,
+		Values: map[string]interface{}{"event": entry},
+	}).Result()
+	if err != nil {
+		// 无法补发，但仍尽量实时推送
+		log.Error("保存实时事件失败:", eventType, " ", err)
+	}
+	e.ID = id
+	msg, _ := json.Marshal(e)
+	if err := rdb.Publish(ctx, channel, msg).Err(); err != nil {
+		log.Error("发布实时事件失败:", eventType, " ", err)
+	}
+}
+
+// Listen 订阅其他实例（和本实例）发布的实时事件，分发给本实例的连接，ctx 取消时退出
+func Listen(ctx context.Context, rdb *redis.Client) {
+	if rdb == nil {
+		return
+	}
+	log := logger.GetLogger()
+	pubsub := rdb.Subscribe(ctx, channel)
+
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var e Event
+				if err := json.Unmarshal([]byte(msg.Payload), &e); err != nil {
+					log.Warn("解析实时事件失败:", err)
+					continue
+				}
+				local.deliver(&e)
+			}
+		}
+	}()
+}
+
+// replay 返回 lastID 之后推送给该用户的事件。lastID 已不在 Stream 中（已被裁剪或Redis数据丢失）时
+// 无法确定错过了哪些事件，只返回一个 resync 事件，其ID为当前最新事件，客户端重新加载数据后从这里继续
+func replay(ctx context.Context, userID uuid.UUID, lastID string) ([]*Event, error) {
+	rdb := database.GetRedis()
+	if rdb == nil || !idPattern.MatchString(lastID) {
+		return nil, nil
+	}
+
+	found, err := rdb.XRange(ctx, streamKey, lastID, lastID).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		resync := &Event{Type: TypeResync, Data: json.RawMessage("{}")}
+		latest, err := rdb.XRevRangeN(ctx, streamKey, "+", "-", 1).Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(latest) > 0 {
+			resync.ID = latest[0].ID
+		}
+		return []*Event{resync}, nil
+	}
+
+	var list []*Event
+	start := lastID
+	for {
+		entries, err := rdb.XRangeN(ctx, streamKey, start, "+", replayBatch).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.ID == start {
+				continue
+			}
+			raw, _ := entry.Values["event"].(string)
+			var e Event
+			if err := json.Unmarshal([]byte(raw), &e); err != nil {
+				continue
+			}
+			if e.visibleTo(userID) {
+				e.ID = entry.ID
+				list = append(list, &e)
+			}
+		}
+		if len(entries) < replayBatch {
+			return list, nil
+		}
+		start = entries[len(entries)-1].ID
+	}
+}
+
+// compareID 比较两个事件ID的先后，格式不正确时按字符串比较
+func compareID(a, b string) int {
+	am, as, aok := splitID(a)
+	bm, bs, bok := splitID(b)
+	if !aok || !bok {
+		return strings.Compare(a, b)
+	}
+	if am != bm {
+		return cmp.Compare(am, bm)
+	}
+	return cmp.Compare(as, bs)
+}
+
+func splitID(id string) (uint64, uint64, bool) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	m, err1 := strconv.ParseUint(ms, 10, 64)
+	s, err2 := strconv.ParseUint(seq, 10, 64)
+	return m, s, err1 == nil && err2 == nil
+}
+
+// replaySize Stream 保留的事件数
+func replaySize() int64 {
+	n := viper.GetInt64("events.replay_size")
+	if n <= 0 {
+		n = defaultReplaySize
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+
+	"xcloud-backend/pkg/database"
+)
+
+const (
+	// ticketKeyPrefix 事件订阅凭证在 Redis 中的键前缀
+	ticketKeyPrefix = "xcloud:events:ticket:"
+	// ticketTTL 凭证有效期，凭证只用于建立连接，取得后应立即使用
+	ticketTTL = 30 * time.Second
+)
+
+// errTicketInvalid 凭证不存在、已过期或已使用
+var errTicketInvalid = errors.New("events: ticket invalid")
+
+// ticket 事件订阅凭证：浏览器 EventSource 不能设置请求头，用一次性短期凭证代替 JWT 放在查询参数中，
+// 避免长期有效的令牌出现在访问日志、代理日志和浏览器历史中
+type ticket struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Language string    `json:"lang,omitempty"`
+	// ExpiresAt 签发凭证时所用访问令牌的过期时间，连接到期后断开
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// issueTicket 签发事件订阅凭证，返回凭证字符串
+func issueTicket(ctx context.Context, t ticket) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(b)
+	raw, _ := json.Marshal(t)
+	if err := database.GetRedis().Set(ctx, ticketKeyPrefix+id, raw, ticketTTL).Err(); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// redeemTicket 使用凭证，凭证取出后即删除，不能重复使用
+func redeemTicket(ctx context.Context, id string) (*ticket, error) {
+	raw, err := database.GetRedis().GetDel(ctx, ticketKeyPrefix+id).Bytes()
+	if err == redis.Nil {
+		return nil, errTicketInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var t ticket
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, errTicketInvalid
+	}
+	return &t, nil
+}
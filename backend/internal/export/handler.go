@@ -0,0 +1,307 @@
+package export
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	exportSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		exportSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// Export 导出数据
+// @Summary 导出数据
+// @Description 按列表接口的查询参数导出客户（customers）、合同（contracts）、账单明细（billing_lines）或返佣记录（commission_records）。
+// @Description 记录数不超过 report.export_stream_limit 时直接下载文件；超过或 async=true 时创建后台任务并返回202，
+// @Description 任务完成后通过 download_url 下载。记录数超过 report.max_export_records 时拒绝导出。
+// @Description 条件：customers 支持 search、status、business_type、parent_id；contracts 支持 customer_id、status、currency、search；
+// @Description billing_lines 支持 customer_id、provider、period、service_type、account_id；
+// @Description commission_records 支持 customer_id、contract_id、period、provider、status、record_type。
+// @Description 同时支持与对应列表接口相同的 filter[字段][运算符] 参数，未知字段或运算符返回400
+// @Tags 数据导出
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json
+// @Security BearerAuth
+// @Param resource path string true "导出对象（customers, contracts, billing_lines, commission_records）"
+// @Param format query string false "文件格式（csv, xlsx）" default(csv)
+// @Param async query bool false "是否强制后台导出" default(false)
+// @Success 200 {file} file "导出文件"
+// @Success 202 {object} JobResponse "已创建后台导出任务"
+// @Failure 400 {object} ErrorResponse "请求参数错误或记录数超过上限"
+// @Router /exports/{resource} [get]
+func (h *Handler) Export(c *gin.Context) {
+	filter := Filter{}
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			filter[key] = values[0]
+		}
+	}
+	e, err := h.exportSvc.Prepare(Resource(c.Param("resource")), Format(c.Query("format")), filter)
+	if err != nil {
+		h.respondError(c, "准备导出失败:", err)
+		return
+	}
+
+	async, _ := strconv.ParseBool(c.Query("async"))
+	if !async && h.exportSvc.Streamable(e) {
+		c.Header("Content-Type", e.Format.ContentType())
+		c.Header("Content-Disposition", "attachment; filename="+strconv.Quote(e.FileName))
+		c.Header("X-Total-Count", strconv.FormatInt(e.Total, 10))
+		c.Status(http.StatusOK)
+		// 响应头已发送，写出失败时只能中断连接并记录日志
+		if err := h.exportSvc.Write(e, c.Writer, nil); err != nil {
+			h.logger.Error("导出数据失败:", e.Resource, " ", err)
+			c.Abort()
+		}
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	job, err := h.exportSvc.StartJob(e, operator)
+	if err != nil {
+		h.respondError(c, "创建导出任务失败:", err)
+		return
+	}
+
+	h.logger.Info("导出任务已创建:", job.ID, " ", job.Resource, " 记录数:", job.TotalCount)
+	c.JSON(http.StatusAccepted, JobResponse{
+		Code:    202,
+		Message: i18n.T(c, "export.job_started"),
+		Data:    *job,
+	})
+}
+
+// ListJobs 获取导出任务列表
+// @Summary 获取导出任务列表
+// @Description 分页获取当前用户的后台导出任务（管理员可查看全部用户的任务），按开始时间倒序
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} JobListResponse "获取成功"
+// @Router /exports/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	owner, ok := jobOwner(c)
+	if !ok {
+		return
+	}
+
+	page, pageSize := pagination(c)
+	jobs, total, err := h.exportSvc.ListJobs(owner, page, pageSize)
+	if err != nil {
+		h.respondError(c, "获取导出任务列表失败:", err)
+		return
+	}
+	for i := range jobs {
+		withDownloadURL(&jobs[i])
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: JobListData{
+			Jobs:     jobs,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetJob 获取导出任务
+// @Summary 获取导出任务
+// @Description 获取后台导出任务的进度和结果，成功后返回下载地址；只能查看本人创建的任务（管理员除外）
+// @Tags 数据导出
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} JobResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /exports/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	owner, ok := jobOwner(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.exportSvc.GetJob(id, owner)
+	if err != nil {
+		h.respondError(c, "获取导出任务失败:", err)
+		return
+	}
+	withDownloadURL(job)
+
+	c.JSON(http.StatusOK, JobResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *job,
+	})
+}
+
+// DownloadJob 下载导出文件
+// @Summary 下载导出文件
+// @Description 下载已完成的后台导出任务生成的文件；只能下载本人创建的任务（管理员除外）
+// @Tags 数据导出
+// @Produce text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {file} file "导出文件"
+// @Failure 400 {object} ErrorResponse "任务未完成"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /exports/jobs/{id}/download [get]
+func (h *Handler) DownloadJob(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	owner, ok := jobOwner(c)
+	if !ok {
+		return
+	}
+
+	job, file, err := h.exportSvc.Open(c.Request.Context(), id, owner)
+	if err != nil {
+		h.respondError(c, "下载导出文件失败:", err)
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, job.FileSize, job.Format.ContentType(), file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}),
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "export.invalid_resource", "export.job_not_found", "export.file_missing":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// withDownloadURL 为已完成的任务设置下载地址
+func withDownloadURL(job *Job) {
+	if job.Status == JobSuccess {
+		job.DownloadURL = downloadURL(job.ID)
+	}
+}
+
+// jobOwner 导出任务的可见范围：管理员可访问全部任务，其他用户只能访问本人创建的任务
+func jobOwner(c *gin.Context) (*uuid.UUID, bool) {
+	if role, _ := c.Get("user_role"); role == "admin" {
+		return nil, true
+	}
+	id, ok := currentUser(c)
+	if !ok {
+		return nil, false
+	}
+	return &id, true
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type JobResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Job    `json:"data"`
+}
+
+type JobListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    JobListData `json:"data"`
+}
+
+type JobListData struct {
+	Jobs     []Job `json:"jobs"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Resource 导出对象
+type Resource string
+
+const (
+	ResourceCustomers         Resource = "customers"
+	ResourceContracts         Resource = "contracts"
+	ResourceBillingLines      Resource = "billing_lines"
+	ResourceCommissionRecords Resource = "commission_records"
+)
+
+// Format 导出文件格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+// IsValid 检查格式是否有效
+func (f Format) IsValid() bool {
+	return f == FormatCSV || f == FormatXLSX
+}
+
+// ContentType 文件的 MIME 类型
+func (f Format) ContentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// JobStatus 导出任务状态
+type JobStatus string
+
+const (
+	JobRunning JobStatus = "running"
+	JobSuccess JobStatus = "success"
+	JobFailed  JobStatus = "failed"
+)
+
+// Filter 导出条件，键为列表接口的查询参数（快捷条件或 filter[字段][运算符]）
+type Filter map[string]string
+
+// Job 后台导出任务
+type Job struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Resource       Resource        `json:"resource" gorm:"type:varchar(50);not null"`
+	Format         Format          `json:"format" gorm:"type:varchar(10);not null"`
+	Filters        json.RawMessage `json:"filters,omitempty" gorm:"type:jsonb"`
+	Status         JobStatus       `json:"status" gorm:"type:varchar(20);not null;default:'running'"`
+	TotalCount     int             `json:"total_count" gorm:"not null;default:0"`
+	ProcessedCount int             `json:"processed_count" gorm:"not null;default:0"`
+	FileName       string          `json:"file_name,omitempty" gorm:"type:varchar(200)"`
+	FilePath       string          `json:"-" gorm:"type:varchar(500)"`
+	FileSize       int64           `json:"file_size" gorm:"not null;default:0"`
+	ErrorMessage   string          `json:"error_message,omitempty" gorm:"type:text"`
+	StartedAt      time.Time       `json:"started_at"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+	CreatedBy      uuid.UUID       `json:"created_by" gorm:"type:uuid;not null"`
+
+	// DownloadURL 任务成功后的下载地址
+	DownloadURL string `json:"download_url,omitempty" gorm:"-"`
+}
+
+// TableName 设置表名
+func (Job) TableName() string {
+	return "export_jobs"
+}
@@ -0,0 +1,319 @@
+package export
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/custimport"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/pkg/fulltext"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/query"
+)
+
+// source 导出对象的数据来源
+type source interface {
+	// filterKeys 支持的快捷导出条件（列表接口的查询参数），filter[...] 参数另由 query 校验
+	filterKeys() []string
+	// columns 表头
+	columns() []string
+	// query 按导出条件构造查询，用于计数和导出
+	query(db *gorm.DB, filter Filter) (*gorm.DB, error)
+	// each 逐行读取查询结果，内存占用与记录数无关
+	each(db, query *gorm.DB, fn func([]interface{}) error) error
+}
+
+// filterFunc 将单个查询参数应用到查询
+type filterFunc func(q *gorm.DB, value string) (*gorm.DB, error)
+
+// table 以 T 为行结构的导出对象
+type table[T any] struct {
+	model  interface{}
+	header []string
+	// resource 列表接口的查询定义，filter[字段][运算符] 参数与列表接口一致
+	resource query.Resource
+	filters  map[string]filterFunc
+	// selects、joins 导出时附加的字段和关联（如客户编码），计数时不使用
+	selects string
+	joins   []string
+	order   string
+	values  func(*T) []interface{}
+}
+
+func (t table[T]) filterKeys() []string {
+	keys := make([]string, 0, len(t.filters))
+	for k := range t.filters {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (t table[T]) columns() []string {
+	return t.header
+}
+
+func (t table[T]) query(db *gorm.DB, filter Filter) (*gorm.DB, error) {
+	q := db.Model(t.model)
+	values := url.Values{}
+	for key, value := range filter {
+		if isFilterParam(key) {
+			values.Set(key, value)
+			continue
+		}
+		apply, ok := t.filters[key]
+		if !ok || value == "" {
+			continue
+		}
+		var err error
+		if q, err = apply(q, value); err != nil {
+			return nil, err
+		}
+	}
+	// 未知字段、不支持的运算符和无效取值返回错误，与列表接口相同
+	params, err := t.resource.Parse(values)
+	if err != nil {
+		return nil, err
+	}
+	return params.Where(q), nil
+}
+
+// isFilterParam 是否为列表接口的通用过滤参数 filter[字段][运算符]
+func isFilterParam(key string) bool {
+	return strings.HasPrefix(key, "filter[")
+}
+
+func (t table[T]) each(db, query *gorm.DB, fn func([]interface{}) error) error {
+	q := query
+	if t.selects != "" {
+		q = q.Select(t.selects)
+	}
+	for _, join := range t.joins {
+		q = q.Joins(join)
+	}
+	rows, err := q.Order(t.order).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item T
+		if err := db.ScanRows(rows, &item); err != nil {
+			return err
+		}
+		if err := fn(t.values(&item)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// sources 已注册的导出对象
+var sources = map[Resource]source{
+	ResourceCustomers:         customers,
+	ResourceContracts:         contracts,
+	ResourceBillingLines:      billingLines,
+	ResourceCommissionRecords: commissionRecords,
+}
+
+// 导出条件
+
+func equals(column string) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		return q.Where(column+" = ?", value), nil
+	}
+}
+
+func uuidEquals(column, param string) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, i18n.NewError("export.invalid_filter", param)
+		}
+		return q.Where(column+" = ?", id), nil
+	}
+}
+
+func oneOf(column, param string, allowed ...string) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		for _, v := range allowed {
+			if v == value {
+				return q.Where(column+" = ?", value), nil
+			}
+		}
+		return nil, i18n.NewError("export.invalid_filter", param)
+	}
+}
+
+func period(column, param string) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		if _, err := billing.ParsePeriod(value); err != nil {
+			return nil, i18n.NewError("export.invalid_filter", param)
+		}
+		return q.Where(column+" = ?", value), nil
+	}
+}
+
+func provider(column, param string) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		if !billing.Provider(value).IsValid() {
+			return nil, i18n.NewError("export.invalid_filter", param)
+		}
+		return q.Where(column+" = ?", value), nil
+	}
+}
+
+// fulltextSearch 按关键词搜索，匹配规则与客户、合同列表接口相同
+func fulltextSearch(fields fulltext.Fields) filterFunc {
+	return func(q *gorm.DB, value string) (*gorm.DB, error) {
+		return fields.Scope(value)(q), nil
+	}
+}
+
+// 导出对象
+
+// customerRow 客户及上级客户编码
+type customerRow struct {
+	customer.Customer
+	ParentCode string
+}
+
+// customers 前几列与客户导入模板一致，导出文件可直接导入
+var customers = table[customerRow]{
+	model:    &customer.Customer{},
+	header:   append(append([]string{}, custimport.Columns...), custimport.ReadOnlyColumns...),
+	resource: customer.ListQuery,
+	filters: map[string]filterFunc{
+		"search":        fulltextSearch(customer.SearchFields),
+		"status":        oneOf("customers.status", "status", string(customer.StatusActive), string(customer.StatusSuspended), string(customer.StatusChurned)),
+		"business_type": equals("customers.business_type"),
+		"parent_id":     uuidEquals("customers.parent_id", "parent_id"),
+	},
+	selects: "customers.*, parent.customer_code AS parent_code",
+	joins:   []string{"LEFT JOIN customers parent ON parent.id = customers.parent_id"},
+	order:   "customers.customer_code",
+	values: func(c *customerRow) []interface{} {
+		return []interface{}{
+			c.CustomerCode, c.CompanyName, c.ContactName, c.ContactPhone, c.ContactEmail, c.Address,
+			c.Level, c.BusinessType, c.CreditLimit, c.ParentCode, string(c.Status), c.CreatedAt,
+		}
+	},
+}
+
+// contractRow 合同及客户编码
+type contractRow struct {
+	contract.Contract
+	CustomerCode string
+}
+
+var contracts = table[contractRow]{
+	model: &contract.Contract{},
+	header: []string{
+		"contract_no", "customer_code", "title", "status", "start_date", "end_date", "settlement_cycle",
+		"currency", "contract_amount", "discount_rate", "payment_terms", "created_at",
+	},
+	resource: contract.ListQuery,
+	filters: map[string]filterFunc{
+		"customer_id": uuidEquals("contracts.customer_id", "customer_id"),
+		"status": oneOf("contracts.status", "status",
+			string(contract.StatusDraft), string(contract.StatusPending), string(contract.StatusActive),
+			string(contract.StatusExpired), string(contract.StatusTerminated)),
+		"currency": equals("contracts.currency"),
+		"search":   fulltextSearch(contract.SearchFields),
+	},
+	selects: "contracts.*, customers.customer_code",
+	joins:   []string{"LEFT JOIN customers ON customers.id = contracts.customer_id"},
+	order:   "contracts.contract_no",
+	values: func(c *contractRow) []interface{} {
+		return []interface{}{
+			c.ContractNo, c.CustomerCode, c.Title, string(c.Status), dateOf(c.StartDate), dateOf(c.EndDate), c.SettlementCycle,
+			c.Currency, c.ContractAmount, c.DiscountRate, c.PaymentTerms, c.CreatedAt,
+		}
+	},
+}
+
+// billingRow 账单明细及客户编码
+type billingRow struct {
+	billing.BillingData
+	CustomerCode string
+}
+
+var billingLines = table[billingRow]{
+	model: &billing.BillingData{},
+	header: []string{
+		"customer_code", "provider", "account_id", "billing_period", "billing_date", "service_type",
+		"resource_id", "resource_name", "region", "usage_amount", "usage_unit", "unit_price",
+		"original_cost", "discounted_cost", "currency",
+	},
+	resource: billing.LineQuery,
+	filters: map[string]filterFunc{
+		"customer_id":  uuidEquals("billing_data_template.customer_id", "customer_id"),
+		"provider":     provider("billing_data_template.provider", "provider"),
+		"period":       period("billing_data_template.billing_period", "period"),
+		"service_type": equals("billing_data_template.service_type"),
+		"account_id":   equals("billing_data_template.account_id"),
+	},
+	selects: "billing_data_template.id, billing_data_template.customer_id, billing_data_template.provider, " +
+		"billing_data_template.account_id, billing_data_template.billing_period, billing_data_template.billing_date, " +
+		"billing_data_template.service_type, billing_data_template.resource_id, billing_data_template.resource_name, " +
+		"billing_data_template.region, billing_data_template.usage_amount, billing_data_template.usage_unit, " +
+		"billing_data_template.unit_price, billing_data_template.original_cost, billing_data_template.discounted_cost, " +
+		"billing_data_template.currency, customers.customer_code",
+	joins: []string{"LEFT JOIN customers ON customers.id = billing_data_template.customer_id"},
+	order: "billing_data_template.billing_date, billing_data_template.id",
+	values: func(b *billingRow) []interface{} {
+		return []interface{}{
+			b.CustomerCode, string(b.Provider), b.AccountID, b.BillingPeriod, dateOf(b.BillingDate), b.ServiceType,
+			b.ResourceID, b.ResourceName, b.Region, b.UsageAmount, b.UsageUnit, b.UnitPrice,
+			b.OriginalCost, b.DiscountedCost, b.Currency,
+		}
+	},
+}
+
+// recordRow 返佣记录及客户编码、合同编号
+type recordRow struct {
+	commission.Record
+	CustomerCode string
+	ContractNo   string
+}
+
+var commissionRecords = table[recordRow]{
+	model: &commission.Record{},
+	header: []string{
+		"id", "billing_period", "customer_code", "contract_no", "provider", "service_type", "record_type",
+		"currency", "base_amount", "commission_rate", "commission_amount", "reporting_currency", "exchange_rate",
+		"base_amount_reporting", "commission_amount_reporting", "status", "settlement_id", "paid_at",
+		"payment_reference", "adjusts_record_id",
+	},
+	resource: commission.RecordQuery,
+	filters: map[string]filterFunc{
+		"customer_id": uuidEquals("commission_records.customer_id", "customer_id"),
+		"contract_id": uuidEquals("commission_records.contract_id", "contract_id"),
+		"period":      period("commission_records.billing_period", "period"),
+		"provider":    provider("commission_records.provider", "provider"),
+		"status": oneOf("commission_records.status", "status",
+			string(commission.StatusPending), string(commission.StatusCalculated), string(commission.StatusPaid)),
+		"record_type": oneOf("commission_records.record_type", "record_type",
+			string(commission.RecordCommission), string(commission.RecordAdjustment)),
+	},
+	selects: "commission_records.*, customers.customer_code, contracts.contract_no",
+	joins: []string{
+		"LEFT JOIN customers ON customers.id = commission_records.customer_id",
+		"LEFT JOIN contracts ON contracts.id = commission_records.contract_id",
+	},
+	order: "commission_records.billing_period, commission_records.created_at, commission_records.id",
+	values: func(r *recordRow) []interface{} {
+		return []interface{}{
+			r.ID, r.BillingPeriod, r.CustomerCode, r.ContractNo, string(r.Provider), r.ServiceType, string(r.RecordType),
+			r.Currency, r.BaseAmount, r.CommissionRate, r.CommissionAmount, r.ReportingCurrency, r.ExchangeRate,
+			r.BaseAmountReporting, r.CommissionAmountReporting, string(r.Status), r.SettlementID, r.PaidAt,
+			r.PaymentReference, r.AdjustsRecordID,
+		}
+	},
+}
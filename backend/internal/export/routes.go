@@ -0,0 +1,16 @@
+package export
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterRoutes 注册数据导出相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/jobs", handler.ListJobs)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.GET("/jobs/:id/download", handler.DownloadJob)
+	router.GET("/:resource", handler.Export)
+}
@@ -0,0 +1,345 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/events"
+	"xcloud-backend/internal/notify"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/storage"
+)
+
+const (
+	// storageDir 导出文件在文件存储中的目录
+	storageDir = "exports"
+	// progressInterval 后台任务每写出多少行更新一次进度
+	progressInterval = 1000
+
+	defaultMaxRecords  = 100000
+	defaultStreamLimit = 10000
+)
+
+// Export 校验通过、待写出的导出
+type Export struct {
+	Resource Resource
+	Format   Format
+	Filter   Filter
+	Total    int64
+	FileName string
+
+	src   source
+	query *gorm.DB
+}
+
+// Service 数据导出服务
+type Service struct {
+	db     *gorm.DB
+	config *sysconfig.Service
+}
+
+// NewService 创建数据导出服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:     db,
+		config: sysconfig.NewService(db, database.GetRedis()),
+	}
+}
+
+// Prepare 校验导出对象、格式和条件并统计记录数
+// 记录数超过 report.max_export_records 时拒绝导出
+func (s *Service) Prepare(resource Resource, format Format, filter Filter) (*Export, error) {
+	src, ok := sources[resource]
+	if !ok {
+		return nil, i18n.NewError("export.invalid_resource", string(resource))
+	}
+	if format == "" {
+		format = FormatCSV
+	}
+	if !format.IsValid() {
+		return nil, i18n.NewError("export.invalid_format")
+	}
+
+	// 只保留该导出对象支持的快捷条件和 filter[...] 条件
+	cleaned := Filter{}
+	for _, key := range src.filterKeys() {
+		if v := filter[key]; v != "" {
+			cleaned[key] = v
+		}
+	}
+	for key, v := range filter {
+		if isFilterParam(key) {
+			cleaned[key] = v
+		}
+	}
+	query, err := src.query(s.db, cleaned)
+	if err != nil {
+		return nil, err
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	max := s.config.GetInt(sysconfig.KeyMaxExportRecords, defaultMaxRecords)
+	if total > int64(max) {
+		return nil, i18n.NewError("export.too_many_records", total, max)
+	}
+
+	return &Export{
+		Resource: resource,
+		Format:   format,
+		Filter:   cleaned,
+		Total:    total,
+		FileName: fmt.Sprintf("%s_%s.%s", resource, time.Now().Format("20060102150405"), format),
+		src:      src,
+		query:    query,
+	}, nil
+}
+
+// Streamable 记录数不超过 report.export_stream_limit 时可直接下载
+func (s *Service) Streamable(e *Export) bool {
+	return e.Total <= int64(s.config.GetInt(sysconfig.KeyExportStreamLimit, defaultStreamLimit))
+}
+
+// Write 将导出结果逐行写出到 w，progress 非空时每写出 progressInterval 行回调一次
+func (s *Service) Write(e *Export, w io.Writer, progress func(rows int)) error {
+	out, err := newRowWriter(e.Format, w)
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(e.src.columns()))
+	for i, col := range e.src.columns() {
+		header[i] = col
+	}
+	if err := out.write(header); err != nil {
+		return err
+	}
+
+	rows := 0
+	err = e.src.each(s.db, e.query, func(values []interface{}) error {
+		if err := out.write(values); err != nil {
+			return err
+		}
+		rows++
+		if progress != nil && rows%progressInterval == 0 {
+			progress(rows)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// StartJob 创建后台导出任务，文件生成后可通过任务下载
+func (s *Service) StartJob(e *Export, operator uuid.UUID) (*Job, error) {
+	filters, _ := json.Marshal(e.Filter)
+	job := &Job{
+		Resource:   e.Resource,
+		Format:     e.Format,
+		Filters:    filters,
+		Status:     JobRunning,
+		TotalCount: int(e.Total),
+		FileName:   e.FileName,
+		StartedAt:  time.Now(),
+		CreatedBy:  operator,
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.run(job.ID, operator, e)
+	return job, nil
+}
+
+// run 执行后台导出任务，写出过程中向发起人推送进度
+func (s *Service) run(id, operator uuid.UUID, e *Export) {
+	log := logger.GetLogger()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("导出任务异常:", id, r)
+			s.finish(id, "", 0, fmt.Errorf("导出任务异常终止"))
+		}
+	}()
+
+	key := path.Join(storageDir, id.String()+"."+string(e.Format))
+	progress := events.JobProgress{JobType: events.JobExport, JobID: id, Status: string(JobRunning), Total: int(e.Total)}
+	size, err := s.writeFile(e, key, func(rows int) {
+		s.db.Model(&Job{}).Where("id = ?", id).Update("processed_count", rows)
+		progress.Processed = rows
+		events.PublishJob(&operator, progress)
+	})
+	if err != nil {
+		log.Error("导出任务失败:", id, " ", err)
+		s.finish(id, "", 0, err)
+		return
+	}
+	s.finish(id, key, size, nil)
+	log.Info("导出任务完成:", id, " 对象:", e.Resource, " 记录数:", e.Total)
+}
+
+// writeFile 写出导出文件并保存到文件存储。先写本地临时文件，完整写出后再保存，
+// 避免下载到写了一半的文件；使用对象存储时其他实例也能提供下载
+func (s *Service) writeFile(e *Export, key string, progress func(rows int)) (int64, error) {
+	f, err := os.CreateTemp("", "xcloud-export-*")
+	if err != nil {
+		return 0, fmt.Errorf("创建导出临时文件失败: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := s.Write(e, f, progress); err != nil {
+		return 0, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	store, err := storage.Default()
+	if err != nil {
+		return 0, err
+	}
+	if err := store.Put(context.Background(), key, f, size, e.Format.ContentType()); err != nil {
+		return 0, fmt.Errorf("保存导出文件失败: %w", err)
+	}
+	return size, nil
+}
+
+// finish 更新任务的结束状态，并通知发起人文件已可下载或导出失败
+func (s *Service) finish(id uuid.UUID, key string, size int64, cause error) {
+	updates := map[string]interface{}{
+		"status":      JobSuccess,
+		"finished_at": time.Now(),
+	}
+	if cause != nil {
+		updates["status"] = JobFailed
+		updates["error_message"] = cause.Error()
+	} else {
+		updates["processed_count"] = gorm.Expr("total_count")
+		updates["file_path"] = key
+		updates["file_size"] = size
+	}
+	log := logger.GetLogger()
+	if err := s.db.Model(&Job{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		log.Error("更新导出任务状态失败:", id, err)
+		return
+	}
+
+	var job Job
+	if err := s.db.First(&job, "id = ?", id).Error; err != nil {
+		log.Error("获取导出任务失败:", id, err)
+		return
+	}
+	events.PublishJob(&job.CreatedBy, events.JobProgress{
+		JobType:   events.JobExport,
+		JobID:     job.ID,
+		Status:    string(job.Status),
+		Processed: job.ProcessedCount,
+		Total:     job.TotalCount,
+		Error:     job.ErrorMessage,
+	})
+	s.notifyFinished(&job)
+}
+
+// notifyFinished 通知发起人导出任务已完成（附下载地址）或失败
+func (s *Service) notifyFinished(job *Job) {
+	recipients, err := notify.Recipients(s.db, []uuid.UUID{job.CreatedBy}, nil)
+	if err != nil {
+		logger.GetLogger().Error("查询导出通知接收人失败:", job.ID, " ", err)
+		return
+	}
+	msg := notify.Message{
+		Event:      "export.ready",
+		Subject:    notify.Text{Code: "export.notify.ready_subject", Args: []interface{}{job.FileName}},
+		Body:       notify.Text{Code: "export.notify.ready_body", Args: []interface{}{job.FileName, job.TotalCount, downloadURL(job.ID)}},
+		Link:       downloadURL(job.ID),
+		Recipients: recipients,
+	}
+	if job.Status == JobFailed {
+		msg = notify.Message{
+			Event:      "export.failed",
+			Subject:    notify.Text{Code: "export.notify.failed_subject", Args: []interface{}{job.FileName}},
+			Body:       notify.Text{Code: "export.notify.failed_body", Args: []interface{}{job.FileName, job.ErrorMessage}},
+			Recipients: recipients,
+		}
+	}
+	notify.Publish(context.Background(), msg)
+}
+
+// downloadURL 导出任务文件的下载地址
+func downloadURL(id uuid.UUID) string {
+	return "/api/v1/exports/jobs/" + id.String() + "/download"
+}
+
+// ListJobs 分页获取导出任务，userID 为空时返回全部用户的任务
+func (s *Service) ListJobs(userID *uuid.UUID, page, pageSize int) ([]Job, int64, error) {
+	query := s.db.Model(&Job{})
+	if userID != nil {
+		query = query.Where("created_by = ?", *userID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var jobs []Job
+	err := query.Order("started_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// GetJob 获取导出任务，userID 非空时只能获取本人创建的任务
+func (s *Service) GetJob(id uuid.UUID, userID *uuid.UUID) (*Job, error) {
+	query := s.db.Where("id = ?", id)
+	if userID != nil {
+		query = query.Where("created_by = ?", *userID)
+	}
+	var job Job
+	if err := query.First(&job).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("export.job_not_found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Open 打开已完成导出任务的文件，调用方负责关闭
+func (s *Service) Open(ctx context.Context, id uuid.UUID, userID *uuid.UUID) (*Job, io.ReadCloser, error) {
+	job, err := s.GetJob(id, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != JobSuccess {
+		return nil, nil, i18n.NewError("export.job_not_ready")
+	}
+	store, err := storage.Default()
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := store.Get(ctx, job.FilePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, i18n.NewError("export.file_missing")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, rc, nil
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+)
+
+// date 只保留日期部分的时间
+type date time.Time
+
+// dateOf 将 date 类型字段按日期导出
+func dateOf(t time.Time) date {
+	return date(t)
+}
+
+// rowWriter 逐行写出导出文件
+type rowWriter interface {
+	// write 写出一行，第一行为表头
+	write(values []interface{}) error
+	// close 写出剩余内容，不关闭底层 io.Writer
+	close() error
+}
+
+// newRowWriter 按格式创建写出器
+func newRowWriter(format Format, w io.Writer) (rowWriter, error) {
+	if format == FormatXLSX {
+		return newXLSXWriter(w)
+	}
+	return newCSVWriter(w)
+}
+
+// csvWriter CSV 写出器，带 UTF-8 BOM 以便 Excel 正确识别中文
+type csvWriter struct {
+	buf *bufio.Writer
+	csv *csv.Writer
+	row []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	buf := bufio.NewWriter(w)
+	if _, err := buf.WriteString("\ufeff"); err != nil {
+		return nil, err
+	}
+	return &csvWriter{buf: buf, csv: csv.NewWriter(buf)}, nil
+}
+
+func (w *csvWriter) write(values []interface{}) error {
+	w.row = w.row[:0]
+	for _, v := range values {
+		s := text(v)
+		if _, ok := v.(string); ok {
+			s = escapeFormula(s)
+		}
+		w.row = append(w.row, s)
+	}
+	return w.csv.Write(w.row)
+}
+
+// escapeFormula 以 = + - @ 制表符或回车开头的文本前加单引号，
+// 避免客户名称等用户录入的内容在 Excel 中打开时被当作公式执行。数值列不经过此处理
+func escapeFormula(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + s
+	}
+	return s
+}
+
+func (w *csvWriter) close() error {
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return err
+	}
+	return w.buf.Flush()
+}
+
+// xlsxWriter XLSX 写出器，使用流式写入，行数据不常驻内存
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	rowNum int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	f := excelize.NewFile()
+	stream, err := f.NewStreamWriter("Sheet1")
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: f, stream: stream}, nil
+}
+
+func (w *xlsxWriter) write(values []interface{}) error {
+	w.rowNum++
+	row := make([]interface{}, len(values))
+	for i, v := range values {
+		row[i] = cell(v)
+	}
+	name, err := excelize.CoordinatesToCellName(1, w.rowNum)
+	if err != nil {
+		return err
+	}
+	return w.stream.SetRow(name, row)
+}
+
+func (w *xlsxWriter) close() error {
+	defer w.file.Close()
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
+
+// cell 转换为 XLSX 单元格的值，数值保持数值类型
+func cell(v interface{}) interface{} {
+	switch val := v.(type) {
+	case float64, int:
+		return val
+	case *float64:
+		if val == nil {
+			return nil
+		}
+		return *val
+	default:
+		return text(v)
+	}
+}
+
+// text 转换为文本，空指针为空字符串
+func text(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case *float64:
+		if val == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*val, 'f', -1, 64)
+	case uuid.UUID:
+		return val.String()
+	case *uuid.UUID:
+		if val == nil {
+			return ""
+		}
+		return val.String()
+	case date:
+		return time.Time(val).Format("2006-01-02")
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return ""
+		}
+		return val.Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
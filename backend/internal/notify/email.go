@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// emailChannel 通过 SMTP 发送邮件通知，按接收人语言分别渲染
+type emailChannel struct {
+	host     string
+	addr     string
+	from     string
+	username string
+	password string
+	// implicitTLS 连接即使用 TLS（通常为 465 端口），否则服务器支持时使用 STARTTLS
+	implicitTLS bool
+	timeout     time.Duration
+}
+
+func newEmailChannel() *emailChannel {
+	host := viper.GetString("notify.email.host")
+	timeout := time.Duration(viper.GetInt("notify.email.timeout_seconds")) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &emailChannel{
+		host:        host,
+		addr:        net.JoinHostPort(host, strconv.Itoa(viper.GetInt("notify.email.port"))),
+		from:        viper.GetString("notify.email.from"),
+		username:    viper.GetString("notify.email.username"),
+		password:    viper.GetString("notify.email.password"),
+		implicitTLS: viper.GetBool("notify.email.tls"),
+		timeout:     timeout,
+	}
+}
+
+func (e *emailChannel) Name() string { return ChannelEmail }
+
+func (e *emailChannel) Send(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, r := range msg.Recipients {
+		if r.Email == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		lang := r.Lang()
+		body := e.compose(r.Email, msg.SubjectIn(lang), msg.BodyIn(lang))
+		if err := e.send(ctx, r.Email, body); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Email, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send 连接 SMTP 服务器发送一封邮件，整个会话不超过 notify.email.timeout_seconds 秒
+func (e *emailChannel) send(ctx context.Context, to string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if e.implicitTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: e.host})
+	}
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !e.implicitTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+
+	sender := e.from
+	if addr, err := mail.ParseAddress(e.from); err == nil {
+		sender = addr.Address
+	}
+	if err := client.Mail(sender); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose 生成 UTF-8 纯文本邮件
+func (e *emailChannel) compose(to, subject, body string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(body)
+	return buf.Bytes()
+}
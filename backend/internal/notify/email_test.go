@@ -0,0 +1,243 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"mime"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// smtpSession SMTP 替身收到的一次会话
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpStandIn 进程内的 SMTP 替身，不支持 STARTTLS 和认证，记录每次会话的信封和邮件内容
+type smtpStandIn struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	sessions []smtpSession
+	wg       sync.WaitGroup
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpStandIn{ln: ln}
+	go s.serve()
+	t.Cleanup(func() {
+		ln.Close()
+		s.wg.Wait()
+	})
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var session smtpSession
+	reply("220 stand-in ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			reply("250-stand-in")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			if i := strings.Index(session.from, ">"); i >= 0 {
+				session.from = session.from[:i]
+			}
+			reply("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			session.to = append(session.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case verb == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			session.data = data.String()
+			s.mu.Lock()
+			s.sessions = append(s.sessions, session)
+			s.mu.Unlock()
+			session = smtpSession{}
+			reply("250 OK")
+		case verb == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *smtpStandIn) received() []smtpSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpSession(nil), s.sessions...)
+}
+
+func newTestEmailChannel(srv *smtpStandIn) *emailChannel {
+	return &emailChannel{
+		host:    "127.0.0.1",
+		addr:    srv.ln.Addr().String(),
+		from:    "XCloud 通知 <noreply@xcloud.test>",
+		timeout: 5 * time.Second,
+	}
+}
+
+// headers 解析邮件头
+func headers(t *testing.T, data string) (map[string]string, string) {
+	t.Helper()
+	head, body, ok := strings.Cut(data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("message has no header separator: %q", data)
+	}
+	h := make(map[string]string)
+	for _, line := range strings.Split(head, "\r\n") {
+		k, v, _ := strings.Cut(line, ": ")
+		h[k] = v
+	}
+	return h, body
+}
+
+func TestEmailSend(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	e := newTestEmailChannel(srv)
+	subject := "【续签提醒】合同 HT2024001 还有 30 天到期"
+	body := "合同 HT2024001 将于 2024-07-01 到期，请及时续签。\r\n.以点开头的行"
+
+	if err := e.send(context.Background(), "finance@xcloud.test", e.compose("finance@xcloud.test", subject, body)); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	sessions := srv.received()
+	if len(sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions))
+	}
+	got := sessions[0]
+	if got.from != "noreply@xcloud.test" {
+		t.Errorf("MAIL FROM = %q, want bare address", got.from)
+	}
+	if len(got.to) != 1 || got.to[0] != "finance@xcloud.test" {
+		t.Errorf("RCPT TO = %q", got.to)
+	}
+
+	h, gotBody := headers(t, got.data)
+	if h["From"] != e.from || h["To"] != "finance@xcloud.test" {
+		t.Errorf("From = %q, To = %q", h["From"], h["To"])
+	}
+	if !strings.HasPrefix(strings.ToUpper(h["Subject"]), "=?UTF-8?B?") {
+		t.Errorf("Subject = %q, want B-encoded UTF-8", h["Subject"])
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(h["Subject"])
+	if err != nil || decoded != subject {
+		t.Errorf("decoded Subject = %q, %v, want %q", decoded, err, subject)
+	}
+	if h["Content-Type"] != "text/plain; charset=UTF-8" || h["MIME-Version"] != "1.0" {
+		t.Errorf("Content-Type = %q, MIME-Version = %q", h["Content-Type"], h["MIME-Version"])
+	}
+	if _, err := time.Parse(time.RFC1123Z, h["Date"]); err != nil {
+		t.Errorf("Date = %q: %v", h["Date"], err)
+	}
+	if strings.TrimSuffix(gotBody, "\r\n") != body {
+		t.Errorf("body = %q, want %q", gotBody, body)
+	}
+}
+
+func TestEmailSendPerRecipient(t *testing.T) {
+	srv := newSMTPStandIn(t)
+	e := newTestEmailChannel(srv)
+	msg := Message{
+		Event:   "contract.expiring",
+		Subject: Text{Code: "合同即将到期"},
+		Body:    Text{Code: "body"},
+		Recipients: []Recipient{
+			{UserID: uuid.New(), Name: "张三", Email: "zhang@xcloud.test", Language: "zh-CN"},
+			{UserID: uuid.New(), Name: "no email"},
+			{UserID: uuid.New(), Name: "Alice", Email: "alice@xcloud.test", Language: "en-US"},
+		},
+		templates: map[i18n.Lang]Template{
+			i18n.EnUS: {Subject: "Contract {1} expires soon", Body: "Contract {1}"},
+		},
+	}
+	msg.Subject.Args = []interface{}{"HT2024001"}
+
+	if err := e.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sessions := srv.received()
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2 (recipients without email skipped)", len(sessions))
+	}
+	want := map[string]string{
+		"zhang@xcloud.test": "合同即将到期",
+		"alice@xcloud.test": "Contract HT2024001 expires soon",
+	}
+	for _, s := range sessions {
+		h, _ := headers(t, s.data)
+		subject, _ := new(mime.WordDecoder).DecodeHeader(h["Subject"])
+		if len(s.to) != 1 || subject != want[s.to[0]] {
+			t.Errorf("RCPT TO %q: Subject = %q, want %q", s.to, subject, want[strings.Join(s.to, "")])
+		}
+	}
+}
+
+func TestEmailSendError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	e := &emailChannel{host: "127.0.0.1", addr: addr, from: "noreply@xcloud.test", timeout: time.Second}
+	msg := Message{Subject: Text{Code: "s"}, Body: Text{Code: "b"}, Recipients: []Recipient{{Email: "a@xcloud.test"}}}
+	if err := e.Send(context.Background(), msg); err == nil || !strings.Contains(err.Error(), "a@xcloud.test") {
+		t.Errorf("Send error = %v, want error naming the recipient", err)
+	}
+}
@@ -0,0 +1,398 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
+)
+
+type Handler struct {
+	notifySvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		notifySvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// ListNotifications 获取站内通知
+// @Summary 获取站内通知
+// @Description 获取当前用户的站内通知，默认按创建时间倒序，游标分页。unread=true 时只返回未读通知；
+// @Description 可过滤字段：event、read_at、created_at
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "只返回未读通知" default(false)
+// @Param filter[event] query string false "事件类型"
+// @Param sort query string false "排序字段（created_at），- 表示倒序" default(-created_at)
+// @Param limit query int false "每页数量（1-100）" default(20)
+// @Param cursor query string false "分页游标"
+// @Param count query bool false "是否返回总数" default(false)
+// @Success 200 {object} NotificationListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /notifications [get]
+func (h *Handler) ListNotifications(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	params, err := listQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		h.respondError(c, "解析通知查询参数失败:", err)
+		return
+	}
+
+	list, page, err := h.notifySvc.List(userID, c.Query("unread") == "true", params)
+	if err != nil {
+		h.respondError(c, "获取站内通知失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, NotificationListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: NotificationListData{
+			Notifications: list,
+			Pagination:    *page,
+		},
+	})
+}
+
+// GetUnreadCount 获取未读通知数
+// @Summary 获取未读通知数
+// @Description 获取当前用户的未读站内通知数
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} UnreadCountResponse "获取成功"
+// @Router /notifications/unread-count [get]
+func (h *Handler) GetUnreadCount(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	count, err := h.notifySvc.UnreadCount(userID)
+	if err != nil {
+		h.respondError(c, "获取未读通知数失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, UnreadCountResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    UnreadCount{Unread: count},
+	})
+}
+
+// MarkRead 标记通知为已读
+// @Summary 标记通知为已读
+// @Description 将当前用户的一条站内通知标记为已读
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "通知ID"
+// @Success 200 {object} NotificationResponse "标记成功"
+// @Failure 404 {object} ErrorResponse "通知不存在"
+// @Router /notifications/{id}/read [post]
+func (h *Handler) MarkRead(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	n, err := h.notifySvc.MarkRead(userID, id)
+	if err != nil {
+		h.respondError(c, "标记通知已读失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, NotificationResponse{
+		Code:    200,
+		Message: i18n.T(c, "notification.marked_read"),
+		Data:    *n,
+	})
+}
+
+// MarkAllRead 全部标记为已读
+// @Summary 全部标记为已读
+// @Description 将当前用户的全部未读站内通知标记为已读，返回标记的数量
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} MarkAllReadResponse "标记成功"
+// @Router /notifications/read-all [post]
+func (h *Handler) MarkAllRead(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	count, err := h.notifySvc.MarkAllRead(userID)
+	if err != nil {
+		h.respondError(c, "标记全部通知已读失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, MarkAllReadResponse{
+		Code:    200,
+		Message: i18n.T(c, "notification.all_marked_read", count),
+		Data:    MarkAllReadData{Updated: count},
+	})
+}
+
+// GetPreferences 获取通知偏好
+// @Summary 获取通知偏好
+// @Description 获取当前用户对每个通知事件在邮件（email）和站内（in_app）渠道的接收设置，未设置时为接收
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} PreferenceResponse "获取成功"
+// @Router /notifications/preferences [get]
+func (h *Handler) GetPreferences(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	prefs, err := h.notifySvc.Preferences(userID)
+	if err != nil {
+		h.respondError(c, "获取通知偏好失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, PreferenceResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    prefs,
+	})
+}
+
+// UpdatePreferences 更新通知偏好
+// @Summary 更新通知偏好
+// @Description 设置当前用户对指定事件、渠道是否接收通知，未提交的事件和渠道保持不变
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PreferenceRequest true "通知偏好"
+// @Success 200 {object} PreferenceResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /notifications/preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	userID, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req PreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	prefs, err := h.notifySvc.UpdatePreferences(userID, req)
+	if err != nil {
+		h.respondError(c, "更新通知偏好失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, PreferenceResponse{
+		Code:    200,
+		Message: i18n.T(c, "notification.preferences_updated"),
+		Data:    prefs,
+	})
+}
+
+// ListTemplates 获取通知模板
+// @Summary 获取通知模板
+// @Description 获取全部通知事件在各语言下的默认文本和自定义模板。模板中的 {1}、{2} 等占位符依次对应 subject_params、body_params 中的参数（需要管理员权限）
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} TemplateListResponse "获取成功"
+// @Router /notifications/templates [get]
+func (h *Handler) ListTemplates(c *gin.Context) {
+	views, err := h.notifySvc.Templates()
+	if err != nil {
+		h.respondError(c, "获取通知模板失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, TemplateListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    views,
+	})
+}
+
+// SetTemplate 设置自定义通知模板
+// @Summary 设置自定义通知模板
+// @Description 设置通知事件在某个语言下的标题和正文模板，之后发送的邮件和站内通知使用该模板（需要管理员权限）
+// @Tags 通知中心
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "事件类型" example(contract.expiring)
+// @Param language path string true "语言（zh-CN, en-US）"
+// @Param request body TemplateRequest true "模板"
+// @Success 200 {object} TemplateResponse "设置成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误或占位符超出参数个数"
+// @Router /notifications/templates/{event}/{language} [put]
+func (h *Handler) SetTemplate(c *gin.Context) {
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	t, err := h.notifySvc.SetTemplate(c.Param("event"), c.Param("language"), req, operator)
+	if err != nil {
+		h.respondError(c, "设置通知模板失败:", err)
+		return
+	}
+	h.logger.Info("通知模板已更新:", t.Event, " ", t.Language)
+	c.JSON(http.StatusOK, TemplateResponse{
+		Code:    200,
+		Message: i18n.T(c, "notification.template_updated"),
+		Data:    *t,
+	})
+}
+
+// DeleteTemplate 删除自定义通知模板
+// @Summary 删除自定义通知模板
+// @Description 删除通知事件在某个语言下的自定义模板，恢复使用默认文本（需要管理员权限）
+// @Tags 通知中心
+// @Produce json
+// @Security BearerAuth
+// @Param event path string true "事件类型"
+// @Param language path string true "语言（zh-CN, en-US）"
+// @Success 200 {object} ErrorResponse "删除成功"
+// @Failure 404 {object} ErrorResponse "没有自定义模板"
+// @Router /notifications/templates/{event}/{language} [delete]
+func (h *Handler) DeleteTemplate(c *gin.Context) {
+	if err := h.notifySvc.DeleteTemplate(c.Param("event"), c.Param("language")); err != nil {
+		h.respondError(c, "删除通知模板失败:", err)
+		return
+	}
+	h.logger.Info("通知模板已恢复默认:", c.Param("event"), " ", c.Param("language"))
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": i18n.T(c, "notification.template_deleted"),
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "notification.not_found", "notification.template_not_found":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type NotificationResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    Notification `json:"data"`
+}
+
+type NotificationListData struct {
+	Notifications []Notification `json:"notifications"`
+	Pagination    query.Page     `json:"pagination"`
+}
+
+type NotificationListResponse struct {
+	Code    int                  `json:"code"`
+	Message string               `json:"message"`
+	Data    NotificationListData `json:"data"`
+}
+
+type UnreadCountResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    UnreadCount `json:"data"`
+}
+
+type MarkAllReadData struct {
+	Updated int64 `json:"updated" example:"3"`
+}
+
+type MarkAllReadResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    MarkAllReadData `json:"data"`
+}
+
+type PreferenceResponse struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Data    []EventPreference `json:"data"`
+}
+
+type TemplateListResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    []TemplateView `json:"data"`
+}
+
+type TemplateResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    Template `json:"data"`
+}
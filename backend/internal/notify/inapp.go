@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/events"
+)
+
+// maxTitleLength 站内通知标题的最大长度（字符）
+const maxTitleLength = 200
+
+// inAppChannel 站内通知：按接收人语言渲染后写入 notifications 表，并实时推送给在线的接收人
+type inAppChannel struct {
+	db *gorm.DB
+}
+
+func (inAppChannel) Name() string { return ChannelInApp }
+
+func (ch inAppChannel) Send(ctx context.Context, msg Message) error {
+	list := make([]Notification, len(msg.Recipients))
+	for i, r := range msg.Recipients {
+		lang := r.Lang()
+		list[i] = Notification{
+			UserID: r.UserID,
+			Event:  msg.Event,
+			Title:  truncate(msg.SubjectIn(lang), maxTitleLength),
+			Body:   msg.BodyIn(lang),
+			Link:   msg.Link,
+		}
+	}
+	if err := ch.db.WithContext(ctx).Create(&list).Error; err != nil {
+		return err
+	}
+	for _, n := range list {
+		events.Publish(n.UserID, events.TypeNotification, events.NotificationCreated{
+			ID:    n.ID,
+			Event: n.Event,
+			Title: n.Title,
+			Link:  n.Link,
+		})
+	}
+	return nil
+}
+
+// truncate 按字符截断文本
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
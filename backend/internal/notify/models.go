@@ -0,0 +1,166 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// Recipient 通知接收人
+type Recipient struct {
+	UserID   uuid.UUID
+	Name     string
+	Email    string
+	Language string
+}
+
+// Lang 接收人的通知语言，未设置或不支持时使用默认语言
+func (r Recipient) Lang() i18n.Lang {
+	if lang, ok := i18n.Parse(r.Language); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+// Text 按接收人语言渲染的文本，Code 为 i18n 消息码
+type Text struct {
+	Code string
+	Args []interface{}
+}
+
+// In 渲染为指定语言的文本
+func (t Text) In(lang i18n.Lang) string {
+	return i18n.Translate(lang, t.Code, t.Args...)
+}
+
+// Message 通知消息
+type Message struct {
+	// Event 事件类型，如 contract.expiring
+	Event   string
+	Subject Text
+	Body    Text
+	// Link 站内通知关联的前端页面路径，如 /contracts/{id}
+	Link       string
+	Recipients []Recipient
+
+	// templates 管理员自定义的模板，按语言索引，由 Publish 加载
+	templates map[i18n.Lang]Template
+}
+
+// SubjectIn 渲染指定语言的标题：有自定义模板时使用模板，否则使用默认文本
+func (m Message) SubjectIn(lang i18n.Lang) string {
+	if t, ok := m.templates[lang]; ok {
+		return render(t.Subject, m.Subject.Args)
+	}
+	return m.Subject.In(lang)
+}
+
+// BodyIn 渲染指定语言的正文：有自定义模板时使用模板，否则使用默认文本
+func (m Message) BodyIn(lang i18n.Lang) string {
+	if t, ok := m.templates[lang]; ok {
+		return render(t.Body, m.Body.Args)
+	}
+	return m.Body.In(lang)
+}
+
+// 可按用户关闭的通知渠道
+const (
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+)
+
+// Channels 用户可设置偏好的通知渠道
+var Channels = []string{ChannelEmail, ChannelInApp}
+
+// Notification 站内通知，按接收人语言渲染后保存
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	Event     string     `json:"event" gorm:"type:varchar(50);not null" example:"contract.expiring"`
+	Title     string     `json:"title" gorm:"type:varchar(200);not null" example:"合同 HT2024001 将于 30 天后到期"`
+	Body      string     `json:"body" gorm:"type:text;not null"`
+	Link      string     `json:"link,omitempty" gorm:"type:varchar(500)" example:"/contracts/0b5c6a1e-3f5d-4a7e-9c2b-1d2e3f4a5b6c"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 设置表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Preference 用户对某个事件、某个渠道的通知偏好，没有记录时默认接收
+type Preference struct {
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Event     string    `json:"event" gorm:"type:varchar(50);primaryKey"`
+	Channel   string    `json:"channel" gorm:"type:varchar(20);primaryKey"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 设置表名
+func (Preference) TableName() string {
+	return "notification_preferences"
+}
+
+// Template 管理员自定义的通知模板，{1}、{2} 等占位符依次替换为事件的参数
+type Template struct {
+	Event     string     `json:"event" gorm:"type:varchar(50);primaryKey" example:"contract.expiring"`
+	Language  string     `json:"language" gorm:"type:varchar(10);primaryKey" example:"zh-CN"`
+	Subject   string     `json:"subject" gorm:"type:varchar(200);not null" example:"【续签提醒】合同 {1} 还有 {2} 天到期"`
+	Body      string     `json:"body" gorm:"type:text;not null"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UpdatedBy *uuid.UUID `json:"updated_by,omitempty" gorm:"type:uuid"`
+}
+
+// TableName 设置表名
+func (Template) TableName() string {
+	return "notification_templates"
+}
+
+// EventPreference 用户在某个事件上各渠道的通知偏好
+type EventPreference struct {
+	Event    string          `json:"event" example:"contract.expiring"`
+	Channels map[string]bool `json:"channels"`
+}
+
+// PreferenceRequest 更新通知偏好请求
+type PreferenceRequest struct {
+	Preferences []PreferenceItem `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// PreferenceItem 单个事件、渠道的通知偏好
+type PreferenceItem struct {
+	Event   string `json:"event" binding:"required" example:"contract.expiring"`
+	Channel string `json:"channel" binding:"required,oneof=email in_app" example:"email"`
+	Enabled *bool  `json:"enabled" binding:"required" example:"false"`
+}
+
+// TemplateView 通知模板：各语言的默认文本和自定义模板
+type TemplateView struct {
+	Event         string             `json:"event" example:"contract.expiring"`
+	SubjectParams []string           `json:"subject_params" example:"contract_no,days_left"`
+	BodyParams    []string           `json:"body_params" example:"contract_no,title,customer_name,end_date,days_left"`
+	Languages     []TemplateLanguage `json:"languages"`
+}
+
+// TemplateLanguage 某个语言的默认文本和自定义模板
+type TemplateLanguage struct {
+	Language       string    `json:"language" example:"zh-CN"`
+	DefaultSubject string    `json:"default_subject"`
+	DefaultBody    string    `json:"default_body"`
+	Custom         *Template `json:"custom,omitempty"`
+}
+
+// TemplateRequest 设置自定义模板请求
+type TemplateRequest struct {
+	Subject string `json:"subject" binding:"required,max=200" example:"【续签提醒】合同 {1} 还有 {2} 天到期"`
+	Body    string `json:"body" binding:"required,max=5000" example:"合同 {1}（{2}），客户：{3}，将于 {4} 到期。"`
+}
+
+// UnreadCount 未读通知数
+type UnreadCount struct {
+	Unread int64 `json:"unread" example:"3"`
+}
@@ -0,0 +1,143 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+// Channel 通知渠道
+type Channel interface {
+	// Name 渠道名称，用于日志和用户偏好
+	Name() string
+	// Send 向消息的全部接收人发送通知
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	mu       sync.RWMutex
+	channels []Channel
+	// store 保存自定义模板、用户偏好和站内通知的数据库，Setup 前为空
+	store *gorm.DB
+)
+
+// Register 注册通知渠道
+func Register(ch Channel) {
+	mu.Lock()
+	defer mu.Unlock()
+	channels = append(channels, ch)
+}
+
+// Setup 按配置注册内置通知渠道：日志和站内通知渠道始终启用，在调用方同步发送；
+// notify.email.enabled 为 true 时启用邮件渠道，邮件在后台排队发送，ctx 取消时停止发送
+func Setup(ctx context.Context, db *gorm.DB) {
+	mu.Lock()
+	store = db
+	mu.Unlock()
+
+	Register(logChannel{})
+	Register(inAppChannel{db: db})
+	if viper.GetBool("notify.email.enabled") {
+		Register(newEmailQueue(ctx))
+	}
+}
+
+// Publish 通过全部已注册渠道发送通知，单个渠道失败不影响其他渠道
+// 管理员自定义了该事件的模板时按模板渲染；接收人关闭了某个渠道时该渠道不再发送给此人。
+// 邮件只放入发送队列，返回的错误不包括邮件发送失败
+func Publish(ctx context.Context, msg Message) error {
+	if len(msg.Recipients) == 0 {
+		return nil
+	}
+	mu.RLock()
+	registered := append([]Channel(nil), channels...)
+	db := store
+	mu.RUnlock()
+
+	var disabled map[string]map[uuid.UUID]bool
+	if db != nil {
+		var err error
+		if msg.templates, err = loadTemplates(db, msg.Event); err != nil {
+			logger.GetLogger().Error("读取通知模板失败:", msg.Event, " ", err)
+		}
+		if disabled, err = loadDisabled(db, msg.Event, msg.Recipients); err != nil {
+			logger.GetLogger().Error("读取通知偏好失败:", msg.Event, " ", err)
+		}
+	}
+
+	var errs []error
+	for _, ch := range registered {
+		out := msg
+		if off := disabled[ch.Name()]; len(off) > 0 {
+			out.Recipients = nil
+			for _, r := range msg.Recipients {
+				if !off[r.UserID] {
+					out.Recipients = append(out.Recipients, r)
+				}
+			}
+			if len(out.Recipients) == 0 {
+				continue
+			}
+		}
+		if err := ch.Send(ctx, out); err != nil {
+			logger.GetLogger().Error("发送通知失败:", ch.Name(), " ", msg.Event, " ", err)
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// loadTemplates 读取事件的自定义模板
+func loadTemplates(db *gorm.DB, event string) (map[i18n.Lang]Template, error) {
+	var list []Template
+	if err := db.Where("event = ?", event).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	templates := make(map[i18n.Lang]Template, len(list))
+	for _, t := range list {
+		templates[i18n.Lang(t.Language)] = t
+	}
+	return templates, nil
+}
+
+// loadDisabled 读取接收人关闭的渠道，按渠道索引
+func loadDisabled(db *gorm.DB, event string, recipients []Recipient) (map[string]map[uuid.UUID]bool, error) {
+	ids := make([]uuid.UUID, len(recipients))
+	for i, r := range recipients {
+		ids[i] = r.UserID
+	}
+	var prefs []Preference
+	err := db.Where("event = ? AND enabled = false AND user_id IN ?", event, ids).Find(&prefs).Error
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[string]map[uuid.UUID]bool)
+	for _, p := range prefs {
+		if disabled[p.Channel] == nil {
+			disabled[p.Channel] = make(map[uuid.UUID]bool)
+		}
+		disabled[p.Channel][p.UserID] = true
+	}
+	return disabled, nil
+}
+
+// logChannel 将通知写入日志
+type logChannel struct{}
+
+func (logChannel) Name() string { return "log" }
+
+func (logChannel) Send(ctx context.Context, msg Message) error {
+	log := logger.GetLogger()
+	for _, r := range msg.Recipients {
+		log.Info("通知:", msg.Event, " 接收人:", r.Name, " ", msg.SubjectIn(r.Lang()))
+	}
+	return nil
+}
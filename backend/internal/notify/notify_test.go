@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// recordingChannel 记录收到的接收人
+type recordingChannel struct {
+	name string
+	err  error
+	got  [][]uuid.UUID
+}
+
+func (r *recordingChannel) Name() string { return r.name }
+
+func (r *recordingChannel) Send(ctx context.Context, msg Message) error {
+	ids := make([]uuid.UUID, len(msg.Recipients))
+	for i, rcpt := range msg.Recipients {
+		ids[i] = rcpt.UserID
+	}
+	r.got = append(r.got, ids)
+	return r.err
+}
+
+// useChannels 临时替换已注册的渠道和数据库，测试结束后恢复
+func useChannels(t *testing.T, db *gorm.DB, chs ...Channel) {
+	t.Helper()
+	mu.Lock()
+	oldChannels, oldStore := channels, store
+	channels, store = chs, db
+	mu.Unlock()
+	t.Cleanup(func() {
+		mu.Lock()
+		channels, store = oldChannels, oldStore
+		mu.Unlock()
+	})
+}
+
+// newPreferenceDB 不连接数据库，查询通知偏好时返回 prefs
+func newPreferenceDB(t *testing.T, prefs []Preference) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Callback().Query().Replace("gorm:query", func(tx *gorm.DB) {
+		if dest, ok := tx.Statement.Dest.(*[]Preference); ok {
+			*dest = prefs
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestPublishPreferences(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	db := newPreferenceDB(t, []Preference{
+		{UserID: alice, Event: "contract.expiring", Channel: ChannelEmail},
+		{UserID: bob, Event: "contract.expiring", Channel: ChannelEmail},
+		{UserID: bob, Event: "contract.expiring", Channel: ChannelInApp},
+	})
+	email := &recordingChannel{name: ChannelEmail}
+	inApp := &recordingChannel{name: ChannelInApp}
+	log := &recordingChannel{name: "log"}
+	useChannels(t, db, email, inApp, log)
+
+	msg := Message{
+		Event:      "contract.expiring",
+		Subject:    Text{Code: "s"},
+		Body:       Text{Code: "b"},
+		Recipients: []Recipient{{UserID: alice}, {UserID: bob}, {UserID: carol}},
+	}
+	if err := Publish(context.Background(), msg); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	tests := []struct {
+		ch   *recordingChannel
+		want []uuid.UUID
+	}{
+		{email, []uuid.UUID{carol}},
+		{inApp, []uuid.UUID{alice, carol}},
+		{log, []uuid.UUID{alice, bob, carol}},
+	}
+	for _, tt := range tests {
+		if len(tt.ch.got) != 1 || !equalIDs(tt.ch.got[0], tt.want) {
+			t.Errorf("%s received %v, want %v", tt.ch.name, tt.ch.got, tt.want)
+		}
+	}
+	if len(msg.Recipients) != 3 {
+		t.Errorf("Publish modified the caller's recipients: %v", msg.Recipients)
+	}
+}
+
+func TestPublishSkipsChannelWithoutRecipients(t *testing.T) {
+	alice := uuid.New()
+	db := newPreferenceDB(t, []Preference{{UserID: alice, Event: "batch.paid", Channel: ChannelEmail}})
+	email := &recordingChannel{name: ChannelEmail}
+	failing := &recordingChannel{name: ChannelInApp, err: errors.New("down")}
+	useChannels(t, db, email, failing)
+
+	err := Publish(context.Background(), Message{Event: "batch.paid", Recipients: []Recipient{{UserID: alice}}})
+	if len(email.got) != 0 {
+		t.Errorf("email received %v, want nothing", email.got)
+	}
+	// 单个渠道失败不影响其他渠道，错误一并返回
+	if len(failing.got) != 1 || err == nil {
+		t.Errorf("in_app received %v, Publish error = %v", failing.got, err)
+	}
+}
+
+func equalIDs(a, b []uuid.UUID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// defaultQueueSize 未配置 notify.email.queue_size 时排队等待发送的消息数上限
+	defaultQueueSize = 1000
+	// defaultWorkers 未配置 notify.email.workers 时的并发发送数
+	defaultWorkers = 2
+)
+
+// queuedChannel 在后台发送通知的渠道：Send 只把消息放入队列，由后台协程调用实际渠道发送，
+// 避免慢速渠道（如 SMTP）阻塞审批等请求。队列在内存中，服务重启时未发送的消息会丢失
+type queuedChannel struct {
+	ch    Channel
+	queue chan Message
+}
+
+// newQueuedChannel 创建后台发送的渠道并启动 workers 个发送协程，ctx 取消时协程退出
+func newQueuedChannel(ctx context.Context, ch Channel, size, workers int) *queuedChannel {
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	q := &queuedChannel{ch: ch, queue: make(chan Message, size)}
+	for i := 0; i < workers; i++ {
+		go q.run(ctx)
+	}
+	return q
+}
+
+func (q *queuedChannel) Name() string { return q.ch.Name() }
+
+// Send 将消息放入发送队列，队列已满时返回错误，不阻塞调用方
+func (q *queuedChannel) Send(ctx context.Context, msg Message) error {
+	select {
+	case q.queue <- msg:
+		return nil
+	default:
+		return fmt.Errorf("%s 发送队列已满", q.ch.Name())
+	}
+}
+
+func (q *queuedChannel) run(ctx context.Context) {
+	log := logger.GetLogger()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-q.queue:
+			if err := q.ch.Send(ctx, msg); err != nil {
+				log.Error("发送通知失败:", q.ch.Name(), " ", msg.Event, " ", err)
+			}
+		}
+	}
+}
+
+// newEmailQueue 后台发送的邮件渠道
+func newEmailQueue(ctx context.Context) *queuedChannel {
+	return newQueuedChannel(ctx, newEmailChannel(),
+		viper.GetInt("notify.email.queue_size"), viper.GetInt("notify.email.workers"))
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// blockingChannel 收到消息后等待 release 才返回的渠道
+type blockingChannel struct {
+	started chan Message
+	release chan struct{}
+}
+
+func (b *blockingChannel) Name() string { return "blocking" }
+
+func (b *blockingChannel) Send(ctx context.Context, msg Message) error {
+	b.started <- msg
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func TestQueuedChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := &blockingChannel{started: make(chan Message, 3), release: make(chan struct{})}
+	q := newQueuedChannel(ctx, ch, 1, 1)
+
+	if q.Name() != "blocking" {
+		t.Errorf("Name = %q", q.Name())
+	}
+
+	// 唯一的发送协程阻塞在第一条消息上
+	if err := q.Send(ctx, Message{Event: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-ch.started:
+		if msg.Event != "first" {
+			t.Fatalf("started %q, want first", msg.Event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not pick up the message")
+	}
+
+	// 第二条排队，队列满后立即返回错误而不阻塞调用方
+	if err := q.Send(ctx, Message{Event: "second"}); err != nil {
+		t.Fatalf("Send second: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- q.Send(ctx, Message{Event: "third"}) }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Send on full queue error = nil")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Send blocked on full queue")
+	}
+
+	ch.release <- struct{}{}
+	select {
+	case msg := <-ch.started:
+		if msg.Event != "second" {
+			t.Errorf("started %q, want second", msg.Event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued message was not sent")
+	}
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/user"
+)
+
+// Recipients 查询通知接收人：指定用户及指定角色的全部用户，仅包含启用的用户
+func Recipients(db *gorm.DB, userIDs []uuid.UUID, roles []user.UserRole) ([]Recipient, error) {
+	if len(userIDs) == 0 && len(roles) == 0 {
+		return nil, nil
+	}
+	query := db.Model(&user.User{}).Where("is_active = ?", true)
+	switch {
+	case len(userIDs) > 0 && len(roles) > 0:
+		query = query.Where("id IN ? OR role IN ?", userIDs, roles)
+	case len(userIDs) > 0:
+		query = query.Where("id IN ?", userIDs)
+	default:
+		query = query.Where("role IN ?", roles)
+	}
+
+	var users []user.User
+	if err := query.Order("username").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	recipients := make([]Recipient, len(users))
+	for i, u := range users {
+		recipients[i] = Recipient{
+			UserID:   u.ID,
+			Name:     u.Username,
+			Email:    u.Email,
+			Language: u.Language,
+		}
+	}
+	return recipients, nil
+}
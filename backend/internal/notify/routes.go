@@ -0,0 +1,27 @@
+package notify
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册通知中心路由：当前用户的站内通知和通知偏好，以及通知模板管理（需要管理员权限）
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+	admins := middleware.RequireRole("admin")
+
+	router.GET("", handler.ListNotifications)
+	router.GET("/unread-count", handler.GetUnreadCount)
+	router.POST("/:id/read", handler.MarkRead)
+	router.POST("/read-all", handler.MarkAllRead)
+
+	router.GET("/preferences", handler.GetPreferences)
+	router.PUT("/preferences", handler.UpdatePreferences)
+
+	templates := router.Group("/templates", admins)
+	templates.GET("", handler.ListTemplates)
+	templates.PUT("/:event/:language", handler.SetTemplate)
+	templates.DELETE("/:event/:language", handler.DeleteTemplate)
+}
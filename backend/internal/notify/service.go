@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/query"
+)
+
+// Service 通知中心服务：站内通知、通知偏好和通知模板
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建通知中心服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// listQuery 站内通知列表可过滤、排序的字段
+var listQuery = query.Resource{
+	Fields: map[string]query.Field{
+		"id":         {Column: "id", Kind: query.UUID, Sortable: true},
+		"event":      {Column: "event", Kind: query.String},
+		"read_at":    {Column: "read_at", Kind: query.Time, Nullable: true},
+		"created_at": {Column: "created_at", Kind: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	Key:  "id",
+}
+
+// List 获取用户的站内通知，unread 为 true 时只返回未读通知
+func (s *Service) List(userID uuid.UUID, unread bool, params *query.Params) ([]Notification, *query.Page, error) {
+	db := s.db.Model(&Notification{}).Where("user_id = ?", userID)
+	if unread {
+		db = db.Where("read_at IS NULL")
+	}
+	return query.Find[Notification](db, params)
+}
+
+// UnreadCount 获取用户的未读通知数
+func (s *Service) UnreadCount(userID uuid.UUID) (int64, error) {
+	var count int64
+	err := s.db.Model(&Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead 将用户的一条通知标记为已读，已读的通知保持原已读时间
+func (s *Service) MarkRead(userID, id uuid.UUID) (*Notification, error) {
+	var n Notification
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&n).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("notification.not_found")
+		}
+		return nil, err
+	}
+	if n.ReadAt == nil {
+		now := time.Now()
+		n.ReadAt = &now
+		if err := s.db.Model(&n).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &n, nil
+}
+
+// MarkAllRead 将用户的全部未读通知标记为已读，返回标记的数量
+func (s *Service) MarkAllRead(userID uuid.UUID) (int64, error) {
+	res := s.db.Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return res.RowsAffected, res.Error
+}
+
+// Preferences 获取用户对全部事件的渠道偏好，没有设置的渠道为接收
+func (s *Service) Preferences(userID uuid.UUID) ([]EventPreference, error) {
+	var prefs []Preference
+	if err := s.db.Where("user_id = ?", userID).Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	set := make(map[string]map[string]bool)
+	for _, p := range prefs {
+		if set[p.Event] == nil {
+			set[p.Event] = make(map[string]bool)
+		}
+		set[p.Event][p.Channel] = p.Enabled
+	}
+
+	list := make([]EventPreference, len(Catalog))
+	for i, info := range Catalog {
+		channels := make(map[string]bool, len(Channels))
+		for _, ch := range Channels {
+			enabled, ok := set[info.Event][ch]
+			channels[ch] = !ok || enabled
+		}
+		list[i] = EventPreference{Event: info.Event, Channels: channels}
+	}
+	return list, nil
+}
+
+// UpdatePreferences 更新用户的通知偏好，未提交的事件和渠道保持不变
+func (s *Service) UpdatePreferences(userID uuid.UUID, req PreferenceRequest) ([]EventPreference, error) {
+	now := time.Now()
+	prefs := make([]Preference, len(req.Preferences))
+	for i, item := range req.Preferences {
+		if _, ok := lookupEvent(item.Event); !ok {
+			return nil, i18n.NewError("notification.unknown_event", item.Event)
+		}
+		prefs[i] = Preference{
+			UserID:    userID,
+			Event:     item.Event,
+			Channel:   item.Channel,
+			Enabled:   *item.Enabled,
+			UpdatedAt: now,
+		}
+	}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&prefs).Error
+	if err != nil {
+		return nil, err
+	}
+	return s.Preferences(userID)
+}
+
+// Templates 获取全部事件的默认文本和自定义模板
+func (s *Service) Templates() ([]TemplateView, error) {
+	var custom []Template
+	if err := s.db.Find(&custom).Error; err != nil {
+		return nil, err
+	}
+	index := make(map[string]*Template, len(custom))
+	for i := range custom {
+		index[custom[i].Event+"/"+custom[i].Language] = &custom[i]
+	}
+
+	views := make([]TemplateView, len(Catalog))
+	for i, info := range Catalog {
+		view := TemplateView{Event: info.Event, SubjectParams: info.SubjectParams, BodyParams: info.BodyParams}
+		for _, lang := range i18n.Supported() {
+			view.Languages = append(view.Languages, TemplateLanguage{
+				Language:       string(lang),
+				DefaultSubject: defaultTemplate(lang, info.SubjectCode),
+				DefaultBody:    defaultTemplate(lang, info.BodyCode),
+				Custom:         index[info.Event+"/"+string(lang)],
+			})
+		}
+		views[i] = view
+	}
+	return views, nil
+}
+
+// SetTemplate 设置事件在某个语言下的自定义模板
+func (s *Service) SetTemplate(event, language string, req TemplateRequest, operator uuid.UUID) (*Template, error) {
+	info, lang, err := templateKey(event, language)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPlaceholders(req.Subject, len(info.SubjectParams)); err != nil {
+		return nil, err
+	}
+	if err := checkPlaceholders(req.Body, len(info.BodyParams)); err != nil {
+		return nil, err
+	}
+
+	t := Template{
+		Event:     info.Event,
+		Language:  string(lang),
+		Subject:   req.Subject,
+		Body:      req.Body,
+		UpdatedAt: time.Now(),
+		UpdatedBy: &operator,
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event"}, {Name: "language"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updated_at", "updated_by"}),
+	}).Create(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// DeleteTemplate 删除自定义模板，恢复使用默认文本
+func (s *Service) DeleteTemplate(event, language string) error {
+	info, lang, err := templateKey(event, language)
+	if err != nil {
+		return err
+	}
+	res := s.db.Where("event = ? AND language = ?", info.Event, string(lang)).Delete(&Template{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return i18n.NewError("notification.template_not_found")
+	}
+	return nil
+}
+
+// templateKey 校验模板的事件和语言
+func templateKey(event, language string) (EventInfo, i18n.Lang, error) {
+	info, ok := lookupEvent(event)
+	if !ok {
+		return EventInfo{}, "", i18n.NewError("notification.unknown_event", event)
+	}
+	lang, ok := i18n.Parse(language)
+	if !ok {
+		return EventInfo{}, "", i18n.NewError("notification.invalid_language", language)
+	}
+	return info, lang, nil
+}
@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// EventInfo 通知事件的默认文本（i18n 消息码）和参数名，参数依次对应模板中的 {1}、{2} 等占位符
+type EventInfo struct {
+	Event         string
+	SubjectCode   string
+	BodyCode      string
+	SubjectParams []string
+	BodyParams    []string
+}
+
+// Catalog 发送通知的全部事件，新增事件时在此登记，用户可按事件设置偏好、管理员可自定义模板
+var Catalog = []EventInfo{
+	{
+		Event: "contract.expiring", SubjectCode: "contract.notify.expiring_subject", BodyCode: "contract.notify.expiring_body",
+		SubjectParams: []string{"contract_no", "days_left"},
+		BodyParams:    []string{"contract_no", "title", "customer_name", "end_date", "days_left"},
+	},
+	{
+		Event: "contract.expired", SubjectCode: "contract.notify.expired_subject", BodyCode: "contract.notify.expired_body",
+		SubjectParams: []string{"contract_no"},
+		BodyParams:    []string{"contract_no", "title", "customer_name", "end_date"},
+	},
+	{
+		Event: "approval.pending", SubjectCode: "approval.notify.pending_subject", BodyCode: "approval.notify.pending_body",
+		SubjectParams: []string{"contract_no"},
+		BodyParams:    []string{"contract_no", "step_no", "step_name"},
+	},
+	{
+		Event: "approval.approved", SubjectCode: "approval.notify.approved_subject", BodyCode: "approval.notify.approved_body",
+		SubjectParams: []string{"contract_no"},
+		BodyParams:    []string{"contract_no"},
+	},
+	{
+		Event: "approval.rejected", SubjectCode: "approval.notify.rejected_subject", BodyCode: "approval.notify.rejected_body",
+		SubjectParams: []string{"contract_no"},
+		BodyParams:    []string{"contract_no", "step_name", "comment"},
+	},
+	{
+		Event: "credit.warning", SubjectCode: "credit.notify.warning_subject", BodyCode: "credit.notify.warning_body",
+		SubjectParams: []string{"company_name", "threshold"},
+		BodyParams:    []string{"company_name", "customer_code", "exposure", "currency", "credit_limit", "currency", "utilization"},
+	},
+	{
+		Event: "credit.exceeded", SubjectCode: "credit.notify.exceeded_subject", BodyCode: "credit.notify.exceeded_body",
+		SubjectParams: []string{"company_name", "threshold"},
+		BodyParams:    []string{"company_name", "customer_code", "exposure", "currency", "credit_limit", "currency", "utilization"},
+	},
+	{
+		Event: "sync.failed", SubjectCode: "datasync.notify.failed_subject", BodyCode: "datasync.notify.failed_body",
+		SubjectParams: []string{"company_name", "provider"},
+		BodyParams:    []string{"company_name", "customer_code", "provider", "period", "error"},
+	},
+	{
+		Event: "export.ready", SubjectCode: "export.notify.ready_subject", BodyCode: "export.notify.ready_body",
+		SubjectParams: []string{"file_name"},
+		BodyParams:    []string{"file_name", "record_count", "download_url"},
+	},
+	{
+		Event: "export.failed", SubjectCode: "export.notify.failed_subject", BodyCode: "export.notify.failed_body",
+		SubjectParams: []string{"file_name"},
+		BodyParams:    []string{"file_name", "error"},
+	},
+}
+
+// lookupEvent 查找登记的事件
+func lookupEvent(event string) (EventInfo, bool) {
+	for _, info := range Catalog {
+		if info.Event == event {
+			return info, true
+		}
+	}
+	return EventInfo{}, false
+}
+
+var (
+	placeholder = regexp.MustCompile(`\{(\d+)\}`)
+	formatVerb  = regexp.MustCompile(`%(\[\d+\])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+)
+
+// render 将模板中的 {n} 替换为第 n 个参数，超出范围的占位符原样保留
+func render(tmpl string, args []interface{}) string {
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		n, _ := strconv.Atoi(m[1 : len(m)-1])
+		if n < 1 || n > len(args) {
+			return m
+		}
+		return formatArg(args[n-1])
+	})
+}
+
+// formatArg 格式化模板参数，小数保留两位
+func formatArg(v interface{}) string {
+	switch val := v.(type) {
+	case float64:
+		return strconv.FormatFloat(val, 'f', 2, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', 2, 32)
+	case *float64:
+		if val == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*val, 'f', 2, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// checkPlaceholders 检查模板中的占位符不超过参数个数
+func checkPlaceholders(tmpl string, params int) error {
+	for _, m := range placeholder.FindAllStringSubmatch(tmpl, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > params {
+			return i18n.NewError("notification.invalid_placeholder", m[0], params)
+		}
+	}
+	return nil
+}
+
+// defaultTemplate 将默认文本的格式化动词转换为 {n} 占位符，作为自定义模板的参考
+func defaultTemplate(lang i18n.Lang, code string) string {
+	next := 0
+	return formatVerb.ReplaceAllStringFunc(i18n.Translate(lang, code), func(verb string) string {
+		if verb == "%%" {
+			return "%"
+		}
+		if m := formatVerb.FindStringSubmatch(verb); m[1] != "" {
+			n, _ := strconv.Atoi(m[1][1 : len(m[1])-1])
+			next = n
+			return "{" + strconv.Itoa(n) + "}"
+		}
+		next++
+		return "{" + strconv.Itoa(next) + "}"
+	})
+}
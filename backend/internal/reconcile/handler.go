@@ -0,0 +1,432 @@
+package reconcile
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	reconcileSvc *Service
+	logger       *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		reconcileSvc: NewService(db),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// CreateRun 发起对账
+// @Summary 发起对账
+// @Description 对客户在指定云平台、计费周期的账单进行对账：比对云平台账单金额与账单明细合计、账单明细与合同预期费用（原价×合同折扣率），
+// @Description 在账号、服务、资源层级生成超出容差的差异记录（需要管理员或经理权限）
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body RunRequest true "对账参数"
+// @Success 201 {object} RunDetailResponse "对账完成"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reconciliations [post]
+func (h *Handler) CreateRun(c *gin.Context) {
+	var req RunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	run, err := h.reconcileSvc.Run(req, &operator)
+	if err != nil {
+		h.logger.Error("对账失败:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	h.logger.Info("对账完成:", run.ID, " 差异数:", run.DiscrepancyCount)
+	h.respondRun(c, http.StatusCreated, "reconcile.completed", run)
+}
+
+// ListRuns 获取对账任务列表
+// @Summary 获取对账任务列表
+// @Description 分页获取对账任务，按创建时间倒序
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id query string false "客户ID"
+// @Param provider query string false "云平台"
+// @Param period query string false "计费周期（YYYY-MM）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} RunListResponse "获取成功"
+// @Router /reconciliations [get]
+func (h *Handler) ListRuns(c *gin.Context) {
+	page, pageSize := pagination(c)
+	filter := RunFilter{
+		Provider: billing.Provider(c.Query("provider")),
+		Period:   c.Query("period"),
+	}
+	if raw := c.Query("customer_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+			})
+			return
+		}
+		filter.CustomerID = &id
+	}
+
+	runs, total, err := h.reconcileSvc.ListRuns(filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("获取对账任务列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RunListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: RunListData{
+			Runs:     runs,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetRun 获取对账任务
+// @Summary 获取对账任务
+// @Description 获取对账任务汇总及各处理状态的差异数
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "对账任务ID"
+// @Success 200 {object} RunDetailResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "对账任务不存在"
+// @Router /reconciliations/{id} [get]
+func (h *Handler) GetRun(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	run, err := h.reconcileSvc.GetRun(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.respondRun(c, http.StatusOK, "common.fetch_success", run)
+}
+
+// ListDiscrepancies 获取对账差异列表
+// @Summary 获取对账差异列表
+// @Description 分页获取对账任务的差异，按差异金额绝对值倒序
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "对账任务ID"
+// @Param level query string false "差异层级" Enums(account, service, resource)
+// @Param type query string false "差异类型" Enums(invoice_vs_billed, billed_vs_expected)
+// @Param status query string false "处理状态" Enums(open, explained, resolved)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} DiscrepancyListResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "对账任务不存在"
+// @Router /reconciliations/{id}/discrepancies [get]
+func (h *Handler) ListDiscrepancies(c *gin.Context) {
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	if _, err := h.reconcileSvc.GetRun(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	page, pageSize := pagination(c)
+	filter := DiscrepancyFilter{
+		Level:  Level(c.Query("level")),
+		Type:   DiscrepancyType(c.Query("type")),
+		Status: Status(c.Query("status")),
+	}
+	items, total, err := h.reconcileSvc.ListDiscrepancies(id, filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("获取对账差异失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, DiscrepancyListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: DiscrepancyListData{
+			Discrepancies: items,
+			Total:         total,
+			Page:          page,
+			PageSize:      pageSize,
+		},
+	})
+}
+
+// ExplainDiscrepancy 说明对账差异
+// @Summary 说明对账差异
+// @Description 填写差异原因，待处理的差异变为已说明
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "对账任务ID"
+// @Param discrepancy_id path string true "差异ID"
+// @Param body body ExplainRequest true "差异说明"
+// @Success 200 {object} DiscrepancyDetailResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reconciliations/{id}/discrepancies/{discrepancy_id}/explain [post]
+func (h *Handler) ExplainDiscrepancy(c *gin.Context) {
+	var req ExplainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	h.transition(c, func(runID, id, operator uuid.UUID) (*Discrepancy, error) {
+		return h.reconcileSvc.Explain(runID, id, req.Explanation, operator)
+	})
+}
+
+// ResolveDiscrepancy 解决对账差异
+// @Summary 解决对账差异
+// @Description 将差异标记为已解决（需要管理员或经理权限）
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "对账任务ID"
+// @Param discrepancy_id path string true "差异ID"
+// @Param body body ResolveRequest false "处理说明"
+// @Success 200 {object} DiscrepancyDetailResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reconciliations/{id}/discrepancies/{discrepancy_id}/resolve [post]
+func (h *Handler) ResolveDiscrepancy(c *gin.Context) {
+	var req ResolveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+				"error":   i18n.Message(c, err),
+			})
+			return
+		}
+	}
+	h.transition(c, func(runID, id, operator uuid.UUID) (*Discrepancy, error) {
+		return h.reconcileSvc.Resolve(runID, id, req.Explanation, operator)
+	})
+}
+
+// ReopenDiscrepancy 重新打开对账差异
+// @Summary 重新打开对账差异
+// @Description 将已说明或已解决的差异恢复为待处理（需要管理员或经理权限）
+// @Tags 对账管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "对账任务ID"
+// @Param discrepancy_id path string true "差异ID"
+// @Success 200 {object} DiscrepancyDetailResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reconciliations/{id}/discrepancies/{discrepancy_id}/reopen [post]
+func (h *Handler) ReopenDiscrepancy(c *gin.Context) {
+	h.transition(c, h.reconcileSvc.Reopen)
+}
+
+// transition 执行差异状态变更并返回结果
+func (h *Handler) transition(c *gin.Context, fn func(runID, id, operator uuid.UUID) (*Discrepancy, error)) {
+	runID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	id, ok := pathID(c, "discrepancy_id")
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	d, err := fn(runID, id, operator)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, i18n.NewError("reconcile.discrepancy_not_found")) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	h.logger.Info("对账差异状态已更新:", d.ID, " 状态:", d.Status)
+	c.JSON(http.StatusOK, DiscrepancyDetailResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.update_success"),
+		Data:    *d,
+	})
+}
+
+// respondRun 返回对账任务及差异状态统计
+func (h *Handler) respondRun(c *gin.Context, status int, messageCode string, run *Run) {
+	counts, err := h.reconcileSvc.StatusCounts(run.ID)
+	if err != nil {
+		h.logger.Error("统计对账差异失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(status, RunDetailResponse{
+		Code:    status,
+		Message: i18n.T(c, messageCode),
+		Data: RunDetail{
+			Run:          *run,
+			StatusCounts: counts,
+		},
+	})
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的UUID参数
+func pathID(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type RunDetail struct {
+	Run
+	StatusCounts map[Status]int64 `json:"status_counts"`
+}
+
+type RunDetailResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    RunDetail `json:"data"`
+}
+
+type RunListData struct {
+	Runs     []Run `json:"runs"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+type RunListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    RunListData `json:"data"`
+}
+
+type DiscrepancyListData struct {
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	Total         int64         `json:"total"`
+	Page          int           `json:"page"`
+	PageSize      int           `json:"page_size"`
+}
+
+type DiscrepancyListResponse struct {
+	Code    int                 `json:"code"`
+	Message string              `json:"message"`
+	Data    DiscrepancyListData `json:"data"`
+}
+
+type DiscrepancyDetailResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    Discrepancy `json:"data"`
+}
@@ -0,0 +1,142 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/internal/billing"
+)
+
+// Level 差异层级
+type Level string
+
+const (
+	LevelAccount  Level = "account"  // 云账号
+	LevelService  Level = "service"  // 云账号 + 服务类型
+	LevelResource Level = "resource" // 云账号 + 服务类型 + 资源
+)
+
+// DiscrepancyType 差异类型
+type DiscrepancyType string
+
+const (
+	// TypeInvoiceVsBilled 云平台账单（发票）金额与账单明细折后合计不一致
+	TypeInvoiceVsBilled DiscrepancyType = "invoice_vs_billed"
+	// TypeBilledVsExpected 账单明细折后金额与合同预期费用（原价×合同折扣率）不一致
+	TypeBilledVsExpected DiscrepancyType = "billed_vs_expected"
+)
+
+// Status 差异处理状态
+type Status string
+
+const (
+	StatusOpen      Status = "open"      // 待处理
+	StatusExplained Status = "explained" // 已说明
+	StatusResolved  Status = "resolved"  // 已解决
+)
+
+// IsValid 检查状态是否有效
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusOpen, StatusExplained, StatusResolved:
+		return true
+	default:
+		return false
+	}
+}
+
+// AllAccounts 发票金额覆盖客户全部云账号时使用的账号标识
+const AllAccounts = "*"
+
+// Run 对账任务
+type Run struct {
+	ID                  uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID          uuid.UUID        `json:"customer_id" gorm:"type:uuid;not null"`
+	Provider            billing.Provider `json:"provider" gorm:"type:cloud_provider;not null"`
+	BillingPeriod       string           `json:"billing_period" gorm:"type:varchar(10);not null"`
+	InvoicedTotal       *float64         `json:"invoiced_total,omitempty" gorm:"type:decimal(15,2)"`
+	BilledOriginalTotal float64          `json:"billed_original_total" gorm:"type:decimal(15,2);not null;default:0"`
+	BilledTotal         float64          `json:"billed_total" gorm:"type:decimal(15,2);not null;default:0"`
+	ExpectedTotal       float64          `json:"expected_total" gorm:"type:decimal(15,2);not null;default:0"`
+	LineCount           int              `json:"line_count" gorm:"not null;default:0"`
+	UncoveredCount      int              `json:"uncovered_count" gorm:"not null;default:0"`
+	ToleranceAmount     float64          `json:"tolerance_amount" gorm:"type:decimal(15,2);not null"`
+	ToleranceRate       float64          `json:"tolerance_rate" gorm:"type:decimal(7,6);not null"`
+	DiscrepancyCount    int              `json:"discrepancy_count" gorm:"not null;default:0"`
+	Details             json.RawMessage  `json:"details,omitempty" gorm:"type:jsonb"`
+	CreatedAt           time.Time        `json:"created_at"`
+	CreatedBy           *uuid.UUID       `json:"created_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Run) TableName() string {
+	return "reconciliation_runs"
+}
+
+// Discrepancy 对账差异
+type Discrepancy struct {
+	ID              uuid.UUID        `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	RunID           uuid.UUID        `json:"run_id" gorm:"type:uuid;not null"`
+	CustomerID      uuid.UUID        `json:"customer_id" gorm:"type:uuid;not null"`
+	Provider        billing.Provider `json:"provider" gorm:"type:cloud_provider;not null"`
+	BillingPeriod   string           `json:"billing_period" gorm:"type:varchar(10);not null"`
+	Level           Level            `json:"level" gorm:"type:varchar(20);not null"`
+	DiscrepancyType DiscrepancyType  `json:"discrepancy_type" gorm:"type:varchar(30);not null"`
+	AccountID       string           `json:"account_id" gorm:"type:varchar(100);not null;default:''"`
+	ServiceType     string           `json:"service_type,omitempty" gorm:"type:varchar(50);not null;default:''"`
+	ResourceID      string           `json:"resource_id,omitempty" gorm:"type:varchar(200);not null;default:''"`
+	ExpectedAmount  float64          `json:"expected_amount" gorm:"type:decimal(15,2);not null"`
+	ActualAmount    float64          `json:"actual_amount" gorm:"type:decimal(15,2);not null"`
+	Difference      float64          `json:"difference" gorm:"type:decimal(15,2);not null"`
+	DifferenceRate  *float64         `json:"difference_rate,omitempty" gorm:"type:decimal(12,6)"`
+	Status          Status           `json:"status" gorm:"type:varchar(20);not null;default:'open'"`
+	Explanation     string           `json:"explanation,omitempty" gorm:"type:text"`
+	ResolvedAt      *time.Time       `json:"resolved_at,omitempty"`
+	ResolvedBy      *uuid.UUID       `json:"resolved_by,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	UpdatedBy       *uuid.UUID       `json:"updated_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Discrepancy) TableName() string {
+	return "reconciliation_discrepancies"
+}
+
+// key 差异的业务键，用于在重新对账时沿用之前的处理结果
+func (d *Discrepancy) key() string {
+	return string(d.Level) + "|" + string(d.DiscrepancyType) + "|" + d.AccountID + "|" + d.ServiceType + "|" + d.ResourceID
+}
+
+// 请求结构体
+
+// RunRequest 发起对账请求
+type RunRequest struct {
+	CustomerID string           `json:"customer_id" binding:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Provider   billing.Provider `json:"provider" binding:"required,oneof=tencent alibaba huawei aws" example:"tencent"`
+	Period     string           `json:"period" binding:"required" example:"2024-06"`
+	// InvoicedTotal 云平台账单总额（覆盖客户在该云平台的全部账号）
+	InvoicedTotal *float64 `json:"invoiced_total,omitempty" example:"125000.00"`
+	// AccountInvoices 分账号的云平台账单金额
+	AccountInvoices map[string]float64 `json:"account_invoices,omitempty"`
+	// ToleranceAmount、ToleranceRate 未指定时使用系统配置
+	ToleranceAmount *float64 `json:"tolerance_amount,omitempty" binding:"omitempty,gte=0" example:"1.00"`
+	ToleranceRate   *float64 `json:"tolerance_rate,omitempty" binding:"omitempty,gte=0,lt=1" example:"0.001"`
+}
+
+// ExplainRequest 差异说明请求
+type ExplainRequest struct {
+	Explanation string `json:"explanation" binding:"required" example:"云平台月末冲正，次月账单抵扣"`
+}
+
+// ResolveRequest 差异解决请求
+type ResolveRequest struct {
+	Explanation string `json:"explanation,omitempty" example:"已与云平台确认并调整"`
+}
+
+// runDetails 对账任务输入明细，保存在 reconciliation_runs.details
+type runDetails struct {
+	AccountInvoices map[string]float64 `json:"account_invoices,omitempty"`
+}
@@ -0,0 +1,22 @@
+package reconcile
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册对账相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+	managers := middleware.RequireRole("admin", "manager")
+
+	router.GET("", handler.ListRuns)
+	router.GET("/:id", handler.GetRun)
+	router.GET("/:id/discrepancies", handler.ListDiscrepancies)
+	router.POST("", managers, handler.CreateRun)
+	router.POST("/:id/discrepancies/:discrepancy_id/explain", middleware.RequireRole("admin", "manager", "employee"), handler.ExplainDiscrepancy)
+	router.POST("/:id/discrepancies/:discrepancy_id/resolve", managers, handler.ResolveDiscrepancy)
+	router.POST("/:id/discrepancies/:discrepancy_id/reopen", managers, handler.ReopenDiscrepancy)
+}
@@ -0,0 +1,468 @@
+package reconcile
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// defaultToleranceAmount 未配置 reconcile.tolerance_amount 时的金额容差
+	defaultToleranceAmount = 1.0
+	// defaultToleranceRate 未配置 reconcile.tolerance_rate 时的比例容差
+	defaultToleranceRate = 0.001
+)
+
+// Service 对账服务
+//
+// 对账口径：
+//   - 账单明细合计：billing_data 中该客户、云平台、计费周期的折后费用之和
+//   - 合同预期费用：明细原价 × 账单日期所在生效合同的折扣率，无合同覆盖或合同未设置折扣率时按原价
+//   - 差异金额 = 实际 - 预期，同时超过金额容差和比例容差时记为差异
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建对账服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// costRow 按（账号, 服务类型, 资源）汇总的费用
+type costRow struct {
+	AccountID      string
+	ServiceType    string
+	ResourceID     string
+	OriginalCost   float64
+	BilledCost     float64
+	ExpectedCost   float64
+	LineCount      int
+	UncoveredCount int
+}
+
+// aggregateSQL 汇总账单明细，并按账单日期匹配生效合同及当时生效的合同版本的折扣率
+// 合同没有版本记录时使用合同当前的折扣率
+const aggregateSQL = `
+SELECT b.account_id, b.service_type, COALESCE(b.resource_id, '') AS resource_id,
+       SUM(b.original_cost) AS original_cost,
+       SUM(b.discounted_cost) AS billed_cost,
+       SUM(b.original_cost * COALESCE(c.discount_rate, 1)) AS expected_cost,
+       COUNT(*) AS line_count,
+       COUNT(*) FILTER (WHERE c.id IS NULL) AS uncovered_count
+FROM billing_data_template b
+LEFT JOIN LATERAL (
+    SELECT ct.id, CASE WHEN v.id IS NULL THEN ct.discount_rate ELSE v.discount_rate END AS discount_rate
+    FROM contracts ct
+    LEFT JOIN LATERAL (
+        SELECT cv.id, cv.discount_rate FROM contract_versions cv
+        WHERE cv.contract_id = ct.id AND cv.effective_date <= b.billing_date
+        ORDER BY cv.effective_date DESC
+        LIMIT 1
+    ) v ON true
+    WHERE ct.customer_id = b.customer_id AND ct.deleted_at IS NULL AND ct.status IN ?
+      AND ct.start_date <= b.billing_date AND ct.end_date >= b.billing_date
+    ORDER BY ct.start_date DESC
+    LIMIT 1
+) c ON true
+WHERE b.customer_id = ? AND b.provider = ? AND b.billing_period = ? AND b.deleted_at IS NULL
+GROUP BY b.account_id, b.service_type, COALESCE(b.resource_id, '')`
+
+// amounts 某一层级的费用汇总
+type amounts struct {
+	billed   float64
+	expected float64
+}
+
+// Run 执行对账，生成对账任务和差异记录
+// 同一范围之前对账中已说明或已解决、且差异金额未变化的差异会沿用原处理结果
+func (s *Service) Run(req RunRequest, operator *uuid.UUID) (*Run, error) {
+	customerID, err := uuid.Parse(req.CustomerID)
+	if err != nil {
+		return nil, i18n.NewError("common.invalid_params")
+	}
+	if _, err := billing.ParsePeriod(req.Period); err != nil {
+		return nil, i18n.NewError("reconcile.invalid_period")
+	}
+	var count int64
+	if err := s.db.Model(&customer.Customer{}).Where("id = ?", customerID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, i18n.NewError("reconcile.customer_not_found")
+	}
+
+	run := &Run{
+		CustomerID:    customerID,
+		Provider:      req.Provider,
+		BillingPeriod: req.Period,
+		InvoicedTotal: req.InvoicedTotal,
+		CreatedBy:     operator,
+	}
+	cfg := sysconfig.NewService(s.db, database.GetRedis())
+	run.ToleranceAmount = cfg.GetFloat(sysconfig.KeyReconcileToleranceAmount, defaultToleranceAmount)
+	run.ToleranceRate = cfg.GetFloat(sysconfig.KeyReconcileToleranceRate, defaultToleranceRate)
+	if req.ToleranceAmount != nil {
+		run.ToleranceAmount = *req.ToleranceAmount
+	}
+	if req.ToleranceRate != nil {
+		run.ToleranceRate = *req.ToleranceRate
+	}
+	if len(req.AccountInvoices) > 0 {
+		run.Details, _ = json.Marshal(runDetails{AccountInvoices: req.AccountInvoices})
+	}
+
+	var rows []costRow
+	err = s.db.Raw(aggregateSQL,
+		[]contract.ContractStatus{contract.StatusActive, contract.StatusExpired, contract.StatusTerminated},
+		customerID, req.Provider, req.Period).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	discrepancies := s.compare(run, rows, req)
+	run.DiscrepancyCount = len(discrepancies)
+
+	previous, err := s.previousOutcomes(customerID, req.Provider, req.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(run).Error; err != nil {
+			return err
+		}
+		for i := range discrepancies {
+			d := &discrepancies[i]
+			d.RunID = run.ID
+			if old, ok := previous[d.key()]; ok && old.Difference == d.Difference {
+				d.Status = old.Status
+				d.Explanation = old.Explanation
+				d.ResolvedAt = old.ResolvedAt
+				d.ResolvedBy = old.ResolvedBy
+			}
+		}
+		if len(discrepancies) > 0 {
+			return tx.CreateInBatches(discrepancies, 500).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// compare 汇总各层级费用并生成差异记录（不写库）
+func (s *Service) compare(run *Run, rows []costRow, req RunRequest) []Discrepancy {
+	accounts := map[string]*amounts{}
+	services := map[[2]string]*amounts{}
+	resources := map[[3]string]*amounts{}
+	add := func(a *amounts, r costRow) {
+		a.billed += r.BilledCost
+		a.expected += r.ExpectedCost
+	}
+
+	for _, r := range rows {
+		run.BilledOriginalTotal += r.OriginalCost
+		run.BilledTotal += r.BilledCost
+		run.ExpectedTotal += r.ExpectedCost
+		run.LineCount += r.LineCount
+		run.UncoveredCount += r.UncoveredCount
+
+		if accounts[r.AccountID] == nil {
+			accounts[r.AccountID] = &amounts{}
+		}
+		add(accounts[r.AccountID], r)
+
+		sk := [2]string{r.AccountID, r.ServiceType}
+		if services[sk] == nil {
+			services[sk] = &amounts{}
+		}
+		add(services[sk], r)
+
+		if r.ResourceID != "" {
+			rk := [3]string{r.AccountID, r.ServiceType, r.ResourceID}
+			if resources[rk] == nil {
+				resources[rk] = &amounts{}
+			}
+			add(resources[rk], r)
+		}
+	}
+	run.BilledOriginalTotal = round2(run.BilledOriginalTotal)
+	run.BilledTotal = round2(run.BilledTotal)
+	run.ExpectedTotal = round2(run.ExpectedTotal)
+
+	var result []Discrepancy
+	check := func(level Level, t DiscrepancyType, account, service, resource string, expected, actual float64) {
+		d, ok := s.evaluate(run, expected, actual)
+		if !ok {
+			return
+		}
+		d.Level = level
+		d.DiscrepancyType = t
+		d.AccountID = account
+		d.ServiceType = service
+		d.ResourceID = resource
+		result = append(result, d)
+	}
+
+	// 云平台账单金额 vs 账单明细
+	if req.InvoicedTotal != nil {
+		check(LevelAccount, TypeInvoiceVsBilled, AllAccounts, "", "", *req.InvoicedTotal, run.BilledTotal)
+	}
+	for account, invoiced := range req.AccountInvoices {
+		billed := 0.0
+		if a := accounts[account]; a != nil {
+			billed = a.billed
+		}
+		check(LevelAccount, TypeInvoiceVsBilled, account, "", "", invoiced, billed)
+	}
+
+	// 账单明细 vs 合同预期费用
+	for account, a := range accounts {
+		check(LevelAccount, TypeBilledVsExpected, account, "", "", a.expected, a.billed)
+	}
+	for k, a := range services {
+		check(LevelService, TypeBilledVsExpected, k[0], k[1], "", a.expected, a.billed)
+	}
+	for k, a := range resources {
+		check(LevelResource, TypeBilledVsExpected, k[0], k[1], k[2], a.expected, a.billed)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return math.Abs(result[i].Difference) > math.Abs(result[j].Difference)
+	})
+	return result
+}
+
+// evaluate 判断预期与实际金额的差异是否超出容差
+func (s *Service) evaluate(run *Run, expected, actual float64) (Discrepancy, bool) {
+	expected = round2(expected)
+	actual = round2(actual)
+	diff := round2(actual - expected)
+	if math.Abs(diff) <= run.ToleranceAmount {
+		return Discrepancy{}, false
+	}
+
+	d := Discrepancy{
+		CustomerID:     run.CustomerID,
+		Provider:       run.Provider,
+		BillingPeriod:  run.BillingPeriod,
+		ExpectedAmount: expected,
+		ActualAmount:   actual,
+		Difference:     diff,
+		Status:         StatusOpen,
+	}
+	if expected != 0 {
+		rate := math.Round(diff/expected*1e6) / 1e6
+		if math.Abs(rate) <= run.ToleranceRate {
+			return Discrepancy{}, false
+		}
+		d.DifferenceRate = &rate
+	}
+	return d, true
+}
+
+// previousOutcomes 获取同一范围最近一次对账中已处理的差异
+func (s *Service) previousOutcomes(customerID uuid.UUID, provider billing.Provider, period string) (map[string]Discrepancy, error) {
+	var last Run
+	err := s.db.Where("customer_id = ? AND provider = ? AND billing_period = ?", customerID, provider, period).
+		Order("created_at DESC").First(&last).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return map[string]Discrepancy{}, nil
+		}
+		return nil, err
+	}
+
+	var handled []Discrepancy
+	if err := s.db.Where("run_id = ? AND status <> ?", last.ID, StatusOpen).Find(&handled).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]Discrepancy, len(handled))
+	for _, d := range handled {
+		result[d.key()] = d
+	}
+	return result, nil
+}
+
+// RunFilter 对账任务查询条件
+type RunFilter struct {
+	CustomerID *uuid.UUID
+	Provider   billing.Provider
+	Period     string
+}
+
+// ListRuns 分页获取对账任务
+func (s *Service) ListRuns(filter RunFilter, page, pageSize int) ([]Run, int64, error) {
+	query := s.db.Model(&Run{})
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Provider != "" {
+		query = query.Where("provider = ?", filter.Provider)
+	}
+	if filter.Period != "" {
+		query = query.Where("billing_period = ?", filter.Period)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var runs []Run
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&runs).Error
+	return runs, total, err
+}
+
+// GetRun 获取对账任务
+func (s *Service) GetRun(id uuid.UUID) (*Run, error) {
+	var run Run
+	if err := s.db.First(&run, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("reconcile.run_not_found")
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+// StatusCounts 按处理状态统计对账任务的差异数
+func (s *Service) StatusCounts(runID uuid.UUID) (map[Status]int64, error) {
+	var rows []struct {
+		Status Status
+		Count  int64
+	}
+	err := s.db.Model(&Discrepancy{}).Select("status, COUNT(*) AS count").
+		Where("run_id = ?", runID).Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := map[Status]int64{StatusOpen: 0, StatusExplained: 0, StatusResolved: 0}
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
+// DiscrepancyFilter 差异查询条件
+type DiscrepancyFilter struct {
+	Level  Level
+	Type   DiscrepancyType
+	Status Status
+}
+
+// ListDiscrepancies 分页获取对账差异，按差异金额绝对值倒序
+func (s *Service) ListDiscrepancies(runID uuid.UUID, filter DiscrepancyFilter, page, pageSize int) ([]Discrepancy, int64, error) {
+	query := s.db.Model(&Discrepancy{}).Where("run_id = ?", runID)
+	if filter.Level != "" {
+		query = query.Where("level = ?", filter.Level)
+	}
+	if filter.Type != "" {
+		query = query.Where("discrepancy_type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var items []Discrepancy
+	err := query.Order("ABS(difference) DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&items).Error
+	return items, total, err
+}
+
+// getDiscrepancy 获取对账任务下的差异
+func (s *Service) getDiscrepancy(runID, id uuid.UUID) (*Discrepancy, error) {
+	var d Discrepancy
+	if err := s.db.Where("id = ? AND run_id = ?", id, runID).First(&d).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("reconcile.discrepancy_not_found")
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Explain 填写差异说明，待处理的差异变为已说明
+func (s *Service) Explain(runID, id uuid.UUID, explanation string, operator uuid.UUID) (*Discrepancy, error) {
+	d, err := s.getDiscrepancy(runID, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == StatusResolved {
+		return nil, i18n.NewError("reconcile.already_resolved")
+	}
+
+	d.Status = StatusExplained
+	d.Explanation = explanation
+	d.UpdatedBy = &operator
+	if err := s.db.Save(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Resolve 将差异标记为已解决，可同时更新说明
+func (s *Service) Resolve(runID, id uuid.UUID, explanation string, operator uuid.UUID) (*Discrepancy, error) {
+	d, err := s.getDiscrepancy(runID, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == StatusResolved {
+		return nil, i18n.NewError("reconcile.already_resolved")
+	}
+
+	now := time.Now()
+	d.Status = StatusResolved
+	if explanation != "" {
+		d.Explanation = explanation
+	}
+	d.ResolvedAt = &now
+	d.ResolvedBy = &operator
+	d.UpdatedBy = &operator
+	if err := s.db.Save(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Reopen 重新打开已说明或已解决的差异
+func (s *Service) Reopen(runID, id uuid.UUID, operator uuid.UUID) (*Discrepancy, error) {
+	d, err := s.getDiscrepancy(runID, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.Status == StatusOpen {
+		return nil, i18n.NewError("reconcile.already_open")
+	}
+
+	d.Status = StatusOpen
+	d.ResolvedAt = nil
+	d.ResolvedBy = nil
+	d.UpdatedBy = &operator
+	if err := s.db.Save(d).Error; err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// round2 金额保留两位小数
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
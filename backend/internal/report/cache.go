@@ -0,0 +1,82 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/events"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// cacheKeyPrefix Redis中统计结果的缓存键前缀
+	cacheKeyPrefix = "xcloud:report:"
+	// versionKey 缓存版本号，失效时递增，旧版本的缓存随有效期过期
+	versionKey = cacheKeyPrefix + "version"
+	// defaultCacheTTL 未配置 report.cache_ttl 时的缓存有效期（秒）
+	defaultCacheTTL = 300
+)
+
+func init() {
+	// 新账单写入后统计结果失效
+	billing.OnIngest(func(uuid.UUID, billing.Provider, string) {
+		Invalidate()
+	})
+}
+
+// Invalidate 使全部统计缓存失效，并通知在线用户刷新仪表盘
+func Invalidate() {
+	if rdb := database.GetRedis(); rdb != nil {
+		if err := rdb.Incr(context.Background(), versionKey).Err(); err != nil {
+			logger.GetLogger().Error("统计缓存失效失败:", err)
+		}
+	}
+	events.Broadcast(events.TypeDashboardInvalidated, events.DashboardInvalidated{Scope: "reports"})
+}
+
+// cacheTTL 统计缓存有效期
+func cacheTTL() time.Duration {
+	ttl := viper.GetInt("report.cache_ttl")
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+// cached 优先读取Redis缓存，未命中或Redis不可用时计算并写入缓存
+func cached[T any](key string, compute func() (*T, error)) (*T, error) {
+	rdb := database.GetRedis()
+	if rdb == nil {
+		return compute()
+	}
+
+	ctx := context.Background()
+	version, err := rdb.Get(ctx, versionKey).Result()
+	if err != nil {
+		version = "0"
+	}
+	fullKey := cacheKeyPrefix + "v" + version + ":" + key
+	if raw, err := rdb.Get(ctx, fullKey).Bytes(); err == nil {
+		var v T
+		if json.Unmarshal(raw, &v) == nil {
+			return &v, nil
+		}
+	}
+
+	v, err := compute()
+	if err != nil {
+		return nil, err
+	}
+	if raw, err := json.Marshal(v); err == nil {
+		if err := rdb.Set(ctx, fullKey, raw, cacheTTL()).Err(); err != nil {
+			logger.GetLogger().Warn("写入统计缓存失败:", err)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,165 @@
+package report
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	reportSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		reportSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// GetDashboard 获取控制台统计
+// @Summary 获取控制台统计
+// @Description 获取本月与上月账单花费、返佣待发放与已发放金额、客户和合同数量及即将到期的合同，金额为报告币种。
+// @Description 结果缓存 report.cache_ttl 秒，新账单写入后失效
+// @Tags 统计报表
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param expiring_days query int false "即将到期天数（1-365）" default(30)
+// @Success 200 {object} DashboardResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "缺少汇率"
+// @Router /reports/dashboard [get]
+func (h *Handler) GetDashboard(c *gin.Context) {
+	days, _ := strconv.Atoi(c.DefaultQuery("expiring_days", strconv.Itoa(DefaultExpiringDays)))
+
+	dashboard, err := h.reportSvc.Dashboard(days)
+	if err != nil {
+		h.respondError(c, "获取控制台统计失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DashboardResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *dashboard,
+	})
+}
+
+// GetSpendByProvider 按云平台的花费趋势
+// @Summary 按云平台的花费趋势
+// @Description 获取起止计费周期内按云平台分组的月度账单花费（折后金额，报告币种），默认最近6个月
+// @Tags 统计报表
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start query string false "开始计费周期（YYYY-MM）"
+// @Param end query string false "结束计费周期（YYYY-MM），默认当月"
+// @Success 200 {object} SpendSeriesResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reports/spend/providers [get]
+func (h *Handler) GetSpendByProvider(c *gin.Context) {
+	h.spendSeries(c, DimensionProvider)
+}
+
+// GetSpendByCustomer 按客户的花费趋势
+// @Summary 按客户的花费趋势
+// @Description 获取起止计费周期内花费最高的客户的月度账单花费（折后金额，报告币种），其余客户合并为 other，默认最近6个月
+// @Tags 统计报表
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start query string false "开始计费周期（YYYY-MM）"
+// @Param end query string false "结束计费周期（YYYY-MM），默认当月"
+// @Param limit query int false "返回的客户数（1-50）" default(10)
+// @Success 200 {object} SpendSeriesResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reports/spend/customers [get]
+func (h *Handler) GetSpendByCustomer(c *gin.Context) {
+	h.spendSeries(c, DimensionCustomer)
+}
+
+// GetSpendByServiceType 按服务类型的花费趋势
+// @Summary 按服务类型的花费趋势
+// @Description 获取起止计费周期内花费最高的服务类型的月度账单花费（折后金额，报告币种），其余服务类型合并为 other，默认最近6个月
+// @Tags 统计报表
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start query string false "开始计费周期（YYYY-MM）"
+// @Param end query string false "结束计费周期（YYYY-MM），默认当月"
+// @Param limit query int false "返回的服务类型数（1-50）" default(10)
+// @Success 200 {object} SpendSeriesResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /reports/spend/service-types [get]
+func (h *Handler) GetSpendByServiceType(c *gin.Context) {
+	h.spendSeries(c, DimensionServiceType)
+}
+
+func (h *Handler) spendSeries(c *gin.Context, dim Dimension) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultSeriesLimit)))
+	series, err := h.reportSvc.SpendSeries(dim, SeriesQuery{
+		Start: c.Query("start"),
+		End:   c.Query("end"),
+		Limit: limit,
+	})
+	if err != nil {
+		h.respondError(c, "获取花费趋势失败:", err)
+		return
+	}
+
+	for i := range series.Series {
+		if series.Series[i].Key == OtherKey {
+			series.Series[i].Name = i18n.T(c, "report.other")
+		}
+	}
+	c.JSON(http.StatusOK, SpendSeriesResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *series,
+	})
+}
+
+// respondError 业务错误返回400，其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type DashboardResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    Dashboard `json:"data"`
+}
+
+type SpendSeriesResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    SpendSeries `json:"data"`
+}
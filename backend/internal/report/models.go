@@ -0,0 +1,108 @@
+package report
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/internal/credit"
+)
+
+// Dimension 花费时间序列的分组维度
+type Dimension string
+
+const (
+	DimensionProvider    Dimension = "provider"
+	DimensionCustomer    Dimension = "customer"
+	DimensionServiceType Dimension = "service_type"
+)
+
+// Dashboard 控制台统计，金额均为报告币种
+type Dashboard struct {
+	Currency       string          `json:"currency"`
+	Period         string          `json:"period"`
+	PreviousPeriod string          `json:"previous_period"`
+	Spend          SpendStats      `json:"spend"`
+	Commission     CommissionStats `json:"commission"`
+	Customers      CustomerStats   `json:"customers"`
+	Contracts      ContractStats   `json:"contracts"`
+	Credit         CreditStats     `json:"credit"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// SpendStats 本月与上月的账单花费（折后金额）
+type SpendStats struct {
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	Change   float64 `json:"change"`
+	// ChangeRate 环比变化率，上月花费为 0 时为空
+	ChangeRate *float64 `json:"change_rate,omitempty"`
+}
+
+// CommissionStats 返佣待发放与已发放统计（含调整记录）
+type CommissionStats struct {
+	PendingAmount float64 `json:"pending_amount"`
+	PendingCount  int64   `json:"pending_count"`
+	PaidAmount    float64 `json:"paid_amount"`
+	PaidCount     int64   `json:"paid_count"`
+	PaidThisMonth float64 `json:"paid_this_month"`
+}
+
+// CustomerStats 客户数量统计
+type CustomerStats struct {
+	Total  int64 `json:"total"`
+	Active int64 `json:"active"`
+}
+
+// CreditStats 信用额度预警统计，Breaches 为使用率最高的预警客户
+type CreditStats struct {
+	Warning  int               `json:"warning"`
+	Exceeded int               `json:"exceeded"`
+	Breaches []credit.Exposure `json:"breaches"`
+}
+
+// ContractStats 合同数量统计
+type ContractStats struct {
+	Total        int64              `json:"total"`
+	Active       int64              `json:"active"`
+	ExpiringDays int                `json:"expiring_days"`
+	ExpiringSoon int64              `json:"expiring_soon"`
+	Expiring     []ExpiringContract `json:"expiring"`
+}
+
+// ExpiringContract 即将到期的合同
+type ExpiringContract struct {
+	ID           uuid.UUID `json:"id"`
+	ContractNo   string    `json:"contract_no"`
+	Title        string    `json:"title"`
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerName string    `json:"customer_name"`
+	EndDate      time.Time `json:"end_date"`
+	DaysLeft     int       `json:"days_left"`
+}
+
+// SpendSeries 按维度分组的月度花费时间序列
+type SpendSeries struct {
+	Dimension Dimension `json:"dimension"`
+	Currency  string    `json:"currency"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	Periods   []string  `json:"periods"`
+	Series    []Series  `json:"series"`
+	Total     float64   `json:"total"`
+}
+
+// Series 单个分组的时间序列，Points 与 Periods 一一对应
+type Series struct {
+	Key    string    `json:"key"`
+	Name   string    `json:"name"`
+	Total  float64   `json:"total"`
+	Points []float64 `json:"points"`
+}
+
+// SeriesQuery 时间序列查询参数
+type SeriesQuery struct {
+	Start string
+	End   string
+	Limit int
+}
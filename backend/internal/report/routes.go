@@ -0,0 +1,16 @@
+package report
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterRoutes 注册统计报表相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/dashboard", handler.GetDashboard)
+	router.GET("/spend/providers", handler.GetSpendByProvider)
+	router.GET("/spend/customers", handler.GetSpendByCustomer)
+	router.GET("/spend/service-types", handler.GetSpendByServiceType)
+}
@@ -0,0 +1,404 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/credit"
+	"xcloud-backend/internal/currency"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// DefaultExpiringDays 默认的合同即将到期天数
+	DefaultExpiringDays = 30
+	// MaxExpiringDays 即将到期天数上限
+	MaxExpiringDays = 365
+	// expiringListSize 控制台列出的即将到期合同数量
+	expiringListSize = 10
+	// breachListSize 控制台列出的信用额度预警客户数量
+	breachListSize = 10
+	// defaultSeriesMonths 未指定起止周期时的时间序列月数（含当月）
+	defaultSeriesMonths = 6
+	// maxSeriesMonths 时间序列最多包含的月数
+	maxSeriesMonths = 36
+	// DefaultSeriesLimit 按客户、服务类型分组时默认返回的分组数，其余合并为 OtherKey
+	DefaultSeriesLimit = 10
+	// MaxSeriesLimit 分组数上限
+	MaxSeriesLimit = 50
+	// OtherKey 超出分组数的合并分组
+	OtherKey = "other"
+)
+
+// Service 统计报表服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建统计报表服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Dashboard 控制台统计，结果按 report.cache_ttl 缓存，新账单写入后失效
+func (s *Service) Dashboard(expiringDays int) (*Dashboard, error) {
+	if expiringDays < 1 || expiringDays > MaxExpiringDays {
+		expiringDays = DefaultExpiringDays
+	}
+	return cached(fmt.Sprintf("dashboard:%d", expiringDays), func() (*Dashboard, error) {
+		return s.dashboard(expiringDays)
+	})
+}
+
+func (s *Service) dashboard(expiringDays int) (*Dashboard, error) {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	d := &Dashboard{
+		Currency:       currency.NewService(s.db).ReportingCurrency(),
+		Period:         billing.PeriodOf(monthStart),
+		PreviousPeriod: billing.PeriodOf(monthStart.AddDate(0, -1, 0)),
+		GeneratedAt:    now,
+	}
+
+	var err error
+	if d.Spend.Current, err = s.periodSpend(d.Period, d.Currency); err != nil {
+		return nil, err
+	}
+	if d.Spend.Previous, err = s.periodSpend(d.PreviousPeriod, d.Currency); err != nil {
+		return nil, err
+	}
+	d.Spend.Change = round(d.Spend.Current - d.Spend.Previous)
+	if d.Spend.Previous != 0 {
+		rate := math.Round(d.Spend.Change/d.Spend.Previous*10000) / 10000
+		d.Spend.ChangeRate = &rate
+	}
+
+	if err := s.commissionStats(&d.Commission, monthStart); err != nil {
+		return nil, err
+	}
+	if err := s.customerStats(&d.Customers); err != nil {
+		return nil, err
+	}
+	if err := s.contractStats(&d.Contracts, expiringDays, now); err != nil {
+		return nil, err
+	}
+	if err := s.creditStats(&d.Credit); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// creditStats 统计达到预警阈值和超过信用额度的客户
+func (s *Service) creditStats(stats *CreditStats) error {
+	breaches, err := credit.NewService(s.db).Breaches(0)
+	if err != nil {
+		return fmt.Errorf("统计信用额度预警失败: %w", err)
+	}
+	for _, b := range breaches {
+		if b.Level == credit.LevelExceeded {
+			stats.Exceeded++
+		} else {
+			stats.Warning++
+		}
+	}
+	if len(breaches) > breachListSize {
+		breaches = breaches[:breachListSize]
+	}
+	stats.Breaches = breaches
+	return nil
+}
+
+// currencyAmount 按币种汇总的金额
+type currencyAmount struct {
+	Currency string
+	Amount   float64
+}
+
+// periodSpend 计费周期的账单折后金额，折算为报告币种
+func (s *Service) periodSpend(period, reporting string) (float64, error) {
+	var rows []currencyAmount
+	err := s.db.Model(&billing.BillingData{}).
+		Select("currency, SUM(discounted_cost) AS amount").
+		Where("billing_period = ?", period).
+		Group("currency").
+		Scan(&rows).Error
+	if err != nil {
+		return 0, fmt.Errorf("汇总账单花费失败: %w", err)
+	}
+
+	conv := currency.NewConverter(s.db, period)
+	var total float64
+	for _, r := range rows {
+		amount, err := conv.Convert(r.Amount, r.Currency, reporting)
+		if err != nil {
+			return 0, err
+		}
+		total += amount
+	}
+	return round(total), nil
+}
+
+// commissionStats 统计待发放（待计算、已计算）和已发放的返佣
+func (s *Service) commissionStats(stats *CommissionStats, monthStart time.Time) error {
+	var rows []struct {
+		Status commission.RecordStatus
+		Count  int64
+		Amount float64
+	}
+	err := s.db.Model(&commission.Record{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(commission_amount_reporting), 0) AS amount").
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return fmt.Errorf("统计返佣失败: %w", err)
+	}
+	for _, r := range rows {
+		if r.Status == commission.StatusPaid {
+			stats.PaidAmount += r.Amount
+			stats.PaidCount += r.Count
+		} else {
+			stats.PendingAmount += r.Amount
+			stats.PendingCount += r.Count
+		}
+	}
+	stats.PendingAmount = round(stats.PendingAmount)
+	stats.PaidAmount = round(stats.PaidAmount)
+
+	err = s.db.Model(&commission.Record{}).
+		Select("COALESCE(SUM(commission_amount_reporting), 0)").
+		Where("status = ? AND paid_at >= ?", commission.StatusPaid, monthStart).
+		Scan(&stats.PaidThisMonth).Error
+	if err != nil {
+		return fmt.Errorf("统计本月已发放返佣失败: %w", err)
+	}
+	stats.PaidThisMonth = round(stats.PaidThisMonth)
+	return nil
+}
+
+func (s *Service) customerStats(stats *CustomerStats) error {
+	if err := s.db.Model(&customer.Customer{}).Count(&stats.Total).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&customer.Customer{}).Where("status = ?", customer.StatusActive).Count(&stats.Active).Error
+}
+
+// contractStats 统计合同数量及 expiringDays 天内到期的生效合同
+func (s *Service) contractStats(stats *ContractStats, expiringDays int, now time.Time) error {
+	if err := s.db.Model(&contract.Contract{}).Count(&stats.Total).Error; err != nil {
+		return err
+	}
+	if err := s.db.Model(&contract.Contract{}).Where("status = ?", contract.StatusActive).Count(&stats.Active).Error; err != nil {
+		return err
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	until := today.AddDate(0, 0, expiringDays)
+	stats.ExpiringDays = expiringDays
+	expiring := s.db.Model(&contract.Contract{}).
+		Where("contracts.status = ? AND contracts.end_date >= ? AND contracts.end_date <= ?", contract.StatusActive, today, until).
+		Session(&gorm.Session{})
+	if err := expiring.Count(&stats.ExpiringSoon).Error; err != nil {
+		return err
+	}
+
+	stats.Expiring = []ExpiringContract{}
+	err := expiring.
+		Select("contracts.id, contracts.contract_no, contracts.title, contracts.customer_id, customers.company_name AS customer_name, contracts.end_date").
+		Joins("LEFT JOIN customers ON customers.id = contracts.customer_id").
+		Order("contracts.end_date, contracts.contract_no").
+		Limit(expiringListSize).
+		Scan(&stats.Expiring).Error
+	if err != nil {
+		return fmt.Errorf("查询即将到期合同失败: %w", err)
+	}
+	for i := range stats.Expiring {
+		end := stats.Expiring[i].EndDate
+		endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local)
+		stats.Expiring[i].DaysLeft = int(endDay.Sub(today).Hours() / 24)
+	}
+	return nil
+}
+
+// SpendSeries 按维度分组的月度花费时间序列，结果按 report.cache_ttl 缓存，新账单写入后失效
+func (s *Service) SpendSeries(dim Dimension, q SeriesQuery) (*SpendSeries, error) {
+	column, ok := dimensionColumns[dim]
+	if !ok {
+		return nil, i18n.NewError("report.invalid_dimension")
+	}
+	periods, err := seriesPeriods(q.Start, q.End)
+	if err != nil {
+		return nil, err
+	}
+	limit := q.Limit
+	if dim == DimensionProvider {
+		limit = 0
+	} else if limit < 1 || limit > MaxSeriesLimit {
+		limit = DefaultSeriesLimit
+	}
+
+	key := fmt.Sprintf("spend:%s:%s:%s:%d", dim, periods[0], periods[len(periods)-1], limit)
+	return cached(key, func() (*SpendSeries, error) {
+		return s.spendSeries(dim, column, periods, limit)
+	})
+}
+
+// dimensionColumns 分组维度对应的账单字段
+var dimensionColumns = map[Dimension]string{
+	DimensionProvider:    "provider",
+	DimensionCustomer:    "customer_id",
+	DimensionServiceType: "service_type",
+}
+
+// seriesPeriods 解析起止周期，返回其间的全部计费周期
+func seriesPeriods(start, end string) ([]string, error) {
+	now := time.Now()
+	endMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	if end != "" {
+		t, err := billing.ParsePeriod(end)
+		if err != nil {
+			return nil, i18n.NewError("report.invalid_period")
+		}
+		endMonth = t
+	}
+	startMonth := endMonth.AddDate(0, 1-defaultSeriesMonths, 0)
+	if start != "" {
+		t, err := billing.ParsePeriod(start)
+		if err != nil {
+			return nil, i18n.NewError("report.invalid_period")
+		}
+		startMonth = t
+	}
+	if startMonth.After(endMonth) || !startMonth.AddDate(0, maxSeriesMonths, 0).After(endMonth) {
+		return nil, i18n.NewError("report.invalid_range", maxSeriesMonths)
+	}
+
+	var periods []string
+	for m := startMonth; !m.After(endMonth); m = m.AddDate(0, 1, 0) {
+		periods = append(periods, billing.PeriodOf(m))
+	}
+	return periods, nil
+}
+
+func (s *Service) spendSeries(dim Dimension, column string, periods []string, limit int) (*SpendSeries, error) {
+	result := &SpendSeries{
+		Dimension: dim,
+		Currency:  currency.NewService(s.db).ReportingCurrency(),
+		Start:     periods[0],
+		End:       periods[len(periods)-1],
+		Periods:   periods,
+		Series:    []Series{},
+	}
+
+	var rows []struct {
+		BillingPeriod string
+		Key           string
+		Currency      string
+		Amount        float64
+	}
+	err := s.db.Model(&billing.BillingData{}).
+		Select(fmt.Sprintf("billing_period, %s::text AS key, currency, SUM(discounted_cost) AS amount", column)).
+		Where("billing_period >= ? AND billing_period <= ?", result.Start, result.End).
+		Group(fmt.Sprintf("billing_period, %s, currency", column)).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("汇总账单花费失败: %w", err)
+	}
+
+	index := make(map[string]int, len(periods))
+	converters := make(map[string]*currency.Converter, len(periods))
+	for i, p := range periods {
+		index[p] = i
+		converters[p] = currency.NewConverter(s.db, p)
+	}
+	byKey := map[string]*Series{}
+	for _, r := range rows {
+		amount, err := converters[r.BillingPeriod].Convert(r.Amount, r.Currency, result.Currency)
+		if err != nil {
+			return nil, err
+		}
+		series, ok := byKey[r.Key]
+		if !ok {
+			series = &Series{Key: r.Key, Name: r.Key, Points: make([]float64, len(periods))}
+			byKey[r.Key] = series
+		}
+		series.Points[index[r.BillingPeriod]] += amount
+		series.Total += amount
+	}
+
+	for _, series := range byKey {
+		result.Series = append(result.Series, *series)
+	}
+	sort.Slice(result.Series, func(i, j int) bool {
+		if result.Series[i].Total != result.Series[j].Total {
+			return result.Series[i].Total > result.Series[j].Total
+		}
+		return result.Series[i].Key < result.Series[j].Key
+	})
+	if limit > 0 && len(result.Series) > limit {
+		other := Series{Key: OtherKey, Name: OtherKey, Points: make([]float64, len(periods))}
+		for _, series := range result.Series[limit:] {
+			for i, v := range series.Points {
+				other.Points[i] += v
+			}
+			other.Total += series.Total
+		}
+		result.Series = append(result.Series[:limit], other)
+	}
+
+	if dim == DimensionCustomer {
+		if err := s.customerNames(result.Series); err != nil {
+			return nil, err
+		}
+	}
+	for i := range result.Series {
+		series := &result.Series[i]
+		for j := range series.Points {
+			series.Points[j] = round(series.Points[j])
+		}
+		series.Total = round(series.Total)
+		result.Total += series.Total
+	}
+	result.Total = round(result.Total)
+	return result, nil
+}
+
+// customerNames 将客户分组的名称设置为客户公司名称（包含已删除的客户）
+func (s *Service) customerNames(series []Series) error {
+	var ids []uuid.UUID
+	for _, item := range series {
+		if id, err := uuid.Parse(item.Key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var customers []customer.Customer
+	if err := s.db.Unscoped().Select("id, company_name").Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		return fmt.Errorf("查询客户失败: %w", err)
+	}
+	names := make(map[string]string, len(customers))
+	for _, c := range customers {
+		names[c.ID.String()] = c.CompanyName
+	}
+	for i := range series {
+		if name, ok := names[series[i].Key]; ok {
+			series[i].Name = name
+		}
+	}
+	return nil
+}
+
+// round 金额保留两位小数
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,164 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	searchSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		searchSvc: NewService(db),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// Search 搜索客户和合同
+// @Summary 搜索客户和合同
+// @Description 客户按编码、公司名称、联系人匹配，关键词只包含英文字母时还匹配公司名称的拼音首字母；合同按合同编号和标题匹配。
+// @Description 关键词先按 simple 分词全文匹配，同时做模糊匹配（支持中文片段），结果按相关度排序。
+// @Description 每类对象返回一页结果和状态、客户级别、业务类型、云平台分面统计，每个分面按除自身以外的过滤条件统计。
+// @Description 状态过滤需对所有请求的对象有效，如同时搜索客户和合同时只能使用 active
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string false "搜索关键词"
+// @Param types query string false "搜索对象，逗号分隔，默认全部" example(customer,contract)
+// @Param status query string false "状态"
+// @Param level query int false "客户级别"
+// @Param business_type query string false "客户业务类型"
+// @Param provider query string false "云平台" Enums(tencent, alibaba, huawei, aws)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} SearchResponse "搜索成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /search [get]
+func (h *Handler) Search(c *gin.Context) {
+	types, err := ParseTypes(c.Query("types"))
+	if err != nil {
+		h.respondError(c, "搜索失败:", err)
+		return
+	}
+	filter := Filter{
+		Status:       c.Query("status"),
+		BusinessType: c.Query("business_type"),
+		Provider:     billing.Provider(c.Query("provider")),
+	}
+	if raw := c.Query("level"); raw != "" {
+		level, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+			})
+			return
+		}
+		filter.Level = level
+	}
+	page, pageSize := pagination(c)
+
+	result, err := h.searchSvc.Search(c.Query("q"), types, filter, page, pageSize)
+	if err != nil {
+		h.respondError(c, "搜索失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *result,
+	})
+}
+
+// Typeahead 输入联想
+// @Summary 输入联想
+// @Description 供前端客户、合同选择器使用，按相关度返回与关键词匹配的候选项，匹配规则与搜索相同；关键词为空时返回空列表
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "关键词"
+// @Param type query string false "搜索对象" Enums(customer, contract) default(customer)
+// @Param status query string false "只返回该状态的对象，如 active"
+// @Param limit query int false "返回数量，最多20" default(10)
+// @Success 200 {object} SuggestionListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /search/typeahead [get]
+func (h *Handler) Typeahead(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	suggestions, err := h.searchSvc.Typeahead(c.Query("q"), Type(c.DefaultQuery("type", string(TypeCustomer))), c.Query("status"), limit)
+	if err != nil {
+		h.respondError(c, "输入联想失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SuggestionListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    suggestions,
+	})
+}
+
+// respondError 业务错误返回400，其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type SearchResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Result `json:"data"`
+}
+
+type SuggestionListResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    []Suggestion `json:"data"`
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"github.com/google/uuid"
+
+	"xcloud-backend/internal/billing"
+)
+
+// Type 搜索对象
+type Type string
+
+const (
+	TypeCustomer Type = "customer" // 客户
+	TypeContract Type = "contract" // 合同
+)
+
+// IsValid 检查搜索对象是否有效
+func (t Type) IsValid() bool {
+	switch t {
+	case TypeCustomer, TypeContract:
+		return true
+	default:
+		return false
+	}
+}
+
+// Filter 分面过滤条件，零值表示不过滤
+// 客户按自身的状态、级别、业务类型和绑定的云平台过滤；合同按合同状态、返佣规则的云平台以及所属客户的级别和业务类型过滤
+type Filter struct {
+	Status       string
+	Level        int
+	BusinessType string
+	Provider     billing.Provider
+}
+
+// FacetValue 分面取值及命中数
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// Facets 分面统计，每个分面按除自身以外的过滤条件统计，便于切换取值
+type Facets struct {
+	Status       []FacetValue `json:"status"`
+	Level        []FacetValue `json:"level"`
+	BusinessType []FacetValue `json:"business_type"`
+	Provider     []FacetValue `json:"provider"`
+}
+
+// CustomerHit 客户搜索结果
+type CustomerHit struct {
+	ID           uuid.UUID `json:"id"`
+	CustomerCode string    `json:"customer_code"`
+	CompanyName  string    `json:"company_name"`
+	ContactName  string    `json:"contact_name"`
+	Status       string    `json:"status"`
+	Level        int       `json:"level"`
+	BusinessType string    `json:"business_type"`
+}
+
+// ContractHit 合同搜索结果
+type ContractHit struct {
+	ID           uuid.UUID `json:"id"`
+	ContractNo   string    `json:"contract_no"`
+	Title        string    `json:"title"`
+	Status       string    `json:"status"`
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerName string    `json:"customer_name"`
+	StartDate    string    `json:"start_date"`
+	EndDate      string    `json:"end_date"`
+}
+
+// CustomerResults 客户搜索结果及分面
+type CustomerResults struct {
+	Items  []CustomerHit `json:"items"`
+	Total  int64         `json:"total"`
+	Facets Facets        `json:"facets"`
+}
+
+// ContractResults 合同搜索结果及分面
+type ContractResults struct {
+	Items  []ContractHit `json:"items"`
+	Total  int64         `json:"total"`
+	Facets Facets        `json:"facets"`
+}
+
+// Result 搜索结果，只包含请求的搜索对象
+type Result struct {
+	Query     string           `json:"query"`
+	Page      int              `json:"page"`
+	PageSize  int              `json:"page_size"`
+	Customers *CustomerResults `json:"customers,omitempty"`
+	Contracts *ContractResults `json:"contracts,omitempty"`
+}
+
+// Suggestion 输入联想候选项
+type Suggestion struct {
+	Type   Type      `json:"type"`
+	ID     uuid.UUID `json:"id"`
+	Code   string    `json:"code"`
+	Label  string    `json:"label"`
+	Status string    `json:"status"`
+	// Extra 辅助信息：客户为联系人，合同为客户名称
+	Extra string `json:"extra,omitempty"`
+}
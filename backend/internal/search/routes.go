@@ -0,0 +1,14 @@
+package search
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// RegisterRoutes 注册客户和合同搜索路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("", handler.Search)
+	router.GET("/typeahead", handler.Typeahead)
+}
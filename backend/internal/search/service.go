@@ -0,0 +1,308 @@
+package search
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/pkg/fulltext"
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// maxFacetValues 每个分面最多返回的取值数
+	maxFacetValues = 20
+	// MaxSuggestions 输入联想最多返回的候选项数
+	MaxSuggestions = 20
+)
+
+// Service 客户和合同搜索服务
+type Service struct {
+	db          *gorm.DB
+	customerSvc *customer.Service
+	contractSvc *contract.Service
+}
+
+// NewService 创建搜索服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{
+		db:          db,
+		customerSvc: customer.NewService(db),
+		contractSvc: contract.NewService(db),
+	}
+}
+
+// dimension 分面：expr 为分组表达式，joins 为统计该分面需要的关联
+type dimension struct {
+	expr  string
+	joins []string
+}
+
+var customerDimensions = map[string]dimension{
+	"status":        {expr: "CAST(customers.status AS TEXT)"},
+	"level":         {expr: "CAST(customers.level AS TEXT)"},
+	"business_type": {expr: "customers.business_type"},
+	"provider": {
+		expr: "CAST(p.provider AS TEXT)",
+		joins: []string{
+			"JOIN customer_cloud_configs cc ON cc.customer_id = customers.id AND cc.deleted_at IS NULL",
+			"JOIN cloud_providers p ON p.id = cc.provider_id",
+		},
+	},
+}
+
+var contractDimensions = map[string]dimension{
+	"status":        {expr: "CAST(contracts.status AS TEXT)"},
+	"level":         {expr: "CAST(cu.level AS TEXT)", joins: []string{"JOIN customers cu ON cu.id = contracts.customer_id"}},
+	"business_type": {expr: "cu.business_type", joins: []string{"JOIN customers cu ON cu.id = contracts.customer_id"}},
+	"provider": {
+		expr:  "CAST(r.provider AS TEXT)",
+		joins: []string{"JOIN commission_rules r ON r.contract_id = contracts.id AND r.deleted_at IS NULL"},
+	},
+}
+
+// ParseTypes 解析逗号分隔的搜索对象，为空时搜索全部对象
+func ParseTypes(raw string) ([]Type, error) {
+	if strings.TrimSpace(raw) == "" {
+		return []Type{TypeCustomer, TypeContract}, nil
+	}
+	var types []Type
+	seen := map[Type]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		t := Type(strings.TrimSpace(part))
+		if !t.IsValid() {
+			return nil, i18n.NewError("search.invalid_type", string(t))
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types, nil
+}
+
+// Search 在指定对象中搜索关键词，按相关度返回每类对象的一页结果和分面统计
+func (s *Service) Search(q string, types []Type, filter Filter, page, pageSize int) (*Result, error) {
+	q = fulltext.Normalize(q)
+	result := &Result{Query: q, Page: page, PageSize: pageSize}
+	for _, t := range types {
+		switch t {
+		case TypeCustomer:
+			if filter.Status != "" && !customer.CustomerStatus(filter.Status).IsValid() {
+				return nil, i18n.NewError("search.invalid_status", filter.Status, string(t))
+			}
+			customers, err := s.searchCustomers(q, filter, page, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			result.Customers = customers
+		case TypeContract:
+			if filter.Status != "" && !contract.ContractStatus(filter.Status).IsValid() {
+				return nil, i18n.NewError("search.invalid_status", filter.Status, string(t))
+			}
+			contracts, err := s.searchContracts(q, filter, page, pageSize)
+			if err != nil {
+				return nil, err
+			}
+			result.Contracts = contracts
+		}
+	}
+	return result, nil
+}
+
+func customerFilter(q string, f Filter) customer.ListFilter {
+	return customer.ListFilter{
+		Search:       q,
+		Status:       customer.CustomerStatus(f.Status),
+		Level:        f.Level,
+		BusinessType: f.BusinessType,
+		Provider:     f.Provider,
+	}
+}
+
+func contractFilter(q string, f Filter) contract.ListFilter {
+	return contract.ListFilter{
+		Search:        q,
+		Status:        contract.ContractStatus(f.Status),
+		CustomerLevel: f.Level,
+		BusinessType:  f.BusinessType,
+		Provider:      f.Provider,
+	}
+}
+
+func (s *Service) searchCustomers(q string, filter Filter, page, pageSize int) (*CustomerResults, error) {
+	customers, total, err := s.customerSvc.Search(customerFilter(q, filter), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	results := &CustomerResults{Items: make([]CustomerHit, 0, len(customers)), Total: total}
+	for _, c := range customers {
+		results.Items = append(results.Items, CustomerHit{
+			ID:           c.ID,
+			CustomerCode: c.CustomerCode,
+			CompanyName:  c.CompanyName,
+			ContactName:  c.ContactName,
+			Status:       string(c.Status),
+			Level:        c.Level,
+			BusinessType: c.BusinessType,
+		})
+	}
+
+	results.Facets, err = s.facets(customerDimensions, "customers.id", filter, func(f Filter) *gorm.DB {
+		return customerFilter(q, f).Scope(s.db.Model(&customer.Customer{}))
+	})
+	return results, err
+}
+
+func (s *Service) searchContracts(q string, filter Filter, page, pageSize int) (*ContractResults, error) {
+	contracts, total, err := s.contractSvc.Search(contractFilter(q, filter), page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	names, err := s.customerNames(contracts)
+	if err != nil {
+		return nil, err
+	}
+	results := &ContractResults{Items: make([]ContractHit, 0, len(contracts)), Total: total}
+	for _, ct := range contracts {
+		results.Items = append(results.Items, ContractHit{
+			ID:           ct.ID,
+			ContractNo:   ct.ContractNo,
+			Title:        ct.Title,
+			Status:       string(ct.Status),
+			CustomerID:   ct.CustomerID,
+			CustomerName: names[ct.CustomerID],
+			StartDate:    ct.StartDate.Format("2006-01-02"),
+			EndDate:      ct.EndDate.Format("2006-01-02"),
+		})
+	}
+
+	results.Facets, err = s.facets(contractDimensions, "contracts.id", filter, func(f Filter) *gorm.DB {
+		return contractFilter(q, f).Scope(s.db.Model(&contract.Contract{}))
+	})
+	return results, err
+}
+
+// facets 统计各分面，每个分面去掉自身的过滤条件
+func (s *Service) facets(dims map[string]dimension, id string, filter Filter, base func(Filter) *gorm.DB) (Facets, error) {
+	var facets Facets
+	for name, target := range map[string]*[]FacetValue{
+		"status":        &facets.Status,
+		"level":         &facets.Level,
+		"business_type": &facets.BusinessType,
+		"provider":      &facets.Provider,
+	} {
+		f := filter
+		switch name {
+		case "status":
+			f.Status = ""
+		case "level":
+			f.Level = 0
+		case "business_type":
+			f.BusinessType = ""
+		case "provider":
+			f.Provider = ""
+		}
+
+		dim := dims[name]
+		query := base(f)
+		for _, join := range dim.joins {
+			query = query.Joins(join)
+		}
+		values := []FacetValue{}
+		err := query.Select(dim.expr + " AS value, COUNT(DISTINCT " + id + ") AS count").
+			Where(dim.expr + " IS NOT NULL AND " + dim.expr + " <> ''").
+			Group(dim.expr).
+			Order("count DESC, value").
+			Limit(maxFacetValues).
+			Scan(&values).Error
+		if err != nil {
+			return facets, err
+		}
+		*target = values
+	}
+	return facets, nil
+}
+
+// customerNames 合同客户的公司名称
+func (s *Service) customerNames(contracts []contract.Contract) (map[uuid.UUID]string, error) {
+	names := map[uuid.UUID]string{}
+	if len(contracts) == 0 {
+		return names, nil
+	}
+	ids := make([]uuid.UUID, 0, len(contracts))
+	for _, ct := range contracts {
+		ids = append(ids, ct.CustomerID)
+	}
+	var customers []customer.Customer
+	if err := s.db.Unscoped().Select("id, company_name").Where("id IN ?", ids).Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	for _, c := range customers {
+		names[c.ID] = c.CompanyName
+	}
+	return names, nil
+}
+
+// Typeahead 输入联想：按相关度返回与关键词匹配的客户或合同，供前端选择器使用
+// status 不为空时只返回该状态的对象，如新建合同时只选择活跃客户
+func (s *Service) Typeahead(q string, t Type, status string, limit int) ([]Suggestion, error) {
+	if !t.IsValid() {
+		return nil, i18n.NewError("search.invalid_type", string(t))
+	}
+	q = fulltext.Normalize(q)
+	suggestions := []Suggestion{}
+	if q == "" {
+		return suggestions, nil
+	}
+	if limit < 1 || limit > MaxSuggestions {
+		limit = 10
+	}
+
+	switch t {
+	case TypeCustomer:
+		if status != "" && !customer.CustomerStatus(status).IsValid() {
+			return nil, i18n.NewError("search.invalid_status", status, string(t))
+		}
+		var rows []customer.Customer
+		err := customerFilter(q, Filter{Status: status}).Scope(s.db.Model(&customer.Customer{})).
+			Select("id, customer_code, company_name, contact_name, status").
+			Scopes(customer.SearchFields.Order(q, "customers.customer_code")).
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range rows {
+			suggestions = append(suggestions, Suggestion{
+				Type: t, ID: c.ID, Code: c.CustomerCode, Label: c.CompanyName, Status: string(c.Status), Extra: c.ContactName,
+			})
+		}
+	case TypeContract:
+		if status != "" && !contract.ContractStatus(status).IsValid() {
+			return nil, i18n.NewError("search.invalid_status", status, string(t))
+		}
+		var rows []contract.Contract
+		err := contractFilter(q, Filter{Status: status}).Scope(s.db.Model(&contract.Contract{})).
+			Select("id, contract_no, title, status, customer_id").
+			Scopes(contract.SearchFields.Order(q, "contracts.contract_no")).
+			Limit(limit).
+			Find(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		names, err := s.customerNames(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, ct := range rows {
+			suggestions = append(suggestions, Suggestion{
+				Type: t, ID: ct.ID, Code: ct.ContractNo, Label: ct.Title, Status: string(ct.Status), Extra: names[ct.CustomerID],
+			})
+		}
+	}
+	return suggestions, nil
+}
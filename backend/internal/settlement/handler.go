@@ -0,0 +1,609 @@
+package settlement
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	settlementSvc *Service
+	logger        *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		settlementSvc: NewService(db),
+		logger:        logger.GetLogger(),
+	}
+}
+
+// CloseSettlement 结算周期关账
+// @Summary 结算周期关账
+// @Description 关账合同在指定计费月份所在的结算周期，生成结算单并锁定周期内已计算的返佣记录（需要管理员或经理权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CloseRequest true "关账参数"
+// @Success 201 {object} SettlementResponse "关账成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /commission/settlements [post]
+func (h *Handler) CloseSettlement(c *gin.Context) {
+	var req CloseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementSvc.Close(uuid.MustParse(req.ContractID), req.Period, &operator)
+	if err != nil {
+		h.respondError(c, "结算关账失败:", err)
+		return
+	}
+
+	h.logger.Info("结算周期已关账:", settlement.SettlementNo, " ", settlement.CycleStart, "~", settlement.CycleEnd)
+	c.JSON(http.StatusCreated, SettlementResponse{
+		Code:    201,
+		Message: i18n.T(c, "settlement.closed"),
+		Data:    *settlement,
+	})
+}
+
+// CloseDueSettlements 批量关账
+// @Summary 批量关账
+// @Description 关账全部合同中已结束且尚未关账的结算周期（需要管理员或经理权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} DueResponse "关账成功"
+// @Failure 400 {object} ErrorResponse "关账失败"
+// @Router /commission/settlements/close-due [post]
+func (h *Handler) CloseDueSettlements(c *gin.Context) {
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.settlementSvc.CloseDue(&operator)
+	if err != nil {
+		h.respondError(c, "批量关账失败:", err)
+		return
+	}
+
+	h.logger.Info("批量关账完成，生成结算单:", len(result.Settlements))
+	c.JSON(http.StatusOK, DueResponse{
+		Code:    200,
+		Message: i18n.T(c, "settlement.closed"),
+		Data:    *result,
+	})
+}
+
+// ListSettlements 获取结算单列表
+// @Summary 获取结算单列表
+// @Description 分页获取结算单，按关账时间倒序
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id query string false "客户ID"
+// @Param contract_id query string false "合同ID"
+// @Param payout_batch_id query string false "付款批次ID"
+// @Param status query string false "状态（closed/cancelled）"
+// @Param unbatched query bool false "仅未进入付款批次的结算单"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} SettlementListResponse "获取成功"
+// @Router /commission/settlements [get]
+func (h *Handler) ListSettlements(c *gin.Context) {
+	page, pageSize := pagination(c)
+	filter := SettlementFilter{
+		Status:    Status(c.Query("status")),
+		Unbatched: c.Query("unbatched") == "true",
+	}
+	for name, target := range map[string]**uuid.UUID{
+		"customer_id":     &filter.CustomerID,
+		"contract_id":     &filter.ContractID,
+		"payout_batch_id": &filter.PayoutBatchID,
+	} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code":    400,
+				"message": i18n.T(c, "common.invalid_params"),
+			})
+			return
+		}
+		*target = &id
+	}
+
+	settlements, total, err := h.settlementSvc.List(filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("获取结算单列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SettlementListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: SettlementListData{
+			Settlements: settlements,
+			Total:       total,
+			Page:        page,
+			PageSize:    pageSize,
+		},
+	})
+}
+
+// GetSettlement 获取结算单
+// @Summary 获取结算单
+// @Description 获取结算单详情
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "结算单ID"
+// @Success 200 {object} SettlementResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "结算单不存在"
+// @Router /commission/settlements/{id} [get]
+func (h *Handler) GetSettlement(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementSvc.Get(id)
+	if err != nil {
+		h.respondError(c, "获取结算单失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, SettlementResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *settlement,
+	})
+}
+
+// ListSettlementRecords 获取结算单返佣记录
+// @Summary 获取结算单返佣记录
+// @Description 分页获取结算单锁定的返佣记录
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "结算单ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} RecordListResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "结算单不存在"
+// @Router /commission/settlements/{id}/records [get]
+func (h *Handler) ListSettlementRecords(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	page, pageSize := pagination(c)
+
+	records, total, err := h.settlementSvc.Records(id, page, pageSize)
+	if err != nil {
+		h.respondError(c, "获取结算单返佣记录失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, RecordListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: RecordListData{
+			Records:  records,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// CancelSettlement 撤销结算单
+// @Summary 撤销结算单
+// @Description 撤销未进入付款批次的结算单，解除返佣记录锁定（需要管理员或经理权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "结算单ID"
+// @Success 200 {object} SettlementResponse "撤销成功"
+// @Failure 400 {object} ErrorResponse "结算单状态不允许撤销"
+// @Failure 404 {object} ErrorResponse "结算单不存在"
+// @Router /commission/settlements/{id}/cancel [post]
+func (h *Handler) CancelSettlement(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementSvc.Cancel(id, &operator)
+	if err != nil {
+		h.respondError(c, "撤销结算单失败:", err)
+		return
+	}
+
+	h.logger.Info("结算单已撤销:", settlement.SettlementNo)
+	c.JSON(http.StatusOK, SettlementResponse{
+		Code:    200,
+		Message: i18n.T(c, "settlement.cancelled"),
+		Data:    *settlement,
+	})
+}
+
+// CreateBatch 创建付款批次
+// @Summary 创建付款批次
+// @Description 将已关账且未进入批次的结算单汇总为付款批次，按客户汇总返佣金额（需要管理员或经理权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateBatchRequest true "批次参数"
+// @Success 201 {object} BatchResponse "创建成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /commission/payout-batches [post]
+func (h *Handler) CreateBatch(c *gin.Context) {
+	var req CreateBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	batch, items, err := h.settlementSvc.CreateBatch(req, &operator)
+	if err != nil {
+		h.respondError(c, "创建付款批次失败:", err)
+		return
+	}
+
+	h.logger.Info("付款批次已创建:", batch.BatchNo, " 金额:", batch.TotalAmount, " ", batch.Currency)
+	c.JSON(http.StatusCreated, BatchResponse{
+		Code:    201,
+		Message: i18n.T(c, "settlement.batch_created"),
+		Data:    BatchData{Batch: *batch, Items: items},
+	})
+}
+
+// ListBatches 获取付款批次列表
+// @Summary 获取付款批次列表
+// @Description 分页获取付款批次，按创建时间倒序
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "状态（draft/approved/paid/cancelled）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} BatchListResponse "获取成功"
+// @Router /commission/payout-batches [get]
+func (h *Handler) ListBatches(c *gin.Context) {
+	page, pageSize := pagination(c)
+	filter := BatchFilter{Status: BatchStatus(c.Query("status"))}
+	if filter.Status != "" && !filter.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return
+	}
+
+	batches, total, err := h.settlementSvc.ListBatches(filter, page, pageSize)
+	if err != nil {
+		h.logger.Error("获取付款批次列表失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "common.internal_error"),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, BatchListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: BatchListData{
+			Batches:  batches,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetBatch 获取付款批次
+// @Summary 获取付款批次
+// @Description 获取付款批次及按客户汇总的返佣金额
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "付款批次ID"
+// @Success 200 {object} BatchResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "付款批次不存在"
+// @Router /commission/payout-batches/{id} [get]
+func (h *Handler) GetBatch(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	batch, items, err := h.settlementSvc.GetBatch(id)
+	if err != nil {
+		h.respondError(c, "获取付款批次失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, BatchResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    BatchData{Batch: *batch, Items: items},
+	})
+}
+
+// ApproveBatch 审批付款批次
+// @Summary 审批付款批次
+// @Description 审批待审批的付款批次（需要管理员权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "付款批次ID"
+// @Success 200 {object} BatchResponse "审批成功"
+// @Failure 400 {object} ErrorResponse "批次状态不允许审批"
+// @Failure 404 {object} ErrorResponse "付款批次不存在"
+// @Router /commission/payout-batches/{id}/approve [post]
+func (h *Handler) ApproveBatch(c *gin.Context) {
+	h.transition(c, "settlement.batch_approved", func(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error) {
+		return h.settlementSvc.Approve(id, operator)
+	})
+}
+
+// PayBatch 登记付款批次已支付
+// @Summary 登记付款批次已支付
+// @Description 登记已审批付款批次的支付参考号，批次内返佣记录标记为已支付（需要管理员权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "付款批次ID"
+// @Param body body PayRequest true "支付信息"
+// @Success 200 {object} BatchResponse "登记成功"
+// @Failure 400 {object} ErrorResponse "批次状态不允许支付"
+// @Failure 404 {object} ErrorResponse "付款批次不存在"
+// @Router /commission/payout-batches/{id}/pay [post]
+func (h *Handler) PayBatch(c *gin.Context) {
+	var req PayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	h.transition(c, "settlement.batch_paid", func(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error) {
+		return h.settlementSvc.Pay(id, req.PaymentReference, operator)
+	})
+}
+
+// CancelBatch 取消付款批次
+// @Summary 取消付款批次
+// @Description 取消未支付的付款批次，批次内结算单可重新进入其他批次（需要管理员或经理权限）
+// @Tags 返佣结算
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "付款批次ID"
+// @Success 200 {object} BatchResponse "取消成功"
+// @Failure 400 {object} ErrorResponse "批次状态不允许取消"
+// @Failure 404 {object} ErrorResponse "付款批次不存在"
+// @Router /commission/payout-batches/{id}/cancel [post]
+func (h *Handler) CancelBatch(c *gin.Context) {
+	h.transition(c, "settlement.batch_cancelled", func(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error) {
+		return h.settlementSvc.CancelBatch(id, operator)
+	})
+}
+
+// transition 付款批次状态变更的公共处理
+func (h *Handler) transition(c *gin.Context, messageCode string, apply func(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error)) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	batch, err := apply(id, &operator)
+	if err != nil {
+		h.respondError(c, "付款批次状态变更失败:", err)
+		return
+	}
+
+	h.logger.Info("付款批次状态变更:", batch.BatchNo, " -> ", batch.Status)
+	_, items, err := h.settlementSvc.GetBatch(id)
+	if err != nil {
+		h.respondError(c, "获取付款批次失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, BatchResponse{
+		Code:    200,
+		Message: i18n.T(c, messageCode),
+		Data:    BatchData{Batch: *batch, Items: items},
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "settlement.not_found", "settlement.batch_not_found", "settlement.contract_not_found":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type SettlementResponse struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    Settlement `json:"data"`
+}
+
+type DueResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    DueResult `json:"data"`
+}
+
+type SettlementListData struct {
+	Settlements []Settlement `json:"settlements"`
+	Total       int64        `json:"total"`
+	Page        int          `json:"page"`
+	PageSize    int          `json:"page_size"`
+}
+
+type SettlementListResponse struct {
+	Code    int                `json:"code"`
+	Message string             `json:"message"`
+	Data    SettlementListData `json:"data"`
+}
+
+type RecordListData struct {
+	Records  []commission.Record `json:"records"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+type RecordListResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    RecordListData `json:"data"`
+}
+
+type BatchData struct {
+	Batch PayoutBatch  `json:"batch"`
+	Items []PayoutItem `json:"items"`
+}
+
+type BatchResponse struct {
+	Code    int       `json:"code"`
+	Message string    `json:"message"`
+	Data    BatchData `json:"data"`
+}
+
+type BatchListData struct {
+	Batches  []PayoutBatch `json:"batches"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+type BatchListResponse struct {
+	Code    int           `json:"code"`
+	Message string        `json:"message"`
+	Data    BatchListData `json:"data"`
+}
@@ -0,0 +1,126 @@
+package settlement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status 结算单状态
+type Status string
+
+const (
+	StatusClosed    Status = "closed"    // 已关账，周期内返佣记录被锁定
+	StatusCancelled Status = "cancelled" // 已撤销，返佣记录解除锁定
+)
+
+// BatchStatus 付款批次状态：draft → approved → paid，未支付前可取消
+type BatchStatus string
+
+const (
+	BatchDraft     BatchStatus = "draft"     // 待审批
+	BatchApproved  BatchStatus = "approved"  // 已审批
+	BatchPaid      BatchStatus = "paid"      // 已支付
+	BatchCancelled BatchStatus = "cancelled" // 已取消
+)
+
+// IsValid 检查批次状态是否有效
+func (s BatchStatus) IsValid() bool {
+	switch s {
+	case BatchDraft, BatchApproved, BatchPaid, BatchCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Settlement 返佣结算单：合同一个结算周期的返佣汇总，金额为报告币种
+type Settlement struct {
+	ID              uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	SettlementNo    string     `json:"settlement_no" gorm:"type:varchar(50);uniqueIndex;not null"`
+	ContractID      uuid.UUID  `json:"contract_id" gorm:"type:uuid;not null"`
+	CustomerID      uuid.UUID  `json:"customer_id" gorm:"type:uuid;not null"`
+	CycleStart      string     `json:"cycle_start" gorm:"type:varchar(10);not null"`
+	CycleEnd        string     `json:"cycle_end" gorm:"type:varchar(10);not null"`
+	Status          Status     `json:"status" gorm:"type:varchar(20);not null;default:'closed'"`
+	Currency        string     `json:"currency" gorm:"type:varchar(3);not null"`
+	RecordCount     int        `json:"record_count" gorm:"not null;default:0"`
+	BaseTotal       float64    `json:"base_total" gorm:"type:decimal(15,2);not null;default:0"`
+	CommissionTotal float64    `json:"commission_total" gorm:"type:decimal(15,4);not null;default:0"`
+	PayoutBatchID   *uuid.UUID `json:"payout_batch_id,omitempty" gorm:"type:uuid"`
+	ClosedAt        time.Time  `json:"closed_at"`
+	ClosedBy        *uuid.UUID `json:"closed_by,omitempty"`
+	CancelledAt     *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy     *uuid.UUID `json:"cancelled_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName 设置表名
+func (Settlement) TableName() string {
+	return "commission_settlements"
+}
+
+// PayoutBatch 付款批次
+type PayoutBatch struct {
+	ID               uuid.UUID   `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BatchNo          string      `json:"batch_no" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Status           BatchStatus `json:"status" gorm:"type:varchar(20);not null;default:'draft'"`
+	Currency         string      `json:"currency" gorm:"type:varchar(3);not null"`
+	SettlementCount  int         `json:"settlement_count" gorm:"not null;default:0"`
+	CustomerCount    int         `json:"customer_count" gorm:"not null;default:0"`
+	TotalAmount      float64     `json:"total_amount" gorm:"type:decimal(15,4);not null;default:0"`
+	Note             string      `json:"note,omitempty" gorm:"type:text"`
+	ApprovedAt       *time.Time  `json:"approved_at,omitempty"`
+	ApprovedBy       *uuid.UUID  `json:"approved_by,omitempty"`
+	PaidAt           *time.Time  `json:"paid_at,omitempty"`
+	PaidBy           *uuid.UUID  `json:"paid_by,omitempty"`
+	PaymentReference string      `json:"payment_reference,omitempty" gorm:"type:varchar(100)"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	CreatedBy        *uuid.UUID  `json:"created_by,omitempty"`
+	UpdatedBy        *uuid.UUID  `json:"updated_by,omitempty"`
+}
+
+// TableName 设置表名
+func (PayoutBatch) TableName() string {
+	return "payout_batches"
+}
+
+// PayoutItem 付款批次中单个客户的汇总
+type PayoutItem struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BatchID         uuid.UUID `json:"batch_id" gorm:"type:uuid;not null"`
+	CustomerID      uuid.UUID `json:"customer_id" gorm:"type:uuid;not null"`
+	SettlementCount int       `json:"settlement_count" gorm:"not null;default:0"`
+	RecordCount     int       `json:"record_count" gorm:"not null;default:0"`
+	BaseTotal       float64   `json:"base_total" gorm:"type:decimal(15,2);not null;default:0"`
+	CommissionTotal float64   `json:"commission_total" gorm:"type:decimal(15,4);not null;default:0"`
+}
+
+// TableName 设置表名
+func (PayoutItem) TableName() string {
+	return "payout_batch_items"
+}
+
+// 请求结构体
+
+// CloseRequest 结算周期关账请求，Period 为周期内任一计费月份
+type CloseRequest struct {
+	ContractID string `json:"contract_id" binding:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Period     string `json:"period" binding:"required" example:"2024-06"`
+}
+
+// CreateBatchRequest 创建付款批次请求
+// 未指定 SettlementIDs 时包含全部已关账且未进入批次的结算单（可按客户、币种筛选）
+type CreateBatchRequest struct {
+	SettlementIDs []string `json:"settlement_ids,omitempty" binding:"omitempty,dive,uuid"`
+	CustomerID    string   `json:"customer_id,omitempty" binding:"omitempty,uuid"`
+	Currency      string   `json:"currency,omitempty" binding:"omitempty,len=3" example:"CNY"`
+	Note          string   `json:"note,omitempty" example:"2024年第二季度返佣"`
+}
+
+// PayRequest 付款批次支付请求
+type PayRequest struct {
+	PaymentReference string `json:"payment_reference" binding:"required,max=100" example:"TRX20240705001"`
+}
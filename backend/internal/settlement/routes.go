@@ -0,0 +1,31 @@
+package settlement
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册返佣结算和付款批次相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+	managers := middleware.RequireRole("admin", "manager")
+	admins := middleware.RequireRole("admin")
+
+	settlements := router.Group("/settlements")
+	settlements.GET("", handler.ListSettlements)
+	settlements.GET("/:id", handler.GetSettlement)
+	settlements.GET("/:id/records", handler.ListSettlementRecords)
+	settlements.POST("", managers, handler.CloseSettlement)
+	settlements.POST("/close-due", managers, handler.CloseDueSettlements)
+	settlements.POST("/:id/cancel", managers, handler.CancelSettlement)
+
+	batches := router.Group("/payout-batches")
+	batches.GET("", handler.ListBatches)
+	batches.GET("/:id", handler.GetBatch)
+	batches.POST("", managers, handler.CreateBatch)
+	batches.POST("/:id/approve", admins, handler.ApproveBatch)
+	batches.POST("/:id/pay", admins, handler.PayBatch)
+	batches.POST("/:id/cancel", managers, handler.CancelBatch)
+}
@@ -0,0 +1,570 @@
+package settlement
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/currency"
+	"xcloud-backend/internal/webhook"
+	"xcloud-backend/pkg/i18n"
+)
+
+// settleableStatuses 可以结算的合同状态
+var settleableStatuses = []contract.ContractStatus{contract.StatusActive, contract.StatusExpired, contract.StatusTerminated}
+
+// Service 返佣结算服务
+//
+// 结算周期按合同开始月份起每 settlement_cycle 个月划分，最后一个周期截止到合同结束月份。
+// 周期结束后关账生成结算单，周期内已计算的返佣记录关联结算单后被锁定，
+// 重算已关账周期时差额以调整记录写入，并在合同下一个周期关账时一并结算；撤销结算单后解除锁定。
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建返佣结算服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// CycleOf 返回计费周期所在的合同结算周期（首月、末月）
+func CycleOf(ct contract.Contract, period string) (string, string, error) {
+	month, err := billing.ParsePeriod(period)
+	if err != nil {
+		return "", "", i18n.NewError("settlement.invalid_period")
+	}
+	first := monthOf(ct.StartDate)
+	last := monthOf(ct.EndDate)
+	if month.Before(first) || month.After(last) {
+		return "", "", i18n.NewError("settlement.period_out_of_contract", period)
+	}
+
+	n := ct.SettlementCycle
+	if n < 1 {
+		n = 1
+	}
+	offset := monthsBetween(first, month) / n * n
+	start := first.AddDate(0, offset, 0)
+	end := start.AddDate(0, n-1, 0)
+	if end.After(last) {
+		end = last
+	}
+	return billing.PeriodOf(start), billing.PeriodOf(end), nil
+}
+
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()) - int(from.Month())
+}
+
+// Close 关账合同在计费周期所在的结算周期
+func (s *Service) Close(contractID uuid.UUID, period string, operator *uuid.UUID) (*Settlement, error) {
+	var settlement *Settlement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ct, err := lockContract(tx, contractID)
+		if err != nil {
+			return err
+		}
+		start, end, err := CycleOf(*ct, period)
+		if err != nil {
+			return err
+		}
+		settlement, err = s.closeCycle(tx, *ct, start, end, operator)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return settlement, nil
+}
+
+// DueResult 批量关账结果
+type DueResult struct {
+	Contracts   int          `json:"contracts"`
+	Settlements []Settlement `json:"settlements"`
+}
+
+// CloseDue 关账全部合同中已结束且尚未关账的结算周期
+func (s *Service) CloseDue(operator *uuid.UUID) (*DueResult, error) {
+	var contracts []contract.Contract
+	if err := s.db.Where("status IN ?", settleableStatuses).Order("contract_no").Find(&contracts).Error; err != nil {
+		return nil, fmt.Errorf("查询合同失败: %w", err)
+	}
+
+	result := &DueResult{Contracts: len(contracts), Settlements: []Settlement{}}
+	current := monthOf(time.Now())
+	for _, ct := range contracts {
+		var closed []string
+		if err := s.db.Model(&Settlement{}).
+			Where("contract_id = ? AND status <> ?", ct.ID, StatusCancelled).
+			Pluck("cycle_start", &closed).Error; err != nil {
+			return nil, err
+		}
+		done := make(map[string]bool, len(closed))
+		for _, p := range closed {
+			done[p] = true
+		}
+
+		for month := monthOf(ct.StartDate); !month.After(monthOf(ct.EndDate)); {
+			start, end, err := CycleOf(ct, billing.PeriodOf(month))
+			if err != nil {
+				return nil, err
+			}
+			endMonth, _ := billing.ParsePeriod(end)
+			if !endMonth.Before(current) {
+				break
+			}
+			if !done[start] {
+				var settlement *Settlement
+				err := s.db.Transaction(func(tx *gorm.DB) error {
+					locked, err := lockContract(tx, ct.ID)
+					if err != nil {
+						return err
+					}
+					settlement, err = s.closeCycle(tx, *locked, start, end, operator)
+					return err
+				})
+				if err != nil {
+					return nil, fmt.Errorf("合同 %s 周期 %s 关账失败: %w", ct.ContractNo, start, err)
+				}
+				result.Settlements = append(result.Settlements, *settlement)
+			}
+			month = endMonth.AddDate(0, 1, 0)
+		}
+	}
+	return result, nil
+}
+
+// lockContract 锁定合同行，与返佣计算互斥
+func lockContract(tx *gorm.DB, contractID uuid.UUID) (*contract.Contract, error) {
+	var ct contract.Contract
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ct, "id = ?", contractID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("settlement.contract_not_found")
+		}
+		return nil, err
+	}
+	settleable := false
+	for _, status := range settleableStatuses {
+		if ct.Status == status {
+			settleable = true
+		}
+	}
+	if !settleable {
+		return nil, i18n.NewError("settlement.contract_not_settleable")
+	}
+	return &ct, nil
+}
+
+// closeCycle 生成结算单并锁定周期内已计算的返佣记录（调用方需持有合同行锁）
+func (s *Service) closeCycle(tx *gorm.DB, ct contract.Contract, start, end string, operator *uuid.UUID) (*Settlement, error) {
+	endMonth, _ := billing.ParsePeriod(end)
+	if !endMonth.Before(monthOf(time.Now())) {
+		return nil, i18n.NewError("settlement.cycle_not_ended", end)
+	}
+
+	var count int64
+	if err := tx.Model(&Settlement{}).
+		Where("contract_id = ? AND cycle_start = ? AND status <> ?", ct.ID, start, StatusCancelled).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, i18n.NewError("settlement.already_closed", start, end)
+	}
+
+	// 周期内的返佣记录，以及之前周期关账后重算产生、尚未结算的调整记录
+	records := tx.Model(&commission.Record{}).
+		Where("contract_id = ? AND status = ? AND settlement_id IS NULL", ct.ID, commission.StatusCalculated).
+		Where("((billing_period >= ? AND billing_period <= ?) OR (record_type = ? AND billing_period < ?))",
+			start, end, commission.RecordAdjustment, start)
+
+	var totals []struct {
+		ReportingCurrency string
+		RecordCount       int
+		BaseTotal         float64
+		CommissionTotal   float64
+	}
+	if err := records.Session(&gorm.Session{}).
+		Select("reporting_currency, COUNT(*) AS record_count, SUM(base_amount_reporting) AS base_total, SUM(commission_amount_reporting) AS commission_total").
+		Group("reporting_currency").
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	if len(totals) > 1 {
+		return nil, i18n.NewError("settlement.mixed_currency")
+	}
+
+	settlement := &Settlement{
+		SettlementNo: newNo("STL"),
+		ContractID:   ct.ID,
+		CustomerID:   ct.CustomerID,
+		CycleStart:   start,
+		CycleEnd:     end,
+		Status:       StatusClosed,
+		Currency:     currency.NewService(s.db).ReportingCurrency(),
+		ClosedAt:     time.Now(),
+		ClosedBy:     operator,
+	}
+	if len(totals) == 1 {
+		settlement.Currency = totals[0].ReportingCurrency
+		settlement.RecordCount = totals[0].RecordCount
+		settlement.BaseTotal = round(totals[0].BaseTotal, 2)
+		settlement.CommissionTotal = round(totals[0].CommissionTotal, 4)
+	}
+	if err := tx.Create(settlement).Error; err != nil {
+		return nil, err
+	}
+
+	result := records.Session(&gorm.Session{}).Update("settlement_id", settlement.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if int(result.RowsAffected) != settlement.RecordCount {
+		return nil, i18n.NewError("settlement.concurrent_update")
+	}
+	return settlement, nil
+}
+
+// Cancel 撤销结算单，解除返佣记录锁定；已进入付款批次的结算单不能撤销
+func (s *Service) Cancel(id uuid.UUID, operator *uuid.UUID) (*Settlement, error) {
+	var settlement Settlement
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&settlement, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return i18n.NewError("settlement.not_found")
+			}
+			return err
+		}
+		if settlement.Status == StatusCancelled {
+			return i18n.NewError("settlement.already_cancelled")
+		}
+		if settlement.PayoutBatchID != nil {
+			return i18n.NewError("settlement.in_batch")
+		}
+
+		if err := tx.Model(&commission.Record{}).
+			Where("settlement_id = ?", settlement.ID).
+			Update("settlement_id", nil).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		settlement.Status = StatusCancelled
+		settlement.CancelledAt = &now
+		settlement.CancelledBy = operator
+		return tx.Model(&settlement).Updates(map[string]interface{}{
+			"status":       settlement.Status,
+			"cancelled_at": now,
+			"cancelled_by": operator,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+// SettlementFilter 结算单查询条件
+type SettlementFilter struct {
+	CustomerID    *uuid.UUID
+	ContractID    *uuid.UUID
+	PayoutBatchID *uuid.UUID
+	Status        Status
+	Unbatched     bool
+}
+
+// List 分页查询结算单，按关账时间倒序
+func (s *Service) List(filter SettlementFilter, page, pageSize int) ([]Settlement, int64, error) {
+	query := s.db.Model(&Settlement{})
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.ContractID != nil {
+		query = query.Where("contract_id = ?", *filter.ContractID)
+	}
+	if filter.PayoutBatchID != nil {
+		query = query.Where("payout_batch_id = ?", *filter.PayoutBatchID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Unbatched {
+		query = query.Where("payout_batch_id IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var settlements []Settlement
+	err := query.Order("closed_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&settlements).Error
+	return settlements, total, err
+}
+
+// Get 获取结算单
+func (s *Service) Get(id uuid.UUID) (*Settlement, error) {
+	var settlement Settlement
+	if err := s.db.First(&settlement, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("settlement.not_found")
+		}
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+// Records 分页查询结算单包含的返佣记录
+func (s *Service) Records(id uuid.UUID, page, pageSize int) ([]commission.Record, int64, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, 0, err
+	}
+	query := s.db.Model(&commission.Record{}).Where("settlement_id = ?", id)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var records []commission.Record
+	err := query.Order("billing_period, provider, service_type").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&records).Error
+	return records, total, err
+}
+
+// CreateBatch 将已关账且未进入批次的结算单汇总为付款批次，按客户汇总金额
+func (s *Service) CreateBatch(req CreateBatchRequest, operator *uuid.UUID) (*PayoutBatch, []PayoutItem, error) {
+	batch := &PayoutBatch{
+		BatchNo:   newNo("PB"),
+		Status:    BatchDraft,
+		Note:      req.Note,
+		CreatedBy: operator,
+		UpdatedBy: operator,
+	}
+	var items []PayoutItem
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("status = ? AND payout_batch_id IS NULL", StatusClosed)
+		if len(req.SettlementIDs) > 0 {
+			query = query.Where("id IN ?", req.SettlementIDs)
+		}
+		if req.CustomerID != "" {
+			query = query.Where("customer_id = ?", req.CustomerID)
+		}
+		if req.Currency != "" {
+			query = query.Where("currency = ?", currency.Normalize(req.Currency))
+		}
+
+		var settlements []Settlement
+		if err := query.Order("customer_id, cycle_start").Find(&settlements).Error; err != nil {
+			return err
+		}
+		if len(settlements) == 0 {
+			return i18n.NewError("settlement.nothing_to_batch")
+		}
+		if len(req.SettlementIDs) > 0 && len(settlements) != len(req.SettlementIDs) {
+			return i18n.NewError("settlement.not_batchable")
+		}
+
+		byCustomer := map[uuid.UUID]*PayoutItem{}
+		ids := make([]uuid.UUID, 0, len(settlements))
+		for _, st := range settlements {
+			if batch.Currency == "" {
+				batch.Currency = st.Currency
+			} else if st.Currency != batch.Currency {
+				return i18n.NewError("settlement.mixed_currency")
+			}
+			item, ok := byCustomer[st.CustomerID]
+			if !ok {
+				items = append(items, PayoutItem{CustomerID: st.CustomerID})
+				item = &items[len(items)-1]
+				byCustomer[st.CustomerID] = item
+			}
+			item.SettlementCount++
+			item.RecordCount += st.RecordCount
+			item.BaseTotal += st.BaseTotal
+			item.CommissionTotal += st.CommissionTotal
+			batch.TotalAmount += st.CommissionTotal
+			ids = append(ids, st.ID)
+		}
+		batch.SettlementCount = len(settlements)
+		batch.CustomerCount = len(items)
+		batch.TotalAmount = round(batch.TotalAmount, 4)
+
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].BatchID = batch.ID
+			items[i].BaseTotal = round(items[i].BaseTotal, 2)
+			items[i].CommissionTotal = round(items[i].CommissionTotal, 4)
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Settlement{}).Where("id IN ?", ids).Update("payout_batch_id", batch.ID).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return batch, items, nil
+}
+
+// BatchFilter 付款批次查询条件
+type BatchFilter struct {
+	Status BatchStatus
+}
+
+// ListBatches 分页查询付款批次，按创建时间倒序
+func (s *Service) ListBatches(filter BatchFilter, page, pageSize int) ([]PayoutBatch, int64, error) {
+	query := s.db.Model(&PayoutBatch{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var batches []PayoutBatch
+	err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&batches).Error
+	return batches, total, err
+}
+
+// GetBatch 获取付款批次及客户汇总
+func (s *Service) GetBatch(id uuid.UUID) (*PayoutBatch, []PayoutItem, error) {
+	var batch PayoutBatch
+	if err := s.db.First(&batch, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, i18n.NewError("settlement.batch_not_found")
+		}
+		return nil, nil, err
+	}
+	var items []PayoutItem
+	if err := s.db.Where("batch_id = ?", id).Order("commission_total DESC").Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+	return &batch, items, nil
+}
+
+// Approve 审批付款批次：draft → approved
+func (s *Service) Approve(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error) {
+	return s.transitionBatch(id, []BatchStatus{BatchDraft}, func(tx *gorm.DB, batch *PayoutBatch, now time.Time) error {
+		batch.Status = BatchApproved
+		batch.ApprovedAt = &now
+		batch.ApprovedBy = operator
+		batch.UpdatedBy = operator
+		return nil
+	})
+}
+
+// Pay 登记付款批次已支付：approved → paid，批次内返佣记录标记为已支付
+func (s *Service) Pay(id uuid.UUID, reference string, operator *uuid.UUID) (*PayoutBatch, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, i18n.NewError("settlement.reference_required")
+	}
+	batch, err := s.transitionBatch(id, []BatchStatus{BatchApproved}, func(tx *gorm.DB, batch *PayoutBatch, now time.Time) error {
+		err := tx.Model(&commission.Record{}).
+			Where("settlement_id IN (?)", tx.Model(&Settlement{}).Select("id").Where("payout_batch_id = ?", batch.ID)).
+			Updates(map[string]interface{}{
+				"status":            commission.StatusPaid,
+				"paid_at":           now,
+				"payment_reference": reference,
+				"updated_by":        operator,
+			}).Error
+		if err != nil {
+			return err
+		}
+		batch.Status = BatchPaid
+		batch.PaidAt = &now
+		batch.PaidBy = operator
+		batch.PaymentReference = reference
+		batch.UpdatedBy = operator
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	webhook.Publish(s.db, webhook.EventBatchPaid, map[string]interface{}{
+		"batch_id":          batch.ID,
+		"batch_no":          batch.BatchNo,
+		"currency":          batch.Currency,
+		"total_amount":      batch.TotalAmount,
+		"settlement_count":  batch.SettlementCount,
+		"customer_count":    batch.CustomerCount,
+		"payment_reference": batch.PaymentReference,
+		"paid_at":           batch.PaidAt,
+	})
+	return batch, nil
+}
+
+// CancelBatch 取消未支付的付款批次，结算单可重新进入其他批次
+func (s *Service) CancelBatch(id uuid.UUID, operator *uuid.UUID) (*PayoutBatch, error) {
+	return s.transitionBatch(id, []BatchStatus{BatchDraft, BatchApproved}, func(tx *gorm.DB, batch *PayoutBatch, now time.Time) error {
+		if err := tx.Model(&Settlement{}).Where("payout_batch_id = ?", batch.ID).Update("payout_batch_id", nil).Error; err != nil {
+			return err
+		}
+		batch.Status = BatchCancelled
+		batch.UpdatedBy = operator
+		return nil
+	})
+}
+
+// transitionBatch 在事务中锁定批次，校验当前状态属于 from 后执行状态变更并保存
+func (s *Service) transitionBatch(id uuid.UUID, from []BatchStatus, apply func(tx *gorm.DB, batch *PayoutBatch, now time.Time) error) (*PayoutBatch, error) {
+	var batch PayoutBatch
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return i18n.NewError("settlement.batch_not_found")
+			}
+			return err
+		}
+		allowed := false
+		for _, status := range from {
+			if batch.Status == status {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return i18n.NewError("settlement.invalid_batch_status", string(batch.Status))
+		}
+
+		if err := apply(tx, &batch, time.Now()); err != nil {
+			return err
+		}
+		return tx.Model(&batch).Select("status", "approved_at", "approved_by", "paid_at", "paid_by", "payment_reference", "updated_by").Updates(&batch).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// newNo 生成带前缀的单号：前缀 + 时间 + 随机后缀
+func newNo(prefix string) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return prefix + time.Now().Format("20060102150405") + strings.ToUpper(hex.EncodeToString(b))
+}
+
+// round 按指定小数位四舍五入
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,366 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/internal/contract"
+	"xcloud-backend/internal/currency"
+	"xcloud-backend/internal/customer"
+	"xcloud-backend/internal/settlement"
+	"xcloud-backend/internal/sysconfig"
+	"xcloud-backend/pkg/database"
+	"xcloud-backend/pkg/i18n"
+)
+
+const defaultCompanyName = "XCloud"
+
+// billedRow 账单明细按云平台、服务类型、币种的汇总
+type billedRow struct {
+	Provider    billing.Provider
+	ServiceType string
+	Currency    string
+	Amount      float64
+}
+
+// summaryKey 汇总分组键
+type summaryKey struct {
+	provider    billing.Provider
+	serviceType string
+}
+
+// Build 汇总客户在计费周期内的账单和返佣数据，金额折算为报告币种
+func (s *Service) Build(customerID uuid.UUID, period string) (*Data, error) {
+	if _, err := billing.ParsePeriod(period); err != nil {
+		return nil, i18n.NewError("statement.invalid_period")
+	}
+
+	var cust customer.Customer
+	if err := s.db.First(&cust, "id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("statement.customer_not_found")
+		}
+		return nil, err
+	}
+
+	var records []commission.Record
+	err := s.db.Where("customer_id = ? AND billing_period = ?", customerID, period).
+		Order("provider, service_type, created_at").
+		Find(&records).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询返佣记录失败: %w", err)
+	}
+
+	var billed []billedRow
+	err = s.db.Model(&billing.BillingData{}).
+		Select("provider, service_type, currency, SUM(discounted_cost) AS amount").
+		Where("customer_id = ? AND billing_period = ?", customerID, period).
+		Group("provider, service_type, currency").
+		Scan(&billed).Error
+	if err != nil {
+		return nil, fmt.Errorf("汇总账单明细失败: %w", err)
+	}
+	if len(records) == 0 && len(billed) == 0 {
+		return nil, i18n.NewError("statement.no_data", period)
+	}
+
+	cfg := sysconfig.NewService(s.db, database.GetRedis())
+	data := &Data{
+		CompanyName:  cfg.GetString(sysconfig.KeyStatementCompanyName, defaultCompanyName),
+		Footer:       cfg.GetString(sysconfig.KeyStatementFooter, ""),
+		CustomerID:   cust.ID,
+		CustomerCode: cust.CustomerCode,
+		CustomerName: cust.CompanyName,
+		Period:       period,
+		Currency:     currency.NewService(s.db).ReportingCurrency(),
+		GeneratedAt:  time.Now(),
+	}
+
+	if err := s.buildSummary(data, records, billed, period); err != nil {
+		return nil, err
+	}
+	if err := s.buildTiers(data, records); err != nil {
+		return nil, err
+	}
+	if err := s.buildSettlements(data, records); err != nil {
+		return nil, err
+	}
+	buildPayouts(data, records)
+	return data, nil
+}
+
+// buildSummary 按云平台、服务类型汇总账单金额、返佣及调整，并生成明细和调整列表
+func (s *Service) buildSummary(data *Data, records []commission.Record, billed []billedRow, period string) error {
+	rows := map[summaryKey]*SummaryRow{}
+	rates := map[summaryKey]map[float64]bool{}
+	row := func(provider billing.Provider, serviceType string) *SummaryRow {
+		key := summaryKey{provider, serviceType}
+		if r, ok := rows[key]; ok {
+			return r
+		}
+		r := &SummaryRow{Provider: provider, ServiceType: serviceType}
+		rows[key] = r
+		rates[key] = map[float64]bool{}
+		return r
+	}
+
+	conv := currency.NewConverter(s.db, period)
+	for _, b := range billed {
+		amount, err := conv.Convert(b.Amount, b.Currency, data.Currency)
+		if err != nil {
+			return err
+		}
+		row(b.Provider, b.ServiceType).BilledAmount += amount
+	}
+
+	for _, rec := range records {
+		r := row(rec.Provider, rec.ServiceType)
+		if rec.RecordType == commission.RecordAdjustment {
+			r.Adjustment += rec.CommissionAmountReporting
+			data.Adjustments = append(data.Adjustments, AdjustmentRow{
+				CreatedAt:       rec.CreatedAt,
+				Provider:        rec.Provider,
+				ServiceType:     rec.ServiceType,
+				BaseAmount:      rec.BaseAmountReporting,
+				Commission:      rec.CommissionAmountReporting,
+				Status:          string(rec.Status),
+				AdjustsRecordID: rec.AdjustsRecordID,
+			})
+		} else {
+			r.BaseAmount += rec.BaseAmountReporting
+			r.Commission += rec.CommissionAmountReporting
+			r.RecordCount++
+			rates[summaryKey{rec.Provider, rec.ServiceType}][rec.CommissionRate] = true
+		}
+		data.Details = append(data.Details, DetailRow{
+			Provider:       rec.Provider,
+			ServiceType:    rec.ServiceType,
+			RecordType:     string(rec.RecordType),
+			Currency:       rec.Currency,
+			BaseAmount:     rec.BaseAmount,
+			CommissionRate: rec.CommissionRate,
+			Commission:     rec.CommissionAmount,
+			ExchangeRate:   rec.ExchangeRate,
+			BaseReporting:  rec.BaseAmountReporting,
+			CommissionRep:  rec.CommissionAmountReporting,
+			Status:         string(rec.Status),
+		})
+	}
+
+	data.Summary = make([]SummaryRow, 0, len(rows))
+	for key, r := range rows {
+		r.BilledAmount = round(r.BilledAmount, 2)
+		r.BaseAmount = round(r.BaseAmount, 2)
+		r.Commission = round(r.Commission, 4)
+		r.Adjustment = round(r.Adjustment, 4)
+		r.NetCommission = round(r.Commission+r.Adjustment, 4)
+		r.CommissionRates = formatRates(rates[key])
+		data.Summary = append(data.Summary, *r)
+
+		data.Totals.BilledAmount += r.BilledAmount
+		data.Totals.BaseAmount += r.BaseAmount
+		data.Totals.Commission += r.Commission
+		data.Totals.Adjustment += r.Adjustment
+	}
+	sort.Slice(data.Summary, func(i, j int) bool {
+		a, b := data.Summary[i], data.Summary[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.ServiceType < b.ServiceType
+	})
+
+	data.Totals.BilledAmount = round(data.Totals.BilledAmount, 2)
+	data.Totals.BaseAmount = round(data.Totals.BaseAmount, 2)
+	data.Totals.Commission = round(data.Totals.Commission, 4)
+	data.Totals.Adjustment = round(data.Totals.Adjustment, 4)
+	data.Totals.NetCommission = round(data.Totals.Commission+data.Totals.Adjustment, 4)
+	return nil
+}
+
+// buildTiers 列出返佣记录引用的规则阶梯（包含已删除的规则）
+func (s *Service) buildTiers(data *Data, records []commission.Record) error {
+	ruleIDs := map[uuid.UUID]bool{}
+	for _, rec := range records {
+		if rec.RecordType == commission.RecordCommission {
+			ruleIDs[rec.RuleID] = true
+		}
+	}
+	data.Tiers = []TierRow{}
+	if len(ruleIDs) == 0 {
+		return nil
+	}
+
+	var rules []commission.Rule
+	if err := s.db.Unscoped().Where("id IN ?", keys(ruleIDs)).Find(&rules).Error; err != nil {
+		return fmt.Errorf("查询返佣规则失败: %w", err)
+	}
+	contractIDs := map[uuid.UUID]bool{}
+	for _, r := range rules {
+		contractIDs[r.ContractID] = true
+	}
+	var contracts []contract.Contract
+	if err := s.db.Unscoped().Where("id IN ?", keys(contractIDs)).Find(&contracts).Error; err != nil {
+		return fmt.Errorf("查询合同失败: %w", err)
+	}
+	byID := make(map[uuid.UUID]contract.Contract, len(contracts))
+	for _, c := range contracts {
+		byID[c.ID] = c
+	}
+
+	for _, r := range rules {
+		c := byID[r.ContractID]
+		data.Tiers = append(data.Tiers, TierRow{
+			ContractNo:     c.ContractNo,
+			Provider:       r.Provider,
+			ServiceType:    r.ServiceType,
+			TierMin:        r.TierMin,
+			TierMax:        r.TierMax,
+			CommissionRate: r.CommissionRate,
+			FixedAmount:    r.FixedAmount,
+			ContractCcy:    c.Currency,
+		})
+	}
+	sort.Slice(data.Tiers, func(i, j int) bool {
+		a, b := data.Tiers[i], data.Tiers[j]
+		if a.ContractNo != b.ContractNo {
+			return a.ContractNo < b.ContractNo
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.ServiceType != b.ServiceType {
+			return a.ServiceType < b.ServiceType
+		}
+		return a.TierMin < b.TierMin
+	})
+	return nil
+}
+
+// buildSettlements 列出返佣记录所在的结算单及付款批次
+func (s *Service) buildSettlements(data *Data, records []commission.Record) error {
+	amounts := map[uuid.UUID]float64{}
+	for _, rec := range records {
+		if rec.SettlementID != nil {
+			amounts[*rec.SettlementID] += rec.CommissionAmountReporting
+		}
+	}
+	data.Settlements = []SettlementRow{}
+	if len(amounts) == 0 {
+		return nil
+	}
+
+	var settlements []settlement.Settlement
+	err := s.db.Where("id IN ?", keys(amounts)).Order("cycle_start, settlement_no").Find(&settlements).Error
+	if err != nil {
+		return fmt.Errorf("查询结算单失败: %w", err)
+	}
+	batchIDs := map[uuid.UUID]bool{}
+	for _, st := range settlements {
+		if st.PayoutBatchID != nil {
+			batchIDs[*st.PayoutBatchID] = true
+		}
+	}
+	batches := map[uuid.UUID]settlement.PayoutBatch{}
+	if len(batchIDs) > 0 {
+		var list []settlement.PayoutBatch
+		if err := s.db.Where("id IN ?", keys(batchIDs)).Find(&list).Error; err != nil {
+			return fmt.Errorf("查询付款批次失败: %w", err)
+		}
+		for _, b := range list {
+			batches[b.ID] = b
+		}
+	}
+
+	for _, st := range settlements {
+		row := SettlementRow{
+			SettlementNo: st.SettlementNo,
+			CycleStart:   st.CycleStart,
+			CycleEnd:     st.CycleEnd,
+			Commission:   round(amounts[st.ID], 4),
+		}
+		if st.PayoutBatchID != nil {
+			if b, ok := batches[*st.PayoutBatchID]; ok {
+				row.BatchNo = b.BatchNo
+				row.BatchStatus = string(b.Status)
+				row.PaymentReference = b.PaymentReference
+			}
+		}
+		data.Settlements = append(data.Settlements, row)
+	}
+	return nil
+}
+
+// buildPayouts 按发放状态汇总返佣（含调整），并计算已支付和未支付金额
+func buildPayouts(data *Data, records []commission.Record) {
+	order := []commission.RecordStatus{commission.StatusPending, commission.StatusCalculated, commission.StatusPaid}
+	byStatus := map[commission.RecordStatus]*PayoutRow{}
+	for _, status := range order {
+		byStatus[status] = &PayoutRow{Status: string(status)}
+	}
+	for _, rec := range records {
+		row, ok := byStatus[rec.Status]
+		if !ok {
+			row = &PayoutRow{Status: string(rec.Status)}
+			byStatus[rec.Status] = row
+			order = append(order, rec.Status)
+		}
+		row.RecordCount++
+		row.Commission += rec.CommissionAmountReporting
+	}
+
+	data.Payouts = make([]PayoutRow, 0, len(order))
+	for _, status := range order {
+		row := byStatus[status]
+		if row.RecordCount == 0 {
+			continue
+		}
+		row.Commission = round(row.Commission, 4)
+		data.Payouts = append(data.Payouts, *row)
+	}
+	data.Totals.Paid = byStatus[commission.StatusPaid].Commission
+	data.Totals.Outstanding = round(data.Totals.NetCommission-data.Totals.Paid, 4)
+}
+
+// formatRates 将比例列表格式化为百分比，如 "3.00%, 5.00%"
+func formatRates(set map[float64]bool) string {
+	list := make([]float64, 0, len(set))
+	for r := range set {
+		list = append(list, r)
+	}
+	sort.Float64s(list)
+	parts := make([]string, len(list))
+	for i, r := range list {
+		parts[i] = formatPercent(r)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatPercent 将比例格式化为百分比
+func formatPercent(rate float64) string {
+	return fmt.Sprintf("%.2f%%", rate*100)
+}
+
+// keys 返回集合中的ID
+func keys[V any](set map[uuid.UUID]V) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// round 按指定小数位四舍五入
+func round(v float64, places int) float64 {
+	p := math.Pow10(places)
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,384 @@
+package statement
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	statementSvc *Service
+	logger       *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		statementSvc: NewService(db),
+		logger:       logger.GetLogger(),
+	}
+}
+
+// GetCustomerStatement 获取客户对账单
+// @Summary 获取客户对账单
+// @Description 按客户和计费周期即时生成返佣对账单，包含按云平台和服务类型的汇总、适用的规则阶梯、调整记录和发放状态。
+// @Description format 为 pdf 或 xlsx 时下载文件（默认 pdf），为 json 时返回对账单数据；即时生成的文件不保存
+// @Tags 客户对账单
+// @Accept json
+// @Produce json,application/pdf,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path string true "客户ID"
+// @Param period path string true "计费周期（YYYY-MM）"
+// @Param format query string false "格式（pdf, xlsx, json）" default(pdf)
+// @Param lang query string false "模板语言（zh-CN, en-US），默认为请求语言"
+// @Success 200 {object} StatementDataResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "客户不存在"
+// @Router /customers/{id}/statements/{period} [get]
+func (h *Handler) GetCustomerStatement(c *gin.Context) {
+	customerID, ok := pathID(c)
+	if !ok {
+		return
+	}
+	period := c.Param("period")
+	lang := requestLang(c, c.Query("lang"))
+
+	format := c.DefaultQuery("format", string(FormatPDF))
+	if format == "json" {
+		data, err := h.statementSvc.Build(customerID, period)
+		if err != nil {
+			h.respondError(c, "获取对账单失败:", err)
+			return
+		}
+		c.JSON(http.StatusOK, StatementDataResponse{
+			Code:    200,
+			Message: i18n.T(c, "common.fetch_success"),
+			Data:    *data,
+		})
+		return
+	}
+
+	data, content, err := h.statementSvc.Generate(customerID, period, Format(format), lang)
+	if err != nil {
+		h.respondError(c, "生成对账单失败:", err)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName(data, Format(format))}))
+	c.Data(http.StatusOK, Format(format).ContentType(), content)
+}
+
+// CreateJob 创建对账单批量生成任务
+// @Summary 创建对账单批量生成任务
+// @Description 后台为计费周期内的客户批量生成对账单并保存文件，未指定客户时包含该周期有返佣记录的全部客户，
+// @Description 未指定格式时同时生成 PDF 和 XLSX（需要管理员或经理权限）
+// @Tags 客户对账单
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body CreateJobRequest true "生成参数"
+// @Success 202 {object} JobResponse "任务已创建"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /statements/jobs [post]
+func (h *Handler) CreateJob(c *gin.Context) {
+	var req CreateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.statementSvc.StartJob(req, requestLang(c, req.Lang), operator)
+	if err != nil {
+		h.respondError(c, "创建对账单生成任务失败:", err)
+		return
+	}
+
+	h.logger.Info("对账单生成任务已创建:", job.ID, " ", job.BillingPeriod, " 客户数:", job.TotalCount)
+	c.JSON(http.StatusAccepted, JobResponse{
+		Code:    202,
+		Message: i18n.T(c, "statement.job_started"),
+		Data:    *job,
+	})
+}
+
+// ListJobs 获取对账单生成任务列表
+// @Summary 获取对账单生成任务列表
+// @Description 分页获取对账单批量生成任务，按开始时间倒序
+// @Tags 客户对账单
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param period query string false "计费周期（YYYY-MM）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} JobListResponse "获取成功"
+// @Router /statements/jobs [get]
+func (h *Handler) ListJobs(c *gin.Context) {
+	page, pageSize := pagination(c)
+	jobs, total, err := h.statementSvc.ListJobs(c.Query("period"), page, pageSize)
+	if err != nil {
+		h.respondError(c, "获取对账单生成任务列表失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, JobListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: JobListData{
+			Jobs:     jobs,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	})
+}
+
+// GetJob 获取对账单生成任务
+// @Summary 获取对账单生成任务
+// @Description 获取对账单批量生成任务的进度和结果
+// @Tags 客户对账单
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} JobResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "任务不存在"
+// @Router /statements/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.statementSvc.GetJob(id)
+	if err != nil {
+		h.respondError(c, "获取对账单生成任务失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, JobResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *job,
+	})
+}
+
+// ListStatements 获取已生成的对账单列表
+// @Summary 获取已生成的对账单列表
+// @Description 分页获取批量任务保存的对账单文件，可按客户、计费周期、任务筛选
+// @Tags 客户对账单
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param customer_id query string false "客户ID"
+// @Param period query string false "计费周期（YYYY-MM）"
+// @Param job_id query string false "生成任务ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} StatementListResponse "获取成功"
+// @Router /statements [get]
+func (h *Handler) ListStatements(c *gin.Context) {
+	filter := StatementFilter{Period: c.Query("period")}
+	var ok bool
+	if filter.CustomerID, ok = queryID(c, "customer_id"); !ok {
+		return
+	}
+	if filter.JobID, ok = queryID(c, "job_id"); !ok {
+		return
+	}
+
+	page, pageSize := pagination(c)
+	list, total, err := h.statementSvc.List(filter, page, pageSize)
+	if err != nil {
+		h.respondError(c, "获取对账单列表失败:", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, StatementListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: StatementListData{
+			Statements: list,
+			Total:      total,
+			Page:       page,
+			PageSize:   pageSize,
+		},
+	})
+}
+
+// DownloadStatement 下载已生成的对账单
+// @Summary 下载已生成的对账单
+// @Description 下载批量任务保存的对账单文件
+// @Tags 客户对账单
+// @Produce application/pdf,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path string true "对账单ID"
+// @Success 200 {file} file "对账单文件"
+// @Failure 404 {object} ErrorResponse "对账单不存在"
+// @Router /statements/{id}/download [get]
+func (h *Handler) DownloadStatement(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	st, file, err := h.statementSvc.Open(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, "下载对账单失败:", err)
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, st.FileSize, st.Format.ContentType(), file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType("attachment", map[string]string{"filename": st.FileName}),
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "statement.customer_not_found", "statement.not_found", "statement.job_not_found", "statement.file_missing":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// requestLang 模板语言：优先使用指定语言，其次是请求语言
+func requestLang(c *gin.Context, lang string) i18n.Lang {
+	if l, ok := i18n.Parse(lang); ok {
+		return l
+	}
+	return i18n.FromContext(c)
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// queryID 解析查询参数中的可选ID
+func queryID(c *gin.Context, name string) (*uuid.UUID, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+// pagination 解析分页参数
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type StatementDataResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Data   `json:"data"`
+}
+
+type JobResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    Job    `json:"data"`
+}
+
+type JobListData struct {
+	Jobs     []Job `json:"jobs"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+type JobListResponse struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    JobListData `json:"data"`
+}
+
+type StatementListData struct {
+	Statements []Statement `json:"statements"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+}
+
+type StatementListResponse struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Data    StatementListData `json:"data"`
+}
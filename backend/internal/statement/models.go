@@ -0,0 +1,190 @@
+package statement
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"xcloud-backend/internal/billing"
+)
+
+// Format 对账单文件格式
+type Format string
+
+const (
+	FormatPDF  Format = "pdf"
+	FormatXLSX Format = "xlsx"
+)
+
+// IsValid 检查格式是否有效
+func (f Format) IsValid() bool {
+	return f == FormatPDF || f == FormatXLSX
+}
+
+// ContentType 文件的 MIME 类型
+func (f Format) ContentType() string {
+	if f == FormatPDF {
+		return "application/pdf"
+	}
+	return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+}
+
+// JobStatus 批量生成任务状态
+type JobStatus string
+
+const (
+	JobRunning JobStatus = "running"
+	JobSuccess JobStatus = "success"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job 对账单批量生成任务
+type Job struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	BillingPeriod  string          `json:"billing_period" gorm:"type:varchar(10);not null"`
+	Formats        string          `json:"formats" gorm:"type:varchar(50);not null"`
+	CustomerIDs    json.RawMessage `json:"customer_ids,omitempty" gorm:"column:customer_ids;type:jsonb"`
+	Status         JobStatus       `json:"status" gorm:"type:varchar(20);not null;default:'running'"`
+	TotalCount     int             `json:"total_count" gorm:"not null;default:0"`
+	ProcessedCount int             `json:"processed_count" gorm:"not null;default:0"`
+	ErrorCount     int             `json:"error_count" gorm:"not null;default:0"`
+	ErrorMessage   string          `json:"error_message,omitempty" gorm:"type:text"`
+	StartedAt      time.Time       `json:"started_at"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+	CreatedBy      *uuid.UUID      `json:"created_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Job) TableName() string {
+	return "statement_jobs"
+}
+
+// Statement 已生成的对账单文件
+type Statement struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	CustomerID    uuid.UUID  `json:"customer_id" gorm:"type:uuid;not null"`
+	BillingPeriod string     `json:"billing_period" gorm:"type:varchar(10);not null"`
+	Format        Format     `json:"format" gorm:"type:varchar(10);not null"`
+	FileName      string     `json:"file_name" gorm:"type:varchar(200);not null"`
+	FilePath      string     `json:"-" gorm:"type:varchar(500);not null"`
+	FileSize      int64      `json:"file_size" gorm:"not null;default:0"`
+	JobID         *uuid.UUID `json:"job_id,omitempty" gorm:"type:uuid"`
+	GeneratedAt   time.Time  `json:"generated_at"`
+	GeneratedBy   *uuid.UUID `json:"generated_by,omitempty"`
+}
+
+// TableName 设置表名
+func (Statement) TableName() string {
+	return "statements"
+}
+
+// Data 对账单内容，金额均为报告币种
+type Data struct {
+	CompanyName  string    `json:"company_name"`
+	Footer       string    `json:"footer"`
+	CustomerID   uuid.UUID `json:"customer_id"`
+	CustomerCode string    `json:"customer_code"`
+	CustomerName string    `json:"customer_name"`
+	Period       string    `json:"period"`
+	Currency     string    `json:"currency"`
+	GeneratedAt  time.Time `json:"generated_at"`
+
+	Summary     []SummaryRow    `json:"summary"`
+	Tiers       []TierRow       `json:"tiers"`
+	Adjustments []AdjustmentRow `json:"adjustments"`
+	Payouts     []PayoutRow     `json:"payouts"`
+	Settlements []SettlementRow `json:"settlements"`
+	Details     []DetailRow     `json:"details,omitempty"`
+	Totals      Totals          `json:"totals"`
+}
+
+// SummaryRow 按云平台、服务类型的汇总
+type SummaryRow struct {
+	Provider        billing.Provider `json:"provider"`
+	ServiceType     string           `json:"service_type"`
+	BilledAmount    float64          `json:"billed_amount"`
+	BaseAmount      float64          `json:"base_amount"`
+	Commission      float64          `json:"commission"`
+	Adjustment      float64          `json:"adjustment"`
+	NetCommission   float64          `json:"net_commission"`
+	RecordCount     int              `json:"record_count"`
+	CommissionRates string           `json:"commission_rates"`
+}
+
+// TierRow 当期适用的返佣规则阶梯
+type TierRow struct {
+	ContractNo     string           `json:"contract_no"`
+	Provider       billing.Provider `json:"provider"`
+	ServiceType    string           `json:"service_type"`
+	TierMin        float64          `json:"tier_min"`
+	TierMax        *float64         `json:"tier_max,omitempty"`
+	CommissionRate float64          `json:"commission_rate"`
+	FixedAmount    *float64         `json:"fixed_amount,omitempty"`
+	ContractCcy    string           `json:"contract_currency"`
+}
+
+// AdjustmentRow 调整记录
+type AdjustmentRow struct {
+	CreatedAt       time.Time        `json:"created_at"`
+	Provider        billing.Provider `json:"provider"`
+	ServiceType     string           `json:"service_type"`
+	BaseAmount      float64          `json:"base_amount"`
+	Commission      float64          `json:"commission"`
+	Status          string           `json:"status"`
+	AdjustsRecordID *uuid.UUID       `json:"adjusts_record_id,omitempty"`
+}
+
+// PayoutRow 按发放状态的汇总
+type PayoutRow struct {
+	Status      string  `json:"status"`
+	RecordCount int     `json:"record_count"`
+	Commission  float64 `json:"commission"`
+}
+
+// SettlementRow 涉及的结算单及付款批次
+type SettlementRow struct {
+	SettlementNo     string  `json:"settlement_no"`
+	CycleStart       string  `json:"cycle_start"`
+	CycleEnd         string  `json:"cycle_end"`
+	BatchNo          string  `json:"batch_no,omitempty"`
+	BatchStatus      string  `json:"batch_status,omitempty"`
+	PaymentReference string  `json:"payment_reference,omitempty"`
+	Commission       float64 `json:"commission"`
+}
+
+// DetailRow 返佣明细
+type DetailRow struct {
+	Provider       billing.Provider `json:"provider"`
+	ServiceType    string           `json:"service_type"`
+	RecordType     string           `json:"record_type"`
+	Currency       string           `json:"currency"`
+	BaseAmount     float64          `json:"base_amount"`
+	CommissionRate float64          `json:"commission_rate"`
+	Commission     float64          `json:"commission"`
+	ExchangeRate   float64          `json:"exchange_rate"`
+	BaseReporting  float64          `json:"base_reporting"`
+	CommissionRep  float64          `json:"commission_reporting"`
+	Status         string           `json:"status"`
+}
+
+// Totals 对账单合计
+type Totals struct {
+	BilledAmount  float64 `json:"billed_amount"`
+	BaseAmount    float64 `json:"base_amount"`
+	Commission    float64 `json:"commission"`
+	Adjustment    float64 `json:"adjustment"`
+	NetCommission float64 `json:"net_commission"`
+	Paid          float64 `json:"paid"`
+	Outstanding   float64 `json:"outstanding"`
+}
+
+// 请求结构体
+
+// CreateJobRequest 对账单批量生成请求
+type CreateJobRequest struct {
+	Period      string   `json:"period" binding:"required" example:"2024-06"`
+	Formats     []Format `json:"formats,omitempty" binding:"omitempty,dive,oneof=pdf xlsx"`
+	CustomerIDs []string `json:"customer_ids,omitempty" binding:"omitempty,dive,uuid"`
+	Lang        string   `json:"lang,omitempty" example:"zh-CN"`
+}
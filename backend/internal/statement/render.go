@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// render 按格式渲染对账单
+func render(format Format, data *Data, lang i18n.Lang, w io.Writer) error {
+	switch format {
+	case FormatPDF:
+		return renderPDF(data, lang, w)
+	case FormatXLSX:
+		return renderXLSX(data, lang, w)
+	default:
+		return i18n.NewError("statement.invalid_format")
+	}
+}
+
+// labels 对账单模板文字，取自 statement.label.* 消息
+type labels struct {
+	lang i18n.Lang
+}
+
+// T 翻译模板文字
+func (l labels) T(key string) string {
+	return i18n.Translate(l.lang, "statement.label."+key)
+}
+
+// status 翻译状态值，没有对应文字时原样返回
+func (l labels) status(value string) string {
+	if value == "" {
+		return ""
+	}
+	code := "statement.label.status_" + value
+	if msg := i18n.Translate(l.lang, code); msg != code {
+		return msg
+	}
+	return value
+}
+
+// fileName 对账单文件名，如 C0001_2024-06.pdf
+func fileName(data *Data, format Format) string {
+	return fmt.Sprintf("%s_%s.%s", data.CustomerCode, data.Period, format)
+}
+
+// money 金额显示格式
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// optional 可选金额的显示格式，为空时显示 fallback
+func optional(v *float64, fallback string) string {
+	if v == nil {
+		return fallback
+	}
+	return money(*v)
+}
@@ -0,0 +1,278 @@
+package statement
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	pdfFontFamily = "statement"
+	pdfCoreFont   = "Helvetica"
+	pdfLineHeight = 6.0
+)
+
+// pdfWriter 封装对账单 PDF 的排版
+type pdfWriter struct {
+	pdf    *gofpdf.Fpdf
+	family string
+	tr     func(string) string
+	l      labels
+	// unsupported 使用内置字体时遇到了无法显示的字符（如中文）
+	unsupported bool
+}
+
+// column 表格列定义
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+// renderPDF 渲染 PDF 对账单
+// 配置了 statement.font_path 时使用该 TrueType 字体，否则使用内置字体，
+// 内置字体不支持中文，此时模板文字固定为英文；客户名称等数据含有内置字体无法显示的字符时
+// 返回 statement.font_required 错误，而不是生成显示为问号的对账单
+func renderPDF(data *Data, lang i18n.Lang, out io.Writer) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	w := &pdfWriter{pdf: pdf, family: pdfCoreFont, l: labels{lang: lang}}
+	if path := viper.GetString("statement.font_path"); path != "" {
+		pdf.AddUTF8Font(pdfFontFamily, "", path)
+		pdf.AddUTF8Font(pdfFontFamily, "B", path)
+		if err := pdf.Error(); err != nil {
+			return fmt.Errorf("加载对账单字体失败: %w", err)
+		}
+		w.family = pdfFontFamily
+		w.tr = func(s string) string { return s }
+	} else {
+		w.l.lang = i18n.EnUS
+		translate := pdf.UnicodeTranslatorFromDescriptor("")
+		w.tr = func(s string) string {
+			if !latin1(s) {
+				w.unsupported = true
+			}
+			return translate(s)
+		}
+	}
+
+	pdf.SetTitle(w.l.T("title"), true)
+	pdf.SetAutoPageBreak(true, 18)
+	pdf.AliasNbPages("")
+	pdf.SetHeaderFunc(func() {
+		w.font("B", 9)
+		pdf.CellFormat(140, 5, w.tr(data.CompanyName), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, w.tr(data.CustomerCode+" / "+data.Period), "", 1, "R", false, 0, "")
+		pdf.Ln(3)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		w.font("", 8)
+		if data.Footer != "" {
+			pdf.CellFormat(0, 4, w.tr(data.Footer), "", 1, "L", false, 0, "")
+		}
+		pdf.CellFormat(0, 4, strconv.Itoa(pdf.PageNo())+" / {nb}", "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	w.writeHeader(data)
+	w.writeSummary(data)
+	w.writePayouts(data)
+	w.writeSettlements(data)
+	w.writeTiers(data)
+	w.writeAdjustments(data)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	if w.unsupported {
+		return i18n.NewError("statement.font_required")
+	}
+	return pdf.Output(out)
+}
+
+// latin1 文本是否只包含内置字体可以显示的 Latin-1 字符
+func latin1(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxLatin1 {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *pdfWriter) font(style string, size float64) {
+	w.pdf.SetFont(w.family, style, size)
+}
+
+// section 小节标题
+func (w *pdfWriter) section(title string) {
+	w.pdf.Ln(4)
+	w.font("B", 11)
+	w.pdf.CellFormat(0, 7, w.tr(title), "", 1, "L", false, 0, "")
+}
+
+// table 输出表格，rows 为空时输出“无数据”提示
+func (w *pdfWriter) table(cols []column, rows [][]string) {
+	w.font("B", 8)
+	w.pdf.SetFillColor(221, 235, 247)
+	for _, c := range cols {
+		w.pdf.CellFormat(c.width, pdfLineHeight, w.tr(c.title), "1", 0, "C", true, 0, "")
+	}
+	w.pdf.Ln(-1)
+
+	w.font("", 8)
+	if len(rows) == 0 {
+		var width float64
+		for _, c := range cols {
+			width += c.width
+		}
+		w.pdf.CellFormat(width, pdfLineHeight, w.tr(w.l.T("no_records")), "1", 1, "C", false, 0, "")
+		return
+	}
+	for _, row := range rows {
+		for i, c := range cols {
+			w.pdf.CellFormat(c.width, pdfLineHeight, w.tr(row[i]), "1", 0, c.align, false, 0, "")
+		}
+		w.pdf.Ln(-1)
+	}
+}
+
+// keyValue 输出“标签：值”行
+func (w *pdfWriter) keyValue(label, value string) {
+	w.font("B", 9)
+	w.pdf.CellFormat(35, 5, w.tr(label), "", 0, "L", false, 0, "")
+	w.font("", 9)
+	w.pdf.CellFormat(0, 5, w.tr(value), "", 1, "L", false, 0, "")
+}
+
+func (w *pdfWriter) writeHeader(data *Data) {
+	w.font("B", 16)
+	w.pdf.CellFormat(0, 10, w.tr(w.l.T("title")), "", 1, "C", false, 0, "")
+	w.pdf.Ln(2)
+	w.keyValue(w.l.T("customer"), data.CustomerName)
+	w.keyValue(w.l.T("customer_code"), data.CustomerCode)
+	w.keyValue(w.l.T("period"), data.Period)
+	w.keyValue(w.l.T("currency"), data.Currency)
+	w.keyValue(w.l.T("generated_at"), data.GeneratedAt.Format("2006-01-02 15:04:05"))
+}
+
+func (w *pdfWriter) writeSummary(data *Data) {
+	l := w.l
+	w.section(l.T("section_summary"))
+	cols := []column{
+		{l.T("provider"), 28, "L"},
+		{l.T("service_type"), 40, "L"},
+		{l.T("billed_amount"), 30, "R"},
+		{l.T("base_amount"), 30, "R"},
+		{l.T("commission_rates"), 35, "L"},
+		{l.T("commission"), 30, "R"},
+		{l.T("adjustment"), 28, "R"},
+		{l.T("net_commission"), 30, "R"},
+		{l.T("record_count"), 18, "R"},
+	}
+	rows := make([][]string, 0, len(data.Summary)+1)
+	for _, r := range data.Summary {
+		rows = append(rows, []string{
+			string(r.Provider), r.ServiceType, money(r.BilledAmount), money(r.BaseAmount), r.CommissionRates,
+			money(r.Commission), money(r.Adjustment), money(r.NetCommission), strconv.Itoa(r.RecordCount),
+		})
+	}
+	if len(rows) > 0 {
+		t := data.Totals
+		rows = append(rows, []string{
+			l.T("total"), "", money(t.BilledAmount), money(t.BaseAmount), "",
+			money(t.Commission), money(t.Adjustment), money(t.NetCommission), "",
+		})
+	}
+	w.table(cols, rows)
+}
+
+func (w *pdfWriter) writePayouts(data *Data) {
+	l := w.l
+	w.section(l.T("section_payouts"))
+	cols := []column{
+		{l.T("status"), 40, "L"},
+		{l.T("record_count"), 25, "R"},
+		{l.T("commission"), 35, "R"},
+	}
+	rows := make([][]string, 0, len(data.Payouts)+2)
+	for _, p := range data.Payouts {
+		rows = append(rows, []string{l.status(p.Status), strconv.Itoa(p.RecordCount), money(p.Commission)})
+	}
+	rows = append(rows,
+		[]string{l.T("paid"), "", money(data.Totals.Paid)},
+		[]string{l.T("outstanding"), "", money(data.Totals.Outstanding)},
+	)
+	w.table(cols, rows)
+}
+
+func (w *pdfWriter) writeSettlements(data *Data) {
+	l := w.l
+	w.section(l.T("section_settlements"))
+	cols := []column{
+		{l.T("settlement_no"), 55, "L"},
+		{l.T("cycle"), 45, "L"},
+		{l.T("batch_no"), 55, "L"},
+		{l.T("batch_status"), 30, "L"},
+		{l.T("payment_reference"), 50, "L"},
+		{l.T("commission"), 35, "R"},
+	}
+	rows := make([][]string, 0, len(data.Settlements))
+	for _, st := range data.Settlements {
+		rows = append(rows, []string{
+			st.SettlementNo, st.CycleStart + " ~ " + st.CycleEnd, st.BatchNo, l.status(st.BatchStatus),
+			st.PaymentReference, money(st.Commission),
+		})
+	}
+	w.table(cols, rows)
+}
+
+func (w *pdfWriter) writeTiers(data *Data) {
+	l := w.l
+	w.section(l.T("section_tiers"))
+	cols := []column{
+		{l.T("contract_no"), 45, "L"},
+		{l.T("provider"), 28, "L"},
+		{l.T("service_type"), 40, "L"},
+		{l.T("tier_min"), 30, "R"},
+		{l.T("tier_max"), 30, "R"},
+		{l.T("commission_rate"), 30, "R"},
+		{l.T("fixed_amount"), 30, "R"},
+		{l.T("contract_currency"), 25, "C"},
+	}
+	rows := make([][]string, 0, len(data.Tiers))
+	for _, t := range data.Tiers {
+		rows = append(rows, []string{
+			t.ContractNo, string(t.Provider), t.ServiceType, money(t.TierMin), optional(t.TierMax, l.T("unlimited")),
+			formatPercent(t.CommissionRate), optional(t.FixedAmount, "-"), t.ContractCcy,
+		})
+	}
+	w.table(cols, rows)
+}
+
+func (w *pdfWriter) writeAdjustments(data *Data) {
+	l := w.l
+	w.section(l.T("section_adjustments"))
+	cols := []column{
+		{l.T("created_at"), 40, "L"},
+		{l.T("provider"), 28, "L"},
+		{l.T("service_type"), 40, "L"},
+		{l.T("base_amount"), 35, "R"},
+		{l.T("commission"), 35, "R"},
+		{l.T("status"), 25, "L"},
+	}
+	rows := make([][]string, 0, len(data.Adjustments))
+	for _, a := range data.Adjustments {
+		rows = append(rows, []string{
+			a.CreatedAt.Format("2006-01-02 15:04"), string(a.Provider), a.ServiceType,
+			money(a.BaseAmount), money(a.Commission), l.status(a.Status),
+		})
+	}
+	w.table(cols, rows)
+}
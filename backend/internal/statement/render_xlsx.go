@@ -0,0 +1,186 @@
+package statement
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+// sheetWriter 按行写入工作表，出现错误后忽略后续写入
+type sheetWriter struct {
+	f     *excelize.File
+	sheet string
+	row   int
+	err   error
+}
+
+// line 写入一行，style 为 0 时不设置样式
+func (w *sheetWriter) line(style int, values ...interface{}) {
+	w.row++
+	if w.err != nil || len(values) == 0 {
+		return
+	}
+	start, _ := excelize.CoordinatesToCellName(1, w.row)
+	if w.err = w.f.SetSheetRow(w.sheet, start, &values); w.err != nil {
+		return
+	}
+	if style != 0 {
+		end, _ := excelize.CoordinatesToCellName(len(values), w.row)
+		w.err = w.f.SetCellStyle(w.sheet, start, end, style)
+	}
+}
+
+// xlsxStyles 对账单工作簿使用的样式
+type xlsxStyles struct {
+	title  int
+	header int
+	total  int
+}
+
+func newXLSXStyles(f *excelize.File) (xlsxStyles, error) {
+	var s xlsxStyles
+	var err error
+	if s.title, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}}); err != nil {
+		return s, err
+	}
+	s.header, err = f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{"DDEBF7"}},
+	})
+	if err != nil {
+		return s, err
+	}
+	s.total, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	return s, err
+}
+
+// renderXLSX 渲染 XLSX 对账单：汇总、规则阶梯、调整记录、返佣明细各一个工作表
+func renderXLSX(data *Data, lang i18n.Lang, out io.Writer) error {
+	l := labels{lang: lang}
+	f := excelize.NewFile()
+	defer f.Close()
+
+	styles, err := newXLSXStyles(f)
+	if err != nil {
+		return err
+	}
+
+	summary := l.T("sheet_summary")
+	if err := f.SetSheetName("Sheet1", summary); err != nil {
+		return err
+	}
+	writers := []*sheetWriter{
+		writeSummarySheet(f, summary, data, l, styles),
+		writeTiersSheet(f, l.T("sheet_tiers"), data, l, styles),
+		writeAdjustmentsSheet(f, l.T("sheet_adjustments"), data, l, styles),
+		writeDetailsSheet(f, l.T("sheet_details"), data, l, styles),
+	}
+	for _, w := range writers {
+		if w.err != nil {
+			return w.err
+		}
+	}
+	if err := f.SetColWidth(summary, "A", "I", 18); err != nil {
+		return err
+	}
+
+	_, err = f.WriteTo(out)
+	return err
+}
+
+// newSheet 创建工作表并写入表头
+func newSheet(f *excelize.File, name string, styles xlsxStyles, headers ...interface{}) *sheetWriter {
+	w := &sheetWriter{f: f, sheet: name}
+	if _, err := f.NewSheet(name); err != nil {
+		w.err = err
+		return w
+	}
+	w.line(styles.header, headers...)
+	if w.err == nil {
+		w.err = f.SetPanes(name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+	}
+	if w.err == nil {
+		last, _ := excelize.ColumnNumberToName(len(headers))
+		w.err = f.SetColWidth(name, "A", last, 18)
+	}
+	return w
+}
+
+func writeSummarySheet(f *excelize.File, sheet string, data *Data, l labels, styles xlsxStyles) *sheetWriter {
+	w := &sheetWriter{f: f, sheet: sheet}
+	w.line(styles.title, data.CompanyName)
+	w.line(styles.total, l.T("title"))
+	w.line(0, l.T("customer"), data.CustomerName)
+	w.line(0, l.T("customer_code"), data.CustomerCode)
+	w.line(0, l.T("period"), data.Period)
+	w.line(0, l.T("currency"), data.Currency)
+	w.line(0, l.T("generated_at"), data.GeneratedAt.Format("2006-01-02 15:04:05"))
+	w.line(0)
+
+	w.line(styles.total, l.T("section_summary"))
+	w.line(styles.header, l.T("provider"), l.T("service_type"), l.T("billed_amount"), l.T("base_amount"),
+		l.T("commission_rates"), l.T("commission"), l.T("adjustment"), l.T("net_commission"), l.T("record_count"))
+	for _, r := range data.Summary {
+		w.line(0, string(r.Provider), r.ServiceType, r.BilledAmount, r.BaseAmount,
+			r.CommissionRates, r.Commission, r.Adjustment, r.NetCommission, r.RecordCount)
+	}
+	t := data.Totals
+	w.line(styles.total, l.T("total"), "", t.BilledAmount, t.BaseAmount, "", t.Commission, t.Adjustment, t.NetCommission)
+	w.line(0)
+
+	w.line(styles.total, l.T("section_payouts"))
+	w.line(styles.header, l.T("status"), l.T("record_count"), l.T("commission"))
+	for _, p := range data.Payouts {
+		w.line(0, l.status(p.Status), p.RecordCount, p.Commission)
+	}
+	w.line(styles.total, l.T("paid"), "", t.Paid)
+	w.line(styles.total, l.T("outstanding"), "", t.Outstanding)
+	w.line(0)
+
+	w.line(styles.total, l.T("section_settlements"))
+	w.line(styles.header, l.T("settlement_no"), l.T("cycle"), l.T("batch_no"), l.T("batch_status"),
+		l.T("payment_reference"), l.T("commission"))
+	for _, st := range data.Settlements {
+		w.line(0, st.SettlementNo, st.CycleStart+" ~ "+st.CycleEnd, st.BatchNo, l.status(st.BatchStatus),
+			st.PaymentReference, st.Commission)
+	}
+
+	if data.Footer != "" {
+		w.line(0)
+		w.line(0, data.Footer)
+	}
+	return w
+}
+
+func writeTiersSheet(f *excelize.File, sheet string, data *Data, l labels, styles xlsxStyles) *sheetWriter {
+	w := newSheet(f, sheet, styles, l.T("contract_no"), l.T("provider"), l.T("service_type"), l.T("tier_min"),
+		l.T("tier_max"), l.T("commission_rate"), l.T("fixed_amount"), l.T("contract_currency"))
+	for _, t := range data.Tiers {
+		w.line(0, t.ContractNo, string(t.Provider), t.ServiceType, t.TierMin,
+			optional(t.TierMax, l.T("unlimited")), formatPercent(t.CommissionRate), optional(t.FixedAmount, ""), t.ContractCcy)
+	}
+	return w
+}
+
+func writeAdjustmentsSheet(f *excelize.File, sheet string, data *Data, l labels, styles xlsxStyles) *sheetWriter {
+	w := newSheet(f, sheet, styles, l.T("created_at"), l.T("provider"), l.T("service_type"),
+		l.T("base_amount"), l.T("commission"), l.T("status"))
+	for _, a := range data.Adjustments {
+		w.line(0, a.CreatedAt.Format("2006-01-02 15:04:05"), string(a.Provider), a.ServiceType,
+			a.BaseAmount, a.Commission, l.status(a.Status))
+	}
+	return w
+}
+
+func writeDetailsSheet(f *excelize.File, sheet string, data *Data, l labels, styles xlsxStyles) *sheetWriter {
+	w := newSheet(f, sheet, styles, l.T("provider"), l.T("service_type"), l.T("record_type"), l.T("original_currency"),
+		l.T("base_amount"), l.T("commission_rate"), l.T("commission"), l.T("exchange_rate"),
+		l.T("base_reporting"), l.T("commission_reporting"), l.T("status"))
+	for _, d := range data.Details {
+		w.line(0, string(d.Provider), d.ServiceType, l.status(d.RecordType), d.Currency, d.BaseAmount,
+			formatPercent(d.CommissionRate), d.Commission, d.ExchangeRate, d.BaseReporting, d.CommissionRep, l.status(d.Status))
+	}
+	return w
+}
@@ -0,0 +1,26 @@
+package statement
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册对账单批量生成和下载相关路由
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("", handler.ListStatements)
+	router.GET("/:id/download", handler.DownloadStatement)
+	router.GET("/jobs", handler.ListJobs)
+	router.GET("/jobs/:id", handler.GetJob)
+	router.POST("/jobs", middleware.RequireRole("admin", "manager"), handler.CreateJob)
+}
+
+// RegisterCustomerRoutes 在客户路由组下注册对账单查询路由
+func RegisterCustomerRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.GET("/:id/statements/:period", handler.GetCustomerStatement)
+}
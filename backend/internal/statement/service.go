@@ -0,0 +1,310 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/internal/billing"
+	"xcloud-backend/internal/commission"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/storage"
+)
+
+const (
+	// storageDir 对账单文件在文件存储中的目录
+	storageDir = "statements"
+	// maxJobErrors 批量任务 error_message 中最多保留的客户错误数
+	maxJobErrors = 20
+)
+
+// Service 客户对账单服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建客户对账单服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Generate 生成客户在计费周期的对账单，返回对账单内容和渲染后的文件
+func (s *Service) Generate(customerID uuid.UUID, period string, format Format, lang i18n.Lang) (*Data, []byte, error) {
+	if !format.IsValid() {
+		return nil, nil, i18n.NewError("statement.invalid_format")
+	}
+	data, err := s.Build(customerID, period)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buf bytes.Buffer
+	if err := render(format, data, lang, &buf); err != nil {
+		return nil, nil, fmt.Errorf("渲染对账单失败: %w", err)
+	}
+	return data, buf.Bytes(), nil
+}
+
+// save 保存对账单文件，同一客户、计费周期、格式只保留最新一份
+// 文件按客户ID存放在 statements/<计费周期>/<客户ID>.<格式>，客户编码只用于下载时的文件名
+func (s *Service) save(data *Data, format Format, content []byte, jobID, operator *uuid.UUID) (*Statement, error) {
+	store, err := storage.Default()
+	if err != nil {
+		return nil, err
+	}
+	name := fileName(data, format)
+	key := path.Join(storageDir, data.Period, data.CustomerID.String()+"."+string(format))
+	if err := store.Put(context.Background(), key, bytes.NewReader(content), int64(len(content)), format.ContentType()); err != nil {
+		return nil, fmt.Errorf("保存对账单文件失败: %w", err)
+	}
+
+	st := &Statement{
+		CustomerID:    data.CustomerID,
+		BillingPeriod: data.Period,
+		Format:        format,
+		FileName:      name,
+		FilePath:      key,
+		FileSize:      int64(len(content)),
+		JobID:         jobID,
+		GeneratedAt:   time.Now(),
+		GeneratedBy:   operator,
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "customer_id"}, {Name: "billing_period"}, {Name: "format"}},
+		DoUpdates: clause.AssignmentColumns([]string{"file_name", "file_path", "file_size", "job_id", "generated_at", "generated_by"}),
+	}).Create(st).Error
+	if err != nil {
+		return nil, fmt.Errorf("保存对账单记录失败: %w", err)
+	}
+	return st, nil
+}
+
+// StartJob 创建对账单批量生成任务并在后台执行
+// 未指定客户时，包含该计费周期有返佣记录的全部客户
+func (s *Service) StartJob(req CreateJobRequest, lang i18n.Lang, operator uuid.UUID) (*Job, error) {
+	if _, err := billing.ParsePeriod(req.Period); err != nil {
+		return nil, i18n.NewError("statement.invalid_period")
+	}
+	formats := uniqueFormats(req.Formats)
+	if len(formats) == 0 {
+		formats = []Format{FormatPDF, FormatXLSX}
+	}
+
+	var customerIDs []uuid.UUID
+	if len(req.CustomerIDs) > 0 {
+		seen := map[uuid.UUID]bool{}
+		for _, v := range req.CustomerIDs {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, i18n.NewError("common.invalid_params")
+			}
+			if !seen[id] {
+				seen[id] = true
+				customerIDs = append(customerIDs, id)
+			}
+		}
+	} else {
+		err := s.db.Model(&commission.Record{}).
+			Where("billing_period = ?", req.Period).
+			Distinct().Pluck("customer_id", &customerIDs).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(customerIDs) == 0 {
+		return nil, i18n.NewError("statement.no_data", req.Period)
+	}
+
+	names := make([]string, len(formats))
+	for i, f := range formats {
+		names[i] = string(f)
+	}
+	job := &Job{
+		BillingPeriod: req.Period,
+		Formats:       strings.Join(names, ","),
+		Status:        JobRunning,
+		TotalCount:    len(customerIDs),
+		StartedAt:     time.Now(),
+		CreatedBy:     &operator,
+	}
+	if len(req.CustomerIDs) > 0 {
+		job.CustomerIDs, _ = json.Marshal(customerIDs)
+	}
+	if err := s.db.Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.run(job.ID, req.Period, customerIDs, formats, lang, operator)
+	return job, nil
+}
+
+// run 执行批量生成任务，单个客户失败不影响其他客户
+func (s *Service) run(id uuid.UUID, period string, customerIDs []uuid.UUID, formats []Format, lang i18n.Lang, operator uuid.UUID) {
+	log := logger.GetLogger()
+	var failures []string
+	processed := 0
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("对账单生成任务异常:", id, r)
+			failures = append(failures, "对账单生成任务异常终止")
+			s.finish(id, processed, len(customerIDs), failures)
+		}
+	}()
+
+	for _, customerID := range customerIDs {
+		if err := s.generateAll(id, customerID, period, formats, lang, operator); err != nil {
+			log.Error("生成对账单失败:", customerID, " ", period, " ", err)
+			failures = append(failures, customerID.String()+": "+err.Error())
+		}
+		processed++
+		s.db.Model(&Job{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"processed_count": processed,
+			"error_count":     len(failures),
+		})
+	}
+	s.finish(id, processed, len(customerIDs), failures)
+	log.Info("对账单生成任务完成:", id, " 客户数:", processed, " 失败:", len(failures))
+}
+
+// generateAll 生成并保存单个客户各格式的对账单
+func (s *Service) generateAll(jobID, customerID uuid.UUID, period string, formats []Format, lang i18n.Lang, operator uuid.UUID) error {
+	data, err := s.Build(customerID, period)
+	if err != nil {
+		return err
+	}
+	for _, format := range formats {
+		var buf bytes.Buffer
+		if err := render(format, data, lang, &buf); err != nil {
+			return fmt.Errorf("渲染%s对账单失败: %w", format, err)
+		}
+		if _, err := s.save(data, format, buf.Bytes(), &jobID, &operator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish 更新批量任务的结束状态：全部客户失败时任务失败
+func (s *Service) finish(id uuid.UUID, processed, total int, failures []string) {
+	errorCount := len(failures)
+	status := JobSuccess
+	if total > 0 && errorCount >= total {
+		status = JobFailed
+	}
+	if errorCount > maxJobErrors {
+		failures = append(failures[:maxJobErrors], fmt.Sprintf("... (%d)", errorCount-maxJobErrors))
+	}
+	updates := map[string]interface{}{
+		"status":          status,
+		"processed_count": processed,
+		"error_count":     errorCount,
+		"error_message":   strings.Join(failures, "\n"),
+		"finished_at":     time.Now(),
+	}
+	if err := s.db.Model(&Job{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		logger.GetLogger().Error("更新对账单任务状态失败:", id, err)
+	}
+}
+
+// ListJobs 分页获取批量生成任务
+func (s *Service) ListJobs(period string, page, pageSize int) ([]Job, int64, error) {
+	query := s.db.Model(&Job{})
+	if period != "" {
+		query = query.Where("billing_period = ?", period)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var jobs []Job
+	err := query.Order("started_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&jobs).Error
+	return jobs, total, err
+}
+
+// GetJob 获取批量生成任务
+func (s *Service) GetJob(id uuid.UUID) (*Job, error) {
+	var job Job
+	if err := s.db.First(&job, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("statement.job_not_found")
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// StatementFilter 对账单文件查询条件
+type StatementFilter struct {
+	CustomerID *uuid.UUID
+	Period     string
+	JobID      *uuid.UUID
+}
+
+// List 分页获取已生成的对账单文件
+func (s *Service) List(filter StatementFilter, page, pageSize int) ([]Statement, int64, error) {
+	query := s.db.Model(&Statement{})
+	if filter.CustomerID != nil {
+		query = query.Where("customer_id = ?", *filter.CustomerID)
+	}
+	if filter.Period != "" {
+		query = query.Where("billing_period = ?", filter.Period)
+	}
+	if filter.JobID != nil {
+		query = query.Where("job_id = ?", *filter.JobID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Statement
+	err := query.Order("billing_period DESC, generated_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// Open 获取对账单记录并打开文件，调用方负责关闭
+func (s *Service) Open(ctx context.Context, id uuid.UUID) (*Statement, io.ReadCloser, error) {
+	var st Statement
+	if err := s.db.First(&st, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, i18n.NewError("statement.not_found")
+		}
+		return nil, nil, err
+	}
+	store, err := storage.Default()
+	if err != nil {
+		return nil, nil, err
+	}
+	rc, err := store.Get(ctx, st.FilePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, i18n.NewError("statement.file_missing")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &st, rc, nil
+}
+
+// uniqueFormats 去除重复格式
+func uniqueFormats(formats []Format) []Format {
+	seen := map[Format]bool{}
+	result := make([]Format, 0, len(formats))
+	for _, f := range formats {
+		if !seen[f] {
+			seen[f] = true
+			result = append(result, f)
+		}
+	}
+	return result
+}
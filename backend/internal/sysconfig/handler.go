@@ -0,0 +1,160 @@
+package sysconfig
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+type Handler struct {
+	configSvc *Service
+	logger    *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB, rdb *redis.Client) *Handler {
+	return &Handler{
+		configSvc: NewService(db, rdb),
+		logger:    logger.GetLogger(),
+	}
+}
+
+// ListConfigs 获取系统配置列表
+// @Summary 获取系统配置列表
+// @Description 获取全部运行时系统配置，加密配置的值已脱敏（需要管理员权限）
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ConfigListResponse "获取成功"
+// @Failure 401 {object} ErrorResponse "未认证"
+// @Failure 403 {object} ErrorResponse "权限不足"
+// @Router /system-configs [get]
+func (h *Handler) ListConfigs(c *gin.Context) {
+	configs, err := h.configSvc.List()
+	if err != nil {
+		h.logger.Error("获取系统配置失败:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code":    500,
+			"message": i18n.T(c, "config.list_failed"),
+		})
+		return
+	}
+
+	items := make([]ConfigResponse, len(configs))
+	for i := range configs {
+		items[i] = configs[i].ToResponse()
+	}
+
+	c.JSON(http.StatusOK, ConfigListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    items,
+	})
+}
+
+// GetConfig 获取单个系统配置
+// @Summary 获取系统配置
+// @Description 根据配置键获取系统配置（需要管理员权限）
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "配置键" example(commission.precision)
+// @Success 200 {object} ConfigDetailResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "配置不存在"
+// @Router /system-configs/{key} [get]
+func (h *Handler) GetConfig(c *gin.Context) {
+	cfg, err := h.configSvc.Find(c.Param("key"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":    404,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConfigDetailResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    cfg.ToResponse(),
+	})
+}
+
+// UpdateConfig 更新系统配置
+// @Summary 更新系统配置
+// @Description 更新系统配置，未提供的字段保持不变；配置不存在时创建（需指定value和config_type）。变更立即在集群内生效（需要管理员权限）
+// @Tags 系统配置
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "配置键" example(commission.precision)
+// @Param body body UpdateConfigRequest true "配置值"
+// @Success 200 {object} ConfigDetailResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /system-configs/{key} [put]
+func (h *Handler) UpdateConfig(c *gin.Context) {
+	var req UpdateConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	operator, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return
+	}
+
+	key := c.Param("key")
+	cfg, err := h.configSvc.Set(key, req, operator)
+	if err != nil {
+		h.logger.Error("更新系统配置失败:", key, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	h.logger.Info("系统配置已更新:", key)
+	c.JSON(http.StatusOK, ConfigDetailResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.update_success"),
+		Data:    cfg.ToResponse(),
+	})
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type ConfigListResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    []ConfigResponse `json:"data"`
+}
+
+type ConfigDetailResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    ConfigResponse `json:"data"`
+}
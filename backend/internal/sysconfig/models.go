@@ -0,0 +1,106 @@
+package sysconfig
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ValueType 配置值类型
+type ValueType string
+
+const (
+	TypeString  ValueType = "string"
+	TypeNumber  ValueType = "number"
+	TypeBoolean ValueType = "boolean"
+	TypeJSON    ValueType = "json"
+)
+
+// IsValid 检查配置值类型是否有效
+func (t ValueType) IsValid() bool {
+	switch t {
+	case TypeString, TypeNumber, TypeBoolean, TypeJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// 常用配置项
+const (
+	KeyDefaultCurrency     = "billing.default_currency"
+	KeyReportingCurrency   = "billing.reporting_currency"
+	KeyCommissionPrecision = "commission.precision"
+	KeyMaxExportRecords    = "report.max_export_records"
+	KeyExportStreamLimit   = "report.export_stream_limit"
+
+	KeyReconcileToleranceAmount = "reconcile.tolerance_amount"
+	KeyReconcileToleranceRate   = "reconcile.tolerance_rate"
+
+	KeyStatementCompanyName = "statement.company_name"
+	KeyStatementFooter      = "statement.footer"
+
+	KeyContractReminderDays = "contract.reminder_days"
+	KeyContractAutoExpire   = "contract.auto_expire"
+
+	KeyCreditWarningThresholds = "credit.warning_thresholds"
+)
+
+// maskedValue 加密配置在接口中的展示值
+const maskedValue = "******"
+
+// SystemConfig 系统配置
+type SystemConfig struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	ConfigKey   string     `json:"config_key" gorm:"type:varchar(100);uniqueIndex;not null"`
+	ConfigValue string     `json:"config_value" gorm:"type:text;not null"`
+	ConfigType  ValueType  `json:"config_type" gorm:"type:varchar(20);not null;default:'string'"`
+	Description string     `json:"description,omitempty" gorm:"type:text"`
+	IsEncrypted bool       `json:"is_encrypted" gorm:"not null;default:false"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedBy   *uuid.UUID `json:"created_by,omitempty"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+// TableName 设置表名
+func (SystemConfig) TableName() string {
+	return "system_configs"
+}
+
+// UpdateConfigRequest 更新配置请求，未提供的字段保持不变
+// 配置不存在时创建，此时 value 和 config_type 必填
+type UpdateConfigRequest struct {
+	Value       *string   `json:"value,omitempty" example:"4"`
+	ConfigType  ValueType `json:"config_type,omitempty" binding:"omitempty,oneof=string number boolean json" example:"number"`
+	Description *string   `json:"description,omitempty" example:"返佣计算精度"`
+	IsEncrypted *bool     `json:"is_encrypted,omitempty" example:"false"`
+}
+
+// ConfigResponse 配置响应（加密配置的值已脱敏）
+type ConfigResponse struct {
+	ConfigKey   string     `json:"config_key"`
+	ConfigValue string     `json:"config_value"`
+	ConfigType  ValueType  `json:"config_type"`
+	Description string     `json:"description,omitempty"`
+	IsEncrypted bool       `json:"is_encrypted"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UpdatedBy   *uuid.UUID `json:"updated_by,omitempty"`
+}
+
+// ToResponse 转换为响应格式
+func (c *SystemConfig) ToResponse() ConfigResponse {
+	value := c.ConfigValue
+	if c.IsEncrypted {
+		value = maskedValue
+	}
+	return ConfigResponse{
+		ConfigKey:   c.ConfigKey,
+		ConfigValue: value,
+		ConfigType:  c.ConfigType,
+		Description: c.Description,
+		IsEncrypted: c.IsEncrypted,
+		UpdatedAt:   c.UpdatedAt,
+		UpdatedBy:   c.UpdatedBy,
+	}
+}
@@ -0,0 +1,19 @@
+package sysconfig
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册系统配置相关路由（需要管理员权限）
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB, rdb *redis.Client) {
+	handler := NewHandler(db, rdb)
+
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("", handler.ListConfigs)
+	router.GET("/:key", handler.GetConfig)
+	router.PUT("/:key", handler.UpdateConfig)
+}
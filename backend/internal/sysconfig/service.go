@@ -0,0 +1,343 @@
+package sysconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/crypto"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// cacheKeyPrefix Redis中配置值的缓存键前缀
+	cacheKeyPrefix = "xcloud:sysconfig:"
+	// cacheTTL Redis缓存有效期
+	cacheTTL = time.Hour
+	// localTTL 进程内缓存有效期，避免Redis断线期间错过失效通知导致长期读到旧值
+	localTTL = time.Minute
+	// InvalidateChannel 配置变更通知频道，消息内容为配置键
+	InvalidateChannel = "xcloud:sysconfig:invalidate"
+)
+
+// cachedValue 缓存的配置值，加密配置保存密文
+type cachedValue struct {
+	Value     string    `json:"value"`
+	Type      ValueType `json:"type"`
+	Encrypted bool      `json:"encrypted"`
+}
+
+type localEntry struct {
+	value   cachedValue
+	expires time.Time
+}
+
+// localCache 进程内缓存，由所有Service实例共享，通过Redis订阅在集群内失效
+var localCache sync.Map
+
+func loadLocal(key string) (cachedValue, bool) {
+	v, ok := localCache.Load(key)
+	if !ok {
+		return cachedValue{}, false
+	}
+	entry := v.(localEntry)
+	if time.Now().After(entry.expires) {
+		localCache.Delete(key)
+		return cachedValue{}, false
+	}
+	return entry.value, true
+}
+
+func storeLocal(key string, cv cachedValue) {
+	localCache.Store(key, localEntry{value: cv, expires: time.Now().Add(localTTL)})
+}
+
+// Service 系统配置服务
+// 读取顺序：进程内缓存 → Redis → 数据库；rdb 为空时不使用Redis缓存
+type Service struct {
+	db  *gorm.DB
+	rdb *redis.Client
+}
+
+// NewService 创建系统配置服务
+func NewService(db *gorm.DB, rdb *redis.Client) *Service {
+	return &Service{db: db, rdb: rdb}
+}
+
+// List 获取全部配置
+func (s *Service) List() ([]SystemConfig, error) {
+	var configs []SystemConfig
+	err := s.db.Order("config_key").Find(&configs).Error
+	return configs, err
+}
+
+// Find 获取单个配置记录
+func (s *Service) Find(key string) (*SystemConfig, error) {
+	var cfg SystemConfig
+	err := s.db.Where("config_key = ?", key).First(&cfg).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("config.not_found")
+		}
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Get 获取配置的明文值
+func (s *Service) Get(key string) (string, bool) {
+	cv, ok := s.load(key)
+	if !ok {
+		return "", false
+	}
+	if !cv.Encrypted {
+		return cv.Value, true
+	}
+
+	cipher, err := crypto.FromConfig()
+	if err != nil {
+		logger.GetLogger().Error("读取加密配置失败:", key, err)
+		return "", false
+	}
+	plain, err := cipher.Decrypt(cv.Value)
+	if err != nil {
+		logger.GetLogger().Error("解密配置失败:", key, err)
+		return "", false
+	}
+	return plain, true
+}
+
+// GetString 获取字符串配置，不存在时返回默认值
+func (s *Service) GetString(key, def string) string {
+	if v, ok := s.Get(key); ok {
+		return v
+	}
+	return def
+}
+
+// GetInt 获取整数配置，不存在或格式错误时返回默认值
+func (s *Service) GetInt(key string, def int) int {
+	if v, ok := s.Get(key); ok {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+// GetFloat 获取数值配置，不存在或格式错误时返回默认值
+func (s *Service) GetFloat(key string, def float64) float64 {
+	if v, ok := s.Get(key); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+// GetBool 获取布尔配置，不存在或格式错误时返回默认值
+func (s *Service) GetBool(key string, def bool) bool {
+	if v, ok := s.Get(key); ok {
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+// GetJSON 将JSON配置解析到 out，配置不存在时返回 false
+func (s *Service) GetJSON(key string, out interface{}) (bool, error) {
+	v, ok := s.Get(key)
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal([]byte(v), out)
+}
+
+// load 依次从进程内缓存、Redis、数据库读取配置
+func (s *Service) load(key string) (cachedValue, bool) {
+	if cv, ok := loadLocal(key); ok {
+		return cv, true
+	}
+
+	ctx := context.Background()
+	if s.rdb != nil {
+		if raw, err := s.rdb.Get(ctx, cacheKeyPrefix+key).Bytes(); err == nil {
+			var cv cachedValue
+			if json.Unmarshal(raw, &cv) == nil {
+				storeLocal(key, cv)
+				return cv, true
+			}
+		}
+	}
+
+	var cfg SystemConfig
+	if err := s.db.Where("config_key = ?", key).First(&cfg).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.GetLogger().Error("读取系统配置失败:", key, err)
+		}
+		return cachedValue{}, false
+	}
+
+	cv := cachedValue{Value: cfg.ConfigValue, Type: cfg.ConfigType, Encrypted: cfg.IsEncrypted}
+	if s.rdb != nil {
+		if raw, err := json.Marshal(cv); err == nil {
+			s.rdb.Set(ctx, cacheKeyPrefix+key, raw, cacheTTL)
+		}
+	}
+	storeLocal(key, cv)
+	return cv, true
+}
+
+// Set 更新配置，配置不存在时创建。未提供新值时保留原值：
+// 修改类型时按新类型校验原值，修改是否加密时将原值解密或重新加密后保存
+func (s *Service) Set(key string, req UpdateConfigRequest, operator uuid.UUID) (*SystemConfig, error) {
+	var cfg SystemConfig
+	err := s.db.Where("config_key = ?", key).First(&cfg).Error
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !isNew {
+		return nil, err
+	}
+
+	if isNew {
+		if req.ConfigType == "" {
+			return nil, i18n.NewError("config.type_required")
+		}
+		if req.Value == nil {
+			return nil, i18n.NewError("config.value_required")
+		}
+		cfg = SystemConfig{ConfigKey: key, CreatedBy: &operator}
+	}
+
+	value := req.Value
+	typeChanged := req.ConfigType != "" && req.ConfigType != cfg.ConfigType
+	encryptionChanged := req.IsEncrypted != nil && *req.IsEncrypted != cfg.IsEncrypted
+	if value == nil && (typeChanged || encryptionChanged) {
+		plain, err := plainValue(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		value = &plain
+	}
+
+	if req.ConfigType != "" {
+		if !req.ConfigType.IsValid() {
+			return nil, i18n.NewError("config.invalid_type")
+		}
+		cfg.ConfigType = req.ConfigType
+	}
+	if req.Description != nil {
+		cfg.Description = *req.Description
+	}
+	if req.IsEncrypted != nil {
+		cfg.IsEncrypted = *req.IsEncrypted
+	}
+
+	if value != nil {
+		if err := validateValue(cfg.ConfigType, *value); err != nil {
+			return nil, err
+		}
+		stored := *value
+		if cfg.IsEncrypted {
+			cipher, err := crypto.FromConfig()
+			if err != nil {
+				return nil, i18n.NewError("config.encryption_unavailable")
+			}
+			if stored, err = cipher.Encrypt(*value); err != nil {
+				return nil, err
+			}
+		}
+		cfg.ConfigValue = stored
+	}
+	cfg.UpdatedBy = &operator
+
+	if err := s.db.Save(&cfg).Error; err != nil {
+		return nil, err
+	}
+
+	s.Invalidate(key)
+	return &cfg, nil
+}
+
+// plainValue 已保存配置的明文值
+func plainValue(cfg *SystemConfig) (string, error) {
+	if !cfg.IsEncrypted {
+		return cfg.ConfigValue, nil
+	}
+	cipher, err := crypto.FromConfig()
+	if err != nil {
+		return "", i18n.NewError("config.encryption_unavailable")
+	}
+	return cipher.Decrypt(cfg.ConfigValue)
+}
+
+// Invalidate 清除配置缓存并通知集群内其他实例
+func (s *Service) Invalidate(key string) {
+	localCache.Delete(key)
+	if s.rdb == nil {
+		return
+	}
+
+	ctx := context.Background()
+	log := logger.GetLogger()
+	if err := s.rdb.Del(ctx, cacheKeyPrefix+key).Err(); err != nil {
+		log.Error("清除配置缓存失败:", key, err)
+	}
+	if err := s.rdb.Publish(ctx, InvalidateChannel, key).Err(); err != nil {
+		log.Error("发布配置变更通知失败:", key, err)
+	}
+}
+
+// Listen 订阅配置变更通知，清除本实例的进程内缓存，ctx 取消时退出
+func Listen(ctx context.Context, rdb *redis.Client) {
+	if rdb == nil {
+		return
+	}
+	log := logger.GetLogger()
+	pubsub := rdb.Subscribe(ctx, InvalidateChannel)
+
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				localCache.Delete(msg.Payload)
+				log.Debug("系统配置已失效:", msg.Payload)
+			}
+		}
+	}()
+}
+
+// validateValue 按配置类型校验值
+func validateValue(t ValueType, value string) error {
+	switch t {
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+			return i18n.NewError("config.invalid_number")
+		}
+	case TypeBoolean:
+		if _, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+			return i18n.NewError("config.invalid_boolean")
+		}
+	case TypeJSON:
+		if !json.Valid([]byte(value)) {
+			return i18n.NewError("config.invalid_json")
+		}
+	}
+	return nil
+}
@@ -2,14 +2,15 @@ package user
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
+	"xcloud-backend/pkg/i18n"
 	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
 )
 
 type Handler struct {
@@ -39,7 +40,7 @@ func (h *Handler) GetProfile(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
-			"message": "用户未认证",
+			"message": i18n.T(c, "common.unauthenticated"),
 		})
 		return
 	}
@@ -49,7 +50,7 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		h.logger.Error("用户ID格式错误:", userID, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -59,49 +60,52 @@ func (h *Handler) GetProfile(c *gin.Context) {
 		h.logger.Error("获取用户信息失败:", err)
 		c.JSON(http.StatusNotFound, gin.H{
 			"code":    404,
-			"message": "用户不存在",
+			"message": i18n.T(c, "user.not_found"),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, UserProfileResponse{
 		Code:    200,
-		Message: "获取成功",
+		Message: i18n.T(c, "common.fetch_success"),
 		Data:    user.ToResponse(),
 	})
 }
 
 // ListUsers 获取用户列表
 // @Summary 获取用户列表
-// @Description 分页获取用户列表（需要管理员权限）
+// @Description 游标分页获取用户列表（需要管理员权限）。通用过滤参数为 filter[字段][运算符]=取值，如 filter[role][in]=admin,manager，
+// @Description 可过滤字段：id、username、email、role、language、is_active、last_login_at、created_at、updated_at；
+// @Description sort 为逗号分隔的排序字段，- 表示倒序，默认按创建时间倒序
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "页码" default(1)
-// @Param page_size query int false "每页大小" default(10)
+// @Param sort query string false "排序字段" default(-created_at)
+// @Param limit query int false "每页大小" default(20)
+// @Param cursor query string false "分页游标，取上一次响应的 next_cursor 或 prev_cursor"
+// @Param count query bool false "是否返回总数"
 // @Success 200 {object} UserListResponse "用户列表"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
 // @Failure 401 {object} ErrorResponse "未认证"
 // @Failure 403 {object} ErrorResponse "权限不足"
 // @Router /users [get]
 func (h *Handler) ListUsers(c *gin.Context) {
-	// 获取分页参数
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 10
+	params, err := listQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
 	}
 
-	users, total, err := h.userSvc.ListUsers(page, pageSize)
+	users, page, err := h.userSvc.ListUsers(params)
 	if err != nil {
 		h.logger.Error("获取用户列表失败:", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
-			"message": "获取用户列表失败",
+			"message": i18n.T(c, "user.list_failed"),
 		})
 		return
 	}
@@ -114,15 +118,10 @@ func (h *Handler) ListUsers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, UserListResponse{
 		Code:    200,
-		Message: "获取成功",
+		Message: i18n.T(c, "common.fetch_success"),
 		Data: UserListData{
-			Users: userResponses,
-			Pagination: PaginationInfo{
-				Page:      page,
-				PageSize:  pageSize,
-				Total:     total,
-				TotalPage: (total + int64(pageSize) - 1) / int64(pageSize),
-			},
+			Users:      userResponses,
+			Pagination: *page,
 		},
 	})
 }
@@ -146,8 +145,8 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		h.logger.Error("创建用户请求参数错误:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "请求参数错误",
-			"error":   err.Error(),
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
 		})
 		return
 	}
@@ -157,7 +156,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
-			"message": "用户未认证",
+			"message": i18n.T(c, "common.unauthenticated"),
 		})
 		return
 	}
@@ -167,7 +166,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		h.logger.Error("创建者ID格式错误:", createdBy, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -177,7 +176,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		h.logger.Error("创建用户失败:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": err.Error(),
+			"message": i18n.Message(c, err),
 		})
 		return
 	}
@@ -185,7 +184,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 	h.logger.Info("用户创建成功:", user.Username)
 	c.JSON(http.StatusCreated, UserProfileResponse{
 		Code:    201,
-		Message: "用户创建成功",
+		Message: i18n.T(c, "user.created"),
 		Data:    user.ToResponse(),
 	})
 }
@@ -212,7 +211,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		h.logger.Error("用户ID格式错误:", idStr, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -222,8 +221,8 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		h.logger.Error("更新用户请求参数错误:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "请求参数错误",
-			"error":   err.Error(),
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
 		})
 		return
 	}
@@ -233,7 +232,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
-			"message": "用户未认证",
+			"message": i18n.T(c, "common.unauthenticated"),
 		})
 		return
 	}
@@ -243,7 +242,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		h.logger.Error("更新者ID格式错误:", updatedBy, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -253,7 +252,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		h.logger.Error("更新用户失败:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": err.Error(),
+			"message": i18n.Message(c, err),
 		})
 		return
 	}
@@ -261,7 +260,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 	h.logger.Info("用户更新成功:", user.Username)
 	c.JSON(http.StatusOK, UserProfileResponse{
 		Code:    200,
-		Message: "更新成功",
+		Message: i18n.T(c, "common.update_success"),
 		Data:    user.ToResponse(),
 	})
 }
@@ -287,7 +286,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		h.logger.Error("用户ID格式错误:", idStr, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -297,7 +296,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
-			"message": "用户未认证",
+			"message": i18n.T(c, "common.unauthenticated"),
 		})
 		return
 	}
@@ -307,7 +306,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		h.logger.Error("删除者ID格式错误:", deletedBy, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -316,7 +315,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	if userID == deletedByUUID {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "不能删除自己的账户",
+			"message": i18n.T(c, "user.cannot_delete_self"),
 		})
 		return
 	}
@@ -326,7 +325,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		h.logger.Error("删除用户失败:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": err.Error(),
+			"message": i18n.Message(c, err),
 		})
 		return
 	}
@@ -334,7 +333,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	h.logger.Info("用户删除成功:", userID)
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "删除成功",
+		"message": i18n.T(c, "common.delete_success"),
 	})
 }
 
@@ -356,8 +355,8 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		h.logger.Error("修改密码请求参数错误:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "请求参数错误",
-			"error":   err.Error(),
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
 		})
 		return
 	}
@@ -366,7 +365,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"code":    401,
-			"message": "用户未认证",
+			"message": i18n.T(c, "common.unauthenticated"),
 		})
 		return
 	}
@@ -376,7 +375,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		h.logger.Error("用户ID格式错误:", userID, err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": "无效的用户ID",
+			"message": i18n.T(c, "common.invalid_user_id"),
 		})
 		return
 	}
@@ -386,7 +385,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		h.logger.Error("修改密码失败:", err)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    400,
-			"message": err.Error(),
+			"message": i18n.Message(c, err),
 		})
 		return
 	}
@@ -395,7 +394,69 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 	h.logger.Info("密码修改成功:", username)
 	c.JSON(http.StatusOK, gin.H{
 		"code":    200,
-		"message": "密码修改成功",
+		"message": i18n.T(c, "user.password_changed"),
+	})
+}
+
+// UpdatePreferences 更新偏好设置
+// @Summary 更新偏好设置
+// @Description 更新当前用户的偏好设置（界面及接口消息语言），重新登录或刷新令牌后生效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body PreferencesRequest true "偏好设置"
+// @Success 200 {object} UserProfileResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 401 {object} ErrorResponse "未认证"
+// @Router /users/preferences [put]
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	var req PreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Error("更新偏好设置请求参数错误:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    401,
+			"message": i18n.T(c, "common.unauthenticated"),
+		})
+		return
+	}
+
+	uid, err := uuid.Parse(userID.(string))
+	if err != nil {
+		h.logger.Error("用户ID格式错误:", userID, err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return
+	}
+
+	user, err := h.userSvc.UpdateLanguage(uid, req.Language)
+	if err != nil {
+		h.logger.Error("更新偏好设置失败:", err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+
+	// 新的语言偏好立即用于本次响应
+	c.Set(i18n.ContextKey, user.Language)
+	c.JSON(http.StatusOK, UserProfileResponse{
+		Code:    200,
+		Message: i18n.T(c, "user.preferences_updated"),
+		Data:    user.ToResponse(),
 	})
 }
 
@@ -425,14 +486,7 @@ type UserListResponse struct {
 
 type UserListData struct {
 	Users      []UserResponse `json:"users"`
-	Pagination PaginationInfo `json:"pagination"`
-}
-
-type PaginationInfo struct {
-	Page      int   `json:"page"`
-	PageSize  int   `json:"page_size"`
-	Total     int64 `json:"total"`
-	TotalPage int64 `json:"total_page"`
+	Pagination query.Page     `json:"pagination"`
 }
 
 type ChangePasswordRequest struct {
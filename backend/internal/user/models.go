@@ -14,9 +14,13 @@ const (
 	RoleAdmin    UserRole = "admin"    // 管理员
 	RoleManager  UserRole = "manager"  // 经理
 	RoleEmployee UserRole = "employee" // 员工
+	RoleFinance  UserRole = "finance"  // 财务
 	RoleViewer   UserRole = "viewer"   // 查看者
 )
 
+// MinAdminPasswordLength 命令行和首次启动创建管理员、重置密码时的最小密码长度
+const MinAdminPasswordLength = 8
+
 // User 用户模型
 type User struct {
 	ID           uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
@@ -24,6 +28,7 @@ type User struct {
 	Email        string         `json:"email" gorm:"type:varchar(100);uniqueIndex;not null"`
 	PasswordHash string         `json:"-" gorm:"type:varchar(255);not null"`
 	Role         UserRole       `json:"role" gorm:"type:varchar(20);not null;default:'viewer'"`
+	Language     string         `json:"language" gorm:"type:varchar(10);not null;default:'zh-CN'"`
 	IsActive     bool           `json:"is_active" gorm:"not null;default:true"`
 	LastLoginAt  *time.Time     `json:"last_login_at,omitempty"`
 	CreatedAt    time.Time      `json:"created_at"`
@@ -41,7 +46,7 @@ func (User) TableName() string {
 // IsValidRole 检查角色是否有效
 func (r UserRole) IsValid() bool {
 	switch r {
-	case RoleAdmin, RoleManager, RoleEmployee, RoleViewer:
+	case RoleAdmin, RoleManager, RoleEmployee, RoleFinance, RoleViewer:
 		return true
 	default:
 		return false
@@ -57,6 +62,8 @@ func (u *User) HasPermission(requiredRole UserRole) bool {
 		return requiredRole == RoleManager || requiredRole == RoleEmployee || requiredRole == RoleViewer
 	case RoleEmployee:
 		return requiredRole == RoleEmployee || requiredRole == RoleViewer
+	case RoleFinance:
+		return requiredRole == RoleFinance || requiredRole == RoleEmployee || requiredRole == RoleViewer
 	case RoleViewer:
 		return requiredRole == RoleViewer
 	default:
@@ -70,6 +77,7 @@ type UserCreateRequest struct {
 	Email    string   `json:"email" binding:"required,email,max=100" example:"john@example.com"`
 	Password string   `json:"password" binding:"required,min=6,max=50" example:"password123"`
 	Role     UserRole `json:"role" binding:"required" example:"employee"`
+	Language string   `json:"language,omitempty" binding:"omitempty,oneof=zh-CN en-US" example:"zh-CN"`
 }
 
 // UserUpdateRequest 用户更新请求
@@ -77,15 +85,22 @@ type UserUpdateRequest struct {
 	Username string   `json:"username,omitempty" binding:"omitempty,min=3,max=50" example:"johndoe"`
 	Email    string   `json:"email,omitempty" binding:"omitempty,email,max=100" example:"john@example.com"`
 	Role     UserRole `json:"role,omitempty" binding:"omitempty" example:"employee"`
+	Language string   `json:"language,omitempty" binding:"omitempty,oneof=zh-CN en-US" example:"en-US"`
 	IsActive *bool    `json:"is_active,omitempty" example:"true"`
 }
 
+// PreferencesRequest 用户偏好设置请求
+type PreferencesRequest struct {
+	Language string `json:"language" binding:"required,oneof=zh-CN en-US" example:"en-US"`
+}
+
 // UserResponse 用户响应
 type UserResponse struct {
 	ID          uuid.UUID  `json:"id"`
 	Username    string     `json:"username"`
 	Email       string     `json:"email"`
 	Role        UserRole   `json:"role"`
+	Language    string     `json:"language"`
 	IsActive    bool       `json:"is_active"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
@@ -99,6 +114,7 @@ func (u *User) ToResponse() UserResponse {
 		Username:    u.Username,
 		Email:       u.Email,
 		Role:        u.Role,
+		Language:    u.Language,
 		IsActive:    u.IsActive,
 		LastLoginAt: u.LastLoginAt,
 		CreatedAt:   u.CreatedAt,
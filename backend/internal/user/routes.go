@@ -14,6 +14,7 @@ func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	// 当前用户信息
 	router.GET("/profile", handler.GetProfile)
 	router.POST("/change-password", handler.ChangePassword)
+	router.PUT("/preferences", handler.UpdatePreferences)
 
 	// 用户管理（需要管理员权限）
 	adminRoutes := router.Group("")
@@ -7,6 +7,9 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/query"
 )
 
 // Service 用户服务
@@ -25,14 +28,14 @@ func (s *Service) AuthenticateUser(username, password string) (*User, error) {
 	err := s.db.Where("username = ? AND is_active = true", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户名或密码错误")
+			return nil, i18n.NewError("auth.invalid_credentials")
 		}
 		return nil, err
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, errors.New("用户名或密码错误")
+		return nil, i18n.NewError("auth.invalid_credentials")
 	}
 
 	// 更新最后登录时间
@@ -49,7 +52,7 @@ func (s *Service) GetUserByID(id uuid.UUID) (*User, error) {
 	err := s.db.First(&user, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, i18n.NewError("user.not_found")
 		}
 		return nil, err
 	}
@@ -62,7 +65,7 @@ func (s *Service) GetUserByUsername(username string) (*User, error) {
 	err := s.db.Where("username = ?", username).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, i18n.NewError("user.not_found")
 		}
 		return nil, err
 	}
@@ -71,11 +74,25 @@ func (s *Service) GetUserByUsername(username string) (*User, error) {
 
 // CreateUser 创建用户
 func (s *Service) CreateUser(req UserCreateRequest, createdBy uuid.UUID) (*User, error) {
+	return s.createUser(req, &createdBy)
+}
+
+// CreateAdmin 创建管理员用户（命令行运维使用，无创建者）
+func (s *Service) CreateAdmin(username, email, password string) (*User, error) {
+	return s.createUser(UserCreateRequest{
+		Username: username,
+		Email:    email,
+		Password: password,
+		Role:     RoleAdmin,
+	}, nil)
+}
+
+func (s *Service) createUser(req UserCreateRequest, createdBy *uuid.UUID) (*User, error) {
 	// 检查用户名是否已存在
 	var existingUser User
 	err := s.db.Where("username = ?", req.Username).First(&existingUser).Error
 	if err == nil {
-		return nil, errors.New("用户名已存在")
+		return nil, i18n.NewError("user.username_exists")
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -84,7 +101,7 @@ func (s *Service) CreateUser(req UserCreateRequest, createdBy uuid.UUID) (*User,
 	// 检查邮箱是否已存在
 	err = s.db.Where("email = ?", req.Email).First(&existingUser).Error
 	if err == nil {
-		return nil, errors.New("邮箱已存在")
+		return nil, i18n.NewError("user.email_exists")
 	}
 	if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -92,7 +109,17 @@ func (s *Service) CreateUser(req UserCreateRequest, createdBy uuid.UUID) (*User,
 
 	// 验证角色
 	if !req.Role.IsValid() {
-		return nil, errors.New("无效的用户角色")
+		return nil, i18n.NewError("user.invalid_role")
+	}
+
+	// 验证语言
+	language := i18n.Default
+	if req.Language != "" {
+		lang, ok := i18n.Parse(req.Language)
+		if !ok {
+			return nil, i18n.NewError("user.invalid_language")
+		}
+		language = lang
 	}
 
 	// 加密密码
@@ -106,9 +133,10 @@ func (s *Service) CreateUser(req UserCreateRequest, createdBy uuid.UUID) (*User,
 		Email:        req.Email,
 		PasswordHash: string(hashedPassword),
 		Role:         req.Role,
+		Language:     string(language),
 		IsActive:     true,
-		CreatedBy:    &createdBy,
-		UpdatedBy:    &createdBy,
+		CreatedBy:    createdBy,
+		UpdatedBy:    createdBy,
 	}
 
 	if err := s.db.Create(&user).Error; err != nil {
@@ -124,7 +152,7 @@ func (s *Service) UpdateUser(id uuid.UUID, req UserUpdateRequest, updatedBy uuid
 	err := s.db.First(&user, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("用户不存在")
+			return nil, i18n.NewError("user.not_found")
 		}
 		return nil, err
 	}
@@ -135,7 +163,7 @@ func (s *Service) UpdateUser(id uuid.UUID, req UserUpdateRequest, updatedBy uuid
 		var existingUser User
 		err := s.db.Where("username = ? AND id != ?", req.Username, id).First(&existingUser).Error
 		if err == nil {
-			return nil, errors.New("用户名已存在")
+			return nil, i18n.NewError("user.username_exists")
 		}
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -148,7 +176,7 @@ func (s *Service) UpdateUser(id uuid.UUID, req UserUpdateRequest, updatedBy uuid
 		var existingUser User
 		err := s.db.Where("email = ? AND id != ?", req.Email, id).First(&existingUser).Error
 		if err == nil {
-			return nil, errors.New("邮箱已存在")
+			return nil, i18n.NewError("user.email_exists")
 		}
 		if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, err
@@ -158,11 +186,19 @@ func (s *Service) UpdateUser(id uuid.UUID, req UserUpdateRequest, updatedBy uuid
 
 	if req.Role != "" {
 		if !req.Role.IsValid() {
-			return nil, errors.New("无效的用户角色")
+			return nil, i18n.NewError("user.invalid_role")
 		}
 		user.Role = req.Role
 	}
 
+	if req.Language != "" {
+		lang, ok := i18n.Parse(req.Language)
+		if !ok {
+			return nil, i18n.NewError("user.invalid_language")
+		}
+		user.Language = string(lang)
+	}
+
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
@@ -182,7 +218,7 @@ func (s *Service) DeleteUser(id uuid.UUID, deletedBy uuid.UUID) error {
 	err := s.db.First(&user, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户不存在")
+			return i18n.NewError("user.not_found")
 		}
 		return err
 	}
@@ -195,23 +231,29 @@ func (s *Service) DeleteUser(id uuid.UUID, deletedBy uuid.UUID) error {
 	return s.db.Delete(&user).Error
 }
 
-// ListUsers 获取用户列表
-func (s *Service) ListUsers(page, pageSize int) ([]User, int64, error) {
-	var users []User
-	var total int64
-
-	// 计算总数
-	if err := s.db.Model(&User{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	// 分页查询
-	offset := (page - 1) * pageSize
-	if err := s.db.Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
-		return nil, 0, err
-	}
+// listQuery 用户列表可过滤、排序的字段
+var listQuery = query.Resource{
+	Fields: map[string]query.Field{
+		"id":       {Column: "users.id", Kind: query.UUID, Sortable: true},
+		"username": {Column: "users.username", Kind: query.String, Sortable: true},
+		"email":    {Column: "users.email", Kind: query.String, Sortable: true},
+		"role": {
+			Column: "users.role", Kind: query.Enum, Sortable: true,
+			Values: []string{string(RoleAdmin), string(RoleManager), string(RoleEmployee), string(RoleFinance), string(RoleViewer)},
+		},
+		"language":      {Column: "users.language", Kind: query.String},
+		"is_active":     {Column: "users.is_active", Kind: query.Bool},
+		"last_login_at": {Column: "users.last_login_at", Kind: query.Time, Nullable: true},
+		"created_at":    {Column: "users.created_at", Kind: query.Time, Sortable: true},
+		"updated_at":    {Column: "users.updated_at", Kind: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	Key:  "id",
+}
 
-	return users, total, nil
+// ListUsers 获取用户列表
+func (s *Service) ListUsers(params *query.Params) ([]User, *query.Page, error) {
+	return query.Find[User](s.db.Model(&User{}), params)
 }
 
 // ChangePassword 修改密码
@@ -220,14 +262,14 @@ func (s *Service) ChangePassword(userID uuid.UUID, oldPassword, newPassword stri
 	err := s.db.First(&user, "id = ?", userID).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("用户不存在")
+			return i18n.NewError("user.not_found")
 		}
 		return err
 	}
 
 	// 验证旧密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(oldPassword)); err != nil {
-		return errors.New("原密码错误")
+		return i18n.NewError("user.old_password_wrong")
 	}
 
 	// 加密新密码
@@ -240,4 +282,56 @@ func (s *Service) ChangePassword(userID uuid.UUID, oldPassword, newPassword stri
 	user.UpdatedBy = &userID
 
 	return s.db.Save(&user).Error
-}
\ No newline at end of file
+}
+
+// ResetPassword 重置用户密码（命令行运维使用，无需原密码）
+func (s *Service) ResetPassword(username, newPassword string) error {
+	var user User
+	err := s.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return i18n.NewError("user.not_found")
+		}
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Model(&user).Update("password_hash", string(hashedPassword)).Error
+}
+
+// CountAdmins 统计管理员数量
+func (s *Service) CountAdmins() (int64, error) {
+	var count int64
+	err := s.db.Model(&User{}).Where("role = ?", RoleAdmin).Count(&count).Error
+	return count, err
+}
+
+// UpdateLanguage 更新用户语言偏好
+func (s *Service) UpdateLanguage(userID uuid.UUID, language string) (*User, error) {
+	lang, ok := i18n.Parse(language)
+	if !ok {
+		return nil, i18n.NewError("user.invalid_language")
+	}
+
+	var user User
+	err := s.db.First(&user, "id = ?", userID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("user.not_found")
+		}
+		return nil, err
+	}
+
+	user.Language = string(lang)
+	user.UpdatedBy = &userID
+
+	if err := s.db.Save(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
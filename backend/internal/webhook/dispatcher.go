@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+
+	"xcloud-backend/pkg/crypto"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+)
+
+const (
+	// HeaderEvent 事件类型
+	HeaderEvent = "X-XCloud-Event"
+	// HeaderDelivery 投递记录ID，重试时不变
+	HeaderDelivery = "X-XCloud-Delivery"
+	// HeaderTimestamp 发送时的 Unix 时间戳（秒）
+	HeaderTimestamp = "X-XCloud-Timestamp"
+	// HeaderSignature 签名：sha256= 加 HMAC-SHA256(密钥, 时间戳 + "." + 请求体) 的十六进制
+	HeaderSignature = "X-XCloud-Signature"
+
+	// batchSize 每次领取的投递记录数
+	batchSize = 20
+	// maxResponseBody 投递记录保存的响应内容长度上限
+	maxResponseBody = 2048
+)
+
+// trigger 新的投递记录生成后唤醒本实例的投递任务
+var trigger = make(chan struct{}, 1)
+
+func wake() {
+	select {
+	case trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Sign 计算请求签名，接收方使用相同方法校验，并应拒绝时间戳与当前时间相差过大的请求
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// StartDispatcher 启动后台投递：生成投递记录后以及每隔 webhook.poll_interval_seconds 秒发送到期的投递，ctx 取消时退出
+// 投递记录在领取时锁定一段时间，多实例同时运行不会重复发送
+func StartDispatcher(ctx context.Context, svc *Service) {
+	interval := time.Duration(viper.GetInt("webhook.poll_interval_seconds")) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	client := newClient()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-trigger:
+			}
+			for {
+				n, err := svc.dispatch(ctx, client)
+				if err != nil {
+					logger.GetLogger().Error("Webhook投递失败:", err)
+					break
+				}
+				if n < batchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// newClient 创建投递使用的 HTTP 客户端
+func newClient() *http.Client {
+	return &http.Client{
+		Timeout: timeout(),
+		// 不跟随重定向，3xx 视为投递失败
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dispatch 领取一批到期的投递记录并发送，返回领取的记录数
+func (s *Service) dispatch(ctx context.Context, client *http.Client) (int, error) {
+	// 领取时将下次尝试时间推后到请求超时之后，实例中途退出时由其他实例在锁定到期后重试
+	now := time.Now()
+	var deliveries []Delivery
+	err := s.db.Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(2*timeout()+time.Minute), now, DeliveryPending, now, batchSize,
+	).Scan(&deliveries).Error
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	subs := make(map[uuid.UUID]*Subscription)
+	for i := range deliveries {
+		d := &deliveries[i]
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			var err error
+			if sub, err = s.Get(d.SubscriptionID); err != nil && !isNotFound(err) {
+				// 锁定到期后重试
+				logger.GetLogger().Error("读取Webhook订阅失败:", d.SubscriptionID, " ", err)
+				continue
+			}
+			// 已删除的订阅查不到，投递直接失败
+			subs[d.SubscriptionID] = sub
+		}
+		s.attempt(ctx, client, d, sub)
+	}
+	return len(deliveries), nil
+}
+
+// attempt 发送一次投递并保存结果：2xx 为成功，失败时按指数退避安排重试，达到最大尝试次数后不再重试
+func (s *Service) attempt(ctx context.Context, client *http.Client, d *Delivery, sub *Subscription) {
+	now := time.Now()
+	d.Attempts++
+	d.ResponseStatus, d.ResponseBody, d.DurationMs = nil, "", nil
+
+	var err error
+	switch {
+	case sub == nil:
+		err = errors.New("订阅已删除")
+		d.Attempts = maxAttempts()
+	case !sub.IsActive && d.Event != EventPing:
+		err = errors.New("订阅已停用")
+		d.Attempts = maxAttempts()
+	default:
+		err = s.send(ctx, client, d, sub)
+	}
+
+	if err == nil {
+		d.Status = DeliverySuccess
+		d.DeliveredAt = &now
+		d.LastError = ""
+	} else {
+		d.LastError = err.Error()
+		if d.Attempts >= maxAttempts() {
+			d.Status = DeliveryFailed
+		} else {
+			d.NextAttemptAt = now.Add(backoff(d.Attempts))
+		}
+		logger.GetLogger().Warn("Webhook投递失败:", d.ID, " ", d.Event, " 第", d.Attempts, "次: ", err)
+	}
+
+	err = s.db.Model(d).Select(
+		"status", "attempts", "next_attempt_at", "response_status", "response_body",
+		"last_error", "duration_ms", "delivered_at",
+	).Updates(d).Error
+	if err != nil {
+		logger.GetLogger().Error("保存Webhook投递结果失败:", d.ID, " ", err)
+	}
+}
+
+// send 签名并发送投递请求，记录响应状态和内容
+func (s *Service) send(ctx context.Context, client *http.Client, d *Delivery, sub *Subscription) error {
+	cipher, err := crypto.FromConfig()
+	if err != nil {
+		return err
+	}
+	secret, err := cipher.Decrypt(sub.Secret)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "XCloud-Webhook/1.0")
+	req.Header.Set(HeaderEvent, string(d.Event))
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, d.Payload))
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	duration := int(time.Since(start).Milliseconds())
+	d.DurationMs = &duration
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	d.ResponseStatus = &resp.StatusCode
+	d.ResponseBody = string(bytes.ToValidUTF8(body, nil))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("接收方返回 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff 第 attempts 次失败后的重试间隔：webhook.retry_base_seconds 秒起每次翻倍，不超过 webhook.retry_max_seconds 秒
+func backoff(attempts int) time.Duration {
+	base := time.Duration(viper.GetInt("webhook.retry_base_seconds")) * time.Second
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+	limit := time.Duration(viper.GetInt("webhook.retry_max_seconds")) * time.Second
+	if limit <= 0 {
+		limit = time.Hour
+	}
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+// isNotFound 是否为订阅不存在的错误
+func isNotFound(err error) bool {
+	var i18nErr *i18n.Error
+	return errors.As(err, &i18nErr) && i18nErr.Code == "webhook.not_found"
+}
+
+// maxAttempts 每条投递的最大尝试次数
+func maxAttempts() int {
+	if n := viper.GetInt("webhook.max_attempts"); n > 0 {
+		return n
+	}
+	return 8
+}
+
+// timeout 投递请求的超时时间
+func timeout() time.Duration {
+	if n := viper.GetInt("webhook.timeout_seconds"); n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	return 10 * time.Second
+}
@@ -0,0 +1,347 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"xcloud-backend/pkg/crypto"
+)
+
+const testSecret = "whsec_test"
+
+// received 接收方收到的一次请求
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver 记录收到的请求并按 status 返回的接收方
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []received
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, received{header: req.Header.Clone(), body: body})
+	if r.status >= 300 && r.status < 400 {
+		w.Header().Set("Location", "/moved")
+	}
+	w.WriteHeader(r.status)
+	w.Write([]byte("ok"))
+}
+
+func (r *receiver) received() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received(nil), r.requests...)
+}
+
+// setConfig 临时修改配置项，测试结束后恢复
+func setConfig(t *testing.T, key string, value interface{}) {
+	t.Helper()
+	old := viper.Get(key)
+	viper.Set(key, value)
+	t.Cleanup(func() { viper.Set(key, old) })
+}
+
+// newTestService 创建不连接数据库的服务，投递结果只生成 SQL 不执行
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	setConfig(t, "security.encryption_key", "webhook-test-key")
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db)
+}
+
+func newTestSubscription(t *testing.T, url string) *Subscription {
+	t.Helper()
+	sub := &Subscription{ID: uuid.New(), Name: "test", URL: url, IsActive: true}
+	if err := setSecret(sub, testSecret); err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func newTestDelivery(t *testing.T, sub *Subscription) *Delivery {
+	t.Helper()
+	envelope := Envelope{ID: uuid.New(), Event: EventPing, OccurredAt: time.Now(), Data: map[string]string{"hello": "world"}}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Delivery{
+		ID:             uuid.New(),
+		SubscriptionID: sub.ID,
+		Event:          envelope.Event,
+		EventID:        envelope.ID,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  envelope.OccurredAt,
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(`1700000000.{"id":"1"}`))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("secret", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("secret", 1700000001, body) == want {
+		t.Error("Sign does not depend on timestamp")
+	}
+	if Sign("other", 1700000000, body) == want {
+		t.Error("Sign does not depend on secret")
+	}
+}
+
+func TestAttemptDelivers(t *testing.T) {
+	svc := newTestService(t)
+	recv := &receiver{status: http.StatusNoContent}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	sub := newTestSubscription(t, srv.URL)
+	d := newTestDelivery(t, sub)
+	before := time.Now().Unix()
+	svc.attempt(context.Background(), newClient(), d, sub)
+
+	reqs := recv.received()
+	if len(reqs) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(reqs))
+	}
+	req := reqs[0]
+	if string(req.body) != string(d.Payload) {
+		t.Errorf("body = %s, want %s", req.body, d.Payload)
+	}
+	if got := req.header.Get(HeaderEvent); got != string(EventPing) {
+		t.Errorf("%s = %q", HeaderEvent, got)
+	}
+	if got := req.header.Get(HeaderDelivery); got != d.ID.String() {
+		t.Errorf("%s = %q, want %s", HeaderDelivery, got, d.ID)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || timestamp < before || timestamp > time.Now().Unix() {
+		t.Errorf("%s = %q", HeaderTimestamp, req.header.Get(HeaderTimestamp))
+	}
+	// 接收方按文档校验签名
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(req.header.Get(HeaderTimestamp) + "."))
+	mac.Write(req.body)
+	if got, want := req.header.Get(HeaderSignature), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+
+	if d.Status != DeliverySuccess || d.Attempts != 1 || d.DeliveredAt == nil || d.LastError != "" {
+		t.Errorf("delivery = status %s, attempts %d, delivered_at %v, last_error %q",
+			d.Status, d.Attempts, d.DeliveredAt, d.LastError)
+	}
+	if d.ResponseStatus == nil || *d.ResponseStatus != http.StatusNoContent {
+		t.Errorf("response_status = %v, want 204", d.ResponseStatus)
+	}
+}
+
+func TestAttemptSchedulesRetry(t *testing.T) {
+	setConfig(t, "webhook.retry_base_seconds", 10)
+	setConfig(t, "webhook.retry_max_seconds", 3600)
+
+	for _, status := range []int{http.StatusInternalServerError, http.StatusNotFound, http.StatusFound, http.StatusMovedPermanently} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			svc := newTestService(t)
+			recv := &receiver{status: status}
+			srv := httptest.NewServer(recv)
+			defer srv.Close()
+
+			sub := newTestSubscription(t, srv.URL)
+			d := newTestDelivery(t, sub)
+			start := time.Now()
+			svc.attempt(context.Background(), newClient(), d, sub)
+
+			// 重定向不跟随
+			if n := len(recv.received()); n != 1 {
+				t.Errorf("receiver got %d requests, want 1", n)
+			}
+			if d.Status != DeliveryPending || d.Attempts != 1 || d.DeliveredAt != nil {
+				t.Errorf("delivery = status %s, attempts %d, delivered_at %v", d.Status, d.Attempts, d.DeliveredAt)
+			}
+			if d.ResponseStatus == nil || *d.ResponseStatus != status {
+				t.Errorf("response_status = %v, want %d", d.ResponseStatus, status)
+			}
+			if !strings.Contains(d.LastError, strconv.Itoa(status)) {
+				t.Errorf("last_error = %q", d.LastError)
+			}
+			next := d.NextAttemptAt.Sub(start)
+			if next < 10*time.Second || next > 11*time.Second {
+				t.Errorf("next attempt in %s, want 10s", next)
+			}
+
+			// 第二次失败间隔翻倍
+			start = time.Now()
+			svc.attempt(context.Background(), newClient(), d, sub)
+			next = d.NextAttemptAt.Sub(start)
+			if d.Attempts != 2 || next < 20*time.Second || next > 21*time.Second {
+				t.Errorf("attempts %d, next attempt in %s, want 2 and 20s", d.Attempts, next)
+			}
+		})
+	}
+}
+
+func TestAttemptGivesUp(t *testing.T) {
+	setConfig(t, "webhook.max_attempts", 3)
+	svc := newTestService(t)
+	recv := &receiver{status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	sub := newTestSubscription(t, srv.URL)
+	d := newTestDelivery(t, sub)
+	d.Attempts = 2
+	svc.attempt(context.Background(), newClient(), d, sub)
+	if d.Status != DeliveryFailed || d.Attempts != 3 {
+		t.Errorf("delivery = status %s, attempts %d, want failed after 3", d.Status, d.Attempts)
+	}
+
+	// 订阅已删除或停用时不发送，直接失败
+	inactive := *sub
+	inactive.IsActive = false
+	event := newTestDelivery(t, sub)
+	event.Event = EventContractActivated
+	for name, s := range map[string]*Subscription{"deleted": nil, "inactive": &inactive} {
+		d := *event
+		svc.attempt(context.Background(), newClient(), &d, s)
+		if d.Status != DeliveryFailed {
+			t.Errorf("%s subscription: status = %s, want failed", name, d.Status)
+		}
+	}
+	if n := len(recv.received()); n != 1 {
+		t.Errorf("receiver got %d requests, want 1", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		setConfig(t, "webhook.retry_base_seconds", 10)
+		setConfig(t, "webhook.retry_max_seconds", 60)
+		tests := []struct {
+			attempts int
+			want     time.Duration
+		}{
+			{1, 10 * time.Second},
+			{2, 20 * time.Second},
+			{3, 40 * time.Second},
+			{4, 60 * time.Second},
+			{5, 60 * time.Second},
+			{100, 60 * time.Second},
+		}
+		for _, tt := range tests {
+			if got := backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		setConfig(t, "webhook.retry_base_seconds", 0)
+		setConfig(t, "webhook.retry_max_seconds", 0)
+		if got := backoff(1); got != 30*time.Second {
+			t.Errorf("backoff(1) = %s, want 30s", got)
+		}
+		if got := backoff(1000); got != time.Hour {
+			t.Errorf("backoff(1000) = %s, want 1h", got)
+		}
+	})
+}
+
+func TestRedeliveryKeepsEventID(t *testing.T) {
+	svc := newTestService(t)
+	recv := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	sub := newTestSubscription(t, srv.URL)
+	original := newTestDelivery(t, sub)
+	// 自动重试使用同一投递记录
+	svc.attempt(context.Background(), newClient(), original, sub)
+	svc.attempt(context.Background(), newClient(), original, sub)
+
+	recv.mu.Lock()
+	recv.status = http.StatusOK
+	recv.mu.Unlock()
+	operator := uuid.New()
+	d := redelivery(original, operator)
+	d.ID = uuid.New()
+	svc.attempt(context.Background(), newClient(), &d, sub)
+
+	if d.EventID != original.EventID || d.Event != original.Event || string(d.Payload) != string(original.Payload) {
+		t.Errorf("redelivery = event %s %s, want %s %s", d.Event, d.EventID, original.Event, original.EventID)
+	}
+	if d.RedeliveryOf == nil || *d.RedeliveryOf != original.ID || d.CreatedBy == nil || *d.CreatedBy != operator {
+		t.Errorf("redelivery_of = %v, created_by = %v", d.RedeliveryOf, d.CreatedBy)
+	}
+	if d.Status != DeliverySuccess || d.Attempts != 1 {
+		t.Errorf("redelivery = status %s, attempts %d", d.Status, d.Attempts)
+	}
+
+	reqs := recv.received()
+	if len(reqs) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(reqs))
+	}
+	wantDelivery := []string{original.ID.String(), original.ID.String(), d.ID.String()}
+	for i, req := range reqs {
+		if got := req.header.Get(HeaderDelivery); got != wantDelivery[i] {
+			t.Errorf("request %d: %s = %s, want %s", i, HeaderDelivery, got, wantDelivery[i])
+		}
+		var envelope Envelope
+		if err := json.Unmarshal(req.body, &envelope); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		if envelope.ID != original.EventID {
+			t.Errorf("request %d: event id = %s, want %s", i, envelope.ID, original.EventID)
+		}
+	}
+}
+
+// 密钥按配置加密保存，发送时解密
+func TestSecretEncrypted(t *testing.T) {
+	newTestService(t)
+	sub := newTestSubscription(t, "http://example.com")
+	if sub.Secret == testSecret || sub.Secret == "" {
+		t.Fatalf("secret stored as %q", sub.Secret)
+	}
+	cipher, err := crypto.FromConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cipher.Decrypt(sub.Secret); err != nil || got != testSecret {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,478 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
+)
+
+type Handler struct {
+	webhookSvc *Service
+	logger     *logrus.Logger
+}
+
+func NewHandler(db *gorm.DB) *Handler {
+	return &Handler{
+		webhookSvc: NewService(db),
+		logger:     logger.GetLogger(),
+	}
+}
+
+// ListEvents 获取可订阅的事件
+// @Summary 获取可订阅的事件
+// @Description 获取 Webhook 可订阅的业务事件：contract.activated（合同审批通过并生效）、commission.batch_paid（返佣付款批次已支付）、
+// @Description sync.failed（云平台账单同步失败）、credit.exceeded（客户信用敞口超过信用额度）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} EventListResponse "获取成功"
+// @Router /webhooks/events [get]
+func (h *Handler) ListEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, EventListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    Events,
+	})
+}
+
+// ListSubscriptions 获取 Webhook 订阅列表
+// @Summary 获取 Webhook 订阅列表
+// @Description 获取全部 Webhook 订阅，按创建时间倒序，不返回签名密钥（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SubscriptionListResponse "获取成功"
+// @Router /webhooks [get]
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.webhookSvc.List()
+	if err != nil {
+		h.respondError(c, "获取Webhook订阅列表失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, SubscriptionListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    subs,
+	})
+}
+
+// GetSubscription 获取 Webhook 订阅
+// @Summary 获取 Webhook 订阅
+// @Description 获取 Webhook 订阅详情，不返回签名密钥（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Success 200 {object} SubscriptionResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id} [get]
+func (h *Handler) GetSubscription(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	sub, err := h.webhookSvc.Get(id)
+	if err != nil {
+		h.respondError(c, "获取Webhook订阅失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *sub,
+	})
+}
+
+// CreateSubscription 创建 Webhook 订阅
+// @Summary 创建 Webhook 订阅
+// @Description 创建 Webhook 订阅。事件发生时向 url 发送 POST 请求，请求体为 {id, event, occurred_at, data}，
+// @Description 请求头 X-XCloud-Signature 为 sha256= 加 HMAC-SHA256(密钥, X-XCloud-Timestamp + "." + 请求体) 的十六进制。
+// @Description 未指定密钥时自动生成，明文密钥仅在本接口和更换密钥接口返回（需要管理员权限）
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SubscriptionRequest true "订阅"
+// @Success 201 {object} SubscriptionSecretResponse "创建成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Router /webhooks [post]
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var req SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookSvc.Create(req, operator)
+	if err != nil {
+		h.respondError(c, "创建Webhook订阅失败:", err)
+		return
+	}
+
+	h.logger.Info("Webhook订阅已创建:", sub.ID, " ", sub.URL)
+	c.JSON(http.StatusCreated, SubscriptionSecretResponse{
+		Code:    201,
+		Message: i18n.T(c, "webhook.created"),
+		Data:    *sub,
+	})
+}
+
+// UpdateSubscription 更新 Webhook 订阅
+// @Summary 更新 Webhook 订阅
+// @Description 更新 Webhook 订阅的名称、地址、事件和启用状态；secret 为空时保持原密钥。停用后待重试的投递不再发送（需要管理员权限）
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Param request body SubscriptionRequest true "订阅"
+// @Success 200 {object} SubscriptionResponse "更新成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id} [put]
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var req SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+			"error":   i18n.Message(c, err),
+		})
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookSvc.Update(id, req, operator)
+	if err != nil {
+		h.respondError(c, "更新Webhook订阅失败:", err)
+		return
+	}
+
+	h.logger.Info("Webhook订阅已更新:", sub.ID, " ", sub.URL)
+	c.JSON(http.StatusOK, SubscriptionResponse{
+		Code:    200,
+		Message: i18n.T(c, "webhook.updated"),
+		Data:    *sub,
+	})
+}
+
+// RotateSecret 更换 Webhook 签名密钥
+// @Summary 更换 Webhook 签名密钥
+// @Description 为订阅生成新的签名密钥并返回明文，之后的投递（包括重试）使用新密钥签名（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Success 200 {object} SubscriptionSecretResponse "更换成功"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id}/rotate-secret [post]
+func (h *Handler) RotateSecret(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.webhookSvc.RotateSecret(id, operator)
+	if err != nil {
+		h.respondError(c, "更换Webhook签名密钥失败:", err)
+		return
+	}
+
+	h.logger.Info("Webhook签名密钥已更换:", sub.ID)
+	c.JSON(http.StatusOK, SubscriptionSecretResponse{
+		Code:    200,
+		Message: i18n.T(c, "webhook.secret_rotated"),
+		Data:    *sub,
+	})
+}
+
+// DeleteSubscription 删除 Webhook 订阅
+// @Summary 删除 Webhook 订阅
+// @Description 删除 Webhook 订阅，投递记录保留，待重试的投递不再发送（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Success 200 {object} ErrorResponse "删除成功"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookSvc.Delete(id); err != nil {
+		h.respondError(c, "删除Webhook订阅失败:", err)
+		return
+	}
+
+	h.logger.Info("Webhook订阅已删除:", id)
+	c.JSON(http.StatusOK, gin.H{
+		"code":    200,
+		"message": i18n.T(c, "webhook.deleted"),
+	})
+}
+
+// PingSubscription 发送测试事件
+// @Summary 发送测试事件
+// @Description 向订阅发送一条 webhook.ping 测试事件（停用的订阅也会发送），投递结果在投递记录中查看（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Success 202 {object} DeliveryResponse "已加入投递队列"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id}/ping [post]
+func (h *Handler) PingSubscription(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookSvc.Ping(id, operator)
+	if err != nil {
+		h.respondError(c, "发送Webhook测试事件失败:", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, DeliveryResponse{
+		Code:    202,
+		Message: i18n.T(c, "webhook.queued"),
+		Data:    *delivery,
+	})
+}
+
+// ListDeliveries 获取投递记录
+// @Summary 获取投递记录
+// @Description 获取订阅的投递记录（不含请求体和响应内容），默认按创建时间倒序，游标分页。
+// @Description 可过滤字段：event、status、event_id、attempts、response_status、next_attempt_at、delivered_at、created_at（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "订阅ID"
+// @Param filter[status] query string false "投递状态（pending, success, failed）"
+// @Param filter[event] query string false "事件类型"
+// @Param sort query string false "排序字段，逗号分隔，- 表示倒序（created_at, next_attempt_at）" default(-created_at)
+// @Param limit query int false "每页数量（1-100）" default(20)
+// @Param cursor query string false "分页游标"
+// @Param count query bool false "是否返回总数" default(false)
+// @Success 200 {object} DeliveryListResponse "获取成功"
+// @Failure 400 {object} ErrorResponse "请求参数错误"
+// @Failure 404 {object} ErrorResponse "订阅不存在"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	params, err := deliveryQuery.Parse(c.Request.URL.Query())
+	if err != nil {
+		h.respondError(c, "解析投递记录查询参数失败:", err)
+		return
+	}
+
+	deliveries, page, err := h.webhookSvc.Deliveries(id, params)
+	if err != nil {
+		h.respondError(c, "获取投递记录失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, DeliveryListResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data: DeliveryListData{
+			Deliveries: deliveries,
+			Pagination: *page,
+		},
+	})
+}
+
+// GetDelivery 获取投递记录详情
+// @Summary 获取投递记录详情
+// @Description 获取投递记录详情，包括请求体、最后一次尝试的响应状态和响应内容（最多2KB）（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "投递记录ID"
+// @Success 200 {object} DeliveryResponse "获取成功"
+// @Failure 404 {object} ErrorResponse "投递记录不存在"
+// @Router /webhooks/deliveries/{id} [get]
+func (h *Handler) GetDelivery(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookSvc.GetDelivery(id)
+	if err != nil {
+		h.respondError(c, "获取投递记录失败:", err)
+		return
+	}
+	c.JSON(http.StatusOK, DeliveryResponse{
+		Code:    200,
+		Message: i18n.T(c, "common.fetch_success"),
+		Data:    *delivery,
+	})
+}
+
+// Redeliver 重新投递
+// @Summary 重新投递
+// @Description 以相同的事件ID和请求体重新投递，生成新的投递记录（redelivery_of 为原记录ID），订阅须处于启用状态（需要管理员权限）
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "投递记录ID"
+// @Success 202 {object} DeliveryResponse "已加入投递队列"
+// @Failure 400 {object} ErrorResponse "订阅已停用"
+// @Failure 404 {object} ErrorResponse "投递记录或订阅不存在"
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (h *Handler) Redeliver(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	operator, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := h.webhookSvc.Redeliver(id, operator)
+	if err != nil {
+		h.respondError(c, "重新投递失败:", err)
+		return
+	}
+
+	h.logger.Info("Webhook重新投递:", id, " 新投递记录:", delivery.ID)
+	c.JSON(http.StatusAccepted, DeliveryResponse{
+		Code:    202,
+		Message: i18n.T(c, "webhook.queued"),
+		Data:    *delivery,
+	})
+}
+
+// respondError 业务错误返回400（不存在时返回404），其他错误返回500
+func (h *Handler) respondError(c *gin.Context, logMessage string, err error) {
+	var i18nErr *i18n.Error
+	if errors.As(err, &i18nErr) {
+		status := http.StatusBadRequest
+		switch i18nErr.Code {
+		case "webhook.not_found", "webhook.delivery_not_found":
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"code":    status,
+			"message": i18n.Message(c, err),
+		})
+		return
+	}
+	h.logger.Error(logMessage, err)
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"code":    500,
+		"message": i18n.T(c, "common.internal_error"),
+	})
+}
+
+// currentUser 获取当前登录用户ID
+func currentUser(c *gin.Context) (uuid.UUID, bool) {
+	userID, _ := c.Get("user_id")
+	id, err := uuid.Parse(userID.(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_user_id"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// pathID 解析路径中的ID参数
+func pathID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": i18n.T(c, "common.invalid_params"),
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// 响应结构体
+
+type ErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Error   string `json:"error,omitempty"`
+}
+
+type EventListResponse struct {
+	Code    int     `json:"code"`
+	Message string  `json:"message"`
+	Data    []Event `json:"data"`
+}
+
+type SubscriptionResponse struct {
+	Code    int          `json:"code"`
+	Message string       `json:"message"`
+	Data    Subscription `json:"data"`
+}
+
+type SubscriptionSecretResponse struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    SubscriptionWithSecret `json:"data"`
+}
+
+type SubscriptionListResponse struct {
+	Code    int            `json:"code"`
+	Message string         `json:"message"`
+	Data    []Subscription `json:"data"`
+}
+
+type DeliveryResponse struct {
+	Code    int      `json:"code"`
+	Message string   `json:"message"`
+	Data    Delivery `json:"data"`
+}
+
+type DeliveryListData struct {
+	Deliveries []Delivery `json:"deliveries"`
+	Pagination query.Page `json:"pagination"`
+}
+
+type DeliveryListResponse struct {
+	Code    int              `json:"code"`
+	Message string           `json:"message"`
+	Data    DeliveryListData `json:"data"`
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Event 业务事件类型
+type Event string
+
+const (
+	EventContractActivated Event = "contract.activated"    // 合同审批通过并生效
+	EventBatchPaid         Event = "commission.batch_paid" // 返佣付款批次已支付
+	EventSyncFailed        Event = "sync.failed"           // 云平台账单同步失败
+	EventCreditExceeded    Event = "credit.exceeded"       // 客户信用敞口超过信用额度
+	// EventPing 测试事件，仅由测试接口发送给指定订阅，不能订阅
+	EventPing Event = "webhook.ping"
+)
+
+// Events 可订阅的事件
+var Events = []Event{EventContractActivated, EventBatchPaid, EventSyncFailed, EventCreditExceeded}
+
+// IsValid 检查事件是否可订阅
+func (e Event) IsValid() bool {
+	for _, v := range Events {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus 投递状态
+type DeliveryStatus string
+
+const (
+	DeliveryPending DeliveryStatus = "pending" // 等待投递或等待重试
+	DeliverySuccess DeliveryStatus = "success" // 接收方返回 2xx
+	DeliveryFailed  DeliveryStatus = "failed"  // 达到最大尝试次数或订阅已停用，不再重试
+)
+
+// Subscription Webhook 订阅
+type Subscription struct {
+	ID   uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	Name string    `json:"name" gorm:"type:varchar(100);not null"`
+	URL  string    `json:"url" gorm:"type:varchar(500);not null"`
+	// Events 订阅的事件，JSON 字符串数组
+	Events json.RawMessage `json:"events" gorm:"type:jsonb;not null" swaggertype:"array,string"`
+	// Secret 签名密钥，使用 security.encryption_key 加密存储
+	Secret    string         `json:"-" gorm:"type:text;not null"`
+	IsActive  bool           `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	CreatedBy *uuid.UUID     `json:"created_by,omitempty"`
+	UpdatedBy *uuid.UUID     `json:"updated_by,omitempty"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// TableName 设置表名
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Delivery 事件投递记录。每个事件对每个订阅生成一条记录，重试时更新同一条记录，手动重新投递时生成新记录
+type Delivery struct {
+	ID             uuid.UUID       `json:"id" gorm:"type:uuid;primary_key;default:uuid_generate_v4()"`
+	SubscriptionID uuid.UUID       `json:"subscription_id" gorm:"type:uuid;not null"`
+	Event          Event           `json:"event" gorm:"type:varchar(50);not null"`
+	EventID        uuid.UUID       `json:"event_id" gorm:"type:uuid;not null"`
+	Payload        json.RawMessage `json:"payload,omitempty" gorm:"type:jsonb;not null" swaggertype:"object"`
+	Status         DeliveryStatus  `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	Attempts       int             `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty" gorm:"type:text"`
+	LastError      string          `json:"last_error,omitempty" gorm:"type:text"`
+	DurationMs     *int            `json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	// RedeliveryOf 手动重新投递时原投递记录的ID
+	RedeliveryOf *uuid.UUID `json:"redelivery_of,omitempty" gorm:"type:uuid"`
+	CreatedBy    *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName 设置表名
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Envelope 投递的请求体
+type Envelope struct {
+	ID         uuid.UUID   `json:"id"`
+	Event      Event       `json:"event" example:"contract.activated"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// SubscriptionRequest 创建或更新订阅请求
+type SubscriptionRequest struct {
+	Name   string   `json:"name" binding:"required,max=100" example:"ERP"`
+	URL    string   `json:"url" binding:"required,url,max=500" example:"https://erp.example.com/hooks/xcloud"`
+	Events []string `json:"events" binding:"required,min=1,dive,required" example:"contract.activated,commission.batch_paid"`
+	// Secret 签名密钥，至少16个字符；创建时为空则自动生成，更新时为空则保持不变
+	Secret   string `json:"secret,omitempty" binding:"omitempty,min=16,max=200"`
+	IsActive *bool  `json:"is_active,omitempty" example:"true"`
+}
+
+// SubscriptionWithSecret 创建订阅或更换密钥后返回的订阅，仅此时返回明文密钥
+type SubscriptionWithSecret struct {
+	Subscription
+	Secret string `json:"secret" example:"whsec_8c5d1c7e0c6f4a7f9e1b2d3c4a5b6c7d"`
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/middleware"
+)
+
+// RegisterRoutes 注册 Webhook 订阅和投递记录路由（需要管理员权限）
+func RegisterRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	handler := NewHandler(db)
+
+	router.Use(middleware.RequireRole("admin"))
+	router.GET("/events", handler.ListEvents)
+	router.GET("", handler.ListSubscriptions)
+	router.POST("", handler.CreateSubscription)
+	router.GET("/:id", handler.GetSubscription)
+	router.PUT("/:id", handler.UpdateSubscription)
+	router.DELETE("/:id", handler.DeleteSubscription)
+	router.POST("/:id/rotate-secret", handler.RotateSecret)
+	router.POST("/:id/ping", handler.PingSubscription)
+	router.GET("/:id/deliveries", handler.ListDeliveries)
+
+	router.GET("/deliveries/:id", handler.GetDelivery)
+	router.POST("/deliveries/:id/redeliver", handler.Redeliver)
+}
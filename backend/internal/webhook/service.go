@@ -0,0 +1,313 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"xcloud-backend/pkg/crypto"
+	"xcloud-backend/pkg/i18n"
+	"xcloud-backend/pkg/logger"
+	"xcloud-backend/pkg/query"
+)
+
+// Service Webhook 订阅和投递服务
+type Service struct {
+	db *gorm.DB
+}
+
+// NewService 创建 Webhook 服务
+func NewService(db *gorm.DB) *Service {
+	return &Service{db: db}
+}
+
+// Publish 为订阅了该事件的全部启用订阅生成投递记录，由后台投递任务发送。
+// 在业务操作提交后调用；生成记录失败只记录日志，不影响业务操作
+func Publish(db *gorm.DB, event Event, data interface{}) {
+	if err := NewService(db).publish(event, data); err != nil {
+		logger.GetLogger().Error("生成Webhook投递记录失败:", event, " ", err)
+	}
+}
+
+func (s *Service) publish(event Event, data interface{}) error {
+	filter, _ := json.Marshal([]Event{event})
+	var subs []Subscription
+	if err := s.db.Where("is_active = true AND events @> ?", string(filter)).Find(&subs).Error; err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	envelope := Envelope{ID: uuid.New(), Event: event, OccurredAt: time.Now(), Data: data}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	deliveries := make([]Delivery, len(subs))
+	for i, sub := range subs {
+		deliveries[i] = Delivery{
+			SubscriptionID: sub.ID,
+			Event:          event,
+			EventID:        envelope.ID,
+			Payload:        payload,
+			Status:         DeliveryPending,
+			NextAttemptAt:  envelope.OccurredAt,
+		}
+	}
+	if err := s.db.Create(&deliveries).Error; err != nil {
+		return err
+	}
+	wake()
+	return nil
+}
+
+// List 获取全部订阅
+func (s *Service) List() ([]Subscription, error) {
+	subs := []Subscription{}
+	err := s.db.Order("created_at DESC").Find(&subs).Error
+	return subs, err
+}
+
+// Get 获取订阅
+func (s *Service) Get(id uuid.UUID) (*Subscription, error) {
+	var sub Subscription
+	if err := s.db.First(&sub, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("webhook.not_found")
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Create 创建订阅，未指定密钥时自动生成，返回明文密钥
+func (s *Service) Create(req SubscriptionRequest, operator uuid.UUID) (*SubscriptionWithSecret, error) {
+	sub := Subscription{IsActive: true, CreatedBy: &operator, UpdatedBy: &operator}
+	if err := apply(&sub, req); err != nil {
+		return nil, err
+	}
+	secret := req.Secret
+	if secret == "" {
+		secret = newSecret()
+	}
+	if err := setSecret(&sub, secret); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(&sub).Error; err != nil {
+		return nil, err
+	}
+	return &SubscriptionWithSecret{Subscription: sub, Secret: secret}, nil
+}
+
+// Update 更新订阅，请求中的密钥为空时保持原密钥
+func (s *Service) Update(id uuid.UUID, req SubscriptionRequest, operator uuid.UUID) (*Subscription, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(sub, req); err != nil {
+		return nil, err
+	}
+	if req.Secret != "" {
+		if err := setSecret(sub, req.Secret); err != nil {
+			return nil, err
+		}
+	}
+	sub.UpdatedBy = &operator
+	if err := s.db.Save(sub).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RotateSecret 为订阅生成新的签名密钥，返回明文密钥
+func (s *Service) RotateSecret(id uuid.UUID, operator uuid.UUID) (*SubscriptionWithSecret, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	secret := newSecret()
+	if err := setSecret(sub, secret); err != nil {
+		return nil, err
+	}
+	sub.UpdatedBy = &operator
+	if err := s.db.Model(sub).Select("secret", "updated_by").Updates(sub).Error; err != nil {
+		return nil, err
+	}
+	return &SubscriptionWithSecret{Subscription: *sub, Secret: secret}, nil
+}
+
+// Delete 删除订阅，已有投递记录保留，未完成的投递不再发送
+func (s *Service) Delete(id uuid.UUID) error {
+	res := s.db.Delete(&Subscription{}, "id = ?", id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return i18n.NewError("webhook.not_found")
+	}
+	return nil
+}
+
+// Ping 向订阅发送一条测试事件
+func (s *Service) Ping(id uuid.UUID, operator uuid.UUID) (*Delivery, error) {
+	sub, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	envelope := Envelope{
+		ID:         uuid.New(),
+		Event:      EventPing,
+		OccurredAt: time.Now(),
+		Data:       map[string]interface{}{"subscription_id": sub.ID, "name": sub.Name},
+	}
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	delivery := Delivery{
+		SubscriptionID: sub.ID,
+		Event:          EventPing,
+		EventID:        envelope.ID,
+		Payload:        payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  envelope.OccurredAt,
+		CreatedBy:      &operator,
+	}
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	wake()
+	return &delivery, nil
+}
+
+// deliveryQuery 投递记录列表可过滤、排序的字段
+var deliveryQuery = query.Resource{
+	Fields: map[string]query.Field{
+		"id":    {Column: "id", Kind: query.UUID, Sortable: true},
+		"event": {Column: "event", Kind: query.String},
+		"status": {
+			Column: "status", Kind: query.Enum,
+			Values: []string{string(DeliveryPending), string(DeliverySuccess), string(DeliveryFailed)},
+		},
+		"event_id":        {Column: "event_id", Kind: query.UUID},
+		"attempts":        {Column: "attempts", Kind: query.Integer},
+		"response_status": {Column: "response_status", Kind: query.Integer, Nullable: true},
+		"next_attempt_at": {Column: "next_attempt_at", Kind: query.Time, Sortable: true},
+		"delivered_at":    {Column: "delivered_at", Kind: query.Time, Nullable: true},
+		"created_at":      {Column: "created_at", Kind: query.Time, Sortable: true},
+	},
+	Sort: "-created_at",
+	Key:  "id",
+}
+
+// Deliveries 获取订阅的投递记录（不含请求体和响应内容）
+func (s *Service) Deliveries(subscriptionID uuid.UUID, params *query.Params) ([]Delivery, *query.Page, error) {
+	if _, err := s.Get(subscriptionID); err != nil {
+		return nil, nil, err
+	}
+	db := s.db.Model(&Delivery{}).Omit("payload", "response_body").Where("subscription_id = ?", subscriptionID)
+	return query.Find[Delivery](db, params)
+}
+
+// GetDelivery 获取投递记录
+func (s *Service) GetDelivery(id uuid.UUID) (*Delivery, error) {
+	var delivery Delivery
+	if err := s.db.First(&delivery, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, i18n.NewError("webhook.delivery_not_found")
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// Redeliver 以相同的事件ID和请求体重新投递，生成新的投递记录
+func (s *Service) Redeliver(id uuid.UUID, operator uuid.UUID) (*Delivery, error) {
+	original, err := s.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.Get(original.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.IsActive {
+		return nil, i18n.NewError("webhook.inactive")
+	}
+	delivery := redelivery(original, operator)
+	if err := s.db.Create(&delivery).Error; err != nil {
+		return nil, err
+	}
+	wake()
+	return &delivery, nil
+}
+
+// redelivery 生成重新投递记录，沿用原投递的事件ID和请求体
+func redelivery(original *Delivery, operator uuid.UUID) Delivery {
+	return Delivery{
+		SubscriptionID: original.SubscriptionID,
+		Event:          original.Event,
+		EventID:        original.EventID,
+		Payload:        original.Payload,
+		Status:         DeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+		CreatedBy:      &operator,
+	}
+}
+
+// apply 校验并设置订阅的名称、地址、事件和启用状态
+func apply(sub *Subscription, req SubscriptionRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return i18n.NewError("webhook.invalid_url", req.URL)
+	}
+	events := make([]Event, 0, len(req.Events))
+	seen := make(map[Event]bool)
+	for _, v := range req.Events {
+		e := Event(v)
+		if !e.IsValid() {
+			return i18n.NewError("webhook.invalid_event", v)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	sub.Name = req.Name
+	sub.URL = req.URL
+	sub.Events, _ = json.Marshal(events)
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// setSecret 加密保存签名密钥
+func setSecret(sub *Subscription, secret string) error {
+	cipher, err := crypto.FromConfig()
+	if err != nil {
+		return i18n.NewError("webhook.encryption_unavailable")
+	}
+	encrypted, err := cipher.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+	sub.Secret = encrypted
+	return nil
+}
+
+// newSecret 生成随机签名密钥
+func newSecret() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
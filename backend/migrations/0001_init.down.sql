@@ -0,0 +1,38 @@
+-- 回滚初始表结构
+
+-- 删除账单分表（继承自 billing_data_template）
+DO $$
+DECLARE
+    r RECORD;
+BEGIN
+    FOR r IN
+        SELECT c.relname FROM pg_inherits i
+        JOIN pg_class c ON c.oid = i.inhrelid
+        JOIN pg_class p ON p.oid = i.inhparent
+        WHERE p.relname = 'billing_data_template'
+    LOOP
+        EXECUTE format('DROP TABLE IF EXISTS %I', r.relname);
+    END LOOP;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS create_billing_data_partition(DATE);
+
+DROP TABLE IF EXISTS system_configs;
+DROP TABLE IF EXISTS sync_logs;
+DROP TABLE IF EXISTS commission_records;
+DROP TABLE IF EXISTS billing_data_template;
+DROP TABLE IF EXISTS commission_rules;
+DROP TABLE IF EXISTS contracts;
+DROP TABLE IF EXISTS customer_cloud_configs;
+DROP TABLE IF EXISTS cloud_providers;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS users;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TYPE IF EXISTS commission_status;
+DROP TYPE IF EXISTS cloud_provider;
+DROP TYPE IF EXISTS contract_status;
+DROP TYPE IF EXISTS customer_status;
+DROP TYPE IF EXISTS user_role;
@@ -1,9 +1,8 @@
--- XCloud多云对账平台数据库表结构设计
--- 创建时间: 2024年
--- 作者: XCloud开发团队
+-- XCloud多云对账平台初始表结构（原 docs/database/schema.sql）
 
--- 设置时区
-SET timezone = 'Asia/Shanghai';
+-- 创建必要的扩展
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
 -- 创建枚举类型
 CREATE TYPE user_role AS ENUM ('admin', 'operator', 'viewer');
@@ -332,5 +331,3 @@ $$ LANGUAGE plpgsql;
 -- 创建当前月份和下个月的分表
 SELECT create_billing_data_partition(CURRENT_DATE);
 SELECT create_billing_data_partition(CURRENT_DATE + INTERVAL '1 month');
-
-COMMENT ON DATABASE xcloud IS 'XCloud多云对账平台数据库 - 包含用户管理、客户管理、合同管理、返佣计算等核心功能的完整数据结构';
\ No newline at end of file
@@ -0,0 +1 @@
+-- PostgreSQL 不支持删除枚举值，回滚时保留 manager、employee
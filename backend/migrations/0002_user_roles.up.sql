@@ -0,0 +1,4 @@
+-- 对齐用户角色枚举与后端 user.UserRole（admin, manager, employee, viewer）
+-- 0001 中的 operator 角色保留以兼容历史数据
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'manager';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'employee';
@@ -0,0 +1,2 @@
+-- 用户语言偏好，决定接口返回消息的语言
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language VARCHAR(10) NOT NULL DEFAULT 'zh-CN';
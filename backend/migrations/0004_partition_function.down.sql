@@ -0,0 +1,40 @@
+-- 恢复原分表函数
+CREATE OR REPLACE FUNCTION create_billing_data_partition(partition_date DATE)
+RETURNS VOID AS $$
+DECLARE
+    table_name TEXT;
+    start_date DATE;
+    end_date DATE;
+BEGIN
+    -- 计算分表名称
+    table_name := 'billing_data_' || to_char(partition_date, 'YYYYMM');
+    
+    -- 计算分表范围
+    start_date := date_trunc('month', partition_date);
+    end_date := start_date + INTERVAL '1 month';
+    
+    -- 创建分表（如果不存在）
+    EXECUTE format('
+        CREATE TABLE IF NOT EXISTS %I (
+            LIKE billing_data_template INCLUDING ALL,
+            CHECK (billing_date >= %L AND billing_date < %L)
+        ) INHERITS (billing_data_template)',
+        table_name, start_date, end_date);
+    
+    -- 创建索引
+    EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(customer_id)', 
+        'idx_' || table_name || '_customer', table_name);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(provider)', 
+        'idx_' || table_name || '_provider', table_name);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(billing_period)', 
+        'idx_' || table_name || '_period', table_name);
+    EXECUTE format('CREATE INDEX IF NOT EXISTS %I ON %I(billing_date)', 
+        'idx_' || table_name || '_date', table_name);
+        
+    -- 创建更新时间触发器
+    EXECUTE format('CREATE TRIGGER update_%I_updated_at BEFORE UPDATE ON %I FOR EACH ROW EXECUTE FUNCTION update_updated_at_column()', 
+        table_name, table_name);
+        
+    RAISE NOTICE '分表 % 创建完成', table_name;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1 @@
+ALTER TABLE commission_records ALTER COLUMN commission_amount TYPE DECIMAL(15,2);
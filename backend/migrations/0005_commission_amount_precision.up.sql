@@ -0,0 +1,2 @@
+-- 返佣金额按系统配置 commission.precision 取整，最多保留4位小数
+ALTER TABLE commission_records ALTER COLUMN commission_amount TYPE DECIMAL(15,4);
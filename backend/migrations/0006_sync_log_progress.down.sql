@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_sync_logs_type;
+
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS created_by;
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS details;
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS error_count;
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS processed_count;
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS total_count;
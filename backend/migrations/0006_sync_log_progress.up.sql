@@ -0,0 +1,8 @@
+-- 同步日志记录任务进度和明细，用于账单文件导入等异步任务
+ALTER TABLE sync_logs ADD COLUMN IF NOT EXISTS total_count INTEGER NOT NULL DEFAULT 0;     -- 待处理总数
+ALTER TABLE sync_logs ADD COLUMN IF NOT EXISTS processed_count INTEGER NOT NULL DEFAULT 0; -- 已处理数
+ALTER TABLE sync_logs ADD COLUMN IF NOT EXISTS error_count INTEGER NOT NULL DEFAULT 0;     -- 出错数
+ALTER TABLE sync_logs ADD COLUMN IF NOT EXISTS details JSONB;                              -- 任务明细（如导入文件、行级错误报告）
+ALTER TABLE sync_logs ADD COLUMN IF NOT EXISTS created_by UUID REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS idx_sync_logs_type ON sync_logs(sync_type);
@@ -0,0 +1,4 @@
+DELETE FROM system_configs WHERE config_key IN ('reconcile.tolerance_amount', 'reconcile.tolerance_rate');
+
+DROP TABLE IF EXISTS reconciliation_discrepancies;
+DROP TABLE IF EXISTS reconciliation_runs;
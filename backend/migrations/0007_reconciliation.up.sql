@@ -0,0 +1,57 @@
+-- 对账：按客户、云平台、计费周期比对云平台账单金额、账单明细合计与合同预期费用
+
+-- 对账任务表
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    provider cloud_provider NOT NULL,
+    billing_period VARCHAR(10) NOT NULL, -- 计费周期（YYYY-MM）
+    invoiced_total DECIMAL(15,2), -- 云平台账单（发票）总额，未提供时不比对
+    billed_original_total DECIMAL(15,2) NOT NULL DEFAULT 0, -- 账单明细原价合计
+    billed_total DECIMAL(15,2) NOT NULL DEFAULT 0, -- 账单明细折后合计
+    expected_total DECIMAL(15,2) NOT NULL DEFAULT 0, -- 合同预期费用（原价×合同折扣率）
+    line_count INTEGER NOT NULL DEFAULT 0, -- 参与对账的账单明细数
+    uncovered_count INTEGER NOT NULL DEFAULT 0, -- 无生效合同覆盖的明细数（按原价计算预期费用）
+    tolerance_amount DECIMAL(15,2) NOT NULL, -- 金额容差
+    tolerance_rate DECIMAL(7,6) NOT NULL, -- 比例容差
+    discrepancy_count INTEGER NOT NULL DEFAULT 0,
+    details JSONB, -- 分账号的发票金额等输入
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id)
+);
+
+-- 对账差异表
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    run_id UUID NOT NULL REFERENCES reconciliation_runs(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    provider cloud_provider NOT NULL,
+    billing_period VARCHAR(10) NOT NULL,
+    level VARCHAR(20) NOT NULL, -- 差异层级（account, service, resource）
+    discrepancy_type VARCHAR(30) NOT NULL, -- 差异类型（invoice_vs_billed, billed_vs_expected）
+    account_id VARCHAR(100) NOT NULL DEFAULT '',
+    service_type VARCHAR(50) NOT NULL DEFAULT '',
+    resource_id VARCHAR(200) NOT NULL DEFAULT '',
+    expected_amount DECIMAL(15,2) NOT NULL,
+    actual_amount DECIMAL(15,2) NOT NULL,
+    difference DECIMAL(15,2) NOT NULL, -- actual - expected
+    difference_rate DECIMAL(12,6), -- difference / expected，预期为0时为空
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- 状态（open, explained, resolved）
+    explanation TEXT,
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconciliation_runs_scope ON reconciliation_runs(customer_id, provider, billing_period);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_run ON reconciliation_discrepancies(run_id);
+CREATE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_status ON reconciliation_discrepancies(status);
+
+CREATE TRIGGER update_reconciliation_discrepancies_updated_at BEFORE UPDATE ON reconciliation_discrepancies FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('reconcile.tolerance_amount', '1.00', 'number', '对账金额容差'),
+('reconcile.tolerance_rate', '0.001', 'number', '对账比例容差')
+ON CONFLICT (config_key) DO NOTHING;
@@ -0,0 +1,11 @@
+DELETE FROM system_configs WHERE config_key = 'billing.reporting_currency';
+
+ALTER TABLE commission_records DROP COLUMN IF EXISTS commission_amount_reporting;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS base_amount_reporting;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS exchange_rate;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS reporting_currency;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE contracts DROP COLUMN IF EXISTS currency;
+
+DROP TABLE IF EXISTS exchange_rates;
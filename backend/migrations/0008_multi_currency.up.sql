@@ -0,0 +1,37 @@
+-- 多币种：汇率表，合同和返佣记录增加币种，返佣记录同时保存原币和报告币种金额
+
+-- 汇率表：1 单位 base_currency = rate 单位 quote_currency
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    base_currency VARCHAR(3) NOT NULL,
+    quote_currency VARCHAR(3) NOT NULL,
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    rate_type VARCHAR(10) NOT NULL DEFAULT 'monthly', -- 汇率类型（daily: 日汇率, monthly: 月度汇率，rate_date 为当月1日）
+    rate_date DATE NOT NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'manual', -- 来源（manual 或汇率适配器名称）
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    UNIQUE (base_currency, quote_currency, rate_type, rate_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair ON exchange_rates(base_currency, quote_currency, rate_date);
+CREATE TRIGGER update_exchange_rates_updated_at BEFORE UPDATE ON exchange_rates FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- 合同币种：合同金额、返佣阶梯和固定返佣金额均以此币种计
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'CNY';
+
+-- 返佣记录：base_amount/commission_amount 为账单原币金额，*_reporting 为按计费周期汇率折算的报告币种金额
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS reporting_currency VARCHAR(3) NOT NULL DEFAULT 'CNY';
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS exchange_rate DECIMAL(18,8) NOT NULL DEFAULT 1;
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS base_amount_reporting DECIMAL(15,2);
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS commission_amount_reporting DECIMAL(15,4);
+UPDATE commission_records SET base_amount_reporting = base_amount, commission_amount_reporting = commission_amount
+WHERE base_amount_reporting IS NULL;
+ALTER TABLE commission_records ALTER COLUMN base_amount_reporting SET NOT NULL;
+ALTER TABLE commission_records ALTER COLUMN commission_amount_reporting SET NOT NULL;
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('billing.reporting_currency', 'CNY', 'string', '报表和返佣汇总使用的报告币种')
+ON CONFLICT (config_key) DO NOTHING;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_commission_records_settlement;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS settlement_id;
+
+DROP TABLE IF EXISTS commission_settlements;
+DROP TABLE IF EXISTS payout_batch_items;
+DROP TABLE IF EXISTS payout_batches;
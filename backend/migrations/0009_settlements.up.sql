@@ -0,0 +1,68 @@
+-- 返佣结算：按合同结算周期关账并锁定返佣记录，结算单汇总为付款批次
+
+-- 付款批次表
+CREATE TABLE IF NOT EXISTS payout_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    batch_no VARCHAR(50) UNIQUE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft', -- 状态（draft: 待审批, approved: 已审批, paid: 已支付, cancelled: 已取消）
+    currency VARCHAR(3) NOT NULL,
+    settlement_count INTEGER NOT NULL DEFAULT 0,
+    customer_count INTEGER NOT NULL DEFAULT 0,
+    total_amount DECIMAL(15,4) NOT NULL DEFAULT 0,
+    note TEXT,
+    approved_at TIMESTAMP,
+    approved_by UUID REFERENCES users(id),
+    paid_at TIMESTAMP,
+    paid_by UUID REFERENCES users(id),
+    payment_reference VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id)
+);
+
+-- 付款批次客户汇总表
+CREATE TABLE IF NOT EXISTS payout_batch_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    batch_id UUID NOT NULL REFERENCES payout_batches(id) ON DELETE CASCADE,
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    settlement_count INTEGER NOT NULL DEFAULT 0,
+    record_count INTEGER NOT NULL DEFAULT 0,
+    base_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    commission_total DECIMAL(15,4) NOT NULL DEFAULT 0,
+    UNIQUE (batch_id, customer_id)
+);
+
+-- 结算单表：一个合同的一个结算周期（cycle_start ~ cycle_end，含首尾月份）
+CREATE TABLE IF NOT EXISTS commission_settlements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    settlement_no VARCHAR(50) UNIQUE NOT NULL,
+    contract_id UUID NOT NULL REFERENCES contracts(id),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    cycle_start VARCHAR(10) NOT NULL, -- 周期首月（YYYY-MM）
+    cycle_end VARCHAR(10) NOT NULL, -- 周期末月（YYYY-MM）
+    status VARCHAR(20) NOT NULL DEFAULT 'closed', -- 状态（closed: 已关账, cancelled: 已撤销）
+    currency VARCHAR(3) NOT NULL, -- 报告币种
+    record_count INTEGER NOT NULL DEFAULT 0,
+    base_total DECIMAL(15,2) NOT NULL DEFAULT 0,
+    commission_total DECIMAL(15,4) NOT NULL DEFAULT 0,
+    payout_batch_id UUID REFERENCES payout_batches(id),
+    closed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by UUID REFERENCES users(id),
+    cancelled_at TIMESTAMP,
+    cancelled_by UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 同一合同周期只能有一个有效结算单
+CREATE UNIQUE INDEX IF NOT EXISTS uq_commission_settlements_cycle ON commission_settlements(contract_id, cycle_start) WHERE status <> 'cancelled';
+CREATE INDEX IF NOT EXISTS idx_commission_settlements_customer ON commission_settlements(customer_id);
+CREATE INDEX IF NOT EXISTS idx_commission_settlements_batch ON commission_settlements(payout_batch_id);
+
+-- 返佣记录关联结算单，已关联的记录被锁定，不再参与重算
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS settlement_id UUID REFERENCES commission_settlements(id);
+CREATE INDEX IF NOT EXISTS idx_commission_records_settlement ON commission_records(settlement_id);
+
+CREATE TRIGGER update_payout_batches_updated_at BEFORE UPDATE ON payout_batches FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_commission_settlements_updated_at BEFORE UPDATE ON commission_settlements FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS commission_runs;
+
+DROP INDEX IF EXISTS idx_commission_records_contract_period;
+DROP INDEX IF EXISTS idx_commission_records_adjusts;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS adjusts_record_id;
+ALTER TABLE commission_records DROP COLUMN IF EXISTS record_type;
@@ -0,0 +1,25 @@
+-- 返佣重算：已支付或已关账的返佣记录不再修改，差额写入关联原记录的调整记录
+
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS record_type VARCHAR(20) NOT NULL DEFAULT 'commission'; -- 记录类型（commission: 返佣, adjustment: 调整）
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS adjusts_record_id UUID REFERENCES commission_records(id); -- 调整记录对应的原返佣记录
+CREATE INDEX IF NOT EXISTS idx_commission_records_adjusts ON commission_records(adjusts_record_id);
+CREATE INDEX IF NOT EXISTS idx_commission_records_contract_period ON commission_records(contract_id, billing_period);
+
+-- 返佣计算任务表：记录每次计算/重算的汇总和按客户的净变动
+CREATE TABLE IF NOT EXISTS commission_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    billing_period VARCHAR(10) NOT NULL,
+    reporting_currency VARCHAR(3) NOT NULL,
+    contracts INTEGER NOT NULL DEFAULT 0,
+    records INTEGER NOT NULL DEFAULT 0,
+    adjustments INTEGER NOT NULL DEFAULT 0,
+    unmatched INTEGER NOT NULL DEFAULT 0,
+    total_base DECIMAL(15,2) NOT NULL DEFAULT 0,
+    total_commission DECIMAL(15,4) NOT NULL DEFAULT 0,
+    net_delta DECIMAL(15,4) NOT NULL DEFAULT 0, -- 本次计算前后返佣金额合计的变动
+    customers JSONB, -- 按客户的返佣金额变动
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_runs_period ON commission_runs(billing_period);
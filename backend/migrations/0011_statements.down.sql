@@ -0,0 +1,4 @@
+DELETE FROM system_configs WHERE config_key IN ('statement.company_name', 'statement.footer');
+
+DROP TABLE IF EXISTS statements;
+DROP TABLE IF EXISTS statement_jobs;
@@ -0,0 +1,40 @@
+-- 客户返佣对账单：批量生成任务和已生成的对账单文件
+
+-- 对账单批量生成任务表
+CREATE TABLE IF NOT EXISTS statement_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    billing_period VARCHAR(10) NOT NULL,
+    formats VARCHAR(50) NOT NULL, -- 生成格式，逗号分隔（pdf, xlsx）
+    customer_ids JSONB, -- 指定客户，为空时包含该周期有返佣记录的全部客户
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- 状态（running, success, failed）
+    total_count INTEGER NOT NULL DEFAULT 0,
+    processed_count INTEGER NOT NULL DEFAULT 0,
+    error_count INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_by UUID REFERENCES users(id)
+);
+
+-- 对账单文件表：同一客户、计费周期、格式只保留最新生成的文件
+CREATE TABLE IF NOT EXISTS statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    billing_period VARCHAR(10) NOT NULL,
+    format VARCHAR(10) NOT NULL, -- 文件格式（pdf, xlsx）
+    file_name VARCHAR(200) NOT NULL,
+    file_path VARCHAR(500) NOT NULL, -- 相对 upload.storage_path 的存储路径
+    file_size BIGINT NOT NULL DEFAULT 0,
+    job_id UUID REFERENCES statement_jobs(id) ON DELETE SET NULL,
+    generated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    generated_by UUID REFERENCES users(id),
+    UNIQUE (customer_id, billing_period, format)
+);
+
+CREATE INDEX IF NOT EXISTS idx_statements_period ON statements(billing_period);
+CREATE INDEX IF NOT EXISTS idx_statement_jobs_period ON statement_jobs(billing_period);
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('statement.company_name', 'XCloud', 'string', '对账单抬头的公司名称'),
+('statement.footer', '如对账单有疑问，请在收到后15日内联系您的客户经理。', 'string', '对账单页脚说明')
+ON CONFLICT (config_key) DO NOTHING;
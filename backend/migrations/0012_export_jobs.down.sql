@@ -0,0 +1,3 @@
+DELETE FROM system_configs WHERE config_key = 'report.export_stream_limit';
+
+DROP TABLE IF EXISTS export_jobs;
@@ -0,0 +1,24 @@
+-- 数据导出：超过直接下载上限的导出在后台生成文件
+
+CREATE TABLE IF NOT EXISTS export_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    resource VARCHAR(50) NOT NULL, -- 导出对象（customers, contracts, billing_lines, commission_records）
+    format VARCHAR(10) NOT NULL, -- 文件格式（csv, xlsx）
+    filters JSONB, -- 导出条件，与列表接口的查询参数一致
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- 状态（running, success, failed）
+    total_count INTEGER NOT NULL DEFAULT 0,
+    processed_count INTEGER NOT NULL DEFAULT 0,
+    file_name VARCHAR(200),
+    file_path VARCHAR(500), -- 相对 upload.storage_path 的存储路径
+    file_size BIGINT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_by UUID NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_jobs_created_by ON export_jobs(created_by, started_at DESC);
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('report.export_stream_limit', '10000', 'number', '导出记录数不超过该值时直接下载，超过时在后台生成文件')
+ON CONFLICT (config_key) DO NOTHING;
@@ -0,0 +1,3 @@
+DELETE FROM system_configs WHERE config_key IN ('contract.reminder_days', 'contract.auto_expire');
+
+DROP TABLE IF EXISTS contract_reminders;
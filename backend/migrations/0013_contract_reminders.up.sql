@@ -0,0 +1,16 @@
+-- 合同到期提醒：记录已发送的提醒，每个合同在同一结束日期的每个提前天数只提醒一次
+
+CREATE TABLE IF NOT EXISTS contract_reminders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    end_date DATE NOT NULL, -- 提醒时的合同结束日期，续期后重新提醒
+    lead_days INTEGER NOT NULL, -- 提前天数
+    recipient_count INTEGER NOT NULL DEFAULT 0,
+    sent_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (contract_id, end_date, lead_days)
+);
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('contract.reminder_days', '60,30,7', 'string', '合同到期提醒的提前天数，逗号分隔'),
+('contract.auto_expire', 'true', 'boolean', '是否自动将超过结束日期的生效合同置为已到期')
+ON CONFLICT (config_key) DO NOTHING;
@@ -0,0 +1,3 @@
+ALTER TABLE commission_records DROP COLUMN IF EXISTS contract_version_id;
+
+DROP TABLE IF EXISTS contract_versions;
@@ -0,0 +1,27 @@
+-- 合同版本：生效后的条款变更以修订生成新版本，历史版本不可修改
+
+CREATE TABLE IF NOT EXISTS contract_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    version_no INTEGER NOT NULL, -- 版本号，从1开始
+    effective_date DATE NOT NULL, -- 生效日期，版本在下一版本生效前有效
+    title VARCHAR(200) NOT NULL,
+    end_date DATE NOT NULL,
+    payment_terms TEXT,
+    contract_amount DECIMAL(15,2),
+    discount_rate DECIMAL(5,4),
+    reason TEXT, -- 修订原因
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    UNIQUE (contract_id, version_no),
+    UNIQUE (contract_id, effective_date)
+);
+
+-- 现有合同以当前条款生成版本1
+INSERT INTO contract_versions (contract_id, version_no, effective_date, title, end_date, payment_terms, contract_amount, discount_rate, created_at, created_by)
+SELECT id, 1, start_date, title, end_date, payment_terms, contract_amount, discount_rate, created_at, created_by
+FROM contracts
+ON CONFLICT DO NOTHING;
+
+-- 返佣记录对应的合同版本（按账单日期生效的版本）
+ALTER TABLE commission_records ADD COLUMN IF NOT EXISTS contract_version_id UUID REFERENCES contract_versions(id);
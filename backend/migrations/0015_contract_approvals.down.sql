@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS approval_tasks;
+DROP TABLE IF EXISTS contract_approvals;
+DROP TABLE IF EXISTS approval_chain_steps;
+DROP TABLE IF EXISTS approval_chains;
+-- PostgreSQL 不支持删除枚举值，回滚时保留 finance 角色
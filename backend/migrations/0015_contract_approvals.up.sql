@@ -0,0 +1,83 @@
+-- 合同审批流程：可配置的多级审批链，提交审批时按合同金额、折扣率选择审批链并生成审批任务
+
+-- 财务角色，用于审批链中的财务审批
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'finance';
+
+-- 审批链表
+CREATE TABLE IF NOT EXISTS approval_chains (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    min_contract_amount DECIMAL(15,2), -- 合同金额不低于该值时适用，为空不限
+    max_discount_rate DECIMAL(5,4), -- 折扣率不高于该值时适用，为空不限
+    priority INTEGER NOT NULL DEFAULT 0, -- 多个审批链适用时选择优先级最高的
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    deleted_at TIMESTAMP
+);
+
+-- 审批链步骤表：每一步由指定用户或指定角色的任一用户审批
+CREATE TABLE IF NOT EXISTS approval_chain_steps (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chain_id UUID NOT NULL REFERENCES approval_chains(id) ON DELETE CASCADE,
+    step_no INTEGER NOT NULL, -- 步骤序号，从1开始
+    name VARCHAR(100) NOT NULL,
+    approver_role VARCHAR(20), -- 审批角色
+    approver_id UUID REFERENCES users(id), -- 指定审批人
+    UNIQUE (chain_id, step_no),
+    CHECK ((approver_role IS NULL) <> (approver_id IS NULL))
+);
+
+-- 合同审批表：每次提交审批一条记录
+CREATE TABLE IF NOT EXISTS contract_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    chain_id UUID REFERENCES approval_chains(id),
+    chain_name VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 状态（pending, approved, rejected）
+    current_step INTEGER NOT NULL DEFAULT 1,
+    submitted_by UUID NOT NULL REFERENCES users(id),
+    submitted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- 审批任务表：提交时按审批链步骤生成，记录每一步的审批结果
+CREATE TABLE IF NOT EXISTS approval_tasks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    approval_id UUID NOT NULL REFERENCES contract_approvals(id) ON DELETE CASCADE,
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    step_no INTEGER NOT NULL,
+    step_name VARCHAR(100) NOT NULL,
+    approver_role VARCHAR(20),
+    approver_id UUID REFERENCES users(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting', -- 状态（waiting, pending, approved, rejected, skipped）
+    comment TEXT,
+    decided_by UUID REFERENCES users(id),
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (approval_id, step_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_approvals_contract ON contract_approvals(contract_id, submitted_at DESC);
+CREATE INDEX IF NOT EXISTS idx_approval_tasks_pending ON approval_tasks(status, approver_role, approver_id);
+
+-- 默认审批链：经理审批；大额合同：经理、财务、管理员依次审批
+WITH chain AS (
+    INSERT INTO approval_chains (name, description, priority)
+    VALUES ('默认审批', '未匹配其他审批链的合同由经理审批', 0)
+    RETURNING id
+)
+INSERT INTO approval_chain_steps (chain_id, step_no, name, approver_role)
+SELECT id, 1, '经理审批', 'manager' FROM chain;
+
+WITH chain AS (
+    INSERT INTO approval_chains (name, description, min_contract_amount, priority)
+    VALUES ('大额合同审批', '合同金额不低于100万的合同依次由经理、财务、管理员审批', 1000000, 10)
+    RETURNING id
+)
+INSERT INTO approval_chain_steps (chain_id, step_no, name, approver_role)
+SELECT id, s.step_no, s.name, s.role FROM chain,
+    (VALUES (1, '经理审批', 'manager'), (2, '财务审批', 'finance'), (3, '管理员审批', 'admin')) AS s(step_no, name, role);
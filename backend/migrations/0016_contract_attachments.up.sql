@@ -0,0 +1,16 @@
+-- 合同附件：签署后的合同扫描件等文件，文件内容保存在存储驱动（本地或 S3 兼容存储）中
+
+CREATE TABLE IF NOT EXISTS contract_attachments (
+    id UUID PRIMARY KEY,
+    contract_id UUID NOT NULL REFERENCES contracts(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL, -- 按文件内容识别的类型
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL, -- SHA-256（十六进制）
+    storage VARCHAR(20) NOT NULL, -- 存储驱动（local, s3）
+    storage_key VARCHAR(500) NOT NULL, -- 存储对象键
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_contract_attachments_contract ON contract_attachments(contract_id, created_at);
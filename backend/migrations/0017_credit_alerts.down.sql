@@ -0,0 +1,5 @@
+DELETE FROM system_configs WHERE config_key = 'credit.warning_thresholds';
+
+DROP INDEX IF EXISTS idx_commission_settlements_customer_cycle;
+
+DROP TABLE IF EXISTS credit_alerts;
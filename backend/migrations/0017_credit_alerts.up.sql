@@ -0,0 +1,18 @@
+-- 客户信用额度预警：记录已发送的额度预警，每个客户每个阈值只通知一次，使用率回落到阈值以下后删除
+
+CREATE TABLE IF NOT EXISTS credit_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    threshold INTEGER NOT NULL, -- 达到的预警阈值（百分比）
+    exposure DECIMAL(15,2) NOT NULL, -- 预警时的信用敞口（含下级客户，报告币种）
+    credit_limit DECIMAL(15,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (customer_id, threshold)
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_settlements_customer_cycle ON commission_settlements(customer_id, cycle_start, cycle_end) WHERE status = 'closed';
+
+INSERT INTO system_configs (config_key, config_value, config_type, description) VALUES
+('credit.warning_thresholds', '80,100', 'string', '客户信用额度预警阈值（百分比），逗号分隔')
+ON CONFLICT (config_key) DO NOTHING;
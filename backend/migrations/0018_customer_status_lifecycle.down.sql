@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS customer_status_history;
+
+ALTER TABLE customer_cloud_configs DROP COLUMN IF EXISTS sync_paused;
+
+CREATE TYPE customer_status_v1 AS ENUM ('active', 'inactive', 'suspended');
+
+ALTER TABLE customers ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE customers ALTER COLUMN status TYPE customer_status_v1
+    USING (CASE status::text WHEN 'churned' THEN 'inactive' ELSE status::text END)::customer_status_v1;
+DROP TYPE customer_status;
+ALTER TYPE customer_status_v1 RENAME TO customer_status;
+ALTER TABLE customers ALTER COLUMN status SET DEFAULT 'active';
@@ -0,0 +1,26 @@
+-- 客户状态生命周期：active（活跃）/ suspended（暂停）/ churned（流失），原 inactive（停用）合并为 churned
+-- 暂停客户时停用其云平台账号的数据同步并以 sync_paused 标记，恢复活跃时只重新启用被暂停的账号
+
+CREATE TYPE customer_status_v2 AS ENUM ('active', 'suspended', 'churned');
+
+ALTER TABLE customers ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE customers ALTER COLUMN status TYPE customer_status_v2
+    USING (CASE status::text WHEN 'inactive' THEN 'churned' ELSE status::text END)::customer_status_v2;
+DROP TYPE customer_status;
+ALTER TYPE customer_status_v2 RENAME TO customer_status;
+ALTER TABLE customers ALTER COLUMN status SET DEFAULT 'active';
+
+ALTER TABLE customer_cloud_configs ADD COLUMN IF NOT EXISTS sync_paused BOOLEAN NOT NULL DEFAULT false; -- 因客户暂停而停用同步
+
+CREATE TABLE IF NOT EXISTS customer_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL, -- 状态变更原因，用于流失分析
+    changed_by UUID REFERENCES users(id),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_status_history_customer ON customer_status_history(customer_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_customer_status_history_to_status ON customer_status_history(to_status, changed_at);
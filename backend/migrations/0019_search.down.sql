@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS idx_contracts_title_trgm;
+DROP INDEX IF EXISTS idx_contracts_contract_no_trgm;
+DROP INDEX IF EXISTS idx_contracts_search_vector;
+
+DROP INDEX IF EXISTS idx_customers_business_type;
+DROP INDEX IF EXISTS idx_customers_name_initials_trgm;
+DROP INDEX IF EXISTS idx_customers_contact_name_trgm;
+DROP INDEX IF EXISTS idx_customers_company_name_trgm;
+DROP INDEX IF EXISTS idx_customers_customer_code_trgm;
+DROP INDEX IF EXISTS idx_customers_search_vector;
+
+ALTER TABLE contracts DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE customers DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE customers DROP COLUMN IF EXISTS name_initials;
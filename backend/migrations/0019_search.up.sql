@@ -0,0 +1,24 @@
+-- 客户和合同搜索：simple 分词的全文索引、pg_trgm 模糊匹配索引，以及客户公司名称的拼音首字母
+-- name_initials 由应用在保存客户时写入，已有客户在服务启动时补全
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS name_initials VARCHAR(200); -- 公司名称拼音首字母
+ALTER TABLE customers ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', COALESCE(customer_code, '') || ' ' || COALESCE(company_name, '') || ' ' || COALESCE(contact_name, ''))
+) STORED;
+
+ALTER TABLE contracts ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', COALESCE(contract_no, '') || ' ' || COALESCE(title, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_customers_search_vector ON customers USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_customers_customer_code_trgm ON customers USING GIN (customer_code gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_company_name_trgm ON customers USING GIN (company_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_contact_name_trgm ON customers USING GIN (contact_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_name_initials_trgm ON customers USING GIN (name_initials gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_customers_business_type ON customers(business_type);
+
+CREATE INDEX IF NOT EXISTS idx_contracts_search_vector ON contracts USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_contracts_contract_no_trgm ON contracts USING GIN (contract_no gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_contracts_title_trgm ON contracts USING GIN (title gin_trgm_ops);
@@ -0,0 +1,40 @@
+-- Webhook：业务事件订阅和投递记录，投递失败时按指数退避重试
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    events JSONB NOT NULL DEFAULT '[]', -- 订阅的事件（contract.activated, commission.batch_paid, sync.failed, credit.exceeded）
+    secret TEXT NOT NULL, -- 签名密钥，使用 security.encryption_key 加密
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    updated_by UUID REFERENCES users(id),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_deleted_at ON webhook_subscriptions(deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id),
+    event VARCHAR(50) NOT NULL,
+    event_id UUID NOT NULL, -- 事件ID，同一事件对各订阅的投递和重新投递相同
+    payload JSONB NOT NULL, -- 请求体
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 状态（pending, success, failed）
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- 下次尝试时间，领取后推后作为锁定期
+    response_status INTEGER, -- 最后一次尝试的响应状态码
+    response_body TEXT, -- 最后一次尝试的响应内容（最多2KB）
+    last_error TEXT,
+    duration_ms INTEGER,
+    delivered_at TIMESTAMP,
+    redelivery_of UUID REFERENCES webhook_deliveries(id), -- 手动重新投递时原投递记录
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
@@ -0,0 +1,36 @@
+-- 通知中心：站内通知、用户通知偏好和管理员自定义的通知模板
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL, -- 事件类型，如 contract.expiring
+    title VARCHAR(200) NOT NULL, -- 按接收人语言渲染的标题
+    body TEXT NOT NULL,
+    link VARCHAR(500), -- 关联的前端页面路径
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+-- 没有记录的事件和渠道默认接收
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL, -- 渠道（email, in_app）
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, event, channel)
+);
+
+-- 没有自定义模板的事件和语言使用内置文本
+CREATE TABLE IF NOT EXISTS notification_templates (
+    event VARCHAR(50) NOT NULL,
+    language VARCHAR(10) NOT NULL, -- 语言（zh-CN, en-US）
+    subject VARCHAR(200) NOT NULL, -- 标题模板，{1}、{2} 等占位符依次替换为事件参数
+    body TEXT NOT NULL, -- 正文模板
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_by UUID REFERENCES users(id),
+    PRIMARY KEY (event, language)
+);
@@ -0,0 +1,12 @@
+// Package migrations 内嵌数据库版本迁移脚本
+//
+// 文件命名格式为 NNNN_描述.up.sql / NNNN_描述.down.sql，版本号单调递增，
+// 已发布的迁移不可修改，表结构变更需新增迁移文件。
+package migrations
+
+import "embed"
+
+// FS 迁移脚本文件系统
+//
+//go:embed *.sql
+var FS embed.FS
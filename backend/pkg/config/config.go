@@ -0,0 +1,212 @@
+// Package config 应用配置加载与校验
+//
+// 配置来源优先级（高到低）：
+//  1. 密钥文件：环境变量 XCLOUD_<KEY>_FILE 指向的文件内容（适用于 Docker/Kubernetes secrets）
+//  2. 环境变量：XCLOUD_<KEY>，键名中的 "." 替换为 "_"，如 XCLOUD_DATABASE_PASSWORD
+//  3. 配置文件 configs/config.yaml
+//  4. 内置默认值
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "XCLOUD"
+
+// 开发环境默认密钥，生产环境禁止使用
+const (
+	defaultDatabasePassword = "xcloud123"
+	defaultJWTSecret        = "xcloud-jwt-secret-key-2024"
+)
+
+// Config 应用配置
+type Config struct {
+	App      AppConfig      `mapstructure:"app"`
+	Server   ServerConfig   `mapstructure:"server"`
+	Database DatabaseConfig `mapstructure:"database"`
+	Redis    RedisConfig    `mapstructure:"redis"`
+	JWT      JWTConfig      `mapstructure:"jwt"`
+	Security SecurityConfig `mapstructure:"security"`
+	Log      LogConfig      `mapstructure:"log"`
+
+	// ConfigFile 实际读取的配置文件路径，未找到配置文件时为空
+	ConfigFile string `mapstructure:"-"`
+}
+
+// AppConfig 应用配置
+type AppConfig struct {
+	Name    string `mapstructure:"name"`
+	Version string `mapstructure:"version"`
+	Mode    string `mapstructure:"mode"`
+}
+
+// IsProduction 是否为生产模式
+func (a AppConfig) IsProduction() bool {
+	return a.Mode == "release" || a.Mode == "production"
+}
+
+// ServerConfig 服务器配置
+type ServerConfig struct {
+	Port         int `mapstructure:"port"`
+	ReadTimeout  int `mapstructure:"read_timeout"`
+	WriteTimeout int `mapstructure:"write_timeout"`
+}
+
+// DatabaseConfig 数据库配置
+type DatabaseConfig struct {
+	Host            string `mapstructure:"host"`
+	Port            int    `mapstructure:"port"`
+	Name            string `mapstructure:"name"`
+	Username        string `mapstructure:"username"`
+	Password        string `mapstructure:"password"`
+	MaxOpenConns    int    `mapstructure:"max_open_conns"`
+	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	AutoMigrate     bool   `mapstructure:"auto_migrate"`
+}
+
+// RedisConfig Redis配置
+type RedisConfig struct {
+	Addr       string `mapstructure:"addr"`
+	Password   string `mapstructure:"password"`
+	DB         int    `mapstructure:"db"`
+	MaxRetries int    `mapstructure:"max_retries"`
+	PoolSize   int    `mapstructure:"pool_size"`
+}
+
+// JWTConfig JWT配置
+type JWTConfig struct {
+	Secret             string `mapstructure:"secret"`
+	ExpireHours        int    `mapstructure:"expire_hours"`
+	RefreshExpireHours int    `mapstructure:"refresh_expire_hours"`
+}
+
+// SecurityConfig 安全配置
+type SecurityConfig struct {
+	EncryptionKey string `mapstructure:"encryption_key"`
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+	Output string `mapstructure:"output"`
+}
+
+// setDefaults 设置默认值
+// 只有设置了默认值（或出现在配置文件中）的键才能被环境变量覆盖
+func setDefaults() {
+	viper.SetDefault("app.name", "XCloud多云对账平台")
+	viper.SetDefault("app.version", "1.0.0")
+	viper.SetDefault("app.mode", "debug")
+	viper.SetDefault("server.port", 8080)
+	viper.SetDefault("server.read_timeout", 60)
+	viper.SetDefault("server.write_timeout", 60)
+	viper.SetDefault("database.host", "localhost")
+	viper.SetDefault("database.port", 5432)
+	viper.SetDefault("database.name", "xcloud")
+	viper.SetDefault("database.username", "xcloud")
+	viper.SetDefault("database.password", defaultDatabasePassword)
+	viper.SetDefault("database.max_open_conns", 100)
+	viper.SetDefault("database.max_idle_conns", 20)
+	viper.SetDefault("database.conn_max_lifetime", 1800)
+	viper.SetDefault("database.auto_migrate", false)
+	viper.SetDefault("redis.addr", "localhost:6379")
+	viper.SetDefault("redis.password", "")
+	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.max_retries", 3)
+	viper.SetDefault("redis.pool_size", 10)
+	viper.SetDefault("jwt.secret", defaultJWTSecret)
+	viper.SetDefault("jwt.expire_hours", 24)
+	viper.SetDefault("jwt.refresh_expire_hours", 168)
+	viper.SetDefault("security.encryption_key", "")
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", "json")
+	viper.SetDefault("log.output", "stdout")
+	viper.SetDefault("upload.import_max_size", 100<<20)
+	viper.SetDefault("upload.storage_path", "./uploads")
+	viper.SetDefault("upload.max_size", 10<<20)
+	viper.SetDefault("upload.allowed_types", []string{"image/jpeg", "image/png", "application/pdf", "text/csv", "application/vnd.ms-excel"})
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.url_expire_seconds", 900)
+	viper.SetDefault("storage.s3.endpoint", "")
+	viper.SetDefault("storage.s3.region", "us-east-1")
+	viper.SetDefault("storage.s3.bucket", "")
+	viper.SetDefault("storage.s3.access_key", "")
+	viper.SetDefault("storage.s3.secret_key", "")
+	viper.SetDefault("storage.s3.path_style", false)
+	viper.SetDefault("statement.font_path", "")
+	viper.SetDefault("report.cache_ttl", 300)
+	viper.SetDefault("contract.expiry_check_hour", 1)
+	viper.SetDefault("credit.check_interval_minutes", 60)
+	viper.SetDefault("notify.email.enabled", false)
+	viper.SetDefault("notify.email.port", 587)
+}
+
+// Load 加载并校验配置
+// 配置文件不存在时使用默认值和环境变量，配置文件格式错误或校验失败时返回错误
+func Load() (*Config, error) {
+	viper.SetConfigName("config")
+	viper.SetConfigType("yaml")
+	viper.AddConfigPath("./configs")
+	viper.AddConfigPath(".")
+
+	setDefaults()
+
+	viper.SetEnvPrefix(EnvPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+	}
+
+	if err := loadSecretFiles(); err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := viper.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	cfg.ConfigFile = viper.ConfigFileUsed()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// envKey 配置键对应的环境变量名
+func envKey(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// loadSecretFiles 读取 XCLOUD_<KEY>_FILE 指定的密钥文件并覆盖对应配置
+func loadSecretFiles() error {
+	for _, key := range viper.AllKeys() {
+		name := envKey(key)
+		path, ok := os.LookupEnv(name + "_FILE")
+		if !ok || path == "" {
+			continue
+		}
+		if _, set := os.LookupEnv(name); set {
+			return fmt.Errorf("环境变量 %s 与 %s_FILE 不能同时设置", name, name)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取密钥文件 %s_FILE 失败: %w", name, err)
+		}
+		viper.Set(key, strings.TrimRight(string(data), "\r\n"))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError 配置校验错误，包含全部不合法的配置项
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置校验失败:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.addf("%s 不能为空（环境变量 %s）", key, envKey(key))
+	}
+}
+
+func (v *validator) port(key string, value int) {
+	if value < 1 || value > 65535 {
+		v.addf("%s 必须在 1-65535 之间，当前为 %d", key, value)
+	}
+}
+
+func (v *validator) positive(key string, value int) {
+	if value <= 0 {
+		v.addf("%s 必须大于0，当前为 %d", key, value)
+	}
+}
+
+func (v *validator) nonNegative(key string, value int) {
+	if value < 0 {
+		v.addf("%s 不能为负数，当前为 %d", key, value)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf("%s 必须为 %s 之一，当前为 %q", key, strings.Join(allowed, "/"), value)
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.oneOf("app.mode", c.App.Mode, "debug", "release", "test", "production")
+
+	v.port("server.port", c.Server.Port)
+	v.nonNegative("server.read_timeout", c.Server.ReadTimeout)
+	v.nonNegative("server.write_timeout", c.Server.WriteTimeout)
+
+	v.required("database.host", c.Database.Host)
+	v.port("database.port", c.Database.Port)
+	v.required("database.name", c.Database.Name)
+	v.required("database.username", c.Database.Username)
+	v.positive("database.max_open_conns", c.Database.MaxOpenConns)
+	v.positive("database.max_idle_conns", c.Database.MaxIdleConns)
+	if c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		v.addf("database.max_idle_conns（%d）不能大于 database.max_open_conns（%d）",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	v.nonNegative("database.conn_max_lifetime", c.Database.ConnMaxLifetime)
+
+	v.required("redis.addr", c.Redis.Addr)
+	v.nonNegative("redis.db", c.Redis.DB)
+	v.nonNegative("redis.max_retries", c.Redis.MaxRetries)
+	v.positive("redis.pool_size", c.Redis.PoolSize)
+
+	v.required("jwt.secret", c.JWT.Secret)
+	v.positive("jwt.expire_hours", c.JWT.ExpireHours)
+	v.positive("jwt.refresh_expire_hours", c.JWT.RefreshExpireHours)
+
+	v.oneOf("log.level", c.Log.Level, "trace", "debug", "info", "warn", "error", "fatal", "panic")
+	v.oneOf("log.format", c.Log.Format, "json", "text")
+	v.oneOf("log.output", c.Log.Output, "stdout", "file")
+
+	// 生产环境禁止使用开发默认密钥
+	if c.App.IsProduction() {
+		if c.JWT.Secret == defaultJWTSecret {
+			v.addf("生产环境必须修改 jwt.secret（环境变量 %s）", envKey("jwt.secret"))
+		} else if len(c.JWT.Secret) < 32 {
+			v.addf("生产环境 jwt.secret 长度不能少于32个字符")
+		}
+		if c.Database.Password == defaultDatabasePassword {
+			v.addf("生产环境必须修改 database.password（环境变量 %s）", envKey("database.password"))
+		}
+		if c.Security.EncryptionKey == "" {
+			v.addf("生产环境必须设置 security.encryption_key（环境变量 %s）", envKey("security.encryption_key"))
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Package crypto 敏感数据加解密
+//
+// 使用 AES-256-GCM 加密，密钥由配置项 security.encryption_key 经 SHA-256 派生。
+// 密文格式为 base64(nonce || ciphertext)。
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+
+	"github.com/spf13/viper"
+)
+
+// ErrNoKey 未配置加密密钥
+var ErrNoKey = errors.New("未配置加密密钥 security.encryption_key")
+
+// Cipher 对称加解密器
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New 使用指定密钥创建加解密器
+func New(key string) (*Cipher, error) {
+	if key == "" {
+		return nil, ErrNoKey
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// FromConfig 使用配置中的密钥创建加解密器
+func FromConfig() (*Cipher, error) {
+	return New(viper.GetString("security.encryption_key"))
+}
+
+// Encrypt 加密明文
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密密文
+func (c *Cipher) Decrypt(encoded string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.New("密文格式错误")
+	}
+	size := c.aead.NonceSize()
+	if len(data) < size {
+		return "", errors.New("密文格式错误")
+	}
+	plain, err := c.aead.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		return "", errors.New("解密失败，请检查加密密钥")
+	}
+	return string(plain), nil
+}
@@ -1,21 +1,31 @@
 package database
 
 import (
-	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
+	"fmt"
+	"os"
+
 	"gorm.io/gorm"
 
 	"xcloud-backend/internal/user"
 	"xcloud-backend/pkg/logger"
 )
 
+// 初始管理员环境变量
+const (
+	EnvAdminUsername = "XCLOUD_ADMIN_USERNAME"
+	EnvAdminEmail    = "XCLOUD_ADMIN_EMAIL"
+	EnvAdminPassword = "XCLOUD_ADMIN_PASSWORD"
+)
+
 // InitializeData 初始化基础数据
+// 系统中没有管理员时，若设置了 XCLOUD_ADMIN_PASSWORD 则据此创建初始管理员，
+// 否则提示通过 create-admin 子命令创建
 func InitializeData(db *gorm.DB) error {
 	log := logger.GetLogger()
-	
-	// 检查是否已有管理员用户
-	var count int64
-	if err := db.Model(&user.User{}).Where("role = ?", user.RoleAdmin).Count(&count).Error; err != nil {
+	userSvc := user.NewService(db)
+
+	count, err := userSvc.CountAdmins()
+	if err != nil {
 		log.Error("检查管理员用户失败:", err)
 		return err
 	}
@@ -25,27 +35,30 @@ func InitializeData(db *gorm.DB) error {
 		return nil
 	}
 
-	// 创建默认管理员用户
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin123"), bcrypt.DefaultCost)
-	if err != nil {
-		log.Error("密码加密失败:", err)
-		return err
+	password := os.Getenv(EnvAdminPassword)
+	if password == "" {
+		log.Warn("系统中没有管理员用户，请执行 xcloud create-admin 创建")
+		return nil
 	}
-
-	adminUser := user.User{
-		ID:           uuid.New(),
-		Username:     "admin",
-		Email:        "admin@xcloud.com",
-		PasswordHash: string(hashedPassword),
-		Role:         user.RoleAdmin,
-		IsActive:     true,
+	if len(password) < user.MinAdminPasswordLength {
+		return fmt.Errorf("%s 长度不能少于%d位", EnvAdminPassword, user.MinAdminPasswordLength)
 	}
 
-	if err := db.Create(&adminUser).Error; err != nil {
-		log.Error("创建默认管理员失败:", err)
+	username := envOrDefault(EnvAdminUsername, "admin")
+	email := envOrDefault(EnvAdminEmail, "admin@xcloud.com")
+	adminUser, err := userSvc.CreateAdmin(username, email, password)
+	if err != nil {
+		log.Error("创建初始管理员失败:", err)
 		return err
 	}
 
-	log.Info("默认管理员用户创建成功 - 用户名: admin, 密码: admin123")
+	log.Info("初始管理员用户创建成功 - 用户名: ", adminUser.Username)
 	return nil
-}
\ No newline at end of file
+}
+
+func envOrDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -14,7 +14,7 @@ var rdb *redis.Client
 
 // InitRedis 初始化Redis连接
 func InitRedis() (*redis.Client, error) {
-    rdb = redis.NewClient(&redis.Options{
+    client := redis.NewClient(&redis.Options{
         Addr:         viper.GetString("redis.addr"),
         Password:     viper.GetString("redis.password"),
         DB:           viper.GetInt("redis.db"),
@@ -29,10 +29,13 @@ func InitRedis() (*redis.Client, error) {
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
     defer cancel()
 
-    _, err := rdb.Ping(ctx).Result()
+    _, err := client.Ping(ctx).Result()
     if err != nil {
+        // 连接失败时不保留客户端，GetRedis 返回 nil，依赖Redis的功能按未启用处理
+        client.Close()
         return nil, err
     }
+    rdb = client
 
     appLogger.GetLogger().Info("Redis连接成功")
     return rdb, nil
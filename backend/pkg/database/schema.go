@@ -0,0 +1,41 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// VerifySchema 检查模型字段与数据库表结构是否一致
+// 数据库表结构只由 migrations 维护，模型的gorm标签变更后若未新增迁移，启动时会在此处失败
+func VerifySchema(db *gorm.DB, models ...interface{}) error {
+	var problems []string
+	migrator := db.Migrator()
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("解析模型失败: %w", err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(table) {
+			problems = append(problems, fmt.Sprintf("缺少表 %s", table))
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(table, field.DBName) {
+				problems = append(problems, fmt.Sprintf("表 %s 缺少字段 %s", table, field.DBName))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("数据库表结构与模型不一致: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package fulltext
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/pkg/pinyin"
+)
+
+// maxQueryLength 关键词最大长度（字符），超出部分忽略
+const maxQueryLength = 100
+
+// Fields 参与搜索的字段
+// 关键词按 simple 分词匹配 Vector 列，同时在 Columns 中做模糊匹配（由 pg_trgm 索引支持，适用于中文和部分编号）；
+// 关键词只包含英文字母时还按前缀匹配 Initials 列中的拼音首字母
+type Fields struct {
+	Vector   string
+	Columns  []string
+	Initials string
+	// Prefix 前缀匹配时加权的列，如编号和名称
+	Prefix []string
+}
+
+// Normalize 去除关键词首尾空白并截断到最大长度
+func Normalize(q string) string {
+	q = strings.TrimSpace(q)
+	if r := []rune(q); len(r) > maxQueryLength {
+		q = string(r[:maxQueryLength])
+	}
+	return q
+}
+
+// Scope 按关键词过滤，关键词为空时不过滤
+func (f Fields) Scope(q string) func(*gorm.DB) *gorm.DB {
+	q = Normalize(q)
+	return func(db *gorm.DB) *gorm.DB {
+		if q == "" {
+			return db
+		}
+		pattern := "%" + escapeLike(q) + "%"
+		conds := []string{f.Vector + " @@ plainto_tsquery('simple', ?)"}
+		args := []interface{}{q}
+		for _, c := range f.Columns {
+			conds = append(conds, c+" ILIKE ?")
+			args = append(args, pattern)
+		}
+		if f.Initials != "" && pinyin.IsInitials(q) {
+			conds = append(conds, f.Initials+" LIKE ?")
+			args = append(args, strings.ToLower(escapeLike(q))+"%")
+		}
+		return db.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+}
+
+// Order 按相关度倒序：分词匹配得分、与各列的三元组相似度、前缀和拼音首字母前缀命中加权，
+// 相关度相同时按 fallback 排序；关键词为空时只按 fallback 排序
+func (f Fields) Order(q, fallback string) func(*gorm.DB) *gorm.DB {
+	q = Normalize(q)
+	return func(db *gorm.DB) *gorm.DB {
+		if q == "" {
+			return db.Order(fallback)
+		}
+		terms := []string{"ts_rank(" + f.Vector + ", plainto_tsquery('simple', ?))"}
+		args := []interface{}{q}
+		if len(f.Columns) > 0 {
+			similarity := make([]string, len(f.Columns))
+			for i, c := range f.Columns {
+				similarity[i] = "similarity(COALESCE(" + c + ", ''), ?)"
+				args = append(args, q)
+			}
+			terms = append(terms, "GREATEST("+strings.Join(similarity, ", ")+")")
+		}
+		prefix := escapeLike(q) + "%"
+		for _, c := range f.Prefix {
+			terms = append(terms, "CASE WHEN "+c+" ILIKE ? THEN 1 ELSE 0 END")
+			args = append(args, prefix)
+		}
+		if f.Initials != "" && pinyin.IsInitials(q) {
+			terms = append(terms, "CASE WHEN "+f.Initials+" LIKE ? THEN 0.5 ELSE 0 END")
+			args = append(args, strings.ToLower(prefix))
+		}
+		return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "(" + strings.Join(terms, " + ") + ") DESC, " + fallback,
+			Vars:               args,
+			WithoutParentheses: true,
+		}})
+	}
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,145 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Lang 语言标识
+type Lang string
+
+const (
+	ZhCN Lang = "zh-CN" // 简体中文
+	EnUS Lang = "en-US" // 美式英语
+
+	// Default 默认语言
+	Default = ZhCN
+
+	// ContextKey 用户语言偏好在gin上下文中的键
+	ContextKey = "user_lang"
+)
+
+// catalog 消息目录，按语言和错误码索引
+var catalog = map[Lang]map[string]string{
+	ZhCN: zhCN,
+	EnUS: enUS,
+}
+
+// Supported 返回支持的语言列表
+func Supported() []Lang {
+	return []Lang{ZhCN, EnUS}
+}
+
+// Parse 解析语言标识，支持 zh、zh-CN、en、en_US 等写法
+func Parse(s string) (Lang, bool) {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "_", "-"))
+	if s == "" {
+		return "", false
+	}
+	tag, err := language.Parse(s)
+	if err != nil {
+		return "", false
+	}
+	return fromTag(tag)
+}
+
+// FromAcceptLanguage 根据Accept-Language请求头选择语言
+func FromAcceptLanguage(header string) Lang {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return Default
+	}
+	// ParseAcceptLanguage 已按权重排序
+	for _, tag := range tags {
+		if lang, ok := fromTag(tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+func fromTag(tag language.Tag) (Lang, bool) {
+	base, _ := tag.Base()
+	switch base.String() {
+	case "zh":
+		return ZhCN, true
+	case "en":
+		return EnUS, true
+	default:
+		return "", false
+	}
+}
+
+// Translate 按语言翻译错误码，缺失时回退到默认语言，仍缺失则返回错误码本身
+func Translate(lang Lang, code string, args ...interface{}) string {
+	msg, ok := catalog[lang][code]
+	if !ok {
+		msg, ok = catalog[Default][code]
+	}
+	if !ok {
+		return code
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Error 带错误码的业务错误
+type Error struct {
+	Code string
+	Args []interface{}
+}
+
+// NewError 创建业务错误
+func NewError(code string, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// Error 返回默认语言的错误信息，用于日志
+func (e *Error) Error() string {
+	return Translate(Default, e.Code, e.Args...)
+}
+
+// Is 按错误码比较
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// FromContext 获取请求语言：优先使用用户偏好，其次是Accept-Language
+func FromContext(c *gin.Context) Lang {
+	if v, ok := c.Get(ContextKey); ok {
+		if lang, ok := Parse(fmt.Sprint(v)); ok {
+			return lang
+		}
+	}
+	return FromAcceptLanguage(c.GetHeader("Accept-Language"))
+}
+
+// T 翻译当前请求语言下的消息
+func T(c *gin.Context, code string, args ...interface{}) string {
+	return Translate(FromContext(c), code, args...)
+}
+
+// Message 将错误转换为当前请求语言的消息
+// 业务错误和参数校验错误会被翻译，其他错误原样返回
+func Message(c *gin.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+	lang := FromContext(c)
+
+	var bizErr *Error
+	if errors.As(err, &bizErr) {
+		return Translate(lang, bizErr.Code, bizErr.Args...)
+	}
+	if msg, ok := translateValidation(lang, err); ok {
+		return msg
+	}
+	return err.Error()
+}
@@ -0,0 +1,757 @@
+package i18n
+
+// zhCN 简体中文消息
+var zhCN = map[string]string{
+	// 通用
+	"common.invalid_params":  "请求参数错误",
+	"common.unauthenticated": "用户未认证",
+	"common.invalid_user_id": "无效的用户ID",
+	"common.internal_error":  "服务器内部错误",
+	"common.role_missing":    "无法获取用户角色信息",
+	"common.forbidden":       "权限不足",
+	"common.fetch_success":   "获取成功",
+	"common.update_success":  "更新成功",
+	"common.delete_success":  "删除成功",
+
+	// 认证
+	"auth.token_required":        "请提供认证令牌",
+	"auth.token_malformed":       "认证令牌格式错误",
+	"auth.token_invalid":         "认证令牌无效",
+	"auth.invalid_credentials":   "用户名或密码错误",
+	"auth.login_failed":          "登录失败",
+	"auth.login_success":         "登录成功",
+	"auth.refresh_token_invalid": "刷新令牌无效",
+	"auth.refresh_token_expired": "刷新令牌已过期",
+	"auth.token_user_invalid":    "令牌无效",
+	"auth.user_invalid":          "用户无效",
+	"auth.user_disabled":         "用户已被禁用",
+	"auth.refresh_failed":        "刷新失败",
+	"auth.refresh_success":       "令牌刷新成功",
+	"auth.logout_success":        "登出成功",
+	"auth.ticket_invalid":        "事件订阅凭证无效或已使用",
+
+	// 用户
+	"user.not_found":           "用户不存在",
+	"user.username_exists":     "用户名已存在",
+	"user.email_exists":        "邮箱已存在",
+	"user.invalid_role":        "无效的用户角色",
+	"user.invalid_language":    "不支持的语言",
+	"user.old_password_wrong":  "原密码错误",
+	"user.list_failed":         "获取用户列表失败",
+	"user.created":             "用户创建成功",
+	"user.cannot_delete_self":  "不能删除自己的账户",
+	"user.password_changed":    "密码修改成功",
+	"user.preferences_updated": "偏好设置已更新",
+
+	// 客户
+	"customer.list_success":       "获取客户列表成功",
+	"customer.detail_success":     "获取客户详情成功",
+	"customer.created":            "客户创建成功",
+	"customer.updated":            "客户更新成功",
+	"customer.deleted":            "客户删除成功",
+	"customer.not_found":          "客户不存在",
+	"customer.invalid_status":     "客户状态 %s 不合法，应为 active、suspended 或 churned",
+	"customer.invalid_transition": "客户状态不能从 %s 变更为 %s",
+	"customer.status_unchanged":   "客户状态已是 %s",
+	"customer.reason_required":    "请填写状态变更原因",
+	"customer.open_contracts":     "客户还有 %d 个未终止或未到期的合同，不能标记为流失",
+	"customer.invalid_date":       "%s 日期格式错误，应为YYYY-MM-DD",
+	"customer.invalid_provider":   "无效的云平台：%s",
+	"customer.status_changed":     "客户状态已更新",
+
+	// 合同
+	"contract.list_success":                  "获取合同列表成功",
+	"contract.detail_success":                "获取合同详情成功",
+	"contract.created":                       "合同创建成功",
+	"contract.invalid_within":                "到期天数格式错误，应为 1-%d 天，如 30d",
+	"contract.not_found":                     "合同不存在",
+	"contract.version_not_found":             "合同版本 %d 不存在",
+	"contract.invalid_date":                  "%s 日期格式错误，应为YYYY-MM-DD",
+	"contract.not_amendable":                 "合同状态为 %s，只有生效中的合同可以修订",
+	"contract.invalid_effective_date":        "修订生效日期必须晚于当前版本的生效日期 %s",
+	"contract.end_before_effective":          "结束日期不能早于修订生效日期",
+	"contract.amendment_no_change":           "修订内容与当前版本相同",
+	"contract.amended":                       "合同修订成功",
+	"contract.customer_not_found":            "客户不存在",
+	"contract.customer_not_active":           "客户状态为 %s，只有活跃客户可以新建合同",
+	"contract.invalid_status":                "无效的合同状态：%s",
+	"contract.invalid_provider":              "无效的云平台：%s",
+	"contract.no_exists":                     "合同编号 %s 已存在",
+	"contract.end_before_start":              "结束日期不能早于开始日期",
+	"contract.attachment_required":           "请选择要上传的文件",
+	"contract.attachment_empty":              "上传文件为空",
+	"contract.attachment_too_large":          "文件大小超过上限 %dMB",
+	"contract.attachment_invalid_name":       "文件名无效：不能为隐藏文件、缺少扩展名、超过255字节或包含可执行文件扩展名",
+	"contract.attachment_type_not_allowed":   "不允许上传的文件类型：%s",
+	"contract.attachment_extension_mismatch": "文件扩展名 %s 与文件内容类型 %s 不一致",
+	"contract.attachment_not_found":          "合同附件不存在",
+	"contract.attachment_forbidden":          "只有上传人、管理员和经理可以删除附件",
+	"contract.attachment_link_invalid":       "下载地址无效或已过期",
+	"contract.attachment_uploaded":           "附件上传成功",
+	"contract.attachment_deleted":            "附件删除成功",
+
+	"contract.notify.expiring_subject": "合同 %s 将于 %d 天后到期",
+	"contract.notify.expiring_body":    "合同 %s（%s），客户：%s，将于 %s 到期，剩余 %d 天，请及时跟进续签。",
+	"contract.notify.expired_subject":  "合同 %s 已到期",
+	"contract.notify.expired_body":     "合同 %s（%s），客户：%s，已于 %s 到期，合同状态已更新为已到期。",
+
+	// 系统配置
+	"config.not_found":              "配置不存在",
+	"config.list_failed":            "获取系统配置失败",
+	"config.type_required":          "新建配置需指定配置类型",
+	"config.value_required":         "新建配置需指定配置值",
+	"config.invalid_type":           "无效的配置类型",
+	"config.invalid_number":         "配置值必须为数字",
+	"config.invalid_boolean":        "配置值必须为布尔值",
+	"config.invalid_json":           "配置值必须为合法的JSON",
+	"config.encryption_unavailable": "未配置加密密钥，无法保存加密配置",
+
+	// 账单导入
+	"import.file_required":        "请上传账单文件",
+	"import.file_too_large":       "上传文件过大",
+	"import.unsupported_format":   "不支持的文件格式，仅支持CSV和XLSX",
+	"import.unsupported_provider": "不支持的云平台",
+	"import.invalid_period":       "计费周期格式错误，应为YYYY-MM",
+	"import.customer_not_found":   "客户不存在",
+	"import.header_not_found":     "未找到可识别的账单表头，请确认云平台与文件是否匹配",
+	"import.empty_file":           "账单文件中没有数据",
+	"import.rows_invalid":         "存在 %d 行错误数据，未导入。可修正后重新导入，或选择跳过错误行",
+	"import.no_valid_rows":        "没有可导入的有效数据",
+	"import.already_running":      "该客户在此云平台和计费周期已有进行中的导入任务",
+	"import.not_found":            "导入任务不存在",
+	"import.started":              "导入任务已创建",
+	"import.preview_success":      "预览成功",
+
+	// 账单导入行级错误
+	"import.row.invalid_number":     "数值格式错误: %s",
+	"import.row.account_missing":    "缺少账号ID，请在文件中提供或在导入时指定 account_id",
+	"import.row.service_missing":    "缺少服务类型",
+	"import.row.cost_missing":       "缺少费用金额",
+	"import.row.invalid_currency":   "无法识别的币种: %s",
+	"import.row.invalid_date":       "日期格式错误: %s",
+	"import.row.date_out_of_period": "账单日期 %s 不在计费周期内",
+
+	// 客户批量导入
+	"customer_import.file_required":   "请上传客户文件",
+	"customer_import.empty_file":      "客户文件中没有数据",
+	"customer_import.too_many_rows":   "单个文件最多导入 %d 个客户",
+	"customer_import.missing_columns": "缺少必填列: %s",
+	"customer_import.rows_invalid":    "存在 %d 行错误数据，未导入任何客户，请修正后重新导入",
+	"customer_import.code_conflict":   "客户编码已被其他请求占用，请重新预览后导入",
+	"customer_import.success":         "已导入 %d 个客户",
+
+	// 客户批量导入行级错误
+	"customer_import.row.required":         "不能为空",
+	"customer_import.row.too_long":         "长度不能超过 %d 个字符",
+	"customer_import.row.code_whitespace":  "客户编码不能包含空白字符: %s",
+	"customer_import.row.invalid_phone":    "电话格式错误: %s",
+	"customer_import.row.invalid_email":    "邮箱格式错误: %s",
+	"customer_import.row.invalid_level":    "客户级别应为正整数: %s",
+	"customer_import.row.invalid_credit":   "信用额度应为非负数，最多两位小数: %s",
+	"customer_import.row.self_parent":      "不能将自己设为上级客户",
+	"customer_import.row.duplicate_code":   "与第 %d 行的客户编码重复",
+	"customer_import.row.code_deleted":     "客户编码已被已删除的客户使用: %s",
+	"customer_import.row.code_exists":      "客户编码已存在: %s",
+	"customer_import.row.parent_not_found": "上级客户不存在: %s",
+	"customer_import.row.parent_cycle":     "上级客户存在循环引用: %s",
+	"customer_import.row.parent_invalid":   "上级客户所在行（第 %d 行）存在错误",
+
+	// 对账
+	"reconcile.invalid_period":        "计费周期格式错误，应为YYYY-MM",
+	"reconcile.customer_not_found":    "客户不存在",
+	"reconcile.run_not_found":         "对账任务不存在",
+	"reconcile.discrepancy_not_found": "对账差异不存在",
+	"reconcile.already_resolved":      "差异已解决，请先重新打开",
+	"reconcile.already_open":          "差异已处于待处理状态",
+	"reconcile.completed":             "对账完成",
+
+	// 汇率
+	"currency.invalid_code":      "币种代码无效，应为三位字母（如 USD）",
+	"currency.same_pair":         "基准币种与报价币种不能相同",
+	"currency.invalid_rate":      "汇率必须大于0",
+	"currency.invalid_rate_type": "无效的汇率类型",
+	"currency.invalid_date":      "汇率日期格式错误: %s，应为YYYY-MM-DD或YYYY-MM",
+	"currency.invalid_rate_row":  "第 %d 条汇率无效: %s",
+	"currency.invalid_csv_row":   "第 %d 行汇率格式错误: %s",
+	"currency.invalid_file":      "汇率文件格式错误",
+	"currency.missing_column":    "汇率文件缺少列: %s",
+	"currency.empty_file":        "汇率文件中没有数据",
+	"currency.file_required":     "请上传汇率文件",
+	"currency.fetcher_not_found": "未注册的汇率来源: %s",
+	"currency.fetch_failed":      "拉取汇率失败: %s",
+	"currency.rate_not_found":    "缺少 %s 兑 %s 在 %s 的汇率",
+	"currency.rate_not_found_id": "汇率不存在",
+	"currency.saved":             "汇率已保存",
+
+	// 返佣结算
+	"settlement.invalid_period":          "计费周期格式错误，应为YYYY-MM",
+	"settlement.period_out_of_contract":  "计费周期 %s 不在合同有效期内",
+	"settlement.contract_not_found":      "合同不存在",
+	"settlement.contract_not_settleable": "合同当前状态不能结算",
+	"settlement.cycle_not_ended":         "结算周期尚未结束（截至 %s），不能关账",
+	"settlement.already_closed":          "结算周期 %s ~ %s 已关账",
+	"settlement.mixed_currency":          "结算单币种不一致，请按币种分别处理",
+	"settlement.concurrent_update":       "返佣记录已被并发修改，请重试",
+	"settlement.not_found":               "结算单不存在",
+	"settlement.already_cancelled":       "结算单已撤销",
+	"settlement.in_batch":                "结算单已进入付款批次，请先取消批次",
+	"settlement.nothing_to_batch":        "没有可进入付款批次的结算单",
+	"settlement.not_batchable":           "部分结算单不存在、已撤销或已进入其他批次",
+	"settlement.batch_not_found":         "付款批次不存在",
+	"settlement.invalid_batch_status":    "付款批次当前状态（%s）不允许此操作",
+	"settlement.reference_required":      "请填写支付参考号",
+	"settlement.closed":                  "关账成功",
+	"settlement.cancelled":               "结算单已撤销",
+	"settlement.batch_created":           "付款批次已创建",
+	"settlement.batch_approved":          "付款批次已审批",
+	"settlement.batch_paid":              "付款批次已支付",
+	"settlement.batch_cancelled":         "付款批次已取消",
+
+	// 返佣计算
+	"commission.invalid_period": "计费周期格式错误，应为YYYY-MM",
+	"commission.run_not_found":  "返佣计算任务不存在",
+	"commission.recalculated":   "返佣重算完成",
+
+	// 客户对账单
+	"statement.invalid_period":     "计费周期格式错误，应为YYYY-MM",
+	"statement.invalid_format":     "对账单格式错误，应为 pdf、xlsx 或 json",
+	"statement.customer_not_found": "客户不存在",
+	"statement.no_data":            "计费周期 %s 没有账单或返佣数据",
+	"statement.not_found":          "对账单不存在",
+	"statement.job_not_found":      "对账单生成任务不存在",
+	"statement.file_missing":       "对账单文件不存在，请重新生成",
+	"statement.job_started":        "对账单生成任务已创建",
+	"statement.font_required":      "对账单包含中文等内置字体无法显示的文字，生成 PDF 需要配置中文字体（statement.font_path）",
+
+	// 对账单模板文字
+	"statement.label.title":                "客户返佣对账单",
+	"statement.label.customer":             "客户名称",
+	"statement.label.customer_code":        "客户编码",
+	"statement.label.period":               "计费周期",
+	"statement.label.currency":             "币种",
+	"statement.label.generated_at":         "生成时间",
+	"statement.label.sheet_summary":        "汇总",
+	"statement.label.sheet_tiers":          "规则阶梯",
+	"statement.label.sheet_adjustments":    "调整记录",
+	"statement.label.sheet_details":        "返佣明细",
+	"statement.label.section_summary":      "按云平台和服务类型汇总",
+	"statement.label.section_tiers":        "适用的返佣规则阶梯",
+	"statement.label.section_adjustments":  "调整记录",
+	"statement.label.section_payouts":      "发放状态",
+	"statement.label.section_settlements":  "结算与付款",
+	"statement.label.provider":             "云平台",
+	"statement.label.service_type":         "服务类型",
+	"statement.label.billed_amount":        "账单金额",
+	"statement.label.base_amount":          "计佣金额",
+	"statement.label.commission_rates":     "返佣比例",
+	"statement.label.commission_rate":      "返佣比例",
+	"statement.label.commission":           "返佣金额",
+	"statement.label.adjustment":           "调整金额",
+	"statement.label.net_commission":       "净返佣",
+	"statement.label.record_count":         "记录数",
+	"statement.label.total":                "合计",
+	"statement.label.paid":                 "已支付",
+	"statement.label.outstanding":          "未支付",
+	"statement.label.contract_no":          "合同编号",
+	"statement.label.tier_min":             "阶梯下限",
+	"statement.label.tier_max":             "阶梯上限",
+	"statement.label.unlimited":            "不限",
+	"statement.label.fixed_amount":         "固定金额",
+	"statement.label.contract_currency":    "合同币种",
+	"statement.label.created_at":           "创建时间",
+	"statement.label.status":               "状态",
+	"statement.label.settlement_no":        "结算单号",
+	"statement.label.cycle":                "结算周期",
+	"statement.label.batch_no":             "付款批次",
+	"statement.label.batch_status":         "批次状态",
+	"statement.label.payment_reference":    "支付参考号",
+	"statement.label.record_type":          "记录类型",
+	"statement.label.original_currency":    "原币种",
+	"statement.label.exchange_rate":        "汇率",
+	"statement.label.base_reporting":       "计佣金额（报告币种）",
+	"statement.label.commission_reporting": "返佣金额（报告币种）",
+	"statement.label.no_records":           "无记录",
+	"statement.label.status_pending":       "待计算",
+	"statement.label.status_calculated":    "已计算",
+	"statement.label.status_paid":          "已支付",
+	"statement.label.status_draft":         "待审批",
+	"statement.label.status_approved":      "已审批",
+	"statement.label.status_cancelled":     "已取消",
+	"statement.label.status_commission":    "返佣",
+	"statement.label.status_adjustment":    "调整",
+
+	// 统计报表
+	"report.invalid_period":    "计费周期格式错误，应为YYYY-MM",
+	"report.invalid_range":     "开始周期不能晚于结束周期，且最多查询 %d 个月",
+	"report.invalid_dimension": "不支持的统计维度",
+	"report.other":             "其他",
+
+	// 数据导出
+	"export.invalid_resource": "不支持的导出对象：%s",
+	"export.invalid_format":   "不支持的导出格式，应为 csv 或 xlsx",
+	"export.invalid_filter":   "导出条件 %s 格式错误",
+	"export.too_many_records": "导出记录数 %d 超过上限 %d，请缩小导出范围",
+	"export.job_started":      "导出任务已创建，完成后可下载文件",
+	"export.job_not_found":    "导出任务不存在",
+	"export.job_not_ready":    "导出任务尚未成功完成",
+	"export.file_missing":     "导出文件不存在",
+
+	"export.notify.ready_subject":  "导出文件 %s 已生成",
+	"export.notify.ready_body":     "导出文件 %s 已生成，共 %d 条记录，下载地址：%s",
+	"export.notify.failed_subject": "导出文件 %s 生成失败",
+	"export.notify.failed_body":    "导出文件 %s 生成失败：%s。请缩小导出范围或稍后重试。",
+
+	// 信用额度
+	"credit.customer_not_found": "客户不存在",
+
+	"credit.notify.warning_subject":  "客户 %s 信用额度使用已达 %d%%",
+	"credit.notify.warning_body":     "客户 %s（%s）未结算花费 %.2f %s，信用额度 %.2f %s，使用率 %.1f%%，请关注回款情况。",
+	"credit.notify.exceeded_subject": "客户 %s 已超出信用额度（%d%%）",
+	"credit.notify.exceeded_body":    "客户 %s（%s）未结算花费 %.2f %s，已超出信用额度 %.2f %s，使用率 %.1f%%，请及时处理。",
+
+	// 合同审批
+	"approval.submitted":          "合同已提交审批",
+	"approval.approved":           "审批已同意",
+	"approval.rejected":           "审批已驳回，合同已退回草稿",
+	"approval.chain_created":      "审批链创建成功",
+	"approval.chain_updated":      "审批链更新成功",
+	"approval.chain_deleted":      "审批链删除成功",
+	"approval.chain_not_found":    "审批链不存在",
+	"approval.task_not_found":     "审批任务不存在",
+	"approval.contract_not_found": "合同不存在",
+	"approval.not_submittable":    "合同状态为 %s，只有草稿合同可以提交审批",
+	"approval.no_chain":           "没有适用于该合同的审批链，请联系管理员配置",
+	"approval.task_not_pending":   "审批任务状态为 %s，不能处理",
+	"approval.not_approver":       "您不是该审批任务的审批人",
+	"approval.self_approval":      "不能审批本人提交的合同",
+	"approval.customer_inactive":  "客户状态为 %s，不是活跃客户，合同不能生效",
+	"approval.comment_required":   "驳回时必须填写审批意见",
+	"approval.invalid_step":       "第 %d 步需指定审批角色或审批人（二选一）",
+	"approval.invalid_role":       "无效的审批角色：%s",
+	"approval.approver_not_found": "第 %d 步的审批人不存在或已禁用",
+
+	"approval.notify.pending_subject":  "合同 %s 待您审批",
+	"approval.notify.pending_body":     "合同 %s 已进入第 %d 步审批（%s），请及时处理。",
+	"approval.notify.approved_subject": "合同 %s 审批通过",
+	"approval.notify.approved_body":    "您提交的合同 %s 已通过全部审批，合同已生效。",
+	"approval.notify.rejected_subject": "合同 %s 审批被驳回",
+	"approval.notify.rejected_body":    "您提交的合同 %s 在“%s”步骤被驳回，意见：%s。合同已退回草稿，可修改后重新提交。",
+
+	// 搜索
+	"search.invalid_type":   "搜索对象 %s 不合法，应为 customer 或 contract",
+	"search.invalid_status": "状态 %s 不适用于搜索对象 %s",
+
+	// 列表查询
+	"query.invalid_param":    "查询参数 %s 格式错误，应为 filter[字段] 或 filter[字段][运算符]",
+	"query.unknown_field":    "不支持按字段 %s 过滤",
+	"query.invalid_operator": "字段 %s 不支持运算符 %s",
+	"query.invalid_value":    "字段 %s 的取值 %s 不合法",
+	"query.invalid_sort":     "不支持按字段 %s 排序",
+	"query.invalid_limit":    "每页数量应为 1-%d",
+	"query.invalid_cursor":   "分页游标无效或与排序条件不一致",
+
+	// 通知中心
+	"notification.not_found":           "通知不存在",
+	"notification.template_not_found":  "该事件在此语言下没有自定义模板",
+	"notification.unknown_event":       "通知事件 %s 不存在",
+	"notification.invalid_language":    "语言 %s 不合法，应为 zh-CN 或 en-US",
+	"notification.invalid_placeholder": "占位符 %s 超出参数个数（%d 个）",
+	"notification.marked_read":         "已标记为已读",
+	"notification.all_marked_read":     "已将 %d 条通知标记为已读",
+	"notification.preferences_updated": "通知偏好已更新",
+	"notification.template_updated":    "通知模板已更新",
+	"notification.template_deleted":    "已恢复默认通知模板",
+	"datasync.notify.failed_subject":   "客户 %s 的 %s 账单同步失败",
+	"datasync.notify.failed_body":      "客户 %s（%s）在 %s 云平台 %s 计费周期的账单同步失败：%s。请检查云平台账号配置后重新同步。",
+
+	// Webhook
+	"webhook.not_found":              "Webhook订阅不存在",
+	"webhook.delivery_not_found":     "投递记录不存在",
+	"webhook.invalid_url":            "回调地址 %s 不合法，应为 http 或 https 地址",
+	"webhook.invalid_event":          "不支持订阅事件 %s",
+	"webhook.inactive":               "Webhook订阅已停用",
+	"webhook.encryption_unavailable": "未配置加密密钥，无法保存签名密钥",
+	"webhook.created":                "Webhook订阅创建成功，请妥善保存签名密钥，之后将不再显示",
+	"webhook.updated":                "Webhook订阅更新成功",
+	"webhook.deleted":                "Webhook订阅删除成功",
+	"webhook.secret_rotated":         "签名密钥已更换，请妥善保存，之后将不再显示",
+	"webhook.queued":                 "已加入投递队列",
+}
+
+// enUS 英文消息
+var enUS = map[string]string{
+	// Common
+	"common.invalid_params":  "Invalid request parameters",
+	"common.unauthenticated": "User not authenticated",
+	"common.invalid_user_id": "Invalid user ID",
+	"common.internal_error":  "Internal server error",
+	"common.role_missing":    "Unable to determine user role",
+	"common.forbidden":       "Insufficient permissions",
+	"common.fetch_success":   "Retrieved successfully",
+	"common.update_success":  "Updated successfully",
+	"common.delete_success":  "Deleted successfully",
+
+	// Auth
+	"auth.token_required":        "Authentication token required",
+	"auth.token_malformed":       "Malformed authentication token",
+	"auth.token_invalid":         "Invalid authentication token",
+	"auth.invalid_credentials":   "Invalid username or password",
+	"auth.login_failed":          "Login failed",
+	"auth.login_success":         "Login successful",
+	"auth.refresh_token_invalid": "Invalid refresh token",
+	"auth.refresh_token_expired": "Refresh token expired",
+	"auth.token_user_invalid":    "Invalid token",
+	"auth.user_invalid":          "Invalid user",
+	"auth.user_disabled":         "User has been disabled",
+	"auth.refresh_failed":        "Token refresh failed",
+	"auth.refresh_success":       "Token refreshed successfully",
+	"auth.logout_success":        "Logged out successfully",
+	"auth.ticket_invalid":        "Event stream ticket is invalid or already used",
+
+	// User
+	"user.not_found":           "User not found",
+	"user.username_exists":     "Username already exists",
+	"user.email_exists":        "Email already exists",
+	"user.invalid_role":        "Invalid user role",
+	"user.invalid_language":    "Unsupported language",
+	"user.old_password_wrong":  "Current password is incorrect",
+	"user.list_failed":         "Failed to list users",
+	"user.created":             "User created successfully",
+	"user.cannot_delete_self":  "You cannot delete your own account",
+	"user.password_changed":    "Password changed successfully",
+	"user.preferences_updated": "Preferences updated",
+
+	// Customer
+	"customer.list_success":       "Customers retrieved successfully",
+	"customer.detail_success":     "Customer retrieved successfully",
+	"customer.created":            "Customer created successfully",
+	"customer.updated":            "Customer updated successfully",
+	"customer.deleted":            "Customer deleted successfully",
+	"customer.not_found":          "Customer not found",
+	"customer.invalid_status":     "Invalid customer status %s, expected active, suspended or churned",
+	"customer.invalid_transition": "Customer status cannot change from %s to %s",
+	"customer.status_unchanged":   "Customer status is already %s",
+	"customer.reason_required":    "Please provide a reason for the status change",
+	"customer.open_contracts":     "Customer still has %d contracts that are not terminated or expired and cannot be marked as churned",
+	"customer.invalid_date":       "Invalid %s, expected YYYY-MM-DD",
+	"customer.invalid_provider":   "Invalid cloud provider: %s",
+	"customer.status_changed":     "Customer status updated",
+
+	// Contract
+	"contract.list_success":                  "Contracts retrieved successfully",
+	"contract.detail_success":                "Contract retrieved successfully",
+	"contract.created":                       "Contract created successfully",
+	"contract.invalid_within":                "Invalid expiry window, expected 1-%d days such as 30d",
+	"contract.not_found":                     "Contract not found",
+	"contract.version_not_found":             "Contract version %d not found",
+	"contract.invalid_date":                  "Invalid %s, expected YYYY-MM-DD",
+	"contract.not_amendable":                 "Contract status is %s, only active contracts can be amended",
+	"contract.invalid_effective_date":        "Amendment effective date must be after the current version's effective date %s",
+	"contract.end_before_effective":          "End date must not be before the amendment effective date",
+	"contract.amendment_no_change":           "Amendment is identical to the current version",
+	"contract.amended":                       "Contract amended successfully",
+	"contract.customer_not_found":            "Customer not found",
+	"contract.customer_not_active":           "Customer status is %s, only active customers can sign new contracts",
+	"contract.invalid_status":                "Invalid contract status: %s",
+	"contract.invalid_provider":              "Invalid cloud provider: %s",
+	"contract.no_exists":                     "Contract number %s already exists",
+	"contract.end_before_start":              "End date must not be before start date",
+	"contract.attachment_required":           "Please choose a file to upload",
+	"contract.attachment_empty":              "Uploaded file is empty",
+	"contract.attachment_too_large":          "File exceeds the size limit of %dMB",
+	"contract.attachment_invalid_name":       "Invalid file name: hidden files, names without an extension, names over 255 bytes and executable extensions are not allowed",
+	"contract.attachment_type_not_allowed":   "File type %s is not allowed",
+	"contract.attachment_extension_mismatch": "File extension %s does not match the content type %s",
+	"contract.attachment_not_found":          "Contract attachment not found",
+	"contract.attachment_forbidden":          "Only the uploader, administrators and managers can delete attachments",
+	"contract.attachment_link_invalid":       "Download link is invalid or has expired",
+	"contract.attachment_uploaded":           "Attachment uploaded successfully",
+	"contract.attachment_deleted":            "Attachment deleted successfully",
+
+	"contract.notify.expiring_subject": "Contract %s expires in %d days",
+	"contract.notify.expiring_body":    "Contract %s (%s) for customer %s expires on %s, %d days left. Please follow up on the renewal.",
+	"contract.notify.expired_subject":  "Contract %s has expired",
+	"contract.notify.expired_body":     "Contract %s (%s) for customer %s expired on %s and its status has been set to expired.",
+
+	// System config
+	"config.not_found":              "Configuration not found",
+	"config.list_failed":            "Failed to list configurations",
+	"config.type_required":          "config_type is required when creating a configuration",
+	"config.value_required":         "value is required when creating a configuration",
+	"config.invalid_type":           "Invalid configuration type",
+	"config.invalid_number":         "Value must be a number",
+	"config.invalid_boolean":        "Value must be a boolean",
+	"config.invalid_json":           "Value must be valid JSON",
+	"config.encryption_unavailable": "Encryption key is not configured; encrypted values cannot be saved",
+
+	// Billing import
+	"import.file_required":        "Please upload a bill file",
+	"import.file_too_large":       "Uploaded file is too large",
+	"import.unsupported_format":   "Unsupported file format; only CSV and XLSX are supported",
+	"import.unsupported_provider": "Unsupported cloud provider",
+	"import.invalid_period":       "Invalid billing period, expected YYYY-MM",
+	"import.customer_not_found":   "Customer not found",
+	"import.header_not_found":     "No recognizable bill header found; check that the provider matches the file",
+	"import.empty_file":           "The bill file contains no data",
+	"import.rows_invalid":         "%d rows are invalid; nothing was imported. Fix them and re-import, or choose to skip invalid rows",
+	"import.no_valid_rows":        "No valid rows to import",
+	"import.already_running":      "An import for this customer, provider and period is already running",
+	"import.not_found":            "Import job not found",
+	"import.started":              "Import job created",
+	"import.preview_success":      "Preview generated",
+
+	// 账单导入行级错误
+	"import.row.invalid_number":     "Invalid number: %s",
+	"import.row.account_missing":    "Account ID is missing; provide it in the file or specify account_id when importing",
+	"import.row.service_missing":    "Service type is missing",
+	"import.row.cost_missing":       "Cost amount is missing",
+	"import.row.invalid_currency":   "Unrecognized currency: %s",
+	"import.row.invalid_date":       "Invalid date: %s",
+	"import.row.date_out_of_period": "Billing date %s is outside the billing period",
+
+	// Customer bulk import
+	"customer_import.file_required":   "Please upload a customer file",
+	"customer_import.empty_file":      "The customer file contains no data",
+	"customer_import.too_many_rows":   "At most %d customers can be imported from one file",
+	"customer_import.missing_columns": "Missing required columns: %s",
+	"customer_import.rows_invalid":    "%d rows are invalid; no customers were imported. Fix them and re-import",
+	"customer_import.code_conflict":   "A customer code was taken by another request; preview again and re-import",
+	"customer_import.success":         "Imported %d customers",
+
+	// 客户批量导入行级错误
+	"customer_import.row.required":         "is required",
+	"customer_import.row.too_long":         "must be at most %d characters",
+	"customer_import.row.code_whitespace":  "Customer code must not contain whitespace: %s",
+	"customer_import.row.invalid_phone":    "Invalid phone number: %s",
+	"customer_import.row.invalid_email":    "Invalid email address: %s",
+	"customer_import.row.invalid_level":    "Customer level must be a positive integer: %s",
+	"customer_import.row.invalid_credit":   "Credit limit must be a non-negative number with at most two decimals: %s",
+	"customer_import.row.self_parent":      "A customer cannot be its own parent",
+	"customer_import.row.duplicate_code":   "Customer code duplicates row %d",
+	"customer_import.row.code_deleted":     "Customer code is used by a deleted customer: %s",
+	"customer_import.row.code_exists":      "Customer code already exists: %s",
+	"customer_import.row.parent_not_found": "Parent customer not found: %s",
+	"customer_import.row.parent_cycle":     "Parent customers form a cycle: %s",
+	"customer_import.row.parent_invalid":   "The parent customer's row (row %d) has errors",
+
+	// Reconciliation
+	"reconcile.invalid_period":        "Invalid billing period, expected YYYY-MM",
+	"reconcile.customer_not_found":    "Customer not found",
+	"reconcile.run_not_found":         "Reconciliation run not found",
+	"reconcile.discrepancy_not_found": "Discrepancy not found",
+	"reconcile.already_resolved":      "Discrepancy is already resolved; reopen it first",
+	"reconcile.already_open":          "Discrepancy is already open",
+	"reconcile.completed":             "Reconciliation completed",
+
+	// Exchange rates
+	"currency.invalid_code":      "Invalid currency code, expected three letters (e.g. USD)",
+	"currency.same_pair":         "Base and quote currency must differ",
+	"currency.invalid_rate":      "Exchange rate must be greater than 0",
+	"currency.invalid_rate_type": "Invalid exchange rate type",
+	"currency.invalid_date":      "Invalid rate date: %s, expected YYYY-MM-DD or YYYY-MM",
+	"currency.invalid_rate_row":  "Exchange rate #%d is invalid: %s",
+	"currency.invalid_csv_row":   "Invalid exchange rate on line %d: %s",
+	"currency.invalid_file":      "Invalid exchange rate file",
+	"currency.missing_column":    "Exchange rate file is missing column: %s",
+	"currency.empty_file":        "Exchange rate file contains no data",
+	"currency.file_required":     "Please upload an exchange rate file",
+	"currency.fetcher_not_found": "Unknown exchange rate source: %s",
+	"currency.fetch_failed":      "Failed to fetch exchange rates: %s",
+	"currency.rate_not_found":    "Missing %s to %s exchange rate for %s",
+	"currency.rate_not_found_id": "Exchange rate not found",
+	"currency.saved":             "Exchange rates saved",
+
+	// Commission settlements
+	"settlement.invalid_period":          "Invalid billing period, expected YYYY-MM",
+	"settlement.period_out_of_contract":  "Billing period %s is outside the contract term",
+	"settlement.contract_not_found":      "Contract not found",
+	"settlement.contract_not_settleable": "Contract cannot be settled in its current status",
+	"settlement.cycle_not_ended":         "Settlement cycle has not ended yet (ends %s)",
+	"settlement.already_closed":          "Settlement cycle %s ~ %s is already closed",
+	"settlement.mixed_currency":          "Settlements use different currencies; process each currency separately",
+	"settlement.concurrent_update":       "Commission records were modified concurrently, please retry",
+	"settlement.not_found":               "Settlement not found",
+	"settlement.already_cancelled":       "Settlement is already cancelled",
+	"settlement.in_batch":                "Settlement is in a payout batch; cancel the batch first",
+	"settlement.nothing_to_batch":        "No settlements available for a payout batch",
+	"settlement.not_batchable":           "Some settlements do not exist, are cancelled or already batched",
+	"settlement.batch_not_found":         "Payout batch not found",
+	"settlement.invalid_batch_status":    "Operation not allowed for payout batch in status %s",
+	"settlement.reference_required":      "Payment reference is required",
+	"settlement.closed":                  "Settlement closed",
+	"settlement.cancelled":               "Settlement cancelled",
+	"settlement.batch_created":           "Payout batch created",
+	"settlement.batch_approved":          "Payout batch approved",
+	"settlement.batch_paid":              "Payout batch marked as paid",
+	"settlement.batch_cancelled":         "Payout batch cancelled",
+
+	// Commission calculation
+	"commission.invalid_period": "Invalid billing period, expected YYYY-MM",
+	"commission.run_not_found":  "Commission run not found",
+	"commission.recalculated":   "Commission recalculated",
+
+	// Customer statements
+	"statement.invalid_period":     "Invalid billing period, expected YYYY-MM",
+	"statement.invalid_format":     "Invalid statement format, expected pdf, xlsx or json",
+	"statement.customer_not_found": "Customer not found",
+	"statement.no_data":            "No billing or commission data for period %s",
+	"statement.not_found":          "Statement not found",
+	"statement.job_not_found":      "Statement job not found",
+	"statement.file_missing":       "Statement file is missing, please regenerate it",
+	"statement.job_started":        "Statement job created",
+	"statement.font_required":      "The statement contains characters (e.g. Chinese) the built-in font cannot display; configure a CJK font (statement.font_path) to generate PDF statements",
+
+	// Statement template labels
+	"statement.label.title":                "Commission Statement",
+	"statement.label.customer":             "Customer",
+	"statement.label.customer_code":        "Customer Code",
+	"statement.label.period":               "Billing Period",
+	"statement.label.currency":             "Currency",
+	"statement.label.generated_at":         "Generated At",
+	"statement.label.sheet_summary":        "Summary",
+	"statement.label.sheet_tiers":          "Rule Tiers",
+	"statement.label.sheet_adjustments":    "Adjustments",
+	"statement.label.sheet_details":        "Details",
+	"statement.label.section_summary":      "Summary by Provider and Service",
+	"statement.label.section_tiers":        "Applied Rule Tiers",
+	"statement.label.section_adjustments":  "Adjustments",
+	"statement.label.section_payouts":      "Payout Status",
+	"statement.label.section_settlements":  "Settlements and Payouts",
+	"statement.label.provider":             "Provider",
+	"statement.label.service_type":         "Service Type",
+	"statement.label.billed_amount":        "Billed",
+	"statement.label.base_amount":          "Base",
+	"statement.label.commission_rates":     "Rates",
+	"statement.label.commission_rate":      "Rate",
+	"statement.label.commission":           "Commission",
+	"statement.label.adjustment":           "Adjustment",
+	"statement.label.net_commission":       "Net",
+	"statement.label.record_count":         "Records",
+	"statement.label.total":                "Total",
+	"statement.label.paid":                 "Paid",
+	"statement.label.outstanding":          "Outstanding",
+	"statement.label.contract_no":          "Contract No.",
+	"statement.label.tier_min":             "Tier From",
+	"statement.label.tier_max":             "Tier To",
+	"statement.label.unlimited":            "Unlimited",
+	"statement.label.fixed_amount":         "Fixed Amount",
+	"statement.label.contract_currency":    "Currency",
+	"statement.label.created_at":           "Created At",
+	"statement.label.status":               "Status",
+	"statement.label.settlement_no":        "Settlement No.",
+	"statement.label.cycle":                "Cycle",
+	"statement.label.batch_no":             "Payout Batch",
+	"statement.label.batch_status":         "Batch Status",
+	"statement.label.payment_reference":    "Payment Reference",
+	"statement.label.record_type":          "Type",
+	"statement.label.original_currency":    "Currency",
+	"statement.label.exchange_rate":        "Exchange Rate",
+	"statement.label.base_reporting":       "Base (Reporting)",
+	"statement.label.commission_reporting": "Commission (Reporting)",
+	"statement.label.no_records":           "No records",
+	"statement.label.status_pending":       "Pending",
+	"statement.label.status_calculated":    "Calculated",
+	"statement.label.status_paid":          "Paid",
+	"statement.label.status_draft":         "Draft",
+	"statement.label.status_approved":      "Approved",
+	"statement.label.status_cancelled":     "Cancelled",
+	"statement.label.status_commission":    "Commission",
+	"statement.label.status_adjustment":    "Adjustment",
+
+	// Reports
+	"report.invalid_period":    "Invalid billing period, expected YYYY-MM",
+	"report.invalid_range":     "Start period must not be after end period, and at most %d months can be queried",
+	"report.invalid_dimension": "Unsupported report dimension",
+	"report.other":             "Other",
+
+	// Data export
+	"export.invalid_resource": "Unsupported export resource: %s",
+	"export.invalid_format":   "Unsupported export format, expected csv or xlsx",
+	"export.invalid_filter":   "Invalid export filter %s",
+	"export.too_many_records": "%d records exceed the export limit of %d, please narrow the export",
+	"export.job_started":      "Export job created, the file can be downloaded when it finishes",
+	"export.job_not_found":    "Export job not found",
+	"export.job_not_ready":    "Export job has not completed successfully",
+	"export.file_missing":     "Export file not found",
+
+	"export.notify.ready_subject":  "Export %s is ready",
+	"export.notify.ready_body":     "Export %s is ready with %d records. Download: %s",
+	"export.notify.failed_subject": "Export %s failed",
+	"export.notify.failed_body":    "Export %s failed: %s. Please narrow the export or try again later.",
+
+	// Credit limit
+	"credit.customer_not_found": "Customer not found",
+
+	"credit.notify.warning_subject":  "Customer %s has used %d%% of its credit limit",
+	"credit.notify.warning_body":     "Customer %s (%s) has unsettled spend of %.2f %s against a credit limit of %.2f %s (%.1f%% used). Please follow up on collections.",
+	"credit.notify.exceeded_subject": "Customer %s has exceeded its credit limit (%d%%)",
+	"credit.notify.exceeded_body":    "Customer %s (%s) has unsettled spend of %.2f %s, exceeding its credit limit of %.2f %s (%.1f%% used). Please take action.",
+
+	// Contract approval
+	"approval.submitted":          "Contract submitted for approval",
+	"approval.approved":           "Approval task approved",
+	"approval.rejected":           "Approval rejected, the contract has been returned to draft",
+	"approval.chain_created":      "Approval chain created successfully",
+	"approval.chain_updated":      "Approval chain updated successfully",
+	"approval.chain_deleted":      "Approval chain deleted successfully",
+	"approval.chain_not_found":    "Approval chain not found",
+	"approval.task_not_found":     "Approval task not found",
+	"approval.contract_not_found": "Contract not found",
+	"approval.not_submittable":    "Contract status is %s, only draft contracts can be submitted for approval",
+	"approval.no_chain":           "No approval chain applies to this contract, please ask an administrator to configure one",
+	"approval.task_not_pending":   "Approval task status is %s and cannot be processed",
+	"approval.not_approver":       "You are not an approver of this task",
+	"approval.self_approval":      "You cannot approve a contract you submitted",
+	"approval.customer_inactive":  "Customer status is %s, the contract can only take effect for active customers",
+	"approval.comment_required":   "A comment is required when rejecting",
+	"approval.invalid_step":       "Step %d must specify either an approver role or an approver",
+	"approval.invalid_role":       "Invalid approver role: %s",
+	"approval.approver_not_found": "The approver of step %d does not exist or is disabled",
+
+	"approval.notify.pending_subject":  "Contract %s awaits your approval",
+	"approval.notify.pending_body":     "Contract %s has reached approval step %d (%s), please review it.",
+	"approval.notify.approved_subject": "Contract %s approved",
+	"approval.notify.approved_body":    "Contract %s you submitted has passed all approval steps and is now active.",
+	"approval.notify.rejected_subject": "Contract %s rejected",
+	"approval.notify.rejected_body":    "Contract %s you submitted was rejected at step \"%s\" with comment: %s. It has been returned to draft and can be resubmitted after changes.",
+
+	// Search
+	"search.invalid_type":   "Invalid search type %s, expected customer or contract",
+	"search.invalid_status": "Status %s does not apply to search type %s",
+
+	// List queries
+	"query.invalid_param":    "Invalid query parameter %s, expected filter[field] or filter[field][operator]",
+	"query.unknown_field":    "Filtering by field %s is not supported",
+	"query.invalid_operator": "Field %s does not support operator %s",
+	"query.invalid_value":    "Invalid value %[2]s for field %[1]s",
+	"query.invalid_sort":     "Sorting by field %s is not supported",
+	"query.invalid_limit":    "Limit must be between 1 and %d",
+	"query.invalid_cursor":   "Invalid cursor or cursor does not match the sort order",
+
+	// Notification center
+	"notification.not_found":           "Notification not found",
+	"notification.template_not_found":  "There is no custom template for this event and language",
+	"notification.unknown_event":       "Unknown notification event %s",
+	"notification.invalid_language":    "Invalid language %s, expected zh-CN or en-US",
+	"notification.invalid_placeholder": "Placeholder %s exceeds the number of parameters (%d)",
+	"notification.marked_read":         "Marked as read",
+	"notification.all_marked_read":     "Marked %d notifications as read",
+	"notification.preferences_updated": "Notification preferences updated",
+	"notification.template_updated":    "Notification template updated",
+	"notification.template_deleted":    "Notification template reset to default",
+	"datasync.notify.failed_subject":   "Billing sync failed for customer %s on %s",
+	"datasync.notify.failed_body":      "Billing sync for customer %s (%s) on %s for period %s failed: %s. Please check the cloud account configuration and sync again.",
+
+	// Webhooks
+	"webhook.not_found":              "Webhook subscription not found",
+	"webhook.delivery_not_found":     "Delivery not found",
+	"webhook.invalid_url":            "Invalid callback URL %s, expected an http or https URL",
+	"webhook.invalid_event":          "Event %s cannot be subscribed to",
+	"webhook.inactive":               "The webhook subscription is disabled",
+	"webhook.encryption_unavailable": "No encryption key configured; the signing secret cannot be stored",
+	"webhook.created":                "Webhook subscription created. Store the signing secret now; it will not be shown again",
+	"webhook.updated":                "Webhook subscription updated",
+	"webhook.deleted":                "Webhook subscription deleted",
+	"webhook.secret_rotated":         "Signing secret rotated. Store it now; it will not be shown again",
+	"webhook.queued":                 "Queued for delivery",
+}
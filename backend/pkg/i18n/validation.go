@@ -0,0 +1,74 @@
+package i18n
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+var translators = map[Lang]ut.Translator{}
+
+// RegisterValidator 为gin的参数校验器注册中英文翻译
+func RegisterValidator() error {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("unsupported validator engine")
+	}
+
+	// 校验错误中使用json字段名
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	uni := ut.New(en.New(), en.New(), zh.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return err
+	}
+	zhTrans, _ := uni.GetTranslator("zh")
+	if err := zhTranslations.RegisterDefaultTranslations(v, zhTrans); err != nil {
+		return err
+	}
+
+	translators[EnUS] = enTrans
+	translators[ZhCN] = zhTrans
+	return nil
+}
+
+// translateValidation 翻译参数校验错误
+func translateValidation(lang Lang, err error) (string, bool) {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return "", false
+	}
+	trans, ok := translators[lang]
+	if !ok {
+		return "", false
+	}
+
+	msgs := make([]string, 0, len(verrs))
+	for _, fe := range verrs {
+		msgs = append(msgs, fe.Translate(trans))
+	}
+	sep := "; "
+	if lang == ZhCN {
+		sep = "；"
+	}
+	return strings.Join(msgs, sep), true
+}
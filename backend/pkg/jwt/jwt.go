@@ -13,6 +13,7 @@ type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	Language string `json:"lang,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -33,12 +34,13 @@ func NewJWTManager() *JWTManager {
 }
 
 // GenerateTokens 生成访问令牌和刷新令牌
-func (j *JWTManager) GenerateTokens(userID, username, role string) (string, string, error) {
+func (j *JWTManager) GenerateTokens(userID, username, role, language string) (string, string, error) {
 	// 生成访问令牌（1小时过期）
 	accessClaims := &Claims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Language: language,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -59,6 +61,7 @@ func (j *JWTManager) GenerateTokens(userID, username, role string) (string, stri
 		UserID:   userID,
 		Username: username,
 		Role:     role,
+		Language: language,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -11,7 +11,7 @@ func CORS() gin.HandlerFunc {
     return cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173", "http://localhost:8080"},
         AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization"},
+        AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "Accept-Language"},
         ExposeHeaders:    []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type"},
         AllowCredentials: true,
         MaxAge:           12 * time.Hour,
@@ -6,9 +6,13 @@ import (
 
     "github.com/gin-gonic/gin"
     
+    "xcloud-backend/pkg/i18n"
     jwtPkg "xcloud-backend/pkg/jwt"
 )
 
+// TokenExpiresAtKey 访问令牌过期时间（time.Time）在gin上下文中的键
+const TokenExpiresAtKey = "token_expires_at"
+
 // JWTAuth JWT认证中间件
 func JWTAuth() gin.HandlerFunc {
     jwtManager := jwtPkg.NewJWTManager()
@@ -19,7 +23,7 @@ func JWTAuth() gin.HandlerFunc {
         if authHeader == "" {
             c.JSON(http.StatusUnauthorized, gin.H{
                 "code":    401,
-                "message": "请提供认证令牌",
+                "message": i18n.T(c, "auth.token_required"),
                 "error":   "Authorization header required",
             })
             c.Abort()
@@ -31,7 +35,7 @@ func JWTAuth() gin.HandlerFunc {
         if len(parts) != 2 || parts[0] != "Bearer" {
             c.JSON(http.StatusUnauthorized, gin.H{
                 "code":    401,
-                "message": "认证令牌格式错误",
+                "message": i18n.T(c, "auth.token_malformed"),
                 "error":   "Authorization header format must be Bearer {token}",
             })
             c.Abort()
@@ -45,7 +49,7 @@ func JWTAuth() gin.HandlerFunc {
         if err != nil {
             c.JSON(http.StatusUnauthorized, gin.H{
                 "code":    401,
-                "message": "认证令牌无效",
+                "message": i18n.T(c, "auth.token_invalid"),
                 "error":   err.Error(),
             })
             c.Abort()
@@ -56,6 +60,12 @@ func JWTAuth() gin.HandlerFunc {
         c.Set("user_id", claims.UserID)
         c.Set("username", claims.Username)
         c.Set("user_role", claims.Role)
+        if claims.ExpiresAt != nil {
+            c.Set(TokenExpiresAtKey, claims.ExpiresAt.Time)
+        }
+        if claims.Language != "" {
+            c.Set(i18n.ContextKey, claims.Language)
+        }
         c.Next()
     }
 }
@@ -67,7 +77,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
         if !exists {
             c.JSON(http.StatusForbidden, gin.H{
                 "code":    403,
-                "message": "无法获取用户角色信息",
+                "message": i18n.T(c, "common.role_missing"),
                 "error":   "User role not found",
             })
             c.Abort()
@@ -84,7 +94,7 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 
         c.JSON(http.StatusForbidden, gin.H{
             "code":    403,
-            "message": "权限不足",
+            "message": i18n.T(c, "common.forbidden"),
             "error":   "Insufficient permissions",
         })
         c.Abort()
@@ -11,10 +11,11 @@ func Logger() gin.HandlerFunc {
 
     return gin.LoggerWithConfig(gin.LoggerConfig{
         Formatter: func(param gin.LogFormatterParams) string {
+            // 只记录路径，不记录查询参数：其中可能带有事件订阅凭证、附件下载签名等敏感信息
             logger.WithFields(map[string]interface{}{
                 "status":     param.StatusCode,
                 "method":     param.Method,
-                "path":       param.Path,
+                "path":       param.Request.URL.Path,
                 "ip":         param.ClientIP,
                 "user-agent": param.Request.UserAgent(),
                 "latency":    param.Latency.String(),
@@ -9,6 +9,7 @@ import (
     "strings"
 
     "github.com/gin-gonic/gin"
+    "xcloud-backend/pkg/i18n"
     appLogger "xcloud-backend/pkg/logger"
 )
 
@@ -37,7 +38,7 @@ func Recovery() gin.HandlerFunc {
 
         c.JSON(http.StatusInternalServerError, gin.H{
             "code":    500,
-            "message": "服务器内部错误",
+            "message": i18n.T(c, "common.internal_error"),
             "error":   "Internal Server Error",
         })
     })
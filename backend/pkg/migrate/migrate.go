@@ -0,0 +1,369 @@
+// Package migrate 数据库版本迁移
+//
+// 迁移记录保存在 schema_migrations 表中，每个版本一行。迁移执行前先将版本标记为
+// dirty，迁移脚本与清除标记在同一事务中提交；若执行失败，版本保持 dirty 状态，
+// 需人工确认数据库状态后通过 Force 修复。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// advisoryLockID 迁移使用的PostgreSQL会话级咨询锁，防止多个实例并发迁移
+const advisoryLockID = 7_311_208_042
+
+var (
+	// ErrDirty 存在未完成的迁移
+	ErrDirty = errors.New("数据库迁移处于dirty状态")
+	// ErrPending 存在未执行的迁移
+	ErrPending = errors.New("存在未执行的数据库迁移")
+	// ErrNoChange 没有需要执行的迁移
+	ErrNoChange = errors.New("没有需要执行的迁移")
+)
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 单个版本迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// AppliedVersion 已执行的迁移版本
+type AppliedVersion struct {
+	Version   int64
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+// Status 迁移状态
+type Status struct {
+	Current int64            // 当前版本，0表示未执行任何迁移
+	Dirty   bool             // 当前版本是否处于dirty状态
+	Applied []AppliedVersion // 已执行的版本
+	Pending []Migration      // 待执行的迁移
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	logf       func(format string, args ...interface{})
+}
+
+// New 从文件系统加载迁移脚本并创建迁移执行器
+func New(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		logf:       func(string, ...interface{}) {},
+	}, nil
+}
+
+// SetLogger 设置迁移过程日志输出
+func (m *Migrator) SetLogger(logf func(format string, args ...interface{})) {
+	m.logf = logf
+}
+
+// Migrations 返回全部迁移
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Latest 返回最新迁移版本
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("迁移版本号无效: %s", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件失败: %w", err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("迁移版本号重复: %d (%s, %s)", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if strings.TrimSpace(mig.Up) == "" {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少up脚本", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// ensureTable 创建迁移记录表
+func (m *Migrator) ensureTable(ctx context.Context, q queryer) error {
+	_, err := q.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		dirty BOOLEAN NOT NULL DEFAULT false,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	return err
+}
+
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+func (m *Migrator) applied(ctx context.Context, q queryer) ([]AppliedVersion, error) {
+	rows, err := q.QueryContext(ctx, `SELECT version, dirty, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []AppliedVersion
+	for rows.Next() {
+		var v AppliedVersion
+		if err := rows.Scan(&v.Version, &v.Dirty, &v.AppliedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (m *Migrator) status(ctx context.Context, q queryer) (*Status, error) {
+	if err := m.ensureTable(ctx, q); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	applied, err := m.applied(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移记录失败: %w", err)
+	}
+
+	st := &Status{Applied: applied}
+	done := make(map[int64]bool, len(applied))
+	for _, v := range applied {
+		done[v.Version] = true
+		if v.Version > st.Current {
+			st.Current = v.Version
+		}
+		if v.Dirty {
+			st.Dirty = true
+		}
+	}
+	for _, mig := range m.migrations {
+		if !done[mig.Version] {
+			st.Pending = append(st.Pending, mig)
+		}
+	}
+	return st, nil
+}
+
+// Status 查询迁移状态
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	return m.status(ctx, m.db)
+}
+
+// Check 检查数据库是否为最新版本，存在dirty或待执行迁移时返回错误
+func (m *Migrator) Check(ctx context.Context) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if st.Dirty {
+		return fmt.Errorf("%w: 版本 %d，请确认数据库状态后执行 migrate force", ErrDirty, st.Current)
+	}
+	if len(st.Pending) > 0 {
+		return fmt.Errorf("%w: 当前版本 %d，最新版本 %d，请执行 migrate up", ErrPending, st.Current, m.Latest())
+	}
+	return nil
+}
+
+// withLock 持有咨询锁执行迁移操作
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("获取迁移锁失败: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	return fn(conn)
+}
+
+// Up 执行全部待执行的迁移
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		st, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if st.Dirty {
+			return fmt.Errorf("%w: 版本 %d", ErrDirty, st.Current)
+		}
+		if len(st.Pending) == 0 {
+			return ErrNoChange
+		}
+		for _, mig := range st.Pending {
+			if err := m.apply(ctx, conn, mig, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Down 回滚最近的 steps 个迁移
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	if steps <= 0 {
+		return errors.New("回滚步数必须大于0")
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		st, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if st.Dirty {
+			return fmt.Errorf("%w: 版本 %d", ErrDirty, st.Current)
+		}
+		if len(st.Applied) == 0 {
+			return ErrNoChange
+		}
+
+		byVersion := make(map[int64]Migration, len(m.migrations))
+		for _, mig := range m.migrations {
+			byVersion[mig.Version] = mig
+		}
+		for i := len(st.Applied) - 1; i >= 0 && steps > 0; i, steps = i-1, steps-1 {
+			mig, ok := byVersion[st.Applied[i].Version]
+			if !ok {
+				return fmt.Errorf("找不到已执行迁移 %d 的脚本", st.Applied[i].Version)
+			}
+			if err := m.apply(ctx, conn, mig, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Force 将指定版本标记为已执行且非dirty，并移除更高版本的记录
+// 用于dirty状态修复，以及为已通过 schema.sql 初始化的数据库建立基线
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if err := m.ensureTable(ctx, conn); err != nil {
+			return err
+		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version > $1`, version); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)
+				ON CONFLICT (version) DO UPDATE SET dirty = false`, mig.Version); err != nil {
+				return err
+			}
+		}
+		return tx.Commit()
+	})
+}
+
+// apply 执行单个迁移
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, up bool) error {
+	direction, script := "up", mig.Up
+	if !up {
+		direction, script = "down", mig.Down
+	}
+	m.logf("执行迁移 %d_%s (%s)", mig.Version, mig.Name, direction)
+
+	// 先标记dirty，失败时保留该标记
+	if up {
+		if _, err := conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, true)`, mig.Version); err != nil {
+			return err
+		}
+	} else {
+		if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations SET dirty = true WHERE version = $1`, mig.Version); err != nil {
+			return err
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return fmt.Errorf("迁移 %d_%s (%s) 执行失败: %w", mig.Version, mig.Name, direction, err)
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, `UPDATE schema_migrations SET dirty = false, applied_at = CURRENT_TIMESTAMP WHERE version = $1`, mig.Version)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
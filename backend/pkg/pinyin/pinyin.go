@@ -0,0 +1,87 @@
+package pinyin
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// boundaries GB2312 一级汉字（按拼音排序）各声母首字的区位码
+var boundaries = []struct {
+	code    int
+	initial byte
+}{
+	{0xB0A1, 'a'}, {0xB0C5, 'b'}, {0xB2C1, 'c'}, {0xB4EE, 'd'}, {0xB6EA, 'e'},
+	{0xB7A2, 'f'}, {0xB8C1, 'g'}, {0xB9FE, 'h'}, {0xBBF7, 'j'}, {0xBFA6, 'k'},
+	{0xC0AC, 'l'}, {0xC2E8, 'm'}, {0xC4C3, 'n'}, {0xC5B6, 'o'}, {0xC5BE, 'p'},
+	{0xC6DA, 'q'}, {0xC8BB, 'r'}, {0xC8F6, 's'}, {0xCBFA, 't'}, {0xCDDA, 'w'},
+	{0xCEF4, 'x'}, {0xD1B9, 'y'}, {0xD4D1, 'z'},
+}
+
+// lastLevelOne GB2312 一级汉字的最后一个区位码
+const lastLevelOne = 0xD7F9
+
+// extra 公司名称中常见的 GB2312 二级汉字和 GB2312 以外汉字的声母
+var extra = map[rune]byte{
+	'圳': 'z', '晟': 's', '鑫': 'x', '淼': 'm', '焱': 'y', '垚': 'y', '昊': 'h', '琦': 'q',
+	'祺': 'q', '璟': 'j', '珑': 'l', '钰': 'y', '玥': 'y', '煜': 'y', '喆': 'z', '彧': 'y',
+	'翊': 'y', '赟': 'y', '骐': 'q', '瑞': 'r', '铠': 'k', '锐': 'r', '珈': 'j', '熠': 'y',
+}
+
+// Initial 汉字的拼音声母（小写字母），无法识别时返回 0
+func Initial(r rune) byte {
+	if b, ok := extra[r]; ok {
+		return b
+	}
+	if !unicode.Is(unicode.Han, r) {
+		return 0
+	}
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(string(r))
+	if err != nil || len(encoded) != 2 {
+		return 0
+	}
+	code := int(encoded[0])<<8 | int(encoded[1])
+	if code < boundaries[0].code || code > lastLevelOne {
+		return 0
+	}
+	initial := boundaries[0].initial
+	for _, b := range boundaries {
+		if code < b.code {
+			break
+		}
+		initial = b.initial
+	}
+	return initial
+}
+
+// Initials 文本的拼音首字母：汉字取声母，英文字母和数字转为小写保留，其他字符忽略
+// 如 "阿里云计算有限公司" 返回 "alyjsyxgs"。多音字取 GB2312 排序所用的读音，
+// 未收录的生僻字忽略
+func Initials(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			if initial := Initial(r); initial != 0 {
+				b.WriteByte(initial)
+			}
+		}
+	}
+	return b.String()
+}
+
+// IsInitials 关键词是否可能为拼音首字母（只包含英文字母）
+func IsInitials(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r >= unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// cursor 分页游标：排序签名、翻页方向和定位行的排序字段值
+type cursor struct {
+	Sort   string   `json:"s"`
+	Prev   bool     `json:"p,omitempty"`
+	Values []string `json:"v"`
+}
+
+func decodeCursor(raw string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Page 游标分页信息
+type Page struct {
+	Limit      int    `json:"limit" example:"20"`
+	HasNext    bool   `json:"has_next" example:"true"`
+	HasPrev    bool   `json:"has_prev" example:"false"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiLWNyZWF0ZWRfYXQsaWQiLCJ2IjpbXX0"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// Total 符合过滤条件的总数，仅在 count=true 时返回
+	Total *int64 `json:"total,omitempty" example:"100"`
+}
+
+// Find 按查询参数读取一页记录。q 为已设置表、关联、字段和固定条件的基础查询，
+// T 中排序字段对应的列名须与字段名一致，用于生成下一页和上一页的游标
+func Find[T any](q *gorm.DB, p *Params) ([]T, *Page, error) {
+	base := q.Session(&gorm.Session{})
+	page := &Page{Limit: p.Limit}
+	if p.Count {
+		var total int64
+		err := q.Session(&gorm.Session{NewDB: true}).
+			Table("(?) AS list", p.Where(base)).
+			Count(&total).Error
+		if err != nil {
+			return nil, nil, err
+		}
+		page.Total = &total
+	}
+
+	rows := []T{}
+	if err := p.Scope(base).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	more := len(rows) > p.Limit
+	if more {
+		rows = rows[:p.Limit]
+	}
+	if p.backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+		page.HasNext, page.HasPrev = true, more
+	} else {
+		page.HasNext, page.HasPrev = more, p.cursor != nil
+	}
+	if len(rows) == 0 {
+		page.HasNext, page.HasPrev = false, false
+		return rows, page, nil
+	}
+
+	var err error
+	if page.HasNext {
+		if page.NextCursor, err = p.cursorOf(q, &rows[len(rows)-1], false); err != nil {
+			return nil, nil, err
+		}
+	}
+	if page.HasPrev {
+		if page.PrevCursor, err = p.cursorOf(q, &rows[0], true); err != nil {
+			return nil, nil, err
+		}
+	}
+	return rows, page, nil
+}
+
+// cursorOf 以 row 的排序字段值生成游标
+func (p *Params) cursorOf(db *gorm.DB, row interface{}, prev bool) (string, error) {
+	stmt := &gorm.Statement{DB: db, Context: db.Statement.Context}
+	if err := stmt.Parse(row); err != nil {
+		return "", err
+	}
+	rv := reflect.ValueOf(row).Elem()
+	c := cursor{Sort: p.signature, Prev: prev, Values: make([]string, len(p.orders))}
+	for i, o := range p.orders {
+		field := stmt.Schema.LookUpField(o.name)
+		if field == nil {
+			return "", fmt.Errorf("query: sort field %s not found in %s", o.name, stmt.Schema.Name)
+		}
+		value, _ := field.ValueOf(stmt.Context, rv)
+		s, err := formatValue(value, o.field.Kind)
+		if err != nil {
+			return "", err
+		}
+		c.Values[i] = s
+	}
+	return c.encode(), nil
+}
+
+// formatValue 将排序字段值格式化为游标中的文本，查询时由数据库按列类型转换
+func formatValue(v interface{}, kind Kind) (string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", errors.New("query: sort value is null")
+		}
+		rv = rv.Elem()
+	}
+	v = rv.Interface()
+	switch val := v.(type) {
+	case time.Time:
+		if kind == Date {
+			return val.Format("2006-01-02"), nil
+		}
+		return val.Format(time.RFC3339Nano), nil
+	case uuid.UUID:
+		return val.String(), nil
+	case fmt.Stringer:
+		return val.String(), nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	}
+	return "", fmt.Errorf("query: unsupported sort value %T", v)
+}
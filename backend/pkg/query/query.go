@@ -0,0 +1,373 @@
+package query
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"xcloud-backend/pkg/i18n"
+)
+
+const (
+	// DefaultLimit 默认每页数量
+	DefaultLimit = 20
+	// MaxLimit 每页最大数量
+	MaxLimit = 100
+	// maxInValues in 运算符最多支持的取值数
+	maxInValues = 100
+)
+
+// Kind 字段类型，决定支持的运算符和取值格式
+type Kind int
+
+const (
+	String  Kind = iota // 文本
+	Enum                // 枚举，取值须在 Field.Values 中
+	UUID                // UUID
+	Integer             // 整数
+	Number              // 小数
+	Bool                // 布尔值 true/false
+	Time                // 时间戳，取值为 RFC3339 或 YYYY-MM-DD
+	Date                // 日期，取值为 YYYY-MM-DD
+)
+
+// Op 过滤运算符
+type Op string
+
+const (
+	Eq   Op = "eq"   // 等于
+	Ne   Op = "ne"   // 不等于
+	Gt   Op = "gt"   // 大于
+	Gte  Op = "gte"  // 大于等于
+	Lt   Op = "lt"   // 小于
+	Lte  Op = "lte"  // 小于等于
+	In   Op = "in"   // 属于逗号分隔的取值之一
+	Like Op = "like" // 包含（不区分大小写）
+	Null Op = "null" // 为空（true）或不为空（false）
+)
+
+var comparisons = map[Op]string{Eq: "=", Ne: "<>", Gt: ">", Gte: ">=", Lt: "<", Lte: "<="}
+
+// Field 可过滤、排序的字段
+type Field struct {
+	// Column SQL 列表达式，如 customers.status
+	Column string
+	Kind   Kind
+	// Values 枚举字段的取值
+	Values []string
+	// Nullable 列可为空，支持 null 运算符
+	Nullable bool
+	// Sortable 可排序，只用于非空列，游标按列值定位
+	Sortable bool
+}
+
+// ops 字段支持的运算符
+func (f Field) ops() []Op {
+	var ops []Op
+	switch f.Kind {
+	case String:
+		ops = []Op{Eq, Ne, In, Like}
+	case Enum, UUID:
+		ops = []Op{Eq, Ne, In}
+	case Bool:
+		ops = []Op{Eq}
+	default:
+		ops = []Op{Eq, Ne, Gt, Gte, Lt, Lte, In}
+	}
+	if f.Nullable {
+		ops = append(ops, Null)
+	}
+	return ops
+}
+
+func (f Field) supports(op Op) bool {
+	for _, o := range f.ops() {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parse 校验并转换过滤取值
+func (f Field) parse(name, raw string) (interface{}, error) {
+	invalid := i18n.NewError("query.invalid_value", name, raw)
+	switch f.Kind {
+	case Enum:
+		for _, v := range f.Values {
+			if v == raw {
+				return raw, nil
+			}
+		}
+		return nil, invalid
+	case UUID:
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, invalid
+		}
+		return id.String(), nil
+	case Integer:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return n, nil
+	case Number:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, invalid
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, invalid
+		}
+		return b, nil
+	case Time:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		t, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return nil, invalid
+		}
+		return t, nil
+	case Date:
+		if _, err := time.Parse("2006-01-02", raw); err != nil {
+			return nil, invalid
+		}
+		return raw, nil
+	default:
+		return raw, nil
+	}
+}
+
+// Resource 列表资源的查询定义：可过滤、排序的字段白名单和默认排序
+type Resource struct {
+	Fields map[string]Field
+	// Sort 默认排序，格式同 sort 参数，如 -created_at
+	Sort string
+	// Key 唯一键字段，自动追加为最后一个排序字段，保证排序稳定和游标唯一
+	Key string
+}
+
+type condition struct {
+	sql  string
+	args []interface{}
+}
+
+type order struct {
+	name  string
+	field Field
+	desc  bool
+}
+
+// Params 解析后的列表查询参数
+type Params struct {
+	Limit int
+	// Count 是否统计符合过滤条件的总数
+	Count bool
+
+	filters   []condition
+	orders    []order
+	signature string
+	cursor    *cursor
+}
+
+var filterKey = regexp.MustCompile(`^filter\[([a-z0-9_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse 解析列表查询参数：
+// filter[字段][运算符]=取值（省略运算符时为 eq）、sort=-created_at,name（- 表示倒序）、
+// limit、cursor 和 count=true；其他参数忽略，由调用方处理
+func (r Resource) Parse(values url.Values) (*Params, error) {
+	p := &Params{Limit: DefaultLimit}
+
+	for key, raws := range values {
+		if !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+		m := filterKey.FindStringSubmatch(key)
+		if m == nil {
+			return nil, i18n.NewError("query.invalid_param", key)
+		}
+		name, op := m[1], Op(m[2])
+		if op == "" {
+			op = Eq
+		}
+		field, ok := r.Fields[name]
+		if !ok {
+			return nil, i18n.NewError("query.unknown_field", name)
+		}
+		if !field.supports(op) {
+			return nil, i18n.NewError("query.invalid_operator", name, string(op))
+		}
+		for _, raw := range raws {
+			cond, err := field.condition(name, op, strings.TrimSpace(raw))
+			if err != nil {
+				return nil, err
+			}
+			p.filters = append(p.filters, cond)
+		}
+	}
+
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = r.Sort
+	}
+	if err := r.parseSort(p, sort); err != nil {
+		return nil, err
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return nil, i18n.NewError("query.invalid_limit", MaxLimit)
+		}
+		p.Limit = limit
+	}
+	p.Count, _ = strconv.ParseBool(values.Get("count"))
+
+	if raw := values.Get("cursor"); raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil || c.Sort != p.signature || len(c.Values) != len(p.orders) {
+			return nil, i18n.NewError("query.invalid_cursor")
+		}
+		p.cursor = c
+	}
+	return p, nil
+}
+
+// condition 生成单个过滤条件
+func (f Field) condition(name string, op Op, raw string) (condition, error) {
+	switch op {
+	case Null:
+		isNull, err := strconv.ParseBool(raw)
+		if err != nil {
+			return condition{}, i18n.NewError("query.invalid_value", name, raw)
+		}
+		if isNull {
+			return condition{sql: f.Column + " IS NULL"}, nil
+		}
+		return condition{sql: f.Column + " IS NOT NULL"}, nil
+	case Like:
+		if raw == "" {
+			return condition{}, i18n.NewError("query.invalid_value", name, raw)
+		}
+		return condition{sql: f.Column + " ILIKE ?", args: []interface{}{"%" + escapeLike(raw) + "%"}}, nil
+	case In:
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxInValues {
+			return condition{}, i18n.NewError("query.invalid_value", name, raw)
+		}
+		list := make([]interface{}, 0, len(parts))
+		for _, part := range parts {
+			v, err := f.parse(name, strings.TrimSpace(part))
+			if err != nil {
+				return condition{}, err
+			}
+			list = append(list, v)
+		}
+		return condition{sql: f.Column + " IN ?", args: []interface{}{list}}, nil
+	default:
+		v, err := f.parse(name, raw)
+		if err != nil {
+			return condition{}, err
+		}
+		return condition{sql: f.Column + " " + comparisons[op] + " ?", args: []interface{}{v}}, nil
+	}
+}
+
+// parseSort 解析排序字段并追加唯一键，生成游标的排序签名
+func (r Resource) parseSort(p *Params, sort string) error {
+	seen := map[string]bool{}
+	for _, part := range strings.Split(sort, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(part, "-")
+		field, ok := r.Fields[name]
+		if !ok || !field.Sortable || seen[name] {
+			return i18n.NewError("query.invalid_sort", name)
+		}
+		seen[name] = true
+		p.orders = append(p.orders, order{name: name, field: field, desc: desc})
+	}
+	if !seen[r.Key] {
+		p.orders = append(p.orders, order{name: r.Key, field: r.Fields[r.Key]})
+	}
+
+	names := make([]string, len(p.orders))
+	for i, o := range p.orders {
+		names[i] = o.name
+		if o.desc {
+			names[i] = "-" + o.name
+		}
+	}
+	p.signature = strings.Join(names, ",")
+	return nil
+}
+
+// backward 是否向前翻页
+func (p *Params) backward() bool {
+	return p.cursor != nil && p.cursor.Prev
+}
+
+// Where 添加过滤条件，不含游标和排序，用于统计总数
+func (p *Params) Where(db *gorm.DB) *gorm.DB {
+	for _, c := range p.filters {
+		db = db.Where(c.sql, c.args...)
+	}
+	return db
+}
+
+// Scope 添加过滤条件、游标位置、排序和分页，多取一条用于判断是否还有下一页
+func (p *Params) Scope(db *gorm.DB) *gorm.DB {
+	db = p.Where(db)
+	if p.cursor != nil {
+		db = db.Where(p.keyset())
+	}
+	backward := p.backward()
+	columns := make([]clause.OrderByColumn, len(p.orders))
+	for i, o := range p.orders {
+		columns[i] = clause.OrderByColumn{
+			Column: clause.Column{Name: o.field.Column, Raw: true},
+			Desc:   o.desc != backward,
+		}
+	}
+	return db.Clauses(clause.OrderBy{Columns: columns}).Limit(p.Limit + 1)
+}
+
+// keyset 游标位置条件：(a > ?) OR (a = ? AND b > ?) ...，向前翻页时比较方向相反
+func (p *Params) keyset() clause.Expr {
+	var ors []string
+	var args []interface{}
+	for i, o := range p.orders {
+		ands := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, p.orders[j].field.Column+" = ?")
+			args = append(args, p.cursor.Values[j])
+		}
+		op := " > ?"
+		if o.desc != p.cursor.Prev {
+			op = " < ?"
+		}
+		ands = append(ands, o.field.Column+op)
+		args = append(args, p.cursor.Values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return clause.Expr{SQL: "(" + strings.Join(ors, " OR ") + ")", Vars: args}
+}
+
+// escapeLike 转义 LIKE 模式中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
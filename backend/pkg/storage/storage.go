@@ -0,0 +1,91 @@
+// Package storage 文件存储
+//
+// 支持本地文件系统（local）和 S3 兼容对象存储（s3，如 AWS S3、MinIO、阿里云 OSS），
+// 由配置项 storage.driver 选择。对象键使用 "/" 分隔的相对路径，如 contracts/<合同ID>/<文件ID>.pdf。
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("存储对象不存在")
+
+// Storage 文件存储驱动
+type Storage interface {
+	// Name 驱动名称，用于日志
+	Name() string
+	// Put 写入对象，size 为内容长度，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	mu      sync.RWMutex
+	current Storage
+)
+
+// Setup 按配置创建默认存储驱动
+func Setup() error {
+	s, err := FromConfig()
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	current = s
+	return nil
+}
+
+// Default 默认存储驱动，未调用 Setup 时按配置创建
+func Default() (Storage, error) {
+	mu.RLock()
+	s := current
+	mu.RUnlock()
+	if s != nil {
+		return s, nil
+	}
+	if err := Setup(); err != nil {
+		return nil, err
+	}
+	return Default()
+}
+
+// FromConfig 按配置项 storage.driver 创建存储驱动
+func FromConfig() (Storage, error) {
+	switch driver := viper.GetString("storage.driver"); driver {
+	case "", "local":
+		return NewLocal(viper.GetString("upload.storage_path"))
+	case "s3":
+		return NewS3(S3Config{
+			Endpoint:  viper.GetString("storage.s3.endpoint"),
+			Region:    viper.GetString("storage.s3.region"),
+			Bucket:    viper.GetString("storage.s3.bucket"),
+			AccessKey: viper.GetString("storage.s3.access_key"),
+			SecretKey: viper.GetString("storage.s3.secret_key"),
+			PathStyle: viper.GetBool("storage.s3.path_style"),
+		})
+	default:
+		return nil, fmt.Errorf("不支持的存储驱动: %s", driver)
+	}
+}
+
+// cleanKey 校验对象键：不能为空、不能是绝对路径、不能包含 ".." 路径段
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean(key)
+	if key == "" || strings.HasPrefix(key, "/") || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("无效的存储对象键: %q", key)
+	}
+	return cleaned, nil
+}
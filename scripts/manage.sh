@@ -130,7 +130,7 @@ start_backend() {
     fi
     
     # 启动后端服务并记录PID
-    nohup go run cmd/main.go > "$BACKEND_LOG_FILE" 2>&1 &
+    nohup go run ./cmd > "$BACKEND_LOG_FILE" 2>&1 &
     local pid=$!
     echo $pid > "$BACKEND_PID_FILE"
     
@@ -396,7 +396,7 @@ migrate_database() {
     print_color $YELLOW "运行数据库迁移..."
     
     cd "$PROJECT_ROOT/backend"
-    go run cmd/migrate.go up
+    go run ./cmd migrate up
     
     if [ $? -eq 0 ]; then
         print_color $GREEN "✓ 数据库迁移完成"